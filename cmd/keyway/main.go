@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/cmd"
 )
 
@@ -13,6 +14,7 @@ var version = "dev"
 func main() {
 	// Set version for analytics
 	analytics.SetVersion(version)
+	api.SetVersion(version)
 
 	// Ensure analytics are flushed on exit
 	defer analytics.Shutdown()
@@ -0,0 +1,106 @@
+// Package activity keeps an opt-in local log of the environments injected on this machine.
+package activity
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// Actions recorded in the log
+const (
+	ActionRun  = "run"
+	ActionPull = "pull"
+)
+
+// Entry is one recorded injection. It never contains secret values.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Repository  string    `json:"repository"`
+	Environment string    `json:"environment"`
+	VaultDigest string    `json:"vaultDigest"`       // sha256 of the pulled environment content
+	Command     string    `json:"command,omitempty"` // Command line, for run
+	File        string    `json:"file,omitempty"`    // Written file, for pull
+	Keys        []string  `json:"keys"`
+}
+
+// IsEnabled returns true if the activity log is enabled via KEYWAY_ACTIVITY_LOG
+func IsEnabled() bool {
+	val := os.Getenv("KEYWAY_ACTIVITY_LOG")
+	return val == "1" || val == "true"
+}
+
+// DefaultPath returns the path of the activity log file
+func DefaultPath() (string, error) {
+	return state.Path("activity.jsonl")
+}
+
+// Log is an append-only JSON lines file of entries
+type Log struct {
+	path string
+}
+
+// NewLog creates a log backed by the given file
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append adds an entry to the log, creating the file with owner-only permissions.
+// Concurrent keyway processes are serialized by the state lock.
+func (l *Log) Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return state.Append(l.path, append(data, '\n'), 0600)
+}
+
+// Since returns the entries recorded at or after t, oldest first.
+// A missing log is not an error; malformed lines are skipped.
+func (l *Log) Since(t time.Time) ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !entry.Time.Before(t) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// ParseSince parses a lookback duration such as 90m, 24h or 7d
+func ParseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 24h, 7d)", s)
+	}
+	return d, nil
+}
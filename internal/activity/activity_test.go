@@ -0,0 +1,90 @@
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyway", "activity.jsonl")
+	log := NewLog(path)
+	now := time.Now().UTC()
+
+	old := Entry{Time: now.Add(-48 * time.Hour), Action: ActionRun, Environment: "staging"}
+	recent := Entry{Time: now.Add(-time.Hour), Action: ActionRun, Environment: "production", Keys: []string{"API_KEY"}}
+	for _, e := range []Entry{old, recent} {
+		if err := log.Append(e); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+
+	entries, err := log.Since(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Environment != "production" || entries[0].Keys[0] != "API_KEY" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLog_SinceMissingFile(t *testing.T) {
+	entries, err := NewLog(filepath.Join(t.TempDir(), "none.jsonl")).Since(time.Time{})
+	if err != nil || entries != nil {
+		t.Errorf("expected no entries and no error, got %v, %v", entries, err)
+	}
+}
+
+func TestLog_SinceSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	os.WriteFile(path, []byte("not json\n{\"time\":\"2024-01-01T00:00:00Z\",\"action\":\"pull\"}\n"), 0600)
+
+	entries, err := NewLog(path).Since(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != ActionPull {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"24h", 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"xd", 0, true},
+		{"soon", 0, true},
+		{"-1h", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSince(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestIsEnabled(t *testing.T) {
+	t.Setenv("KEYWAY_ACTIVITY_LOG", "")
+	if IsEnabled() {
+		t.Error("expected disabled by default")
+	}
+	t.Setenv("KEYWAY_ACTIVITY_LOG", "1")
+	if !IsEnabled() {
+		t.Error("expected enabled")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/keywaysh/cli/internal/background"
 	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/state"
 	"github.com/posthog/posthog-go"
 )
 
@@ -23,6 +25,7 @@ const (
 	EventDiff   = "cli_diff"
 	EventDoctor = "cli_doctor"
 	EventScan   = "cli_scan"
+	EventStats  = "cli_stats"
 
 	// Provider integration
 	EventConnect    = "cli_connect"
@@ -91,16 +94,19 @@ func getDistinctID() string {
 		}
 	}
 
-	// Create new ID
-	distinctID = uuid.New().String()
-
-	// Persist it
+	// Create and persist a new ID, unless a parallel keyway process just did
+	candidate := uuid.New().String()
 	if err := os.MkdirAll(configDir, 0700); err == nil {
-		cfg := idConfig{DistinctID: distinctID}
-		if data, err := json.MarshalIndent(cfg, "", "  "); err == nil {
-			_ = os.WriteFile(idFile, data, 0600)
-		}
+		_ = state.Update(idFile, 0600, func(current []byte) ([]byte, error) {
+			var cfg idConfig
+			if json.Unmarshal(current, &cfg) == nil && cfg.DistinctID != "" {
+				candidate = cfg.DistinctID
+				return current, nil
+			}
+			return json.MarshalIndent(idConfig{DistinctID: candidate}, "", "  ")
+		})
 	}
+	distinctID = candidate
 
 	return distinctID
 }
@@ -112,13 +118,14 @@ func initClient() {
 	}
 
 	apiKey := config.GetPostHogKey()
-	if apiKey == "" {
+	if apiKey == "" || !background.Enabled() {
 		return
 	}
 
 	var err error
 	client, err = posthog.NewWithConfig(apiKey, posthog.Config{
-		Endpoint: config.GetPostHogHost(),
+		Endpoint:  config.GetPostHogHost(),
+		Transport: background.NewClient().Transport,
 	})
 	if err != nil {
 		client = nil
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeReadSecrets  = "read:secrets"
+	ScopeWriteSecrets = "write:secrets"
+)
+
+// APIKeyScopes lists the scopes an API key can be given
+var APIKeyScopes = []string{ScopeReadSecrets, ScopeWriteSecrets}
+
+// APIKey is a machine token for CI and other non-interactive use, passed in
+// KEYWAY_TOKEN
+type APIKey struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Repositories restricts the key to these vaults, empty means all
+	Repositories []string   `json:"repositories,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"`
+	// Token is the key itself; only returned on creation
+	Token string `json:"token,omitempty"`
+}
+
+// CreateAPIKeyRequest describes an API key to create
+type CreateAPIKeyRequest struct {
+	Name         string     `json:"name"`
+	Scopes       []string   `json:"scopes"`
+	Repositories []string   `json:"repositories,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CreateAPIKey mints an API key for the signed-in user. The returned key holds its token.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error) {
+	var wrapper struct {
+		Data APIKey `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/api-keys", req, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Data, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_CreateAPIKey(t *testing.T) {
+	expiresAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/api-keys" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body CreateAPIKeyRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Name != "ci" || body.Scopes[0] != ScopeReadSecrets || body.Repositories[0] != "owner/repo" || !body.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("unexpected body: %+v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": "key_1", "name": body.Name, "scopes": body.Scopes, "token": "kw_abc"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	key, err := client.CreateAPIKey(context.Background(), CreateAPIKeyRequest{
+		Name:         "ci",
+		Scopes:       []string{ScopeReadSecrets},
+		Repositories: []string{"owner/repo"},
+		ExpiresAt:    &expiresAt,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.ID != "key_1" || key.Token != "kw_abc" {
+		t.Errorf("unexpected key: %+v", key)
+	}
+}
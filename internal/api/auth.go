@@ -21,7 +21,7 @@ type DeviceStartResponse struct {
 
 // DevicePollResponse is the response from polling device login
 type DevicePollResponse struct {
-	Status      string `json:"status"` // pending, approved, expired, denied
+	Status      string `json:"status"` // pending, approved, expired, denied, slow_down
 	KeywayToken string `json:"keywayToken,omitempty"`
 	GitHubLogin string `json:"githubLogin,omitempty"`
 	ExpiresAt   string `json:"expiresAt,omitempty"`
@@ -142,6 +142,28 @@ func (c *Client) PollDeviceLogin(ctx context.Context, deviceCode string) (*Devic
 	return &resp, err
 }
 
+// OIDCExchangeResponse is the response from exchanging a CI identity token
+type OIDCExchangeResponse struct {
+	KeywayToken string `json:"keywayToken"`
+	Subject     string `json:"subject,omitempty"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+}
+
+// ExchangeOIDCToken trades an identity token issued by a CI provider
+// (github-actions, gitlab) for a short-lived Keyway token
+func (c *Client) ExchangeOIDCToken(ctx context.Context, provider, idToken string) (*OIDCExchangeResponse, error) {
+	body := map[string]string{
+		"provider": provider,
+		"idToken":  idToken,
+	}
+
+	var wrapper struct {
+		Data OIDCExchangeResponse `json:"data"`
+	}
+	err := c.do(ctx, "POST", "/v1/auth/oidc/exchange", body, &wrapper)
+	return &wrapper.Data, err
+}
+
 // ValidateToken validates the current token
 func (c *Client) ValidateToken(ctx context.Context) (*ValidateTokenResponse, error) {
 	var wrapper struct {
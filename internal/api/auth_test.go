@@ -277,3 +277,32 @@ func TestClient_CheckGitHubAppInstallation_NotInstalled(t *testing.T) {
 		t.Errorf("expected installUrl, got '%s'", status.InstallURL)
 	}
 }
+
+func TestClient_ExchangeOIDCToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/auth/oidc/exchange" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["provider"] != "github-actions" || body["idToken"] != "id-token" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"keywayToken": "kw_token", "subject": "repo:owner/repo:ref:refs/heads/main"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.baseURL = server.URL
+
+	resp, err := client.ExchangeOIDCToken(context.Background(), "github-actions", "id-token")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.KeywayToken != "kw_token" || resp.Subject == "" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
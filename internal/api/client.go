@@ -5,8 +5,10 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -15,16 +17,21 @@ import (
 	"github.com/keywaysh/cli/internal/config"
 )
 
-const (
-	defaultTimeout = 30 * time.Second
-)
-
 // Client is the Keyway API client
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	token      string
 	userAgent  string
+	version    string
+}
+
+// clientVersion is the CLI version sent to the API, set at startup with SetVersion
+var clientVersion = "dev"
+
+// SetVersion sets the CLI version of the clients created afterwards
+func SetVersion(v string) {
+	clientVersion = v
 }
 
 // TrialEligibility contains trial information for org repos
@@ -35,46 +42,50 @@ type TrialEligibility struct {
 	Reason        string `json:"reason,omitempty"`
 }
 
-// APIError represents an error from the API (RFC 7807)
-type APIError struct {
-	StatusCode int               `json:"-"`
-	Type       string            `json:"type,omitempty"`
-	Title      string            `json:"title,omitempty"`
-	Detail     string            `json:"detail,omitempty"`
-	UpgradeURL string            `json:"upgradeUrl,omitempty"`
-	TrialInfo  *TrialEligibility `json:"trialInfo,omitempty"`
-}
-
-func (e *APIError) Error() string {
-	if e.Detail != "" {
-		return e.Detail
-	}
-	if e.Title != "" {
-		return e.Title
-	}
-	return fmt.Sprintf("HTTP %d", e.StatusCode)
-}
-
-// NewClient creates a new API client
+// NewClient creates a new API client.
+// Timeouts default to config.DefaultAPITimeouts and can be set with KEYWAY_*_TIMEOUT.
+// KEYWAY_HTTP3=1 sends requests over HTTP/3, falling back to HTTP/2.
 func NewClient(token string) *Client {
-	httpClient := &http.Client{
-		Timeout: defaultTimeout,
-	}
+	timeouts, _ := config.DefaultAPITimeouts.ApplyEnv()
+	httpClient := NewHTTPClient(timeouts)
 
 	// Allow insecure TLS for local development (self-signed certs)
+	var tlsConfig *tls.Config
 	if os.Getenv("KEYWAY_INSECURE") == "1" {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: true,
 		}
+		httpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	}
+
+	// Experimental: QUIC saves round trips on lossy, high-latency links
+	if config.IsHTTP3Enabled() {
+		httpClient.Transport = newHTTP3Transport(httpClient.Transport, timeouts, tlsConfig)
 	}
 
 	return &Client{
 		baseURL:    config.GetAPIURL(),
 		httpClient: httpClient,
 		token:      token,
-		userAgent:  "keyway-cli/dev", // Will be set properly at build time
+		userAgent:  "keyway-cli/" + clientVersion,
+		version:    clientVersion,
+	}
+}
+
+// NewHTTPClient returns an HTTP client with separate connect, TLS handshake,
+// response header and total timeouts
+func NewHTTPClient(timeouts config.HTTPTimeouts) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   timeouts.Connect,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+	transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeouts.Total,
 	}
 }
 
@@ -82,6 +93,7 @@ func NewClient(token string) *Client {
 func NewClientWithVersion(token, version string) *Client {
 	c := NewClient(token)
 	c.userAgent = fmt.Sprintf("keyway-cli/%s", version)
+	c.version = version
 	return c
 }
 
@@ -123,21 +135,37 @@ func (c *Client) do(ctx context.Context, method, path string, body, result inter
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// Checked first: an outdated client may not understand the response at all
+	minimum := resp.Header.Get(MinClientVersionHeader)
+	if resp.StatusCode == http.StatusUpgradeRequired || versionBelow(c.version, minimum) {
+		return &UpgradeRequiredError{Current: c.version, Minimum: minimum}
+	}
+
 	if resp.StatusCode >= 400 {
 		var apiErr APIError
 		if err := json.Unmarshal(respBody, &apiErr); err != nil {
-			return &APIError{
-				StatusCode: resp.StatusCode,
-				Detail:     string(respBody),
-			}
+			apiErr = APIError{Detail: string(respBody)}
 		}
 		apiErr.StatusCode = resp.StatusCode
+		apiErr.Resource = resourceKind(path)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		apiErr.Method = method
+		apiErr.Path = strings.SplitN(path, "?", 2)[0]
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = resp.Header.Get("X-Request-Id")
+		}
 		return &apiErr
 	}
 
+	// Unknown fields are ignored so older clients keep working as the API grows
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return &ResponseError{
+				Method: method,
+				Path:   strings.SplitN(path, "?", 2)[0],
+				HTML:   bytes.HasPrefix(bytes.TrimSpace(respBody), []byte("<")),
+				Err:    err,
+			}
 		}
 	}
 
@@ -146,19 +174,22 @@ func (c *Client) do(ctx context.Context, method, path string, body, result inter
 
 // handleNetworkError converts network errors to user-friendly messages
 func (c *Client) handleNetworkError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return &NetworkError{Message: "request cancelled", Err: err, Permanent: true}
+	}
 	if os.IsTimeout(err) {
-		return fmt.Errorf("connection timed out - check your network connection")
+		return &NetworkError{Message: "connection timed out - check your network connection or raise KEYWAY_HTTP_TIMEOUT", Err: err}
 	}
 	// Check for common network errors
 	errStr := err.Error()
 	if strings.Contains(errStr, "no such host") {
-		return fmt.Errorf("DNS lookup failed - check your internet connection")
+		return &NetworkError{Message: "DNS lookup failed - check your internet connection", Err: err}
 	}
 	if strings.Contains(errStr, "connection refused") {
-		return fmt.Errorf("connection refused - is the API server running?")
+		return &NetworkError{Message: "connection refused - is the API server running?", Err: err}
 	}
 	if strings.Contains(errStr, "certificate") {
-		return fmt.Errorf("SSL certificate error - check your system time")
+		return &NetworkError{Message: "SSL certificate error - check your system time", Err: err, Permanent: true}
 	}
-	return fmt.Errorf("network error: %w", err)
+	return &NetworkError{Message: fmt.Sprintf("network error: %s", err.Error()), Err: err}
 }
@@ -3,10 +3,14 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/keywaysh/cli/internal/config"
 )
 
 func TestNewClient(t *testing.T) {
@@ -66,6 +70,11 @@ func TestAPIError_Error(t *testing.T) {
 			err:      APIError{Detail: "detail", Title: "title", StatusCode: 500},
 			expected: "detail",
 		},
+		{
+			name:     "with request ID",
+			err:      APIError{Detail: "Vault not found", RequestID: "req_123"},
+			expected: "Vault not found (request ID: req_123)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,6 +220,42 @@ func TestClient_do_APIError(t *testing.T) {
 	}
 }
 
+func TestClient_do_APIErrorRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "hdr_456")
+		w.WriteHeader(http.StatusInternalServerError)
+		if r.URL.Path == "/body" {
+			w.Write([]byte(`{"detail":"Boom","requestId":"req_123","instance":"/v1/secrets/pull"}`))
+		} else {
+			w.Write([]byte(`{"detail":"Boom"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	err := client.do(context.Background(), "GET", "/body?keys=API_KEY", nil, nil)
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.RequestID != "req_123" || apiErr.Instance != "/v1/secrets/pull" {
+		t.Errorf("expected the body's request ID and instance, got %q %q", apiErr.RequestID, apiErr.Instance)
+	}
+	if apiErr.Method != "GET" || apiErr.Path != "/body" {
+		t.Errorf("expected the request without its query, got %s %s", apiErr.Method, apiErr.Path)
+	}
+
+	err = client.do(context.Background(), "POST", "/header", nil, nil)
+	if apiErr, _ := AsAPIError(err); apiErr == nil || apiErr.RequestID != "hdr_456" {
+		t.Errorf("expected the X-Request-Id header as fallback, got %v", err)
+	}
+	if err.Error() != "Boom (request ID: hdr_456)" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
+
 func TestClient_do_ContextCancellation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond)
@@ -340,3 +385,155 @@ func TestAPIError_WithTrialInfo(t *testing.T) {
 		t.Errorf("expected 14 days, got %d", err.TrialInfo.DaysAvailable)
 	}
 }
+
+func TestNewHTTPClient(t *testing.T) {
+	client := NewHTTPClient(config.HTTPTimeouts{
+		Connect:        time.Second,
+		TLSHandshake:   2 * time.Second,
+		ResponseHeader: 3 * time.Second,
+		Total:          4 * time.Second,
+	})
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.Transport)
+	}
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("unexpected transport timeouts: tls=%v header=%v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+	if client.Timeout != 4*time.Second {
+		t.Errorf("expected total timeout 4s, got %v", client.Timeout)
+	}
+}
+
+func TestNewClient_TimeoutsFromEnv(t *testing.T) {
+	t.Setenv(config.EnvHTTPTimeout, "90s")
+	t.Setenv(config.EnvResponseTimeout, "20s")
+
+	client := NewClient("token")
+
+	if client.httpClient.Timeout != 90*time.Second {
+		t.Errorf("expected total timeout 90s, got %v", client.httpClient.Timeout)
+	}
+	if rt := client.httpClient.Transport.(*http.Transport).ResponseHeaderTimeout; rt != 20*time.Second {
+		t.Errorf("expected response header timeout 20s, got %v", rt)
+	}
+}
+
+func TestClient_ResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+	client.httpClient = NewHTTPClient(config.HTTPTimeouts{ResponseHeader: 20 * time.Millisecond})
+
+	_, err := client.PullSecrets(context.Background(), "owner/repo", "production")
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || !strings.Contains(netErr.Message, "timed out") {
+		t.Errorf("expected a timeout network error, got %v", err)
+	}
+}
+
+func TestClient_do_MinClientVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(MinClientVersionHeader, "1.5.0")
+		w.Write([]byte(`{"data": {"renamed": true}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithVersion("token", "1.2.3")
+	client.baseURL = server.URL
+
+	err := client.do(context.Background(), "GET", "/v1/vaults", nil, &map[string]interface{}{})
+	var upgradeErr *UpgradeRequiredError
+	if !errors.As(err, &upgradeErr) {
+		t.Fatalf("expected UpgradeRequiredError, got %v", err)
+	}
+	if upgradeErr.Minimum != "1.5.0" || !strings.Contains(err.Error(), "please upgrade the CLI") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Supported and development builds go on
+	for _, v := range []string{"1.5.0", "2.0.0", "dev"} {
+		client := NewClientWithVersion("token", v)
+		client.baseURL = server.URL
+		if err := client.do(context.Background(), "GET", "/v1/vaults", nil, &map[string]interface{}{}); err != nil {
+			t.Errorf("version %s: unexpected error: %v", v, err)
+		}
+	}
+}
+
+func TestClient_do_UpgradeRequiredStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUpgradeRequired)
+	}))
+	defer server.Close()
+
+	client := NewClientWithVersion("token", "dev")
+	client.baseURL = server.URL
+
+	err := client.do(context.Background(), "GET", "/v1/vaults", nil, nil)
+	var upgradeErr *UpgradeRequiredError
+	if !errors.As(err, &upgradeErr) {
+		t.Fatalf("expected UpgradeRequiredError, got %v", err)
+	}
+}
+
+func TestClient_do_UnknownFieldsIgnored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"content": "A=1", "checksum": "abc", "version": {"n": 3}}, "meta": {}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	var result struct {
+		Data PullSecretsResponse `json:"data"`
+	}
+	if err := client.do(context.Background(), "GET", "/v1/secrets/pull", nil, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Data.Content != "A=1" {
+		t.Errorf("unexpected content: %q", result.Data.Content)
+	}
+}
+
+func TestClient_do_ResponseError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"type mismatch", `{"data": {"content": 42}}`, "unexpected response to GET /v1/secrets/pull (json: cannot unmarshal"},
+		{"html", "<html><body>Sign in to the network</body></html>", "unexpected HTML response to GET /v1/secrets/pull"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("token")
+			client.baseURL = server.URL
+
+			var result struct {
+				Data PullSecretsResponse `json:"data"`
+			}
+			err := client.do(context.Background(), "GET", "/v1/secrets/pull?repo=a/b", nil, &result)
+			var respErr *ResponseError
+			if !errors.As(err, &respErr) {
+				t.Fatalf("expected ResponseError, got %v", err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("got %q, want prefix %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
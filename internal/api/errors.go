@@ -0,0 +1,320 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError represents an error from the API (RFC 7807)
+type APIError struct {
+	StatusCode int               `json:"-"`
+	Type       string            `json:"type,omitempty"`
+	Title      string            `json:"title,omitempty"`
+	Detail     string            `json:"detail,omitempty"`
+	Instance   string            `json:"instance,omitempty"`
+	UpgradeURL string            `json:"upgradeUrl,omitempty"`
+	TrialInfo  *TrialEligibility `json:"trialInfo,omitempty"`
+	// RequestID identifies the request in server logs; quote it in support tickets
+	RequestID string `json:"requestId,omitempty"`
+
+	// Resource is the kind of resource the request targeted (e.g. "vault", "shared value")
+	Resource string `json:"-"`
+	// RetryAfter is the delay requested by the server on 429/503, 0 if none
+	RetryAfter time.Duration `json:"-"`
+	// Method and Path are the failed request, for correlation
+	Method string `json:"-"`
+	Path   string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (request ID: %s)", e.Message(), e.RequestID)
+	}
+	return e.Message()
+}
+
+// Message returns the error without the request ID
+func (e *APIError) Message() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	if e.Title != "" {
+		return e.Title
+	}
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// AuthExpired returns true if the token is missing, expired or revoked
+func (e *APIError) AuthExpired() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// Forbidden returns true if the user lacks access or the plan does not allow the action
+func (e *APIError) Forbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+// NotFound returns true if the targeted resource does not exist (see Resource)
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// Conflict returns true if the resource already exists or was modified concurrently
+func (e *APIError) Conflict() bool {
+	return e.StatusCode == http.StatusConflict
+}
+
+// RateLimited returns true if the request was throttled
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Temporary returns true if the same request may succeed when retried
+func (e *APIError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented
+}
+
+// NetworkError is a request that did not get a response (DNS, refused connection, timeout...)
+type NetworkError struct {
+	Message string
+	Err     error
+	// Permanent is set for failures a retry cannot fix (TLS errors, cancelled requests)
+	Permanent bool
+}
+
+func (e *NetworkError) Error() string {
+	return e.Message
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary returns true if the request is worth retrying
+func (e *NetworkError) Temporary() bool {
+	return !e.Permanent
+}
+
+// MinClientVersionHeader is set by the API to the oldest CLI version it supports
+const MinClientVersionHeader = "X-Keyway-Min-Client-Version"
+
+// UpgradeRequiredError is returned when the API no longer supports this CLI version
+type UpgradeRequiredError struct {
+	Current string
+	Minimum string // Empty when the server did not say
+}
+
+func (e *UpgradeRequiredError) Error() string {
+	if e.Minimum != "" {
+		return fmt.Sprintf("keyway %s is no longer supported by the API (%s or newer required), please upgrade the CLI", e.Current, e.Minimum)
+	}
+	return fmt.Sprintf("keyway %s is no longer supported by the API, please upgrade the CLI", e.Current)
+}
+
+// ResponseError is a successful response the client could not decode
+type ResponseError struct {
+	Method string
+	Path   string
+	// HTML is set when the body was a web page, usually from a proxy or captive portal
+	HTML bool
+	Err  error
+}
+
+func (e *ResponseError) Error() string {
+	if e.HTML {
+		return fmt.Sprintf("unexpected HTML response to %s %s, a proxy or captive portal may be intercepting API requests", e.Method, e.Path)
+	}
+	return fmt.Sprintf("unexpected response to %s %s (%s), the API may be newer than this CLI: try upgrading", e.Method, e.Path, e.Err.Error())
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// versionBelow returns true if current is an older release than minimum.
+// Development builds and unparsable versions are never below.
+func versionBelow(current, minimum string) bool {
+	have, want := versionParts(current), versionParts(minimum)
+	if have == nil || want == nil {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if have[i] != want[i] {
+			return have[i] < want[i]
+		}
+	}
+	return false
+}
+
+// versionParts parses major.minor.patch from v1.2.3 or 1.2.3-rc1
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "-+"); idx != -1 {
+		v = v[:idx]
+	}
+	if v == "" {
+		return nil
+	}
+	parts := make([]int, 3)
+	for i, part := range strings.SplitN(v, ".", 3) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		parts[i] = n
+	}
+	return parts
+}
+
+// AsAPIError returns the APIError in err's chain, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsAuthExpired returns true if err is a 401 from the API
+func IsAuthExpired(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.AuthExpired()
+}
+
+// IsNotFound returns true if err is a 404 from the API
+func IsNotFound(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.NotFound()
+}
+
+// IsRateLimited returns true if err is a 429 from the API
+func IsRateLimited(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.RateLimited()
+}
+
+// IsTemporary returns true if err is a retryable API or network error
+func IsTemporary(err error) bool {
+	var temp interface{ Temporary() bool }
+	return errors.As(err, &temp) && temp.Temporary()
+}
+
+// Retry settings for WithRetry
+var (
+	retryAttempts = 3
+	retryMaxWait  = 10 * time.Second
+)
+
+// RetryBaseWait is the backoff before WithRetry's first retry, doubled for
+// each one after it. Tests set it to zero.
+var RetryBaseWait = 500 * time.Millisecond
+
+// SetRetries sets how many times WithRetry retries a failed call; 0 disables retries
+func SetRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	retryAttempts = n + 1
+}
+
+// WithRetry calls fn until it succeeds, fails with a non-temporary error or
+// the attempts are exhausted. Only use it for idempotent requests.
+func WithRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < retryAttempts; attempt++ {
+		if err = fn(); err == nil || !IsTemporary(err) {
+			return err
+		}
+		if attempt == retryAttempts-1 {
+			break
+		}
+
+		wait := retryWait(attempt)
+		if apiErr, ok := AsAPIError(err); ok && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		if wait > retryMaxWait {
+			wait = retryMaxWait
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
+
+// retryWait is the exponential backoff before retry attempt+1, randomized
+// between half and all of it so clients failing together don't retry together
+func retryWait(attempt int) time.Duration {
+	wait := RetryBaseWait << attempt
+	if wait > retryMaxWait {
+		wait = retryMaxWait
+	}
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or HTTP-date form
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// resourceKind names the resource targeted by an API path, for NotFound messages
+func resourceKind(path string) string {
+	segments := strings.Split(strings.Trim(strings.SplitN(path, "?", 2)[0], "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+
+	switch segments[1] {
+	case "vaults":
+		// /v1/vaults/{owner}/{repo}/{sub}
+		if len(segments) >= 5 {
+			switch segments[4] {
+			case "shared":
+				return "shared value"
+			case "activity":
+				return "activity"
+			}
+		}
+		return "vault"
+	case "secrets":
+		return "secrets"
+	case "integrations":
+		if len(segments) >= 3 && segments[2] == "connections" {
+			return "connection"
+		}
+		return "integration"
+	case "orgs":
+		return "organization"
+	case "auth", "github":
+		return "auth"
+	}
+	return ""
+}
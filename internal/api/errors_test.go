@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIError_Classification(t *testing.T) {
+	tests := []struct {
+		status      int
+		temporary   bool
+		rateLimited bool
+		authExpired bool
+		notFound    bool
+	}{
+		{400, false, false, false, false},
+		{401, false, false, true, false},
+		{404, false, false, false, true},
+		{408, true, false, false, false},
+		{429, true, true, false, false},
+		{500, true, false, false, false},
+		{501, false, false, false, false},
+		{503, true, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d", tt.status), func(t *testing.T) {
+			e := &APIError{StatusCode: tt.status}
+			if e.Temporary() != tt.temporary {
+				t.Errorf("Temporary() = %v", e.Temporary())
+			}
+			if e.RateLimited() != tt.rateLimited {
+				t.Errorf("RateLimited() = %v", e.RateLimited())
+			}
+			if e.AuthExpired() != tt.authExpired {
+				t.Errorf("AuthExpired() = %v", e.AuthExpired())
+			}
+			if e.NotFound() != tt.notFound {
+				t.Errorf("NotFound() = %v", e.NotFound())
+			}
+		})
+	}
+}
+
+func TestErrorHelpers_Wrapped(t *testing.T) {
+	err := fmt.Errorf("pull failed: %w", &APIError{StatusCode: 401})
+	if !IsAuthExpired(err) {
+		t.Error("expected IsAuthExpired through wrapping")
+	}
+	if IsNotFound(err) || IsTemporary(err) || IsRateLimited(err) {
+		t.Error("unexpected classification")
+	}
+
+	if !IsTemporary(&NetworkError{Message: "connection refused"}) {
+		t.Error("expected network errors to be temporary")
+	}
+	if IsTemporary(&NetworkError{Message: "cancelled", Permanent: true}) {
+		t.Error("expected permanent network error not to be temporary")
+	}
+	if IsTemporary(errors.New("plain")) {
+		t.Error("plain errors are not temporary")
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	oldWait := RetryBaseWait
+	RetryBaseWait = time.Millisecond
+	defer func() { RetryBaseWait = oldWait }()
+
+	t.Run("retries temporary errors", func(t *testing.T) {
+		calls := 0
+		err := WithRetry(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return &APIError{StatusCode: 503}
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("expected success after 3 calls, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("stops on permanent errors", func(t *testing.T) {
+		calls := 0
+		err := WithRetry(context.Background(), func() error {
+			calls++
+			return &APIError{StatusCode: 403}
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := WithRetry(context.Background(), func() error {
+			calls++
+			return &APIError{StatusCode: 500}
+		})
+		if !IsTemporary(err) || calls != retryAttempts {
+			t.Errorf("expected %d calls, got %d (%v)", retryAttempts, calls, err)
+		}
+	})
+
+	t.Run("SetRetries(0) disables retries", func(t *testing.T) {
+		oldAttempts := retryAttempts
+		defer func() { retryAttempts = oldAttempts }()
+		SetRetries(0)
+
+		calls := 0
+		_ = WithRetry(context.Background(), func() error {
+			calls++
+			return &APIError{StatusCode: 503}
+		})
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+}
+
+func TestRetryWait(t *testing.T) {
+	for attempt := 0; attempt < 8; attempt++ {
+		full := RetryBaseWait << attempt
+		if full > retryMaxWait {
+			full = retryMaxWait
+		}
+		for i := 0; i < 20; i++ {
+			if wait := retryWait(attempt); wait < full/2 || wait > full {
+				t.Fatalf("attempt %d: wait %s outside [%s, %s]", attempt, wait, full/2, full)
+			}
+		}
+	}
+}
+
+func TestClient_do_TypedErrorFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	err := client.do(context.Background(), "GET", "/v1/vaults/owner/repo/shared", nil, nil)
+
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if !apiErr.RateLimited() || apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+	if apiErr.Resource != "shared value" {
+		t.Errorf("expected resource 'shared value', got %q", apiErr.Resource)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("30"); got != 30*time.Second {
+		t.Errorf("expected 30s, got %v", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("unexpected duration for HTTP date: %v", got)
+	}
+}
+
+func TestResourceKind(t *testing.T) {
+	tests := map[string]string{
+		"/v1/vaults/acme/api":                   "vault",
+		"/v1/vaults/acme/shared":                "vault",
+		"/v1/vaults/acme/api/shared/DSN":        "shared value",
+		"/v1/vaults/acme/api/activity":          "activity",
+		"/v1/secrets/pull?repo=acme/api":        "secrets",
+		"/v1/integrations/connections/abc":      "connection",
+		"/v1/integrations/vaults/acme/api/sync": "integration",
+		"/v1/orgs/acme":                         "organization",
+		"/v1/auth/device/poll":                  "auth",
+		"/health":                               "",
+	}
+	for path, want := range tests {
+		if got := resourceKind(path); got != want {
+			t.Errorf("resourceKind(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestVersionBelow(t *testing.T) {
+	tests := []struct {
+		current, minimum string
+		want             bool
+	}{
+		{"1.2.3", "1.5.0", true},
+		{"v1.2.3", "1.2.4", true},
+		{"1.5.0", "1.5.0", false},
+		{"1.10.0", "1.9.9", false},
+		{"1.5.0-rc1", "1.5.0", false},
+		{"dev", "1.5.0", false},
+		{"1.2.3", "", false},
+	}
+	for _, tt := range tests {
+		if got := versionBelow(tt.current, tt.minimum); got != tt.want {
+			t.Errorf("versionBelow(%q, %q) = %v, want %v", tt.current, tt.minimum, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// fallbackTransport sends requests over HTTP/3 and switches to the HTTP/2
+// transport for the rest of the process once a QUIC connection cannot be
+// established, e.g. on networks blocking UDP. A request is only resent when
+// its connection failed, so it never reaches the API twice.
+type fallbackTransport struct {
+	h3       http.RoundTripper
+	fallback http.RoundTripper
+	failed   atomic.Bool
+}
+
+// quicDialError marks errors establishing a QUIC connection
+type quicDialError struct {
+	err error
+}
+
+func (e *quicDialError) Error() string { return e.err.Error() }
+func (e *quicDialError) Unwrap() error { return e.err }
+
+// newHTTP3Transport returns an HTTP/3 transport falling back to fallback.
+// tlsConfig may be nil.
+func newHTTP3Transport(fallback http.RoundTripper, timeouts config.HTTPTimeouts, tlsConfig *tls.Config) *fallbackTransport {
+	h3 := &http3.Transport{
+		TLSClientConfig: tlsConfig,
+		QUICConfig:      &quic.Config{HandshakeIdleTimeout: timeouts.TLSHandshake},
+		// A full handshake instead of 0-RTT, whose early data can be replayed
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+			conn, err := quic.DialAddr(ctx, addr, tlsCfg, cfg)
+			if err != nil {
+				return nil, &quicDialError{err: err}
+			}
+			return conn, nil
+		},
+	}
+	return &fallbackTransport{h3: h3, fallback: fallback}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// HTTP/3 only exists over TLS, KEYWAY_API_URL may point to a plain HTTP server
+	if t.failed.Load() || req.URL.Scheme != "https" {
+		return t.fallback.RoundTrip(req)
+	}
+
+	resp, err := t.h3.RoundTrip(req)
+	var dialErr *quicDialError
+	if err == nil || !errors.As(err, &dialErr) {
+		return resp, err
+	}
+
+	t.failed.Store(true)
+	if req.Body != nil && req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return t.fallback.RoundTrip(req)
+}
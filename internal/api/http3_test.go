@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/config"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestFallbackTransport_FallsBackOnDialError(t *testing.T) {
+	var bodies []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	h3Calls := 0
+	transport := &fallbackTransport{
+		h3: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			h3Calls++
+			return nil, &quicDialError{err: errors.New("timeout: no recent network activity")}
+		}),
+		fallback: server.Client().Transport,
+	}
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if h3Calls != 1 {
+		t.Errorf("expected HTTP/3 tried once, got %d", h3Calls)
+	}
+	if len(bodies) != 2 || bodies[0] != "payload" {
+		t.Errorf("expected both requests sent with their body, got %v", bodies)
+	}
+}
+
+func TestFallbackTransport_KeepsOtherErrors(t *testing.T) {
+	fallbackCalls := 0
+	transport := &fallbackTransport{
+		h3: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("stream reset")
+		}),
+		fallback: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fallbackCalls++
+			return nil, errors.New("unexpected")
+		}),
+	}
+
+	req, _ := http.NewRequest("POST", "https://api.keyway.sh/v1/vaults", strings.NewReader("payload"))
+	if _, err := transport.RoundTrip(req); err == nil || err.Error() != "stream reset" {
+		t.Errorf("expected the HTTP/3 error, got %v", err)
+	}
+	if fallbackCalls != 0 {
+		t.Error("expected a request that may have been sent not to be resent")
+	}
+}
+
+func TestFallbackTransport_PlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	transport := newHTTP3Transport(http.DefaultTransport, config.DefaultAPITimeouts, nil)
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+}
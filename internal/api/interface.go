@@ -1,6 +1,9 @@
 package api
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // APIClient defines the interface for the Keyway API client
 // This interface enables mocking in tests
@@ -8,6 +11,7 @@ type APIClient interface {
 	// Auth methods
 	StartDeviceLogin(ctx context.Context, repository string, repoIds *RepoIds) (*DeviceStartResponse, error)
 	PollDeviceLogin(ctx context.Context, deviceCode string) (*DevicePollResponse, error)
+	ExchangeOIDCToken(ctx context.Context, provider, idToken string) (*OIDCExchangeResponse, error)
 	ValidateToken(ctx context.Context) (*ValidateTokenResponse, error)
 	CheckGitHubAppInstallation(ctx context.Context, repoOwner, repoName string) (*GitHubAppInstallationStatus, error)
 	GetRepoIdsFromBackend(ctx context.Context, repoFullName string) (*RepoIds, error)
@@ -17,13 +21,37 @@ type APIClient interface {
 	CheckVaultExists(ctx context.Context, repoFullName string) (bool, error)
 	GetVaultDetails(ctx context.Context, repoFullName string) (*VaultDetails, error)
 	GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error)
+	CreateEnvironment(ctx context.Context, repoFullName, name string) error
+	DeleteEnvironment(ctx context.Context, repoFullName, name string) error
+	GetEnvironmentAccess(ctx context.Context, repoFullName string) ([]EnvironmentAccess, error)
+	GetVaultActivity(ctx context.Context, repoFullName string) (*VaultActivity, error)
+	GetPublicVault(ctx context.Context, repoFullName string) (*PublicVault, error)
+	TransferVault(ctx context.Context, fromRepo, toRepo string) error
+
+	// Webhook methods
+	ListWebhooks(ctx context.Context, repoFullName string) ([]Webhook, error)
+	CreateWebhook(ctx context.Context, repoFullName string, req CreateWebhookRequest) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, repoFullName, id string) error
+	TestWebhook(ctx context.Context, repoFullName, id string) (*WebhookDelivery, error)
+
+	// API key methods
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error)
 
 	// Org methods
 	StartOrganizationTrial(ctx context.Context, orgLogin string) (*StartTrialResponse, error)
 
 	// Secrets methods
 	PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
-	PullSecrets(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PatchSecrets(ctx context.Context, repo, env string, patch SecretsPatch) (*PushSecretsResponse, error)
+	PullSecrets(ctx context.Context, repo, env string, keys ...string) (*PullSecretsResponse, error)
+	PullSecretsSnapshot(ctx context.Context, repo, env string, ref SnapshotRef) (*PullSecretsResponse, error)
+	GetSecretsMetadata(ctx context.Context, repo, env string) ([]KeyMetadata, error)
+	SetSecretExpiry(ctx context.Context, repo, env, key string, expiresAt *time.Time) error
+
+	// Shared value methods
+	GetSharedValues(ctx context.Context, repoFullName string) (map[string]string, error)
+	SetSharedValue(ctx context.Context, repoFullName, name, value string) error
+	DeleteSharedValue(ctx context.Context, repoFullName, name string) error
 
 	// Provider methods
 	GetProviders(ctx context.Context) ([]Provider, error)
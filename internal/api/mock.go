@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // MockClient is a mock implementation of APIClient for testing
@@ -10,6 +11,7 @@ type MockClient struct {
 	// Auth mocks
 	StartDeviceLoginFn           func(ctx context.Context, repository string, repoIds *RepoIds) (*DeviceStartResponse, error)
 	PollDeviceLoginFn            func(ctx context.Context, deviceCode string) (*DevicePollResponse, error)
+	ExchangeOIDCTokenFn          func(ctx context.Context, provider, idToken string) (*OIDCExchangeResponse, error)
 	ValidateTokenFn              func(ctx context.Context) (*ValidateTokenResponse, error)
 	CheckGitHubAppInstallationFn func(ctx context.Context, repoOwner, repoName string) (*GitHubAppInstallationStatus, error)
 	GetRepoIdsFromBackendFn      func(ctx context.Context, repoFullName string) (*RepoIds, error)
@@ -19,10 +21,34 @@ type MockClient struct {
 	CheckVaultExistsFn     func(ctx context.Context, repoFullName string) (bool, error)
 	GetVaultDetailsFn      func(ctx context.Context, repoFullName string) (*VaultDetails, error)
 	GetVaultEnvironmentsFn func(ctx context.Context, repoFullName string) ([]string, error)
+	GetVaultActivityFn     func(ctx context.Context, repoFullName string) (*VaultActivity, error)
+	GetPublicVaultFn       func(ctx context.Context, repoFullName string) (*PublicVault, error)
+	TransferVaultFn        func(ctx context.Context, fromRepo, toRepo string) error
+	CreateEnvironmentFn    func(ctx context.Context, repoFullName, name string) error
+	DeleteEnvironmentFn    func(ctx context.Context, repoFullName, name string) error
+	GetEnvironmentAccessFn func(ctx context.Context, repoFullName string) ([]EnvironmentAccess, error)
+
+	// Webhook mocks
+	ListWebhooksFn  func(ctx context.Context, repoFullName string) ([]Webhook, error)
+	CreateWebhookFn func(ctx context.Context, repoFullName string, req CreateWebhookRequest) (*Webhook, error)
+	DeleteWebhookFn func(ctx context.Context, repoFullName, id string) error
+	TestWebhookFn   func(ctx context.Context, repoFullName, id string) (*WebhookDelivery, error)
+
+	// API key mocks
+	CreateAPIKeyFn func(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error)
 
 	// Secrets mocks
-	PushSecretsFn func(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
-	PullSecretsFn func(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PushSecretsFn        func(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error)
+	PatchSecretsFn       func(ctx context.Context, repo, env string, patch SecretsPatch) (*PushSecretsResponse, error)
+	PullSecretsFn        func(ctx context.Context, repo, env string) (*PullSecretsResponse, error)
+	PullSecretsSnapshotFn func(ctx context.Context, repo, env string, ref SnapshotRef) (*PullSecretsResponse, error)
+	GetSecretsMetadataFn func(ctx context.Context, repo, env string) ([]KeyMetadata, error)
+	SetSecretExpiryFn    func(ctx context.Context, repo, env, key string, expiresAt *time.Time) error
+
+	// Shared value mocks
+	GetSharedValuesFn   func(ctx context.Context, repoFullName string) (map[string]string, error)
+	SetSharedValueFn    func(ctx context.Context, repoFullName, name, value string) error
+	DeleteSharedValueFn func(ctx context.Context, repoFullName, name string) error
 
 	// Provider mocks
 	GetProvidersFn           func(ctx context.Context) ([]Provider, error)
@@ -92,6 +118,14 @@ func (m *MockClient) PollDeviceLogin(ctx context.Context, deviceCode string) (*D
 	}, nil
 }
 
+func (m *MockClient) ExchangeOIDCToken(ctx context.Context, provider, idToken string) (*OIDCExchangeResponse, error) {
+	m.track("ExchangeOIDCToken")
+	if m.ExchangeOIDCTokenFn != nil {
+		return m.ExchangeOIDCTokenFn(ctx, provider, idToken)
+	}
+	return &OIDCExchangeResponse{KeywayToken: "test-keyway-token"}, nil
+}
+
 func (m *MockClient) ValidateToken(ctx context.Context) (*ValidateTokenResponse, error) {
 	m.track("ValidateToken")
 	if m.ValidateTokenFn != nil {
@@ -155,6 +189,54 @@ func (m *MockClient) GetVaultEnvironments(ctx context.Context, repoFullName stri
 	return []string{"production", "staging", "development"}, nil
 }
 
+func (m *MockClient) GetVaultActivity(ctx context.Context, repoFullName string) (*VaultActivity, error) {
+	m.track("GetVaultActivity")
+	if m.GetVaultActivityFn != nil {
+		return m.GetVaultActivityFn(ctx, repoFullName)
+	}
+	return nil, nil
+}
+
+func (m *MockClient) TransferVault(ctx context.Context, fromRepo, toRepo string) error {
+	m.track("TransferVault")
+	if m.TransferVaultFn != nil {
+		return m.TransferVaultFn(ctx, fromRepo, toRepo)
+	}
+	return nil
+}
+
+func (m *MockClient) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	m.track("CreateEnvironment")
+	if m.CreateEnvironmentFn != nil {
+		return m.CreateEnvironmentFn(ctx, repoFullName, name)
+	}
+	return nil
+}
+
+func (m *MockClient) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	m.track("DeleteEnvironment")
+	if m.DeleteEnvironmentFn != nil {
+		return m.DeleteEnvironmentFn(ctx, repoFullName, name)
+	}
+	return nil
+}
+
+func (m *MockClient) GetEnvironmentAccess(ctx context.Context, repoFullName string) ([]EnvironmentAccess, error) {
+	m.track("GetEnvironmentAccess")
+	if m.GetEnvironmentAccessFn != nil {
+		return m.GetEnvironmentAccessFn(ctx, repoFullName)
+	}
+	return nil, nil
+}
+
+func (m *MockClient) GetPublicVault(ctx context.Context, repoFullName string) (*PublicVault, error) {
+	m.track("GetPublicVault")
+	if m.GetPublicVaultFn != nil {
+		return m.GetPublicVaultFn(ctx, repoFullName)
+	}
+	return nil, nil
+}
+
 // Secrets methods
 func (m *MockClient) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*PushSecretsResponse, error) {
 	m.track("PushSecrets")
@@ -176,7 +258,15 @@ func (m *MockClient) PushSecrets(ctx context.Context, repo, env string, secrets
 	}, nil
 }
 
-func (m *MockClient) PullSecrets(ctx context.Context, repo, env string) (*PullSecretsResponse, error) {
+func (m *MockClient) PatchSecrets(ctx context.Context, repo, env string, patch SecretsPatch) (*PushSecretsResponse, error) {
+	m.track("PatchSecrets")
+	if m.PatchSecretsFn != nil {
+		return m.PatchSecretsFn(ctx, repo, env, patch)
+	}
+	return &PushSecretsResponse{Success: true, Message: fmt.Sprintf("Updated %d secrets in %s/%s", len(patch.Set)+len(patch.Delete), repo, env)}, nil
+}
+
+func (m *MockClient) PullSecrets(ctx context.Context, repo, env string, keys ...string) (*PullSecretsResponse, error) {
 	m.track("PullSecrets")
 	if m.PullSecretsFn != nil {
 		return m.PullSecretsFn(ctx, repo, env)
@@ -186,6 +276,99 @@ func (m *MockClient) PullSecrets(ctx context.Context, repo, env string) (*PullSe
 	}, nil
 }
 
+func (m *MockClient) PullSecretsSnapshot(ctx context.Context, repo, env string, ref SnapshotRef) (*PullSecretsResponse, error) {
+	m.track("PullSecretsSnapshot")
+	if m.PullSecretsSnapshotFn != nil {
+		return m.PullSecretsSnapshotFn(ctx, repo, env, ref)
+	}
+	return &PullSecretsResponse{
+		Content: "API_KEY=old-api-key\nDB_HOST=localhost\n",
+	}, nil
+}
+
+func (m *MockClient) GetSecretsMetadata(ctx context.Context, repo, env string) ([]KeyMetadata, error) {
+	m.track("GetSecretsMetadata")
+	if m.GetSecretsMetadataFn != nil {
+		return m.GetSecretsMetadataFn(ctx, repo, env)
+	}
+	return nil, nil
+}
+
+func (m *MockClient) SetSecretExpiry(ctx context.Context, repo, env, key string, expiresAt *time.Time) error {
+	m.track("SetSecretExpiry")
+	if m.SetSecretExpiryFn != nil {
+		return m.SetSecretExpiryFn(ctx, repo, env, key, expiresAt)
+	}
+	return nil
+}
+
+// Webhook methods
+func (m *MockClient) ListWebhooks(ctx context.Context, repoFullName string) ([]Webhook, error) {
+	m.track("ListWebhooks")
+	if m.ListWebhooksFn != nil {
+		return m.ListWebhooksFn(ctx, repoFullName)
+	}
+	return []Webhook{}, nil
+}
+
+func (m *MockClient) CreateWebhook(ctx context.Context, repoFullName string, req CreateWebhookRequest) (*Webhook, error) {
+	m.track("CreateWebhook")
+	if m.CreateWebhookFn != nil {
+		return m.CreateWebhookFn(ctx, repoFullName, req)
+	}
+	return &Webhook{ID: "wh_test", URL: req.URL, Events: req.Events, Environments: req.Environments, Secret: "whsec_test"}, nil
+}
+
+func (m *MockClient) DeleteWebhook(ctx context.Context, repoFullName, id string) error {
+	m.track("DeleteWebhook")
+	if m.DeleteWebhookFn != nil {
+		return m.DeleteWebhookFn(ctx, repoFullName, id)
+	}
+	return nil
+}
+
+func (m *MockClient) TestWebhook(ctx context.Context, repoFullName, id string) (*WebhookDelivery, error) {
+	m.track("TestWebhook")
+	if m.TestWebhookFn != nil {
+		return m.TestWebhookFn(ctx, repoFullName, id)
+	}
+	return &WebhookDelivery{StatusCode: 200, DurationMs: 42}, nil
+}
+
+// API key methods
+func (m *MockClient) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error) {
+	m.track("CreateAPIKey")
+	if m.CreateAPIKeyFn != nil {
+		return m.CreateAPIKeyFn(ctx, req)
+	}
+	return &APIKey{ID: "key_test", Name: req.Name, Scopes: req.Scopes, Repositories: req.Repositories, ExpiresAt: req.ExpiresAt, Token: "kw_test"}, nil
+}
+
+// Shared value methods
+func (m *MockClient) GetSharedValues(ctx context.Context, repoFullName string) (map[string]string, error) {
+	m.track("GetSharedValues")
+	if m.GetSharedValuesFn != nil {
+		return m.GetSharedValuesFn(ctx, repoFullName)
+	}
+	return map[string]string{}, nil
+}
+
+func (m *MockClient) SetSharedValue(ctx context.Context, repoFullName, name, value string) error {
+	m.track("SetSharedValue")
+	if m.SetSharedValueFn != nil {
+		return m.SetSharedValueFn(ctx, repoFullName, name, value)
+	}
+	return nil
+}
+
+func (m *MockClient) DeleteSharedValue(ctx context.Context, repoFullName, name string) error {
+	m.track("DeleteSharedValue")
+	if m.DeleteSharedValueFn != nil {
+		return m.DeleteSharedValueFn(ctx, repoFullName, name)
+	}
+	return nil
+}
+
 // Provider methods
 func (m *MockClient) GetProviders(ctx context.Context) ([]Provider, error) {
 	m.track("GetProviders")
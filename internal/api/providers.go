@@ -100,6 +100,9 @@ type SyncOptions struct {
 	ProviderEnvironment string  `json:"providerEnvironment"`
 	Direction           string  `json:"direction,omitempty"` // "push" or "pull"
 	AllowDelete         bool    `json:"allowDelete,omitempty"`
+	// PlainKeys are pushed without the provider's secret/secured flag
+	// (Azure DevOps, Bitbucket); every other key is pushed as secret
+	PlainKeys []string `json:"plainKeys,omitempty"`
 }
 
 // GetProviders returns available providers
@@ -2,7 +2,11 @@ package api
 
 import (
 	"context"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // PushSecretsResponse is the response from pushing secrets
@@ -36,11 +40,58 @@ func (c *Client) PushSecrets(ctx context.Context, repo, env string, secrets map[
 	return &wrapper.Data, err
 }
 
-// PullSecrets downloads secrets from the vault
-func (c *Client) PullSecrets(ctx context.Context, repo, env string) (*PullSecretsResponse, error) {
+// SecretsPatch is a delta push: the changed keys only, with the digests of
+// the vault values they were computed against
+type SecretsPatch struct {
+	Set    map[string]string
+	Delete []string
+	// Base maps every set or deleted key to the sha256 hex digest of the vault
+	// value it replaces, "" for a key that did not exist
+	Base map[string]string
+}
+
+// IsEmpty returns true if the patch changes nothing
+func (p SecretsPatch) IsEmpty() bool {
+	return len(p.Set) == 0 && len(p.Delete) == 0
+}
+
+// PatchSecrets applies a delta to an existing environment. The server refuses
+// the whole patch with a 409 when a key's value no longer matches its Base
+// digest. That includes a patch it already applied, so a repeated patch
+// conflicts: callers retrying one must check the vault before reporting a
+// conflict. Servers without delta support answer 404 or 405.
+func (c *Client) PatchSecrets(ctx context.Context, repo, env string, patch SecretsPatch) (*PushSecretsResponse, error) {
+	body := map[string]interface{}{
+		"repoFullName": repo,
+		"environment":  env,
+		"set":          patch.Set,
+		"delete":       patch.Delete,
+		"base":         patch.Base,
+	}
+
+	var wrapper struct {
+		Data PushSecretsResponse `json:"data"`
+	}
+	err := c.do(ctx, "PATCH", "/v1/secrets", body, &wrapper)
+	return &wrapper.Data, err
+}
+
+// IsPatchUnsupported returns true if err means the server has no delta push
+func IsPatchUnsupported(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && (apiErr.NotFound() || apiErr.StatusCode == http.StatusMethodNotAllowed || apiErr.StatusCode == http.StatusNotImplemented)
+}
+
+// PullSecrets downloads secrets from the vault. When keys are given, only
+// those keys are requested; servers without key filtering return them all,
+// so callers still select the keys they need.
+func (c *Client) PullSecrets(ctx context.Context, repo, env string, keys ...string) (*PullSecretsResponse, error) {
 	params := url.Values{}
 	params.Set("repo", repo)
 	params.Set("environment", env)
+	if len(keys) > 0 {
+		params.Set("keys", strings.Join(keys, ","))
+	}
 
 	var wrapper struct {
 		Data PullSecretsResponse `json:"data"`
@@ -48,3 +99,74 @@ func (c *Client) PullSecrets(ctx context.Context, repo, env string) (*PullSecret
 	err := c.do(ctx, "GET", "/v1/secrets/pull?"+params.Encode(), nil, &wrapper)
 	return &wrapper.Data, err
 }
+
+// SnapshotRef selects a past state of an environment, by version number or
+// point in time. Version takes precedence when set.
+type SnapshotRef struct {
+	Version int
+	At      time.Time
+}
+
+// PullSecretsSnapshot downloads the secrets of an environment as they were
+// at ref, from the vault's version history
+func (c *Client) PullSecretsSnapshot(ctx context.Context, repo, env string, ref SnapshotRef) (*PullSecretsResponse, error) {
+	params := url.Values{}
+	params.Set("repo", repo)
+	params.Set("environment", env)
+	if ref.Version > 0 {
+		params.Set("version", strconv.Itoa(ref.Version))
+	} else {
+		params.Set("at", ref.At.UTC().Format(time.RFC3339))
+	}
+
+	var wrapper struct {
+		Data PullSecretsResponse `json:"data"`
+	}
+	err := c.do(ctx, "GET", "/v1/secrets/pull?"+params.Encode(), nil, &wrapper)
+	return &wrapper.Data, err
+}
+
+// KeyMetadata contains usage data for a single key of an environment
+type KeyMetadata struct {
+	Key          string     `json:"key"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+	LastPulledAt *time.Time `json:"lastPulledAt,omitempty"`
+	LastPulledBy string     `json:"lastPulledBy,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"` // Set for keys with a TTL
+}
+
+// GetSecretsMetadata returns per-key timestamps for an environment, without values.
+// Returns nil without error when the API does not expose key metadata.
+func (c *Client) GetSecretsMetadata(ctx context.Context, repo, env string) ([]KeyMetadata, error) {
+	params := url.Values{}
+	params.Set("repo", repo)
+	params.Set("environment", env)
+
+	var wrapper struct {
+		Data struct {
+			Keys []KeyMetadata `json:"keys"`
+		} `json:"data"`
+	}
+	err := c.do(ctx, "GET", "/v1/secrets/metadata?"+params.Encode(), nil, &wrapper)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return wrapper.Data.Keys, nil
+}
+
+// SetSecretExpiry sets when a key of an environment expires, or removes its
+// expiry when expiresAt is nil. Expired keys are kept in the vault but not
+// injected.
+func (c *Client) SetSecretExpiry(ctx context.Context, repo, env, key string, expiresAt *time.Time) error {
+	body := map[string]interface{}{
+		"repoFullName": repo,
+		"environment":  env,
+		"key":          key,
+		"expiresAt":    expiresAt,
+	}
+	return c.do(ctx, "PUT", "/v1/secrets/expiry", body, nil)
+}
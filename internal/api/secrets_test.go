@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func TestClient_PushSecrets_Success(t *testing.T) {
@@ -199,6 +201,34 @@ func TestClient_PullSecrets_Success(t *testing.T) {
 	}
 }
 
+func TestClient_PullSecrets_KeyFilter(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"content": "API_KEY=secret123"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if _, err := client.PullSecrets(context.Background(), "owner/repo", "staging", "API_KEY", "DB_URL"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := query.Get("keys"); got != "API_KEY,DB_URL" {
+		t.Errorf("expected keys=API_KEY,DB_URL, got %q", got)
+	}
+
+	if _, err := client.PullSecrets(context.Background(), "owner/repo", "staging"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Has("keys") {
+		t.Error("expected no keys parameter without a filter")
+	}
+}
+
 func TestClient_PullSecrets_EmptyVault(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -320,3 +350,175 @@ func TestClient_PushSecrets_SpecialCharacters(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestClient_GetSecretsMetadata_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secrets/metadata" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("repo") != "owner/repo" || r.URL.Query().Get("environment") != "production" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"keys": []map[string]interface{}{
+					{"key": "API_KEY", "createdAt": "2026-01-02T03:04:05Z", "lastPulledAt": "2026-03-01T00:00:00Z", "lastPulledBy": "alice"},
+					{"key": "OLD_KEY", "createdAt": "2025-01-02T03:04:05Z"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	keys, err := client.GetSecretsMetadata(context.Background(), "owner/repo", "production")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %+v", keys)
+	}
+	if keys[0].LastPulledBy != "alice" || keys[0].LastPulledAt == nil {
+		t.Errorf("unexpected first key: %+v", keys[0])
+	}
+	if keys[1].LastPulledAt != nil || keys[1].UpdatedAt != nil {
+		t.Errorf("expected missing timestamps to stay nil: %+v", keys[1])
+	}
+}
+
+func TestClient_GetSecretsMetadata_NotExposed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "Not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	keys, err := client.GetSecretsMetadata(context.Background(), "owner/repo", "production")
+
+	if err != nil {
+		t.Fatalf("expected no error on 404, got %v", err)
+	}
+	if keys != nil {
+		t.Errorf("expected nil metadata, got %+v", keys)
+	}
+}
+
+func TestClient_SetSecretExpiry(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/v1/secrets/expiry" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	expiresAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if err := client.SetSecretExpiry(context.Background(), "owner/repo", "production", "TEMP_TOKEN", &expiresAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["key"] != "TEMP_TOKEN" || body["environment"] != "production" || body["expiresAt"] != "2026-10-16T12:00:00Z" {
+		t.Errorf("unexpected body: %v", body)
+	}
+
+	if err := client.SetSecretExpiry(context.Background(), "owner/repo", "production", "TEMP_TOKEN", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := body["expiresAt"]; !ok || v != nil {
+		t.Errorf("expected a null expiresAt to clear the TTL, got %v", body)
+	}
+}
+
+func TestClient_PullSecretsSnapshot(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secrets/pull" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query())
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"content": "API_KEY=old\n"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	resp, err := client.PullSecretsSnapshot(context.Background(), "owner/repo", "production", SnapshotRef{At: at})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Content != "API_KEY=old\n" {
+		t.Errorf("unexpected content: %q", resp.Content)
+	}
+	if _, err := client.PullSecretsSnapshot(context.Background(), "owner/repo", "production", SnapshotRef{Version: 12}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := queries[0].Get("at"); got != "2023-12-31T23:00:00Z" {
+		t.Errorf("expected the timestamp in UTC, got %q", got)
+	}
+	if got := queries[1].Get("version"); got != "12" || queries[1].Get("at") != "" {
+		t.Errorf("expected only the version, got %v", queries[1])
+	}
+}
+
+func TestClient_PatchSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/v1/secrets" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Environment string            `json:"environment"`
+			Set         map[string]string `json:"set"`
+			Delete      []string          `json:"delete"`
+			Base        map[string]string `json:"base"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Environment != "production" || body.Set["API_KEY"] != "new" || len(body.Delete) != 1 || body.Base["OLD"] != "abc" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"success": true, "message": "Secrets updated"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	patch := SecretsPatch{
+		Set:    map[string]string{"API_KEY": "new"},
+		Delete: []string{"OLD"},
+		Base:   map[string]string{"API_KEY": "", "OLD": "abc"},
+	}
+	resp, err := client.PatchSecrets(context.Background(), "owner/repo", "production", patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Message != "Secrets updated" {
+		t.Errorf("unexpected message: %q", resp.Message)
+	}
+}
+
+func TestIsPatchUnsupported(t *testing.T) {
+	for status, want := range map[int]bool{404: true, 405: true, 501: true, 409: false, 500: false} {
+		if got := IsPatchUnsupported(&APIError{StatusCode: status}); got != want {
+			t.Errorf("IsPatchUnsupported(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetSharedValues returns the vault-level shared values referenced as $ref:shared/NAME
+func (c *Client) GetSharedValues(ctx context.Context, repoFullName string) (map[string]string, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/shared", owner, repo)
+	var wrapper struct {
+		Data struct {
+			Values map[string]string `json:"values"`
+		} `json:"data"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.Data.Values == nil {
+		return map[string]string{}, nil
+	}
+	return wrapper.Data.Values, nil
+}
+
+// SetSharedValue creates or updates a shared value
+func (c *Client) SetSharedValue(ctx context.Context, repoFullName, name, value string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/shared/%s", owner, repo, url.PathEscape(name))
+	body := map[string]string{"value": value}
+	return c.do(ctx, http.MethodPut, path, body, nil)
+}
+
+// DeleteSharedValue removes a shared value
+func (c *Client) DeleteSharedValue(ctx context.Context, repoFullName, name string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/shared/%s", owner, repo, url.PathEscape(name))
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
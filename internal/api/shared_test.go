@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_GetSharedValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/vaults/owner/repo/shared" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"values": map[string]string{"SENTRY_DSN": "https://sentry.io/1"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	values, err := client.GetSharedValues(context.Background(), "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["SENTRY_DSN"] != "https://sentry.io/1" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestClient_SetSharedValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/v1/vaults/owner/repo/shared/SENTRY_DSN" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["value"] != "https://sentry.io/1" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.SetSharedValue(context.Background(), "owner/repo", "SENTRY_DSN", "https://sentry.io/1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_DeleteSharedValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/v1/vaults/owner/repo/shared/SENTRY_DSN" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.DeleteSharedValue(context.Background(), "owner/repo", "SENTRY_DSN"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_SharedValues_InvalidRepo(t *testing.T) {
+	client := NewClient("token")
+
+	if _, err := client.GetSharedValues(context.Background(), "invalid"); err == nil {
+		t.Error("expected error for invalid repo")
+	}
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"time"
 )
 
 // InitVaultResponse is the response from initializing a vault
@@ -69,7 +71,7 @@ func (c *Client) CheckVaultExists(ctx context.Context, repoFullName string) (boo
 	path := fmt.Sprintf("/v1/vaults/%s/%s", owner, repo)
 	err := c.do(ctx, "GET", path, nil, nil)
 	if err != nil {
-		if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == 404 {
+		if IsNotFound(err) {
 			return false, nil
 		}
 		return false, err
@@ -77,11 +79,12 @@ func (c *Client) CheckVaultExists(ctx context.Context, repoFullName string) (boo
 	return true, nil
 }
 
-// GetVaultEnvironments returns the environments for a vault
+// GetVaultEnvironments returns the environments for a vault.
+// A vault without environments yet returns ["production"]; request failures are returned as errors.
 func (c *Client) GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error) {
 	owner, repo := splitRepo(repoFullName)
 	if owner == "" || repo == "" {
-		return []string{"production"}, nil
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
 	}
 
 	path := fmt.Sprintf("/v1/vaults/%s/%s", owner, repo)
@@ -91,9 +94,12 @@ func (c *Client) GetVaultEnvironments(ctx context.Context, repoFullName string)
 		} `json:"data"`
 	}
 
-	err := c.do(ctx, "GET", path, nil, &wrapper)
+	// Listing is idempotent: retry on rate limits, 5xx and network failures
+	err := WithRetry(ctx, func() error {
+		return c.do(ctx, "GET", path, nil, &wrapper)
+	})
 	if err != nil {
-		return []string{"production"}, nil
+		return nil, err
 	}
 
 	if len(wrapper.Data.Environments) == 0 {
@@ -102,6 +108,153 @@ func (c *Client) GetVaultEnvironments(ctx context.Context, repoFullName string)
 	return wrapper.Data.Environments, nil
 }
 
+// VaultActivity contains per-environment usage data for a vault
+type VaultActivity struct {
+	Environments []EnvironmentActivity `json:"environments"`
+}
+
+// EnvironmentActivity contains usage data for a single environment
+type EnvironmentActivity struct {
+	Environment  string     `json:"environment"`
+	LastPulledAt *time.Time `json:"lastPulledAt,omitempty"`
+	LastPushedAt *time.Time `json:"lastPushedAt,omitempty"`
+	TopPullers   []Puller   `json:"topPullers,omitempty"`
+}
+
+// Puller is a user who pulled secrets from an environment
+type Puller struct {
+	Login string `json:"login"`
+	Pulls int    `json:"pulls"`
+}
+
+// GetVaultActivity returns pull/push activity for a vault.
+// Returns nil without error when the API does not expose activity data.
+func (c *Client) GetVaultActivity(ctx context.Context, repoFullName string) (*VaultActivity, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/activity", owner, repo)
+	var wrapper struct {
+		Data VaultActivity `json:"data"`
+	}
+
+	err := c.do(ctx, "GET", path, nil, &wrapper)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &wrapper.Data, nil
+}
+
+// CreateEnvironment adds an empty environment to a vault
+func (c *Client) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments", owner, repo)
+	body := map[string]string{"name": name}
+	return c.do(ctx, "POST", path, body, nil)
+}
+
+// DeleteEnvironment removes an environment and all its secrets from a vault
+func (c *Client) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments/%s", owner, repo, url.PathEscape(name))
+	return c.do(ctx, "DELETE", path, nil, nil)
+}
+
+// EnvironmentAccess is what the current token may do in an environment
+type EnvironmentAccess struct {
+	Name  string `json:"name"`
+	Read  bool   `json:"read"`
+	Write bool   `json:"write"`
+}
+
+// GetEnvironmentAccess returns the access of the current token to each
+// environment of a vault. Returns nil without error when the API does not
+// support access checks.
+func (c *Client) GetEnvironmentAccess(ctx context.Context, repoFullName string) ([]EnvironmentAccess, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/environments/access", owner, repo)
+	var wrapper struct {
+		Data struct {
+			Environments []EnvironmentAccess `json:"environments"`
+		} `json:"data"`
+	}
+
+	err := c.do(ctx, "GET", path, nil, &wrapper)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return wrapper.Data.Environments, nil
+}
+
+// TransferVault moves a vault to another repository, e.g. after the GitHub
+// repository was renamed or transferred to another organization
+func (c *Client) TransferVault(ctx context.Context, fromRepo, toRepo string) error {
+	owner, repo := splitRepo(fromRepo)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format: %s", fromRepo)
+	}
+
+	path := fmt.Sprintf("/v1/vaults/%s/%s/transfer", owner, repo)
+	body := map[string]string{"repoFullName": toRepo}
+	return c.do(ctx, "POST", path, body, nil)
+}
+
+// PublicVault describes a vault its owner made readable without login,
+// such as the vault of a sample app
+type PublicVault struct {
+	Public       bool     `json:"public"`
+	Environments []string `json:"environments"` // Environments readable anonymously
+}
+
+// GetPublicVault returns the anonymous access of a vault. It needs no token.
+// Returns nil without error when the vault is not public or the API does not
+// support anonymous reads.
+func (c *Client) GetPublicVault(ctx context.Context, repoFullName string) (*PublicVault, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+
+	path := fmt.Sprintf("/v1/public/vaults/%s/%s", owner, repo)
+	var wrapper struct {
+		Data PublicVault `json:"data"`
+	}
+
+	err := c.do(ctx, "GET", path, nil, &wrapper)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !wrapper.Data.Public {
+		return nil, nil
+	}
+
+	return &wrapper.Data, nil
+}
+
 // splitRepo splits "owner/repo" into owner and repo
 func splitRepo(repoFullName string) (string, string) {
 	for i, c := range repoFullName {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestClient_InitVault_Success(t *testing.T) {
@@ -226,12 +227,42 @@ func TestClient_GetVaultEnvironments_NotFound(t *testing.T) {
 
 	envs, err := client.GetVaultEnvironments(context.Background(), "owner/repo")
 
-	// Should return default production on error
+	// Failures are surfaced so callers can decide whether to fall back
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if envs != nil {
+		t.Errorf("expected no environments on error, got %v", envs)
+	}
+}
+
+func TestClient_GetVaultEnvironments_RetriesUnavailable(t *testing.T) {
+	oldWait := RetryBaseWait
+	RetryBaseWait = time.Millisecond
+	defer func() { RetryBaseWait = oldWait }()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"environments": []string{"development"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	envs, err := client.GetVaultEnvironments(context.Background(), "owner/repo")
 	if err != nil {
-		t.Fatalf("should not error, should return default: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(envs) != 1 || envs[0] != "production" {
-		t.Errorf("expected default production on error, got %v", envs)
+	if calls != 2 || len(envs) != 1 || envs[0] != "development" {
+		t.Errorf("expected a retry then development, got %v after %d calls", envs, calls)
 	}
 }
 
@@ -261,3 +292,197 @@ func TestSplitRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetVaultActivity_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/vaults/owner/repo/activity" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"environments": []map[string]interface{}{
+					{
+						"environment":  "production",
+						"lastPulledAt": "2026-01-02T03:04:05Z",
+						"topPullers":   []map[string]interface{}{{"login": "alice", "pulls": 4}},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	activity, err := client.GetVaultActivity(context.Background(), "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if activity == nil || len(activity.Environments) != 1 {
+		t.Fatalf("expected one environment, got %+v", activity)
+	}
+	env := activity.Environments[0]
+	if env.LastPulledAt == nil || env.LastPushedAt != nil {
+		t.Errorf("unexpected timestamps: pulled=%v pushed=%v", env.LastPulledAt, env.LastPushedAt)
+	}
+	if len(env.TopPullers) != 1 || env.TopPullers[0].Pulls != 4 {
+		t.Errorf("unexpected top pullers: %+v", env.TopPullers)
+	}
+}
+
+func TestClient_GetVaultActivity_NotExposed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "Not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	activity, err := client.GetVaultActivity(context.Background(), "owner/repo")
+
+	if err != nil {
+		t.Fatalf("expected no error on 404, got %v", err)
+	}
+	if activity != nil {
+		t.Errorf("expected nil activity, got %+v", activity)
+	}
+}
+
+func TestClient_GetPublicVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("expected an anonymous request")
+		}
+		switch r.URL.Path {
+		case "/v1/public/vaults/owner/demo":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"public": true, "environments": []string{"development"}},
+			})
+		case "/v1/public/vaults/owner/private":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"public": false},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Not found"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.baseURL = server.URL
+
+	public, err := client.GetPublicVault(context.Background(), "owner/demo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if public == nil || len(public.Environments) != 1 || public.Environments[0] != "development" {
+		t.Errorf("unexpected public vault: %+v", public)
+	}
+
+	for _, repo := range []string{"owner/private", "owner/unknown"} {
+		public, err := client.GetPublicVault(context.Background(), repo)
+		if err != nil || public != nil {
+			t.Errorf("%s: expected nil without error, got %+v, %v", repo, public, err)
+		}
+	}
+}
+
+func TestClient_CreateEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/vaults/owner/repo/environments" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "staging" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.CreateEnvironment(context.Background(), "owner/repo", "staging"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_DeleteEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/v1/vaults/owner/repo/environments/staging" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.DeleteEnvironment(context.Background(), "owner/repo", "staging"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_GetEnvironmentAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/vaults/owner/old/environments/access" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Not found"}`))
+			return
+		}
+		if r.Method != "GET" || r.URL.Path != "/v1/vaults/owner/repo/environments/access" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"data":{"environments":[{"name":"staging","read":true,"write":true},{"name":"production","read":false,"write":false}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	access, err := client.GetEnvironmentAccess(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(access) != 2 || !access[0].Write || access[1].Read {
+		t.Errorf("unexpected access: %+v", access)
+	}
+
+	// An API without access checks
+	access, err = client.GetEnvironmentAccess(context.Background(), "owner/old")
+	if err != nil || access != nil {
+		t.Errorf("expected nil without error, got %+v, %v", access, err)
+	}
+}
+
+func TestClient_TransferVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/vaults/oldorg/app/transfer" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["repoFullName"] != "neworg/app" {
+			t.Errorf("unexpected body: %v", body)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.TransferVault(context.Background(), "oldorg/app", "neworg/app"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Webhook events
+const (
+	WebhookEventPush   = "push"
+	WebhookEventRotate = "rotate"
+	WebhookEventPull   = "pull"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to
+var WebhookEvents = []string{WebhookEventPush, WebhookEventRotate, WebhookEventPull}
+
+// Webhook is an outbound notification of vault events
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Environments restricts the webhook to these environments, empty means all
+	Environments []string   `json:"environments,omitempty"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"`
+	// Secret signs the payloads (X-Keyway-Signature); only returned on creation
+	Secret string `json:"secret,omitempty"`
+}
+
+// CreateWebhookRequest describes a webhook to create
+type CreateWebhookRequest struct {
+	URL          string   `json:"url"`
+	Events       []string `json:"events"`
+	Environments []string `json:"environments,omitempty"`
+}
+
+// WebhookDelivery is the outcome of sending a payload to a webhook
+type WebhookDelivery struct {
+	StatusCode int    `json:"statusCode"`
+	DurationMs int    `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// webhooksPath returns the webhooks path of a vault, followed by parts
+func webhooksPath(repoFullName string, parts ...string) (string, error) {
+	owner, repo := splitRepo(repoFullName)
+	if owner == "" || repo == "" {
+		return "", fmt.Errorf("invalid repository format: %s", repoFullName)
+	}
+	path := fmt.Sprintf("/v1/vaults/%s/%s/webhooks", owner, repo)
+	for _, part := range parts {
+		path += "/" + url.PathEscape(part)
+	}
+	return path, nil
+}
+
+// ListWebhooks returns the webhooks of a vault
+func (c *Client) ListWebhooks(ctx context.Context, repoFullName string) ([]Webhook, error) {
+	path, err := webhooksPath(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper struct {
+		Data struct {
+			Webhooks []Webhook `json:"webhooks"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Data.Webhooks, nil
+}
+
+// CreateWebhook adds a webhook to a vault. The returned webhook holds its signing secret.
+func (c *Client) CreateWebhook(ctx context.Context, repoFullName string, req CreateWebhookRequest) (*Webhook, error) {
+	path, err := webhooksPath(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper struct {
+		Data Webhook `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, path, req, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Data, nil
+}
+
+// DeleteWebhook removes a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, repoFullName, id string) error {
+	path, err := webhooksPath(repoFullName, id)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// TestWebhook asks the API to send a signed sample payload to a webhook
+func (c *Client) TestWebhook(ctx context.Context, repoFullName, id string) (*WebhookDelivery, error) {
+	path, err := webhooksPath(repoFullName, id, "test")
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper struct {
+		Data WebhookDelivery `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, path, nil, &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Data, nil
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListWebhooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v1/vaults/owner/repo/webhooks" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"webhooks": []map[string]interface{}{
+					{"id": "wh_1", "url": "https://example.com/hook", "events": []string{"push"}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	webhooks, err := client.ListWebhooks(context.Background(), "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].ID != "wh_1" || webhooks[0].Events[0] != "push" {
+		t.Errorf("unexpected webhooks: %+v", webhooks)
+	}
+}
+
+func TestClient_CreateWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/vaults/owner/repo/webhooks" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body CreateWebhookRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.URL != "https://example.com/hook" || len(body.Events) != 2 || body.Environments[0] != "production" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": "wh_2", "url": body.URL, "events": body.Events, "secret": "whsec_abc"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	webhook, err := client.CreateWebhook(context.Background(), "owner/repo", CreateWebhookRequest{
+		URL:          "https://example.com/hook",
+		Events:       []string{"push", "rotate"},
+		Environments: []string{"production"},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if webhook.ID != "wh_2" || webhook.Secret != "whsec_abc" {
+		t.Errorf("unexpected webhook: %+v", webhook)
+	}
+}
+
+func TestClient_DeleteAndTestWebhook(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"statusCode": 502, "durationMs": 120, "error": "bad gateway"},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.DeleteWebhook(context.Background(), "owner/repo", "wh_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delivery, err := client.TestWebhook(context.Background(), "owner/repo", "wh_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivery.StatusCode != 502 || delivery.Error != "bad gateway" {
+		t.Errorf("unexpected delivery: %+v", delivery)
+	}
+
+	want := []string{"DELETE /v1/vaults/owner/repo/webhooks/wh_1", "POST /v1/vaults/owner/repo/webhooks/wh_1/test"}
+	if len(requests) != 2 || requests[0] != want[0] || requests[1] != want[1] {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+}
+
+func TestClient_Webhooks_InvalidRepo(t *testing.T) {
+	client := NewClient("token")
+	if _, err := client.ListWebhooks(context.Background(), "invalid"); err == nil {
+		t.Error("expected an error for an invalid repository")
+	}
+}
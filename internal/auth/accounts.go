@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// Account is a GitHub account signed in on this machine
+type Account struct {
+	Login string
+	// Default is the account used where no binding applies
+	Default bool
+	// Bindings are the repositories (owner/repo) and owners that use this account
+	Bindings []string
+}
+
+// accountIndex is the accounts file: which accounts are signed in besides the
+// default one, and which repositories and owners use which account. It holds
+// no secrets, the sessions stay in the credential store or the encrypted file.
+type accountIndex struct {
+	// Accounts are the logins signed in besides the default account
+	Accounts []string `json:"accounts,omitempty"`
+	// Repos maps a lowercase owner/repo to a login
+	Repos map[string]string `json:"repos,omitempty"`
+	// Orgs maps a lowercase owner to a login
+	Orgs map[string]string `json:"orgs,omitempty"`
+}
+
+// accountSlot names where the session of an account other than the default
+// one is stored; the default account keeps the "auth" slot
+func accountSlot(login string) string {
+	return keyringAccount + ":" + strings.ToLower(login)
+}
+
+// loadIndex reads the accounts file, empty if there is none
+func (s *Store) loadIndex() accountIndex {
+	var index accountIndex
+	if s.accountsPath == "" {
+		return index
+	}
+	if data, err := os.ReadFile(s.accountsPath); err == nil {
+		_ = json.Unmarshal(data, &index)
+	}
+	return index
+}
+
+// updateIndex applies fn to the accounts file
+func (s *Store) updateIndex(fn func(index *accountIndex)) error {
+	if s.accountsPath == "" {
+		return nil
+	}
+	return state.Update(s.accountsPath, 0600, func(current []byte) ([]byte, error) {
+		var index accountIndex
+		_ = json.Unmarshal(current, &index)
+		fn(&index)
+		return json.MarshalIndent(index, "", "  ")
+	})
+}
+
+// HasOtherAccounts reports whether accounts besides the default one are signed in
+func (s *Store) HasOtherAccounts() bool {
+	return len(s.loadIndex().Accounts) > 0
+}
+
+// Accounts lists the signed-in accounts, the default one first
+func (s *Store) Accounts() ([]Account, error) {
+	index := s.loadIndex()
+	var accounts []Account
+	current, err := s.GetAuth()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		accounts = append(accounts, Account{Login: current.GitHubLogin, Default: true})
+	}
+	for _, login := range index.Accounts {
+		accounts = append(accounts, Account{Login: login})
+	}
+
+	for i := range accounts {
+		for _, bindings := range []map[string]string{index.Orgs, index.Repos} {
+			for scope, login := range bindings {
+				if strings.EqualFold(login, accounts[i].Login) {
+					accounts[i].Bindings = append(accounts[i].Bindings, scope)
+				}
+			}
+		}
+		sort.Strings(accounts[i].Bindings)
+	}
+	return accounts, nil
+}
+
+// GetAccount retrieves the session of login, nil if it isn't signed in
+func (s *Store) GetAccount(login string) (*StoredAuth, error) {
+	current, err := s.GetAuth()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && strings.EqualFold(current.GitHubLogin, login) {
+		return current, nil
+	}
+	if !containsFold(s.loadIndex().Accounts, login) {
+		return nil, nil
+	}
+	auth, err := s.getSlot(accountSlot(login))
+	if err == nil && auth == nil {
+		// Expired or removed behind our back
+		_ = s.dropStashedAccount(login)
+	}
+	return auth, err
+}
+
+// SwitchAccount makes login the default account
+func (s *Store) SwitchAccount(login string) error {
+	target, err := s.GetAccount(login)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return fmt.Errorf("not signed in as %s - run keyway login", login)
+	}
+	current, err := s.GetAuth()
+	if err != nil {
+		return err
+	}
+	if current != nil && strings.EqualFold(current.GitHubLogin, target.GitHubLogin) {
+		return nil
+	}
+
+	// Keep the current account before its slot is overwritten
+	if current != nil && current.GitHubLogin != "" {
+		if err := s.stashAccount(current); err != nil {
+			return err
+		}
+	}
+	if err := s.putSlot(keyringAccount, target); err != nil {
+		return err
+	}
+	return s.dropStashedAccount(login)
+}
+
+// RemoveAccount signs login out, with its bindings. When it was the default
+// account another one, if any, takes over; its login is returned.
+func (s *Store) RemoveAccount(login string) (string, error) {
+	if err := s.updateIndex(func(index *accountIndex) {
+		for _, bindings := range []map[string]string{index.Repos, index.Orgs} {
+			for scope, bound := range bindings {
+				if strings.EqualFold(bound, login) {
+					delete(bindings, scope)
+				}
+			}
+		}
+	}); err != nil {
+		return "", err
+	}
+
+	current, err := s.GetAuth()
+	if err != nil {
+		return "", err
+	}
+	if current == nil || !strings.EqualFold(current.GitHubLogin, login) {
+		return "", s.dropStashedAccount(login)
+	}
+
+	if err := s.ClearAuth(); err != nil {
+		return "", err
+	}
+	for _, next := range s.loadIndex().Accounts {
+		if err := s.SwitchAccount(next); err == nil {
+			return next, nil
+		}
+	}
+	return "", nil
+}
+
+// RemoveAllAccounts signs every account out and forgets the bindings
+func (s *Store) RemoveAllAccounts() error {
+	for _, login := range s.loadIndex().Accounts {
+		if err := s.dropStashedAccount(login); err != nil {
+			return err
+		}
+	}
+	if err := s.updateIndex(func(index *accountIndex) { *index = accountIndex{} }); err != nil {
+		return err
+	}
+	return s.ClearAuth()
+}
+
+// AccountFor returns the login bound to repo (owner/repo), else to its owner,
+// "" if neither is bound
+func (s *Store) AccountFor(repo string) string {
+	index := s.loadIndex()
+	repo = strings.ToLower(repo)
+	if login := index.Repos[repo]; login != "" {
+		return login
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	return index.Orgs[owner]
+}
+
+// BindAccount makes login the account of scope, an owner/repo or an owner.
+// An empty login removes the binding.
+func (s *Store) BindAccount(scope, login string) error {
+	scope = strings.ToLower(scope)
+	return s.updateIndex(func(index *accountIndex) {
+		bindings := &index.Orgs
+		if strings.Contains(scope, "/") {
+			bindings = &index.Repos
+		}
+		if login == "" {
+			delete(*bindings, scope)
+			return
+		}
+		if *bindings == nil {
+			*bindings = make(map[string]string)
+		}
+		(*bindings)[scope] = login
+	})
+}
+
+// stashAccount moves auth out of the default slot into its own
+func (s *Store) stashAccount(auth *StoredAuth) error {
+	if err := s.putSlot(accountSlot(auth.GitHubLogin), auth); err != nil {
+		return err
+	}
+	return s.updateIndex(func(index *accountIndex) {
+		if !containsFold(index.Accounts, auth.GitHubLogin) {
+			index.Accounts = append(index.Accounts, auth.GitHubLogin)
+		}
+	})
+}
+
+// dropStashedAccount removes the session kept for login besides the default one
+func (s *Store) dropStashedAccount(login string) error {
+	index := s.loadIndex()
+	if !containsFold(index.Accounts, login) {
+		return nil
+	}
+	if err := s.clearSlot(accountSlot(login)); err != nil {
+		return err
+	}
+	return s.updateIndex(func(index *accountIndex) {
+		kept := index.Accounts[:0]
+		for _, l := range index.Accounts {
+			if !strings.EqualFold(l, login) {
+				kept = append(kept, l)
+			}
+		}
+		index.Accounts = kept
+	})
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func loginAs(t *testing.T, store *Store, token, login string) {
+	t.Helper()
+	if err := store.SaveAuth(token, login, ""); err != nil {
+		t.Fatalf("SaveAuth(%s) failed: %v", login, err)
+	}
+}
+
+func TestStore_SecondLoginKeepsFirstAccount(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+
+	current, _ := store.GetAuth()
+	if current == nil || current.KeywayToken != "kw_work" {
+		t.Fatalf("expected the last login to be the default, got %+v", current)
+	}
+	personal, err := store.GetAccount("OctoCat")
+	if err != nil || personal == nil || personal.KeywayToken != "kw_personal" {
+		t.Fatalf("expected the first account kept, got %+v, %v", personal, err)
+	}
+
+	accounts, err := store.Accounts()
+	if err != nil || len(accounts) != 2 || accounts[0].Login != "octo-work" || !accounts[0].Default || accounts[1].Login != "octocat" {
+		t.Errorf("unexpected accounts %+v, %v", accounts, err)
+	}
+
+	// Signing in again to the same account replaces its session
+	loginAs(t, store, "kw_personal2", "octocat")
+	if accounts, _ := store.Accounts(); len(accounts) != 2 || accounts[0].Login != "octocat" {
+		t.Errorf("expected no duplicate account, got %+v", accounts)
+	}
+	if current, _ := store.GetAuth(); current.KeywayToken != "kw_personal2" {
+		t.Errorf("expected the new session, got %+v", current)
+	}
+}
+
+func TestStore_SwitchAccount(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+
+	if err := store.SwitchAccount("octocat"); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := store.GetAuth(); current.KeywayToken != "kw_personal" {
+		t.Errorf("expected octocat as default, got %+v", current)
+	}
+	if work, _ := store.GetAccount("octo-work"); work == nil || work.KeywayToken != "kw_work" {
+		t.Errorf("expected octo-work kept, got %+v", work)
+	}
+
+	if err := store.SwitchAccount("nobody"); err == nil || !strings.Contains(err.Error(), "not signed in as nobody") {
+		t.Errorf("expected an error for an unknown account, got %v", err)
+	}
+}
+
+func TestStore_AccountBindings(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+
+	if err := store.BindAccount("Acme", "octo-work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.BindAccount("acme/oss-site", "octocat"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.AccountFor("acme/API"); got != "octo-work" {
+		t.Errorf("expected the owner binding, got %q", got)
+	}
+	if got := store.AccountFor("acme/oss-site"); got != "octocat" {
+		t.Errorf("expected the repository binding to win, got %q", got)
+	}
+	if got := store.AccountFor("other/repo"); got != "" {
+		t.Errorf("expected no binding, got %q", got)
+	}
+
+	accounts, _ := store.Accounts()
+	if strings.Join(accounts[0].Bindings, ",") != "acme" || strings.Join(accounts[1].Bindings, ",") != "acme/oss-site" {
+		t.Errorf("unexpected bindings %+v", accounts)
+	}
+
+	if err := store.BindAccount("acme/oss-site", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.AccountFor("acme/oss-site"); got != "octo-work" {
+		t.Errorf("expected the owner binding after unbinding the repository, got %q", got)
+	}
+}
+
+func TestStore_RemoveAccount(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+	_ = store.BindAccount("acme", "octo-work")
+
+	next, err := store.RemoveAccount("octo-work")
+	if err != nil || next != "octocat" {
+		t.Fatalf("expected octocat to take over, got %q, %v", next, err)
+	}
+	if current, _ := store.GetAuth(); current == nil || current.KeywayToken != "kw_personal" {
+		t.Errorf("expected octocat as default, got %+v", current)
+	}
+	if store.HasOtherAccounts() {
+		t.Error("expected a single account left")
+	}
+	if got := store.AccountFor("acme/api"); got != "" {
+		t.Errorf("expected the binding removed, got %q", got)
+	}
+
+	if next, err := store.RemoveAccount("octocat"); err != nil || next != "" {
+		t.Errorf("expected no account left, got %q, %v", next, err)
+	}
+	if current, _ := store.GetAuth(); current != nil {
+		t.Errorf("expected no session, got %+v", current)
+	}
+}
+
+func TestStore_RemoveAllAccounts(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+	_ = store.BindAccount("acme", "octo-work")
+
+	if err := store.RemoveAllAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	if accounts, _ := store.Accounts(); len(accounts) != 0 {
+		t.Errorf("expected no account left, got %+v", accounts)
+	}
+	if fileAuth, _ := store.getFileAuth(accountSlot("octocat")); fileAuth != nil {
+		t.Error("expected the other session removed from the file")
+	}
+	if store.AccountFor("acme/api") != "" {
+		t.Error("expected the bindings forgotten")
+	}
+}
+
+func TestStore_AccountsInKeyring(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	kr := newFakeKeyring()
+	store.keyring = kr
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+
+	if !strings.Contains(kr.items["keyway/auth:octocat"], "kw_personal") || !strings.Contains(kr.items["keyway/auth"], "kw_work") {
+		t.Errorf("expected both sessions in the keyring, got %v", kr.items)
+	}
+}
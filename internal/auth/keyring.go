@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// EnvCredentialStore selects where the session is stored: "auto" (default)
+// uses the OS credential store when there is one, "keychain" requires it,
+// "file" always uses the encrypted file
+const EnvCredentialStore = "KEYWAY_CREDENTIAL_STORE"
+
+// Values of KEYWAY_CREDENTIAL_STORE
+const (
+	CredentialStoreAuto     = "auto"
+	CredentialStoreKeychain = "keychain"
+	CredentialStoreFile     = "file"
+)
+
+// keyringService and keyringAccount identify the session in the OS credential store
+const (
+	keyringService = "keyway"
+	keyringAccount = "auth"
+)
+
+// errKeyringNotFound is returned by keyring.Get when nothing is stored
+var errKeyringNotFound = errors.New("not found in the credential store")
+
+// errNoKeyring is returned when KEYWAY_CREDENTIAL_STORE=keychain finds no credential store
+var errNoKeyring = fmt.Errorf("no OS credential store available (%s=%s)", EnvCredentialStore, CredentialStoreKeychain)
+
+// keyring is an OS credential store: macOS Keychain, Windows Credential
+// Manager or a Secret Service (GNOME Keyring, KWallet) through libsecret
+type keyring interface {
+	Name() string
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// lookPath is a variable so tests can simulate installed utilities
+var lookPath = exec.LookPath
+
+// CredentialStoreMode returns the KEYWAY_CREDENTIAL_STORE mode, auto when unset
+func CredentialStoreMode() (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(EnvCredentialStore)))
+	switch mode {
+	case "":
+		return CredentialStoreAuto, nil
+	case CredentialStoreAuto, CredentialStoreKeychain, CredentialStoreFile:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid %s=%s (use %s, %s or %s)", EnvCredentialStore, mode, CredentialStoreAuto, CredentialStoreKeychain, CredentialStoreFile)
+}
+
+// commandKeyring drives a credential store through its command-line utility
+type commandKeyring struct {
+	name string
+	get  func(service, account string) *exec.Cmd
+	set  func(service, account, secret string) *exec.Cmd
+	del  func(service, account string) *exec.Cmd
+	// notFound reports whether a failed command means nothing is stored
+	notFound func(err error, stderr string) bool
+}
+
+func (k *commandKeyring) Name() string { return k.name }
+
+func (k *commandKeyring) Get(service, account string) (string, error) {
+	out, err := k.run(k.get(service, account))
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", errKeyringNotFound
+	}
+	return out, nil
+}
+
+func (k *commandKeyring) Set(service, account, secret string) error {
+	_, err := k.run(k.set(service, account, secret))
+	return err
+}
+
+func (k *commandKeyring) Delete(service, account string) error {
+	_, err := k.run(k.del(service, account))
+	return err
+}
+
+// run returns the command's output without its trailing newline
+func (k *commandKeyring) run(cmd *exec.Cmd) (string, error) {
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if k.notFound(err, stderr.String()) {
+			return "", errKeyringNotFound
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", k.name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", k.name, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// exitCode returns the exit status of a command that ran, -1 otherwise
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
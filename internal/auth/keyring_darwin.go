@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFound is the exit status of security when no item matches
+const securityNotFound = 44
+
+// platformKeyring returns the login keychain, driven by /usr/bin/security
+func platformKeyring() keyring {
+	if _, err := lookPath("security"); err != nil {
+		return nil
+	}
+	return &commandKeyring{
+		name: "macOS Keychain",
+		get: func(service, account string) *exec.Cmd {
+			return exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+		},
+		set: func(service, account, secret string) *exec.Cmd {
+			// Read the command from stdin so the secret never shows in the process list
+			cmd := exec.Command("security", "-i")
+			cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+				service, account, hex.EncodeToString([]byte(secret))))
+			return cmd
+		},
+		del: func(service, account string) *exec.Cmd {
+			return exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+		},
+		notFound: func(err error, stderr string) bool {
+			return exitCode(err) == securityNotFound
+		},
+	}
+}
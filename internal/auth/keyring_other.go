@@ -0,0 +1,40 @@
+//go:build !darwin && !windows
+
+package auth
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// platformKeyring returns the Secret Service (GNOME Keyring, KWallet) through
+// libsecret's secret-tool. Without a D-Bus session, as over SSH or in
+// containers, there is nothing to talk to.
+func platformKeyring() keyring {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	if _, err := lookPath("secret-tool"); err != nil {
+		return nil
+	}
+	return &commandKeyring{
+		name: "Secret Service",
+		get: func(service, account string) *exec.Cmd {
+			return exec.Command("secret-tool", "lookup", "service", service, "account", account)
+		},
+		set: func(service, account, secret string) *exec.Cmd {
+			// secret-tool reads the secret from stdin, keeping it out of the process list
+			cmd := exec.Command("secret-tool", "store", "--label=Keyway CLI", "service", service, "account", account)
+			cmd.Stdin = strings.NewReader(secret)
+			return cmd
+		},
+		del: func(service, account string) *exec.Cmd {
+			return exec.Command("secret-tool", "clear", "service", service, "account", account)
+		},
+		notFound: func(err error, stderr string) bool {
+			// lookup exits with 1 and says nothing when no item matches
+			return exitCode(err) == 1 && strings.TrimSpace(stderr) == ""
+		},
+	}
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// fakeKeyring is an in-memory credential store
+type fakeKeyring struct {
+	items  map[string]string
+	setErr error
+}
+
+func newFakeKeyring() *fakeKeyring {
+	return &fakeKeyring{items: make(map[string]string)}
+}
+
+func (k *fakeKeyring) Name() string { return "fake keyring" }
+func (k *fakeKeyring) Get(service, account string) (string, error) {
+	secret, ok := k.items[service+"/"+account]
+	if !ok {
+		return "", errKeyringNotFound
+	}
+	return secret, nil
+}
+func (k *fakeKeyring) Set(service, account, secret string) error {
+	if k.setErr != nil {
+		return k.setErr
+	}
+	k.items[service+"/"+account] = secret
+	return nil
+}
+func (k *fakeKeyring) Delete(service, account string) error {
+	if _, ok := k.items[service+"/"+account]; !ok {
+		return errKeyringNotFound
+	}
+	delete(k.items, service+"/"+account)
+	return nil
+}
+
+func TestStore_Keyring_SaveAndGet(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	kr := newFakeKeyring()
+	store.keyring = kr
+
+	if err := store.SaveAuth("kw_token", "octocat", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(kr.items["keyway/auth"], "kw_token") {
+		t.Errorf("expected the session in the keyring, got %v", kr.items)
+	}
+	if fileAuth, _ := store.getFileAuth(keyringAccount); fileAuth != nil {
+		t.Error("no copy should be left in the file")
+	}
+
+	auth, err := store.GetAuth()
+	if err != nil || auth == nil || auth.KeywayToken != "kw_token" || auth.GitHubLogin != "octocat" {
+		t.Fatalf("unexpected auth %+v, %v", auth, err)
+	}
+	if store.Location() != "fake keyring" {
+		t.Errorf("unexpected location %q", store.Location())
+	}
+
+	if err := store.ClearAuth(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kr.items) != 0 {
+		t.Errorf("expected the keyring cleared, got %v", kr.items)
+	}
+}
+
+func TestStore_Keyring_CopiesFileSession(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	if err := store.SaveAuth("kw_legacy", "octocat", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	kr := newFakeKeyring()
+	store.keyring = kr
+	auth, err := store.GetAuth()
+	if err != nil || auth == nil || auth.KeywayToken != "kw_legacy" {
+		t.Fatalf("expected the file session, got %+v, %v", auth, err)
+	}
+	if !strings.Contains(kr.items["keyway/auth"], "kw_legacy") {
+		t.Error("expected the session copied to the keyring")
+	}
+	// The Node.js CLI shares the file: it must stay signed in
+	if fileAuth, _ := store.getFileAuth(keyringAccount); fileAuth == nil || fileAuth.KeywayToken != "kw_legacy" {
+		t.Errorf("expected the file session kept, got %+v", fileAuth)
+	}
+}
+
+func TestStore_Keyring_MovesOtherAccounts(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	loginAs(t, store, "kw_personal", "octocat")
+	loginAs(t, store, "kw_work", "octo-work")
+
+	kr := newFakeKeyring()
+	store.keyring = kr
+	if auth, err := store.GetAccount("octocat"); err != nil || auth == nil || auth.KeywayToken != "kw_personal" {
+		t.Fatalf("expected the stashed session, got %+v, %v", auth, err)
+	}
+	if !strings.Contains(kr.items["keyway/auth:octocat"], "kw_personal") {
+		t.Error("expected the session moved to the keyring")
+	}
+	if fileAuth, _ := store.getFileAuth(accountSlot("octocat")); fileAuth != nil {
+		t.Error("expected no copy of another account left in the file")
+	}
+}
+
+func TestStore_Keyring_FallsBackToFile(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	kr := newFakeKeyring()
+	kr.items["keyway/auth"] = `{"keywayToken":"kw_old"}`
+	kr.setErr = errors.New("keychain locked")
+	store.keyring = kr
+
+	if err := store.SaveAuth("kw_new", "octocat", ""); err != nil {
+		t.Fatalf("auto mode should fall back to the file, got %v", err)
+	}
+	auth, err := store.GetAuth()
+	if err != nil || auth == nil || auth.KeywayToken != "kw_new" {
+		t.Errorf("expected the new session from the file, got %+v, %v", auth, err)
+	}
+}
+
+func TestStore_Keyring_Required(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	store.requireKeyring = true
+
+	if err := store.SaveAuth("kw_token", "", ""); !errors.Is(err, errNoKeyring) {
+		t.Errorf("expected errNoKeyring, got %v", err)
+	}
+	if _, err := store.GetAuth(); !errors.Is(err, errNoKeyring) {
+		t.Errorf("expected errNoKeyring, got %v", err)
+	}
+
+	kr := newFakeKeyring()
+	kr.setErr = errors.New("keychain locked")
+	store.keyring = kr
+	if err := store.SaveAuth("kw_token", "", ""); err == nil || !strings.Contains(err.Error(), "keychain locked") {
+		t.Errorf("expected the keyring error, got %v", err)
+	}
+	if fileAuth, _ := store.getFileAuth(keyringAccount); fileAuth != nil {
+		t.Error("keychain mode must never write the file")
+	}
+}
+
+func TestStore_Keyring_Expired(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	kr := newFakeKeyring()
+	kr.items["keyway/auth"] = `{"keywayToken":"kw_token","expiresAt":"2020-01-01T00:00:00Z"}`
+	store.keyring = kr
+
+	if auth, err := store.GetAuth(); auth != nil || err != nil {
+		t.Errorf("expected no session, got %+v, %v", auth, err)
+	}
+	if len(kr.items) != 0 {
+		t.Error("expected the expired session cleared")
+	}
+}
+
+func TestCredentialStoreMode(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"", CredentialStoreAuto, false},
+		{"File", CredentialStoreFile, false},
+		{"keychain", CredentialStoreKeychain, false},
+		{"vault", "", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvCredentialStore, tt.env)
+		got, err := CredentialStoreMode()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%q: got %q, %v", tt.env, got, err)
+		}
+	}
+}
+
+func TestCommandKeyring(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs sh")
+	}
+	sh := func(script string) *exec.Cmd { return exec.Command("sh", "-c", script) }
+	kr := &commandKeyring{
+		name:     "test",
+		get:      func(service, account string) *exec.Cmd { return sh("echo secret") },
+		set:      func(service, account, secret string) *exec.Cmd { return sh("echo denied >&2; exit 2") },
+		del:      func(service, account string) *exec.Cmd { return sh("exit 44") },
+		notFound: func(err error, stderr string) bool { return exitCode(err) == 44 },
+	}
+
+	if got, err := kr.Get("keyway", "auth"); got != "secret" || err != nil {
+		t.Errorf("expected the secret without newline, got %q, %v", got, err)
+	}
+	if err := kr.Set("keyway", "auth", "secret"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected the utility's message, got %v", err)
+	}
+	if err := kr.Delete("keyway", "auth"); !errors.Is(err, errKeyringNotFound) {
+		t.Errorf("expected errKeyringNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// credential is the CREDENTIALW structure of wincred.h
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager stores generic credentials in the Windows Credential Manager
+type credentialManager struct{}
+
+// platformKeyring returns the Windows Credential Manager
+func platformKeyring() keyring {
+	if procCredRead.Find() != nil {
+		return nil
+	}
+	return credentialManager{}
+}
+
+func (credentialManager) Name() string { return "Windows Credential Manager" }
+
+// target names the credential, as listed in the Credential Manager
+func (credentialManager) target(service, account string) (*uint16, error) {
+	return windows.UTF16PtrFromString(service + ":" + account)
+}
+
+func (c credentialManager) Get(service, account string) (string, error) {
+	target, err := c.target(service, account)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return "", errKeyringNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (c credentialManager) Set(service, account, secret string) error {
+	target, err := c.target(service, account)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (c credentialManager) Delete(service, account string) error {
+	target, err := c.target(service, account)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return errKeyringNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OIDCProvider fetches a short-lived identity token from a CI platform.
+// The token is exchanged with the Keyway API for a session, so CI jobs
+// don't need a static KEYWAY_TOKEN.
+type OIDCProvider interface {
+	// Name is the value passed to keyway login --oidc
+	Name() string
+	// IDToken returns an identity token for the given audience
+	IDToken(ctx context.Context, audience string) (string, error)
+}
+
+var oidcProviders = map[string]OIDCProvider{
+	"github-actions": &githubActionsOIDC{},
+	"gitlab":         &gitlabOIDC{},
+}
+
+// OIDCProviderNames returns the supported provider names, sorted
+func OIDCProviderNames() []string {
+	names := make([]string, 0, len(oidcProviders))
+	for name := range oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetOIDCProvider returns the provider registered under name
+func GetOIDCProvider(name string) (OIDCProvider, error) {
+	provider, ok := oidcProviders[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown OIDC provider %q (supported: %s)", name, strings.Join(OIDCProviderNames(), ", "))
+	}
+	return provider, nil
+}
+
+// githubActionsOIDC requests a token from the GitHub Actions token endpoint.
+// The workflow needs the id-token: write permission.
+type githubActionsOIDC struct{}
+
+func (p *githubActionsOIDC) Name() string { return "github-actions" }
+
+func (p *githubActionsOIDC) IDToken(ctx context.Context, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", fmt.Errorf("GitHub Actions OIDC is not available: add 'permissions: id-token: write' to the workflow")
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("audience", audience)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub Actions ID token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitHub Actions ID token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub Actions ID token request failed: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.Value == "" {
+		return "", fmt.Errorf("unexpected GitHub Actions ID token response")
+	}
+	return result.Value, nil
+}
+
+// GitLabIDTokenEnv is the variable the job's id_tokens section must define
+const GitLabIDTokenEnv = "KEYWAY_ID_TOKEN"
+
+// gitlabOIDC reads the token GitLab injects through the job's id_tokens keyword.
+// GitLab sets the audience in .gitlab-ci.yml, so the requested one is not used.
+type gitlabOIDC struct{}
+
+func (p *gitlabOIDC) Name() string { return "gitlab" }
+
+func (p *gitlabOIDC) IDToken(ctx context.Context, audience string) (string, error) {
+	token := strings.TrimSpace(os.Getenv(GitLabIDTokenEnv))
+	if token == "" {
+		return "", fmt.Errorf("GitLab OIDC is not available: define %s under id_tokens in .gitlab-ci.yml (aud: %s)", GitLabIDTokenEnv, audience)
+	}
+	return token, nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetOIDCProvider(t *testing.T) {
+	for _, name := range []string{"github-actions", "GitLab"} {
+		if _, err := GetOIDCProvider(name); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+
+	_, err := GetOIDCProvider("circleci")
+	if err == nil || !strings.Contains(err.Error(), "github-actions, gitlab") {
+		t.Errorf("expected error listing supported providers, got %v", err)
+	}
+}
+
+func TestGitHubActionsOIDC_IDToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" {
+			t.Errorf("unexpected authorization header: %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("audience") != "https://api.keyway.sh" {
+			t.Errorf("unexpected audience: %q", r.URL.Query().Get("audience"))
+		}
+		if r.URL.Query().Get("api-version") != "2.0" {
+			t.Errorf("existing query parameters should be kept: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"value": "id-token"})
+	}))
+	defer server.Close()
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	token, err := (&githubActionsOIDC{}).IDToken(context.Background(), "https://api.keyway.sh")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "id-token" {
+		t.Errorf("expected id-token, got %q", token)
+	}
+}
+
+func TestGitHubActionsOIDC_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	_, err := (&githubActionsOIDC{}).IDToken(context.Background(), "aud")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected HTTP 403 error, got %v", err)
+	}
+}
+
+func TestGitHubActionsOIDC_NotAvailable(t *testing.T) {
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+
+	_, err := (&githubActionsOIDC{}).IDToken(context.Background(), "aud")
+	if err == nil || !strings.Contains(err.Error(), "id-token: write") {
+		t.Errorf("expected hint about id-token permission, got %v", err)
+	}
+}
+
+func TestGitLabOIDC_IDToken(t *testing.T) {
+	t.Setenv(GitLabIDTokenEnv, "gitlab-token\n")
+
+	token, err := (&gitlabOIDC{}).IDToken(context.Background(), "aud")
+	if err != nil || token != "gitlab-token" {
+		t.Errorf("expected gitlab-token, got %q (%v)", token, err)
+	}
+
+	t.Setenv(GitLabIDTokenEnv, "")
+	if _, err := (&gitlabOIDC{}).IDToken(context.Background(), "aud"); err == nil {
+		t.Error("expected error when the id_tokens variable is missing")
+	}
+}
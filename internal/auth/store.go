@@ -6,12 +6,15 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/keywaysh/cli/internal/state"
 )
 
 // StoredAuth represents the stored authentication data
@@ -22,10 +25,18 @@ type StoredAuth struct {
 	CreatedAt   string `json:"createdAt"`
 }
 
-// Store handles authentication storage
+// Store handles authentication storage. The session goes to the OS
+// credential store when there is one, else to a file encrypted with a key
+// kept next to it.
 type Store struct {
 	configPath string
 	keyPath    string
+	// accountsPath lists the accounts besides the default one (see accountIndex)
+	accountsPath string
+	// keyring holds the session when set; the encrypted file is the fallback
+	keyring keyring
+	// requireKeyring fails instead of falling back (KEYWAY_CREDENTIAL_STORE=keychain)
+	requireKeyring bool
 }
 
 // NewStore creates a new auth store
@@ -44,15 +55,94 @@ func NewStore() *Store {
 		configDir = filepath.Join(homeDir, ".config", "keyway-nodejs")
 	}
 
-	return &Store{
+	store := &Store{
 		configPath: filepath.Join(configDir, "config.json"),
 		keyPath:    filepath.Join(homeDir, ".keyway", ".key"),
 	}
+	if path, err := state.Path("accounts.json"); err == nil {
+		store.accountsPath = path
+	}
+
+	// An invalid KEYWAY_CREDENTIAL_STORE is reported by the root command and means auto
+	mode, _ := CredentialStoreMode()
+	if mode != CredentialStoreFile {
+		store.keyring = platformKeyring()
+		store.requireKeyring = mode == CredentialStoreKeychain
+	}
+	return store
+}
+
+// UsesKeyring reports whether the session may be in the OS credential store
+func (s *Store) UsesKeyring() bool {
+	return s.keyring != nil
+}
+
+// Location describes where SaveAuth stores the session
+func (s *Store) Location() string {
+	if s.UsesKeyring() {
+		return s.keyring.Name()
+	}
+	return s.configPath
 }
 
-// GetAuth retrieves stored authentication
+// GetAuth retrieves the session of the default account, from the OS
+// credential store first. A session found in the encrypted file, saved before
+// the credential store was available or by the Node.js CLI, is copied to the
+// credential store and left in the file for the Node.js CLI.
 func (s *Store) GetAuth() (*StoredAuth, error) {
-	// Read config file
+	return s.getSlot(keyringAccount)
+}
+
+// getSlot retrieves the session stored under slot (see accountSlot)
+func (s *Store) getSlot(slot string) (*StoredAuth, error) {
+	if s.requireKeyring && s.keyring == nil {
+		return nil, errNoKeyring
+	}
+	if s.keyring != nil {
+		data, err := s.keyring.Get(keyringService, slot)
+		if err == nil {
+			var auth StoredAuth
+			if err := json.Unmarshal([]byte(data), &auth); err != nil {
+				// Corrupted data, clear it
+				_ = s.clearSlot(slot)
+				return nil, nil
+			}
+			return s.unlessExpired(slot, &auth), nil
+		}
+		if s.requireKeyring && !errors.Is(err, errKeyringNotFound) {
+			return nil, err
+		}
+	}
+
+	auth, err := s.getFileAuth(slot)
+	if err != nil || auth == nil {
+		return nil, err
+	}
+	// Copy the session to the keyring. The default session stays in the
+	// file too: the Node.js CLI reads it there, and would be logged out.
+	// Other accounts are only known to this CLI and are moved.
+	if s.keyring != nil {
+		if data, err := json.Marshal(auth); err == nil && s.keyring.Set(keyringService, slot, string(data)) == nil && slot != keyringAccount {
+			_ = s.clearFile(slot)
+		}
+	}
+	return s.unlessExpired(slot, auth), nil
+}
+
+// unlessExpired returns auth, or clears slot and returns nil once it expired
+func (s *Store) unlessExpired(slot string, auth *StoredAuth) *StoredAuth {
+	if auth.ExpiresAt != "" {
+		expires, err := time.Parse(time.RFC3339, auth.ExpiresAt)
+		if err == nil && time.Now().After(expires) {
+			_ = s.clearSlot(slot)
+			return nil
+		}
+	}
+	return auth
+}
+
+// readFile returns the entries of the encrypted file, nil if it doesn't exist
+func (s *Store) readFile() (map[string]string, error) {
 	data, err := os.ReadFile(s.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -65,8 +155,17 @@ func (s *Store) GetAuth() (*StoredAuth, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	return config, nil
+}
 
-	encryptedAuth, ok := config["auth"]
+// getFileAuth retrieves the session stored under slot in the encrypted file
+func (s *Store) getFileAuth(slot string) (*StoredAuth, error) {
+	config, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedAuth, ok := config[slot]
 	if !ok || encryptedAuth == "" {
 		return nil, nil
 	}
@@ -75,7 +174,7 @@ func (s *Store) GetAuth() (*StoredAuth, error) {
 	decrypted, err := s.decrypt(encryptedAuth)
 	if err != nil {
 		// Corrupted data, clear it
-		_ = s.ClearAuth()
+		_ = s.clearSlot(slot)
 		return nil, nil
 	}
 
@@ -83,20 +182,11 @@ func (s *Store) GetAuth() (*StoredAuth, error) {
 	if err := json.Unmarshal([]byte(decrypted), &auth); err != nil {
 		return nil, err
 	}
-
-	// Check expiration
-	if auth.ExpiresAt != "" {
-		expires, err := time.Parse(time.RFC3339, auth.ExpiresAt)
-		if err == nil && time.Now().After(expires) {
-			_ = s.ClearAuth()
-			return nil, nil
-		}
-	}
-
 	return &auth, nil
 }
 
-// SaveAuth stores authentication data
+// SaveAuth stores the session of the default account. Signing in to another
+// account keeps the current one, for keyway account switch.
 func (s *Store) SaveAuth(token, githubLogin, expiresAt string) error {
 	auth := StoredAuth{
 		KeywayToken: token,
@@ -105,39 +195,96 @@ func (s *Store) SaveAuth(token, githubLogin, expiresAt string) error {
 		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
 	}
 
-	authJSON, err := json.Marshal(auth)
-	if err != nil {
-		return err
+	if githubLogin != "" {
+		if current, err := s.GetAuth(); err == nil && current != nil && current.GitHubLogin != "" && !strings.EqualFold(current.GitHubLogin, githubLogin) {
+			if err := s.stashAccount(current); err != nil {
+				return err
+			}
+		}
+		// This session replaces any other one of the same account
+		if err := s.dropStashedAccount(githubLogin); err != nil {
+			return err
+		}
 	}
+	return s.putSlot(keyringAccount, &auth)
+}
 
-	encrypted, err := s.encrypt(string(authJSON))
+// putSlot stores auth under slot
+func (s *Store) putSlot(slot string, auth *StoredAuth) error {
+	authJSON, err := json.Marshal(auth)
 	if err != nil {
 		return err
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(s.configPath), 0700); err != nil {
-		return err
+	if s.requireKeyring && s.keyring == nil {
+		return errNoKeyring
+	}
+	if s.keyring != nil {
+		err := s.keyring.Set(keyringService, slot, string(authJSON))
+		if err == nil {
+			// No copy is left on disk
+			return s.clearFile(slot)
+		}
+		if s.requireKeyring {
+			return fmt.Errorf("cannot save the session to the %s: %w", s.keyring.Name(), err)
+		}
+		// The file takes over: an older session must not shadow it
+		_ = s.keyring.Delete(keyringService, slot)
 	}
 
-	config := map[string]string{"auth": encrypted}
-	data, err := json.MarshalIndent(config, "", "  ")
+	encrypted, err := s.encrypt(string(authJSON))
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.configPath, data, 0600)
+	return s.updateFile(func(config map[string]string) {
+		config[slot] = encrypted
+	})
 }
 
-// ClearAuth removes stored authentication
+// ClearAuth removes the session of the default account, from the credential
+// store and the file
 func (s *Store) ClearAuth() error {
+	return s.clearSlot(keyringAccount)
+}
+
+// clearSlot removes the session stored under slot
+func (s *Store) clearSlot(slot string) error {
+	if s.keyring != nil {
+		err := s.keyring.Delete(keyringService, slot)
+		if err != nil && !errors.Is(err, errKeyringNotFound) {
+			_ = s.clearFile(slot)
+			return fmt.Errorf("cannot remove the session from the %s: %w", s.keyring.Name(), err)
+		}
+	}
+	return s.clearFile(slot)
+}
+
+// clearFile removes the session stored under slot from the encrypted file
+func (s *Store) clearFile(slot string) error {
 	if _, err := os.Stat(s.configPath); os.IsNotExist(err) {
 		return nil
 	}
+	return s.updateFile(func(config map[string]string) {
+		delete(config, slot)
+	})
+}
 
-	config := map[string]string{}
-	data, _ := json.MarshalIndent(config, "", "  ")
-	return os.WriteFile(s.configPath, data, 0600)
+// updateFile applies fn to the entries of the encrypted file and writes it
+// back; an unreadable file is replaced
+func (s *Store) updateFile(fn func(config map[string]string)) error {
+	return state.WithLock(s.configPath, func() error {
+		config, err := s.readFile()
+		if err != nil || config == nil {
+			config = map[string]string{}
+		}
+		fn(config)
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return err
+		}
+		return state.WriteFile(s.configPath, data, 0600)
+	})
 }
 
 // GetConfigPath returns the path to the config file
@@ -148,30 +295,43 @@ func (s *Store) GetConfigPath() string {
 // getOrCreateKey gets or creates the encryption key
 func (s *Store) getOrCreateKey() ([]byte, error) {
 	// Try to read existing key
-	keyHex, err := os.ReadFile(s.keyPath)
-	if err == nil && len(strings.TrimSpace(string(keyHex))) == 64 {
-		return hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if key, ok := s.readKey(); ok {
+		return key, nil
 	}
 
-	// Generate new key (32 bytes = 256 bits)
-	key := make([]byte, 32)
-	if _, err := rand.Read(key); err != nil {
-		return nil, fmt.Errorf("failed to generate key: %w", err)
-	}
+	// Two processes logging in at once must not each create a different key
+	var key []byte
+	err := state.WithLock(s.keyPath, func() error {
+		if existing, ok := s.readKey(); ok {
+			key = existing
+			return nil
+		}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
-		return nil, err
-	}
+		// Generate new key (32 bytes = 256 bits)
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
 
-	// Save key
-	if err := os.WriteFile(s.keyPath, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return state.WriteFile(s.keyPath, []byte(hex.EncodeToString(key)), 0600)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return key, nil
 }
 
+// readKey reads the encryption key, if a valid one exists
+func (s *Store) readKey() ([]byte, bool) {
+	keyHex, err := os.ReadFile(s.keyPath)
+	if err != nil || len(strings.TrimSpace(string(keyHex))) != 64 {
+		return nil, false
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	return key, err == nil
+}
+
 // encrypt encrypts plaintext using AES-256-GCM
 // Format: iv:authTag:encrypted (hex encoded) - compatible with Node.js CLI
 func (s *Store) encrypt(plaintext string) (string, error) {
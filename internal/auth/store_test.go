@@ -15,8 +15,9 @@ func newTestStore(t *testing.T) (*Store, func()) {
 	}
 
 	store := &Store{
-		configPath: filepath.Join(tmpDir, "config.json"),
-		keyPath:    filepath.Join(tmpDir, ".key"),
+		configPath:   filepath.Join(tmpDir, "config.json"),
+		keyPath:      filepath.Join(tmpDir, ".key"),
+		accountsPath: filepath.Join(tmpDir, "accounts.json"),
 	}
 
 	cleanup := func() {
@@ -0,0 +1,118 @@
+// Package background is the HTTP client of the requests keyway makes for
+// itself rather than for the user: the update check and telemetry.
+//
+// They never share the API client: they have their own timeouts and proxy,
+// announce themselves with their own User-Agent, and network.background =
+// "off" in keyway.toml (or KEYWAY_BACKGROUND_NETWORK=off) turns them all off
+// for locked-down environments, without touching API calls.
+package background
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+// Environment variables of background requests
+const (
+	// EnvNetwork is "off" to disable background requests, "on" to allow them
+	// whatever keyway.toml says
+	EnvNetwork = "KEYWAY_BACKGROUND_NETWORK"
+	// EnvProxy is the proxy URL of background requests, or "direct" for none.
+	// Unset, the standard HTTPS_PROXY and NO_PROXY variables apply.
+	EnvProxy = "KEYWAY_BACKGROUND_PROXY"
+	// EnvTimeout bounds each background request, e.g. 5s
+	EnvTimeout = "KEYWAY_BACKGROUND_TIMEOUT"
+)
+
+// UserAgent identifies background requests in proxy and firewall logs
+const UserAgent = "keyway-cli-background"
+
+// DefaultTimeout bounds each background request: nothing waits on them
+const DefaultTimeout = 2 * time.Second
+
+// loadProject is swapped in tests
+var loadProject = func() (*config.ProjectConfig, error) {
+	return config.LoadProject(".")
+}
+
+// Enabled reports whether background requests may be sent. KEYWAY_BACKGROUND_NETWORK
+// takes precedence over network.background in keyway.toml.
+func Enabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvNetwork))) {
+	case config.BackgroundOff, "0", "false":
+		return false
+	case config.BackgroundOn, "1", "true":
+		return true
+	}
+
+	project, err := loadProject()
+	if err != nil {
+		// An unreadable keyway.toml may hold "off": don't risk a request
+		return false
+	}
+	return project.Network.Background != config.BackgroundOff
+}
+
+// Timeouts returns the timeouts of background requests: short by default,
+// overridable with the KEYWAY_*_TIMEOUT variables of API requests, and
+// KEYWAY_BACKGROUND_TIMEOUT for the total
+func Timeouts() config.HTTPTimeouts {
+	timeouts, _ := config.HTTPTimeouts{
+		Connect:      time.Second,
+		TLSHandshake: time.Second,
+		Total:        DefaultTimeout,
+	}.ApplyEnv()
+	if raw := strings.TrimSpace(os.Getenv(EnvTimeout)); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+			timeouts.Total = d
+		}
+	}
+	return timeouts
+}
+
+// NewClient returns the HTTP client of background requests
+func NewClient() *http.Client {
+	client := api.NewHTTPClient(Timeouts())
+	transport := client.Transport.(*http.Transport)
+	transport.Proxy = proxy(os.Getenv(EnvProxy))
+	client.Transport = &userAgentTransport{base: transport}
+	return client
+}
+
+// proxy returns the proxy function of a KEYWAY_BACKGROUND_PROXY value. An
+// invalid value fails every request rather than bypass the intended proxy.
+func proxy(raw string) func(*http.Request) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	switch raw {
+	case "":
+		return http.ProxyFromEnvironment
+	case "direct":
+		return nil
+	}
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("invalid %s=%s", EnvProxy, raw)
+		}
+	}
+	return http.ProxyURL(proxyURL)
+}
+
+// userAgentTransport sets the User-Agent of background requests
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", UserAgent)
+	return t.base.RoundTrip(req)
+}
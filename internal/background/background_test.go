@@ -0,0 +1,90 @@
+package background
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func withProject(t *testing.T, cfg *config.ProjectConfig, err error) {
+	t.Helper()
+	old := loadProject
+	loadProject = func() (*config.ProjectConfig, error) { return cfg, err }
+	t.Cleanup(func() { loadProject = old })
+}
+
+func TestEnabled(t *testing.T) {
+	off := &config.ProjectConfig{Network: config.NetworkConfig{Background: config.BackgroundOff}}
+
+	tests := []struct {
+		name    string
+		env     string
+		project *config.ProjectConfig
+		err     error
+		want    bool
+	}{
+		{"default", "", &config.ProjectConfig{}, nil, true},
+		{"keyway.toml off", "", off, nil, false},
+		{"env off", "off", &config.ProjectConfig{}, nil, false},
+		{"env on overrides keyway.toml", "on", off, nil, true},
+		{"unreadable keyway.toml", "", nil, errors.New("bad toml"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvNetwork, tt.env)
+			withProject(t, tt.project, tt.err)
+			if got := Enabled(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	t.Setenv(EnvTimeout, "")
+	t.Setenv(config.EnvHTTPTimeout, "")
+	if got := Timeouts(); got.Total != DefaultTimeout || got.Connect != time.Second {
+		t.Errorf("unexpected default timeouts %+v", got)
+	}
+
+	t.Setenv(EnvTimeout, "5s")
+	if got := Timeouts(); got.Total != 5*time.Second {
+		t.Errorf("expected %s to apply, got %+v", EnvTimeout, got)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://api.github.com/", nil)
+
+	if proxy("direct") != nil {
+		t.Error("expected no proxy for direct")
+	}
+	if u, err := proxy("http://proxy.internal:3128")(req); err != nil || u.Host != "proxy.internal:3128" {
+		t.Errorf("expected the proxy, got %v, %v", u, err)
+	}
+	if _, err := proxy("::not a url")(req); err == nil {
+		t.Error("expected an invalid proxy to fail requests")
+	}
+}
+
+func TestNewClient_UserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer server.Close()
+	t.Setenv(EnvProxy, "direct")
+
+	resp, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if userAgent != UserAgent {
+		t.Errorf("expected User-Agent %q, got %q", UserAgent, userAgent)
+	}
+}
@@ -0,0 +1,7 @@
+package clipboard
+
+func platformBackends() []*commandBackend {
+	return []*commandBackend{
+		{name: "pbcopy", copyCmd: []string{"pbcopy"}, pasteCmd: []string{"pbpaste"}},
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !darwin && !windows
+
+package clipboard
+
+import "os"
+
+func platformBackends() []*commandBackend {
+	x11 := []*commandBackend{
+		{name: "xclip", copyCmd: []string{"xclip", "-selection", "clipboard"}, pasteCmd: []string{"xclip", "-selection", "clipboard", "-o"}},
+		{name: "xsel", copyCmd: []string{"xsel", "--clipboard", "--input"}, pasteCmd: []string{"xsel", "--clipboard", "--output"}},
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return x11
+	}
+
+	wayland := &commandBackend{
+		name:     "wl-copy",
+		copyCmd:  []string{"wl-copy"},
+		pasteCmd: []string{"wl-paste", "--no-newline"},
+		clearCmd: []string{"wl-copy", "--clear"},
+	}
+	return append([]*commandBackend{wayland}, x11...)
+}
@@ -0,0 +1,13 @@
+package clipboard
+
+func platformBackends() []*commandBackend {
+	// clip.exe mangles non-ASCII input, so go through PowerShell for both directions
+	return []*commandBackend{
+		{
+			name:     "powershell",
+			copyCmd:  []string{"powershell", "-NoProfile", "-Command", "Set-Clipboard -Value ([Console]::In.ReadToEnd())"},
+			pasteCmd: []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+			clearCmd: []string{"powershell", "-NoProfile", "-Command", "Set-Clipboard -Value $null"},
+		},
+	}
+}
@@ -0,0 +1,91 @@
+// Package clipboard provides access to the system clipboard through
+// platform-specific command-line utilities (pbcopy, wl-copy, xclip, ...).
+package clipboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnavailable is returned when no clipboard utility is installed
+var ErrUnavailable = errors.New("no clipboard utility found")
+
+// Backend reads and writes the system clipboard
+type Backend interface {
+	Name() string
+	Write(text string) error
+	Read() (string, error)
+	Clear() error
+}
+
+// commandBackend drives external copy/paste utilities
+type commandBackend struct {
+	name     string
+	copyCmd  []string
+	pasteCmd []string
+	clearCmd []string // optional, defaults to writing an empty string
+}
+
+func (b *commandBackend) Name() string { return b.name }
+
+func (b *commandBackend) Write(text string) error {
+	cmd := exec.Command(b.copyCmd[0], b.copyCmd[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+func (b *commandBackend) Read() (string, error) {
+	output, err := exec.Command(b.pasteCmd[0], b.pasteCmd[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (b *commandBackend) Clear() error {
+	if len(b.clearCmd) > 0 {
+		return exec.Command(b.clearCmd[0], b.clearCmd[1:]...).Run()
+	}
+	return b.Write("")
+}
+
+// lookPath is a variable so tests can simulate installed utilities
+var lookPath = exec.LookPath
+
+// Detect returns the first clipboard backend available on this machine
+func Detect() (Backend, error) {
+	for _, b := range platformBackends() {
+		if _, err := lookPath(b.copyCmd[0]); err == nil {
+			return b, nil
+		}
+	}
+	return nil, ErrUnavailable
+}
+
+// Digest returns a fingerprint of a clipboard value, so a value can later be
+// recognized without keeping it in memory or passing it to another process
+func Digest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// ClearIfUnchanged clears the clipboard only if it still holds the value with
+// the given digest. Returns true if the clipboard was cleared.
+// Anything the user copied in the meantime is left untouched.
+func ClearIfUnchanged(b Backend, digest string) (bool, error) {
+	current, err := b.Read()
+	if err != nil {
+		return false, err
+	}
+	// Some paste utilities append a trailing newline
+	if Digest(current) != digest && Digest(strings.TrimSuffix(current, "\n")) != digest {
+		return false, nil
+	}
+	if err := b.Clear(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeBackend is an in-memory clipboard
+type fakeBackend struct {
+	content  string
+	readErr  error
+	clearErr error
+	cleared  bool
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+func (f *fakeBackend) Write(text string) error {
+	f.content = text
+	return nil
+}
+func (f *fakeBackend) Read() (string, error) { return f.content, f.readErr }
+func (f *fakeBackend) Clear() error {
+	if f.clearErr != nil {
+		return f.clearErr
+	}
+	f.cleared = true
+	f.content = ""
+	return nil
+}
+
+func TestDigest_Deterministic(t *testing.T) {
+	if Digest("secret") != Digest("secret") {
+		t.Error("Digest should be deterministic")
+	}
+	if Digest("secret") == Digest("other") {
+		t.Error("Digest should differ for different values")
+	}
+	if Digest("secret") == "secret" {
+		t.Error("Digest must not return the value itself")
+	}
+}
+
+func TestClearIfUnchanged_SameValue(t *testing.T) {
+	b := &fakeBackend{content: "sk_live_123"}
+
+	cleared, err := ClearIfUnchanged(b, Digest("sk_live_123"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cleared || !b.cleared {
+		t.Error("expected clipboard to be cleared")
+	}
+}
+
+func TestClearIfUnchanged_TrailingNewline(t *testing.T) {
+	b := &fakeBackend{content: "sk_live_123\n"}
+
+	cleared, err := ClearIfUnchanged(b, Digest("sk_live_123"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cleared {
+		t.Error("expected clipboard to be cleared despite trailing newline")
+	}
+}
+
+func TestClearIfUnchanged_UserCopiedSomethingElse(t *testing.T) {
+	b := &fakeBackend{content: "something the user copied"}
+
+	cleared, err := ClearIfUnchanged(b, Digest("sk_live_123"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cleared || b.cleared {
+		t.Error("clipboard should be left untouched")
+	}
+	if b.content != "something the user copied" {
+		t.Errorf("clipboard content changed: %q", b.content)
+	}
+}
+
+func TestClearIfUnchanged_ReadError(t *testing.T) {
+	b := &fakeBackend{readErr: errors.New("no display")}
+
+	if _, err := ClearIfUnchanged(b, Digest("x")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestClearIfUnchanged_ClearError(t *testing.T) {
+	b := &fakeBackend{content: "x", clearErr: errors.New("failed")}
+
+	cleared, err := ClearIfUnchanged(b, Digest("x"))
+	if err == nil {
+		t.Error("expected error")
+	}
+	if cleared {
+		t.Error("expected cleared=false on error")
+	}
+}
+
+func TestDetect_NoUtility(t *testing.T) {
+	original := lookPath
+	defer func() { lookPath = original }()
+	lookPath = func(file string) (string, error) { return "", errors.New("not found") }
+
+	if _, err := Detect(); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestDetect_FirstAvailable(t *testing.T) {
+	original := lookPath
+	defer func() { lookPath = original }()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	b, err := Detect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Name() != platformBackends()[0].name {
+		t.Errorf("expected first backend %q, got %q", platformBackends()[0].name, b.Name())
+	}
+}
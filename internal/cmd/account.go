@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keywaysh/cli/internal/auth"
+	"github.com/keywaysh/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage the GitHub accounts you are signed in with",
+	Long: `Use several GitHub accounts, e.g. a personal one and one for work.
+
+Signing in with keyway login to another account keeps the current one. Each
+repository uses the account bound to it or to its owner, else the default
+account. The first time a repository of an unbound owner needs a session,
+keyway asks which account to use and remembers the answer for the owner.
+
+Examples:
+  keyway account list
+  keyway account switch octo-work           # Make it the default account
+  keyway account switch octo-work --org     # Use it for this repository's owner
+  keyway account switch octocat --this-repo # Use it for this repository only
+  keyway account switch octocat --this-repo --repo acme/api`,
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List signed-in accounts and where they are used",
+	Args:  cobra.NoArgs,
+	RunE:  runAccountList,
+}
+
+var accountSwitchCmd = &cobra.Command{
+	Use:   "switch [login]",
+	Short: "Change the default account, or the one of this repository",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runAccountSwitch,
+}
+
+func init() {
+	accountSwitchCmd.Flags().Bool("this-repo", false, "Use the account for the current repository only (the global --repo picks another one)")
+	accountSwitchCmd.Flags().Bool("org", false, "Use the account for every repository of the current repository's owner")
+
+	accountCmd.AddCommand(accountListCmd)
+	accountCmd.AddCommand(accountSwitchCmd)
+}
+
+// AccountSwitchOptions contains the parsed arguments of account switch
+type AccountSwitchOptions struct {
+	Login    string
+	ThisRepo bool
+	Org      bool
+}
+
+func runAccountList(cmd *cobra.Command, args []string) error {
+	return runAccountListWithDeps(os.Stdout, defaultDeps)
+}
+
+// runAccountListWithDeps is the testable version of runAccountList
+func runAccountListWithDeps(w io.Writer, deps *Dependencies) error {
+	deps.UI.Intro("account list")
+
+	accounts, err := deps.Accounts.Accounts()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if len(accounts) == 0 {
+		deps.UI.Message("Not signed in.")
+		deps.UI.Message(deps.UI.Dim("Sign in with: keyway login"))
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  ACCOUNT\tUSED FOR")
+	for _, a := range accounts {
+		usedFor := a.Bindings
+		if a.Default {
+			usedFor = append([]string{"default"}, usedFor...)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", accountName(a.Login), strings.Join(usedFor, ", "))
+	}
+	_ = tw.Flush()
+
+	if repo, err := deps.Git.DetectRepo(); err == nil && len(accounts) > 1 {
+		login := deps.Accounts.AccountFor(repo)
+		if login == "" {
+			login = accounts[0].Login
+		}
+		deps.UI.Outro(fmt.Sprintf("%s uses %s", repo, accountName(login)))
+	}
+	return nil
+}
+
+func runAccountSwitch(cmd *cobra.Command, args []string) error {
+	var opts AccountSwitchOptions
+	if len(args) > 0 {
+		opts.Login = args[0]
+	}
+	opts.ThisRepo, _ = cmd.Flags().GetBool("this-repo")
+	opts.Org, _ = cmd.Flags().GetBool("org")
+	return runAccountSwitchWithDeps(opts, defaultDeps)
+}
+
+// runAccountSwitchWithDeps is the testable version of runAccountSwitch
+func runAccountSwitchWithDeps(opts AccountSwitchOptions, deps *Dependencies) error {
+	deps.UI.Intro("account switch")
+
+	if opts.ThisRepo && opts.Org {
+		deps.UI.Error("--this-repo and --org cannot be combined")
+		return fmt.Errorf("--this-repo and --org cannot be combined")
+	}
+
+	accounts, err := deps.Accounts.Accounts()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if len(accounts) == 0 {
+		deps.UI.Error("Not signed in")
+		deps.UI.Message(deps.UI.Dim("Sign in with: keyway login"))
+		return fmt.Errorf("not signed in")
+	}
+
+	login := strings.TrimPrefix(strings.TrimSpace(opts.Login), "@")
+	if login == "" {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error("Pass the account to switch to")
+			return fmt.Errorf("login is required")
+		}
+		options := make([]string, len(accounts))
+		for i, a := range accounts {
+			options[i] = a.Login
+		}
+		if login, err = deps.UI.Select("Account:", options); err != nil {
+			return err
+		}
+	}
+
+	found := false
+	for _, a := range accounts {
+		if strings.EqualFold(a.Login, login) {
+			login, found = a.Login, true
+			break
+		}
+	}
+	if !found {
+		deps.UI.Error(fmt.Sprintf("Not signed in as %s", accountName(login)))
+		deps.UI.Message(deps.UI.Dim("Sign in with: keyway login (your other accounts stay signed in)"))
+		return fmt.Errorf("unknown account %s", login)
+	}
+
+	if !opts.ThisRepo && !opts.Org {
+		if err := deps.Accounts.SwitchAccount(login); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		deps.UI.Success(fmt.Sprintf("Default account: %s", accountName(login)))
+		if repo, err := deps.Git.DetectRepo(); err == nil {
+			if bound := deps.Accounts.AccountFor(repo); bound != "" && !strings.EqualFold(bound, login) {
+				deps.UI.Message(deps.UI.Dim(fmt.Sprintf("%s still uses %s (keyway account switch %s --this-repo)", repo, accountName(bound), login)))
+			}
+		}
+		return nil
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	scope := repo
+	if opts.Org {
+		scope, _, _ = strings.Cut(repo, "/")
+	}
+	if err := deps.Accounts.BindAccount(scope, login); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if opts.Org {
+		deps.UI.Success(fmt.Sprintf("Repositories of %s use %s", scope, accountName(login)))
+	} else {
+		deps.UI.Success(fmt.Sprintf("%s uses %s", scope, accountName(login)))
+	}
+	return nil
+}
+
+// accountName formats a login for display
+func accountName(login string) string {
+	if login == "" {
+		return "(unknown account)"
+	}
+	return "@" + login
+}
+
+// sessionLogin is the account EnsureLogin returned a session of, when it is
+// not the default account: a rejected token then signs out that account
+var sessionLogin string
+
+// sessionForRepo returns the session of the account bound to the current
+// repository or its owner. With several accounts and no binding, an
+// interactive terminal asks once which one the owner's repositories use.
+// Otherwise, the default account's session is returned.
+func sessionForRepo(store *auth.Store) (*auth.StoredAuth, error) {
+	sessionLogin = ""
+	if !store.HasOtherAccounts() {
+		return store.GetAuth()
+	}
+	repo, err := defaultDeps.Git.DetectRepo()
+	if err != nil || repo == "" {
+		return store.GetAuth()
+	}
+
+	login := store.AccountFor(repo)
+	if login == "" && ui.IsInteractive() {
+		login = chooseAccount(store, repo)
+	}
+	current, err := store.GetAuth()
+	if login == "" || (current != nil && strings.EqualFold(current.GitHubLogin, login)) {
+		return current, err
+	}
+	if bound, err := store.GetAccount(login); err == nil && bound != nil {
+		sessionLogin = bound.GitHubLogin
+		return bound, nil
+	}
+	return current, err
+}
+
+// chooseAccount asks which account the repositories of repo's owner use and
+// remembers it. Returns "" if nothing was chosen.
+func chooseAccount(store *auth.Store, repo string) string {
+	accounts, err := store.Accounts()
+	if err != nil || len(accounts) < 2 {
+		return ""
+	}
+	options := make([]string, len(accounts))
+	for i, a := range accounts {
+		options[i] = a.Login
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	login, err := ui.Select(fmt.Sprintf("Account for %s repositories:", owner), options)
+	if err != nil {
+		return ""
+	}
+	if err := store.BindAccount(owner, login); err == nil {
+		ui.Message(ui.Dim("Saved. Change it with: keyway account switch <login> --org"))
+	}
+	return login
+}
+
+// clearSession signs out the account whose token the API rejected
+func clearSession(store *auth.Store) {
+	if sessionLogin != "" {
+		_, _ = store.RemoveAccount(sessionLogin)
+		sessionLogin = ""
+		return
+	}
+	_ = store.ClearAuth()
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func accountTestDeps() (*Dependencies, *MockUIProvider, *MockAccountStore) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	accounts := deps.Accounts.(*MockAccountStore)
+	accounts.List = []AccountInfo{
+		{Login: "octocat", Default: true},
+		{Login: "octo-work", Bindings: []string{"owner"}},
+	}
+	accounts.Bindings = map[string]string{"owner": "octo-work"}
+	return deps, uiMock, accounts
+}
+
+func TestRunAccountListWithDeps(t *testing.T) {
+	deps, uiMock, _ := accountTestDeps()
+	var out bytes.Buffer
+
+	if err := runAccountListWithDeps(&out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "@octocat     default") || !strings.Contains(out.String(), "@octo-work   owner") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if len(uiMock.OutroCalls) != 1 || uiMock.OutroCalls[0] != "owner/repo uses @octo-work" {
+		t.Errorf("expected the account of this repository, got %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunAccountListWithDeps_NotSignedIn(t *testing.T) {
+	deps, uiMock, accounts := accountTestDeps()
+	accounts.List = nil
+	var out bytes.Buffer
+
+	if err := runAccountListWithDeps(&out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Len() != 0 || !strings.Contains(strings.Join(uiMock.MessageCalls, "\n"), "keyway login") {
+		t.Errorf("expected a login hint, got %q, %v", out.String(), uiMock.MessageCalls)
+	}
+}
+
+func TestRunAccountSwitchWithDeps_Default(t *testing.T) {
+	deps, uiMock, accounts := accountTestDeps()
+	accounts.Bindings = nil
+
+	if err := runAccountSwitchWithDeps(AccountSwitchOptions{Login: "@Octo-Work"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(accounts.Switched) != 1 || accounts.Switched[0] != "octo-work" {
+		t.Errorf("expected a switch to octo-work, got %v", accounts.Switched)
+	}
+	if len(uiMock.SuccessCalls) != 1 || uiMock.SuccessCalls[0] != "Default account: @octo-work" {
+		t.Errorf("unexpected success %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunAccountSwitchWithDeps_DefaultKeepsBinding(t *testing.T) {
+	deps, uiMock, _ := accountTestDeps()
+
+	if err := runAccountSwitchWithDeps(AccountSwitchOptions{Login: "octocat"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(uiMock.MessageCalls, "\n"), "owner/repo still uses @octo-work") {
+		t.Errorf("expected a note about the binding, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunAccountSwitchWithDeps_Bind(t *testing.T) {
+	deps, _, accounts := accountTestDeps()
+
+	if err := runAccountSwitchWithDeps(AccountSwitchOptions{Login: "octocat", ThisRepo: true}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accounts.Bindings["owner/repo"] != "octocat" {
+		t.Errorf("expected the repository bound, got %v", accounts.Bindings)
+	}
+
+	if err := runAccountSwitchWithDeps(AccountSwitchOptions{Login: "octocat", Org: true}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accounts.Bindings["owner"] != "octocat" {
+		t.Errorf("expected the owner bound, got %v", accounts.Bindings)
+	}
+	if len(accounts.Switched) != 0 {
+		t.Errorf("binding must not change the default account, got %v", accounts.Switched)
+	}
+}
+
+func TestRunAccountSwitchWithDeps_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AccountSwitchOptions
+		want string
+	}{
+		{"unknown account", AccountSwitchOptions{Login: "nobody"}, "unknown account nobody"},
+		{"no login in scripts", AccountSwitchOptions{}, "login is required"},
+		{"both scopes", AccountSwitchOptions{Login: "octocat", ThisRepo: true, Org: true}, "cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, accounts := accountTestDeps()
+			err := runAccountSwitchWithDeps(tt.opts, deps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+			if len(accounts.Switched) != 0 {
+				t.Errorf("expected no switch, got %v", accounts.Switched)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/spf13/cobra"
+)
+
+var activityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Show which environments were injected on this machine",
+	Long: `Show the local history of environments injected by keyway run and written by keyway pull.
+
+The history is opt-in: set KEYWAY_ACTIVITY_LOG=1 to record it. Entries are kept in
+~/.keyway/state/activity.jsonl and hold key names and a digest of the environment
+version, never values.
+
+Examples:
+  keyway activity
+  keyway activity --since 7d --env production
+  keyway activity --json`,
+	Args: cobra.NoArgs,
+	RunE: runActivity,
+}
+
+func init() {
+	activityCmd.Flags().String("since", "24h", "How far back to look (e.g. 30m, 24h, 7d)")
+	activityCmd.Flags().StringP("env", "e", "", "Only show this environment")
+	activityCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// ActivityOptions contains the parsed flags for the activity command
+type ActivityOptions struct {
+	Since      string
+	EnvName    string
+	JSONOutput bool
+}
+
+func runActivity(cmd *cobra.Command, args []string) error {
+	opts := ActivityOptions{}
+	opts.Since, _ = cmd.Flags().GetString("since")
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runActivityWithDeps(opts, defaultDeps)
+}
+
+// runActivityWithDeps is the testable version of runActivity
+func runActivityWithDeps(opts ActivityOptions, deps *Dependencies) error {
+	since, err := activity.ParseSince(opts.Since)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	entries, err := deps.Activity.Since(time.Now().Add(-since))
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to read activity log: %s", err.Error()))
+		return err
+	}
+
+	if opts.EnvName != "" {
+		filtered := entries[:0]
+		for _, e := range entries {
+			if strings.EqualFold(e.Environment, opts.EnvName) {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+
+	if opts.JSONOutput {
+		if entries == nil {
+			entries = []activity.Entry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	deps.UI.Intro("activity")
+
+	if len(entries) == 0 {
+		deps.UI.Message(fmt.Sprintf("No activity in the last %s.", opts.Since))
+		if !deps.Activity.Enabled() {
+			deps.UI.Message(deps.UI.Dim("Recording is off. Enable it with: export KEYWAY_ACTIVITY_LOG=1"))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tREPOSITORY\tENVIRONMENT\tVERSION\tKEYS\tTARGET")
+	for _, e := range entries {
+		target := e.Command
+		if e.Action == activity.ActionPull {
+			target = "pull > " + e.File
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04"), e.Repository, e.Environment, shortDigest(e.VaultDigest), len(e.Keys), target)
+	}
+	w.Flush()
+
+	deps.UI.Outro(fmt.Sprintf("%d entries", len(entries)))
+	return nil
+}
+
+// recordActivity appends to the local activity log when it is enabled.
+// Failures only warn: the history must never block an injection.
+func recordActivity(deps *Dependencies, entry activity.Entry) {
+	if !deps.Activity.Enabled() {
+		return
+	}
+	entry.Time = time.Now().UTC()
+	if err := deps.Activity.Record(entry); err != nil {
+		deps.UI.Warn(fmt.Sprintf("Failed to record activity: %s", err.Error()))
+	}
+}
+
+// shortDigest returns the first 12 characters of a digest
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+// sortedSecretKeys returns the key names of a secrets map
+func sortedSecretKeys(secrets map[string]string) []string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunRunWithDeps_RecordsActivity(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDepsWithRunner()
+	activityLog := deps.Activity.(*MockActivityLog)
+	activityLog.IsEnabled = true
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "B=2\nA=1"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "docker", Args: []string{"run", "app"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(activityLog.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(activityLog.Entries))
+	}
+	e := activityLog.Entries[0]
+	if e.Action != activity.ActionRun || e.Environment != "production" || e.Repository != "owner/repo" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.Command != "docker run app" {
+		t.Errorf("unexpected command: %q", e.Command)
+	}
+	if strings.Join(e.Keys, ",") != "A,B" {
+		t.Errorf("expected sorted keys, got %v", e.Keys)
+	}
+	if e.VaultDigest != contentDigest("B=2\nA=1") {
+		t.Errorf("unexpected digest: %s", e.VaultDigest)
+	}
+}
+
+func TestRunRunWithDeps_ActivityDisabled(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := len(deps.Activity.(*MockActivityLog).Entries); n != 0 {
+		t.Errorf("expected nothing recorded, got %d entries", n)
+	}
+}
+
+func TestRunRunWithDeps_ActivityRecordFailureOnlyWarns(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	activityLog := deps.Activity.(*MockActivityLog)
+	activityLog.IsEnabled = true
+	activityLog.RecordError = errors.New("disk full")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "npm" {
+		t.Error("expected command to run")
+	}
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "disk full") {
+		t.Errorf("expected a warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunPullWithDeps_RecordsActivity(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	activityLog := deps.Activity.(*MockActivityLog)
+	activityLog.IsEnabled = true
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := PullOptions{EnvName: "staging", File: ".env.staging", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(activityLog.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(activityLog.Entries))
+	}
+	e := activityLog.Entries[0]
+	if e.Action != activity.ActionPull || e.File != ".env.staging" || e.Keys[0] != "API_KEY" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestRunActivityWithDeps_FiltersByEnvironment(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	activityLog := deps.Activity.(*MockActivityLog)
+	now := time.Now()
+	activityLog.Entries = []activity.Entry{
+		{Time: now.Add(-time.Hour), Action: activity.ActionRun, Environment: "production", Command: "./deploy.sh"},
+		{Time: now.Add(-2 * time.Hour), Action: activity.ActionRun, Environment: "staging", Command: "npm test"},
+		{Time: now.Add(-72 * time.Hour), Action: activity.ActionRun, Environment: "production", Command: "old"},
+	}
+
+	err := runActivityWithDeps(ActivityOptions{Since: "24h", EnvName: "Production"}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(activityLog.SinceCalls) != 1 || time.Since(activityLog.SinceCalls[0]) < 24*time.Hour {
+		t.Errorf("unexpected lookback: %v", activityLog.SinceCalls)
+	}
+	if len(uiMock.OutroCalls) != 1 || uiMock.OutroCalls[0] != "1 entries" {
+		t.Errorf("expected 1 entry, got %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunActivityWithDeps_EmptyHintsWhenDisabled(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runActivityWithDeps(ActivityOptions{Since: "24h"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, msg := range uiMock.MessageCalls {
+		if strings.Contains(msg, "KEYWAY_ACTIVITY_LOG=1") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected enable hint, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunActivityWithDeps_InvalidSince(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runActivityWithDeps(ActivityOptions{Since: "yesterday"}, deps); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// expandAlias replaces a leading alias from keyway.toml's [alias] table with
+// its arguments. Built-in commands always win over aliases, and aliases do not
+// expand other aliases. Returns args unchanged when the first one is not an alias.
+func expandAlias(root *cobra.Command, args []string, aliases map[string]string) ([]string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(root, args[0]) {
+		return args, nil
+	}
+	expansion, ok := aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+
+	expanded, err := splitArgs(expansion)
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", args[0], err)
+	}
+	if len(expanded) == 0 || !isBuiltinCommand(root, expanded[0]) {
+		return nil, fmt.Errorf("alias %q must start with a keyway command, got %q", args[0], expansion)
+	}
+	return append(expanded, args[1:]...), nil
+}
+
+// isBuiltinCommand returns true if name is a subcommand of root or one of cobra's own
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitArgs splits a command line on whitespace, honoring single and double
+// quotes and backslash escapes (outside single quotes)
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg, escaped := false, false
+	quote := rune(0)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// applyConfigAlias expands an alias defined in keyway.toml into root's arguments.
+// keyway.toml is only read when the first argument is not a built-in command.
+func applyConfigAlias(root *cobra.Command, args []string, deps *Dependencies) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(root, args[0]) {
+		return nil
+	}
+	project, err := deps.Config.LoadProject()
+	if err != nil || len(project.Aliases) == 0 {
+		// Let cobra report the unknown command
+		return nil
+	}
+
+	expanded, err := expandAlias(root, args, project.Aliases)
+	if err != nil {
+		return err
+	}
+	root.SetArgs(expanded)
+	return nil
+}
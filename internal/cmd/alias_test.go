@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newAliasTestRoot() *cobra.Command {
+	root := &cobra.Command{Use: "keyway"}
+	root.AddCommand(&cobra.Command{Use: "run", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(&cobra.Command{Use: "pull", Run: func(*cobra.Command, []string) {}})
+	return root
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"run -e development -- npm run dev", []string{"run", "-e", "development", "--", "npm", "run", "dev"}},
+		{`run -- sh -c "echo $HOME && ls"`, []string{"run", "--", "sh", "-c", "echo $HOME && ls"}},
+		{`pull -f 'my file.env'`, []string{"pull", "-f", "my file.env"}},
+		{`pull -f my\ file.env`, []string{"pull", "-f", "my file.env"}},
+		{`run -- echo ""`, []string{"run", "--", "echo", ""}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("%q: expected %q, got %q", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestSplitArgs_Errors(t *testing.T) {
+	for _, input := range []string{`run "unterminated`, `run 'x`, `run \`} {
+		if _, err := splitArgs(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	root := newAliasTestRoot()
+	aliases := map[string]string{
+		"dev":  "run -e development -- npm run dev",
+		"pull": "run -- echo shadowed",
+		"bad":  "npm run dev",
+	}
+
+	got, err := expandAlias(root, []string{"dev", "--watch"}, aliases)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, " ") != "run -e development -- npm run dev --watch" {
+		t.Errorf("unexpected expansion %q", got)
+	}
+
+	got, _ = expandAlias(root, []string{"pull", "-y"}, aliases)
+	if strings.Join(got, " ") != "pull -y" {
+		t.Errorf("built-in command should win over alias, got %q", got)
+	}
+
+	got, _ = expandAlias(root, []string{"unknown"}, aliases)
+	if strings.Join(got, " ") != "unknown" {
+		t.Errorf("unknown command should be left alone, got %q", got)
+	}
+
+	if _, err := expandAlias(root, []string{"bad"}, aliases); err == nil {
+		t.Error("expected error for alias not starting with a keyway command")
+	}
+}
+
+func TestApplyConfigAlias(t *testing.T) {
+	root := newAliasTestRoot()
+	deps, _, _, _, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Project: &config.ProjectConfig{
+		Aliases: map[string]string{"dev": "run -- npm run dev"},
+	}}
+
+	var ran []string
+	root.Commands()[1].Run = func(cmd *cobra.Command, args []string) { ran = args }
+
+	if err := applyConfigAlias(root, []string{"dev"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(ran, " ") != "npm run dev" {
+		t.Errorf("expected run to receive the alias arguments, got %q", ran)
+	}
+}
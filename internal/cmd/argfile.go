@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expandArgFiles replaces each @file argument before the first -- with the
+// arguments read from file, so CI scripts can keep long command lines (a
+// docker run with dozens of flags) in a file:
+//
+//	keyway run @deploy.args
+//
+// The file may itself hold "-- docker run ...": the arguments of the command
+// are never expanded, as @ means something else to curl, npm and others.
+// @@name passes a literal @name. Files are read once, not recursively.
+func expandArgFiles(args []string, readFile func(name string) ([]byte, error)) ([]string, error) {
+	var expanded []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...), nil
+		}
+		switch {
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			data, err := readFile(arg[1:])
+			if err != nil {
+				return nil, fmt.Errorf("cannot read argument file %s: %w", arg[1:], err)
+			}
+			fileArgs, err := parseArgFile(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument file %s: %w", arg[1:], err)
+			}
+			expanded = append(expanded, fileArgs...)
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+// parseArgFile splits the content of an argument file into arguments, quoted
+// as in a shell. Lines starting with # are comments, and a trailing \ is
+// allowed for readability: line breaks separate arguments anyway.
+func parseArgFile(content string) ([]string, error) {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\\"))
+	}
+	return splitArgs(strings.Join(lines, "\n"))
+}
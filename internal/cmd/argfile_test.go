@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandArgFiles(t *testing.T) {
+	files := map[string]string{
+		"deploy.args": `# production API container
+--env production
+-- docker run --rm \
+  -p 8080:8080 \
+  # secrets are passed by name
+  -e API_KEY -e DATABASE_URL \
+  --label "team=payments api" \
+  ghcr.io/acme/api:latest
+`,
+		"env.args": "-e staging",
+	}
+	readFile := func(name string) ([]byte, error) {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+		return nil, errors.New("file not found")
+	}
+
+	got, err := expandArgFiles([]string{"run", "@deploy.args"}, readFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"run", "--env", "production", "--", "docker", "run", "--rm", "-p", "8080:8080",
+		"-e", "API_KEY", "-e", "DATABASE_URL", "--label", "team=payments api", "ghcr.io/acme/api:latest"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, _ = expandArgFiles([]string{"run", "@env.args", "@@literal", "--", "curl", "-d", "@body.json"}, readFile)
+	want = []string{"run", "-e", "staging", "@literal", "--", "curl", "-d", "@body.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("command arguments should be left alone: got %q, want %q", got, want)
+	}
+
+	if _, err := expandArgFiles([]string{"run", "@missing.args"}, readFile); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestParseArgFile_Unterminated(t *testing.T) {
+	if _, err := parseArgFile(`--label "unterminated`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
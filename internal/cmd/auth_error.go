@@ -6,18 +6,23 @@ import (
 )
 
 // handleAuthError checks if the error is a 401 and handles it appropriately.
+// A rejected KEYWAY_TOKEN is reported as is: signing in again wouldn't replace it.
 // In interactive mode, it clears the stored auth and prompts for re-login.
 // In non-interactive mode, it shows a clear error message.
 // Returns the new token if re-login was successful, empty string and original error otherwise.
 func handleAuthError(err error, deps *Dependencies) (string, error) {
-	apiErr, ok := err.(*api.APIError)
-	if !ok || apiErr.StatusCode != 401 {
+	if !api.IsAuthExpired(err) {
+		return "", err
+	}
+
+	if token, _ := lookupHostEnv("KEYWAY_TOKEN"); token != "" {
+		deps.UI.Error("KEYWAY_TOKEN is expired or invalid")
+		deps.UI.Message(deps.UI.Dim("Create a new one with: keyway token create --name ci"))
 		return "", err
 	}
 
 	// Clear the expired/invalid token
-	store := auth.NewStore()
-	_ = store.ClearAuth()
+	clearSession(auth.NewStore())
 
 	if deps.UI.IsInteractive() {
 		deps.UI.Warn("Session expired or invalid")
@@ -41,6 +46,5 @@ func handleAuthError(err error, deps *Dependencies) (string, error) {
 
 // isAuthError checks if the error is an authentication error (401)
 func isAuthError(err error) bool {
-	apiErr, ok := err.(*api.APIError)
-	return ok && apiErr.StatusCode == 401
+	return api.IsAuthExpired(err)
 }
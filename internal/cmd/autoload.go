@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// autoloadFileNames returns the env files frameworks load for an environment,
+// lowest precedence first, as dotenv-flow, Next.js and Vite do. .env.local is
+// skipped for test, so tests don't depend on one machine's overrides.
+func autoloadFileNames(envName string) []string {
+	names := []string{".env"}
+	if envName != "test" {
+		names = append(names, ".env.local")
+	}
+	return append(names, ".env."+envName, ".env."+envName+".local")
+}
+
+// AutoloadResult reports what --autoload added to the injected secrets
+type AutoloadResult struct {
+	Files []string
+	Added []string // Keys not present in the vault
+	// Origins is the file each added key was read from
+	Origins map[string]string
+}
+
+// applyAutoload layers the framework env files of dir beneath secrets, in
+// place: vault values always win, the files only add missing keys. Later
+// files win over earlier ones, like overlays. Missing files are skipped.
+func applyAutoload(deps *Dependencies, secrets map[string]string, dir, envName string) (*AutoloadResult, error) {
+	loaded := make(map[string]string)
+	result := &AutoloadResult{Files: []string{}, Added: []string{}, Origins: make(map[string]string)}
+	for _, name := range autoloadFileNames(envName) {
+		file := filepath.Join(dir, name)
+		if info, err := deps.Stat.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		data, err := deps.FS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", file, err)
+		}
+		result.Files = append(result.Files, file)
+		for key, value := range env.Parse(string(data)) {
+			loaded[key] = value
+			result.Origins[key] = file
+		}
+	}
+
+	for key, value := range loaded {
+		if _, inVault := secrets[key]; inVault {
+			delete(result.Origins, key)
+			continue
+		}
+		secrets[key] = value
+		result.Added = append(result.Added, key)
+	}
+	sort.Strings(result.Added)
+	return result, nil
+}
+
+// reportAutoload prints which files were loaded and the keys they added (never values)
+func reportAutoload(deps *Dependencies, result *AutoloadResult) {
+	if len(result.Files) == 0 {
+		deps.UI.Step(fmt.Sprintf("Autoload: %s", deps.UI.Dim("no env files found")))
+		return
+	}
+	deps.UI.Step(fmt.Sprintf("Autoload: %s %s", strings.Join(result.Files, ", "), deps.UI.Dim("(beneath vault values)")))
+	if len(result.Added) > 0 {
+		deps.UI.Message(fmt.Sprintf("  adds: %s", strings.Join(result.Added, ", ")))
+	} else {
+		deps.UI.Message(deps.UI.Dim("  no keys missing from the vault"))
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// caseCollisions groups the keys that differ only by case (API_KEY, api_key).
+// Windows, and so Windows containers, treat them as one variable and keep an
+// arbitrary value.
+func caseCollisions(secrets map[string]string) [][]string {
+	byFold := make(map[string][]string)
+	for key := range secrets {
+		fold := strings.ToUpper(key)
+		byFold[fold] = append(byFold[fold], key)
+	}
+
+	var groups [][]string
+	for _, keys := range byFold {
+		if len(keys) > 1 {
+			sort.Strings(keys)
+			groups = append(groups, keys)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// checkCaseCollisions warns about keys that differ only by case, or fails
+// when strict (the command runs on Windows, where one of them would be lost)
+func checkCaseCollisions(deps *Dependencies, secrets map[string]string, strict bool) error {
+	groups := caseCollisions(secrets)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(groups))
+	for i, keys := range groups {
+		names[i] = strings.Join(keys, "/")
+	}
+	message := fmt.Sprintf("Keys differ only by case: %s", strings.Join(names, ", "))
+
+	if strict {
+		deps.UI.Error(message)
+		deps.UI.Message(deps.UI.Dim("Windows keeps only one of them. Rename or remove the duplicates in the vault."))
+		return fmt.Errorf("case-only key collisions: %s", strings.Join(names, ", "))
+	}
+	deps.UI.Warn(message)
+	deps.UI.Message(deps.UI.Dim("Windows and Windows containers keep only one of them"))
+	return nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestCaseCollisions(t *testing.T) {
+	groups := caseCollisions(map[string]string{
+		"API_KEY":  "a",
+		"api_key":  "b",
+		"Api_Key":  "c",
+		"DB_URL":   "d",
+		"TOKEN":    "e",
+		"token":    "f",
+		"UNRELATE": "g",
+	})
+
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %v", groups)
+	}
+	if strings.Join(groups[0], ",") != "API_KEY,Api_Key,api_key" {
+		t.Errorf("unexpected first group: %v", groups[0])
+	}
+	if strings.Join(groups[1], ",") != "TOKEN,token" {
+		t.Errorf("unexpected second group: %v", groups[1])
+	}
+}
+
+func TestCheckCaseCollisions(t *testing.T) {
+	secrets := map[string]string{"API_KEY": "a", "api_key": "b"}
+
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	if err := checkCaseCollisions(deps, secrets, false); err != nil {
+		t.Fatalf("expected a warning only, got %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 || uiMock.WarnCalls[0] != "Keys differ only by case: API_KEY/api_key" {
+		t.Errorf("unexpected warnings: %v", uiMock.WarnCalls)
+	}
+
+	deps, _, _, _, _, _ = NewTestDeps()
+	if err := checkCaseCollisions(deps, secrets, true); err == nil {
+		t.Error("expected an error when strict")
+	}
+}
+
+func TestRunRunWithDeps_WarnsAboutCaseCollisions(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=a\napi_key=b"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	err := runRunWithDeps(opts, deps)
+
+	found := false
+	for _, w := range uiMock.WarnCalls {
+		found = found || strings.Contains(w, "API_KEY/api_key")
+	}
+	if runtime.GOOS == "windows" {
+		if err == nil || cmdRunner.LastCommand != "" {
+			t.Error("expected the run to fail on Windows")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !found {
+		t.Errorf("expected a case collision warning, got %v", uiMock.WarnCalls)
+	}
+}
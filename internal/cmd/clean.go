@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/offline"
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove files keyway left behind",
+	Long: `Remove what keyway leaves in its state directory when a command is
+interrupted, and offline cache entries too old to be used:
+
+  - temporary files of writes that never completed
+  - ECS overrides files of keyway cloud run ecs (they hold secret values)
+  - values files of keyway helm and flags files of keyway cloud run cloudrun
+    (they hold secret values too)
+  - offline cache entries older than KEYWAY_OFFLINE_TTL (all of them when the cache is off)
+
+Files younger than an hour are kept: another keyway command may still use
+them. Sessions, settings and history are never removed.`,
+	Example: `  keyway clean --dry-run
+  keyway clean`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().Bool("dry-run", false, "List what would be removed, without removing it")
+}
+
+// CleanOptions contains the parsed flags for the clean command
+type CleanOptions struct {
+	DryRun bool
+}
+
+// cleanStaleAfter is the age after which a leftover file is no longer in use
+const cleanStaleAfter = time.Hour
+
+// cleanPatterns are the leftover files in the state directory, by kind
+var cleanPatterns = []struct {
+	Pattern string
+	Kind    string
+}{
+	{".*.tmp-*", "interrupted write"},
+	{"ecs-overrides-*.json", "ECS overrides"},
+	{"helm-values-*.yaml", "Helm values"},
+	{"gcloud-flags-*.yaml", "gcloud flags"},
+}
+
+// cleanFile is a leftover file found in the state directory
+type cleanFile struct {
+	Path string
+	Kind string
+	Size int64
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	var opts CleanOptions
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	return runCleanWithDeps(opts, defaultDeps)
+}
+
+// runCleanWithDeps is the testable version of runClean
+func runCleanWithDeps(opts CleanOptions, deps *Dependencies) error {
+	deps.UI.Intro("clean")
+
+	dir, err := state.Dir()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	now := time.Now()
+	files, err := staleStateFiles(dir, now.Add(-cleanStaleAfter))
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	count, freed := 0, int64(0)
+	for _, f := range files {
+		if !opts.DryRun {
+			if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+				deps.UI.Warn(fmt.Sprintf("Could not remove %s: %s", f.Path, err))
+				continue
+			}
+		}
+		deps.UI.Message(fmt.Sprintf("%s %s (%s)", deps.UI.File(f.Path), deps.UI.Dim(f.Kind), formatSize(int(f.Size))))
+		count++
+		freed += f.Size
+	}
+
+	ttl, _ := config.OfflineTTL()
+	if cache, err := offline.Default(); err == nil {
+		entries, size, err := cache.Prune(now.Add(-ttl), opts.DryRun)
+		if err != nil {
+			deps.UI.Warn(fmt.Sprintf("Could not prune the offline cache: %s", err))
+		} else if entries > 0 {
+			deps.UI.Message(fmt.Sprintf("%d expired offline cache entries (%s)", entries, formatSize(int(size))))
+			count += entries
+			freed += size
+		}
+	}
+
+	switch {
+	case count == 0:
+		deps.UI.Success("Nothing to clean")
+	case opts.DryRun:
+		deps.UI.Outro(fmt.Sprintf("Would free %s - run without --dry-run to remove", formatSize(int(freed))))
+	default:
+		deps.UI.Success(fmt.Sprintf("Freed %s", formatSize(int(freed))))
+	}
+	return nil
+}
+
+// staleStateFiles lists the leftover files in dir last modified before cutoff
+func staleStateFiles(dir string, cutoff time.Time) ([]cleanFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []cleanFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, p := range cleanPatterns {
+			if ok, _ := filepath.Match(p.Pattern, entry.Name()); !ok {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				break
+			}
+			files = append(files, cleanFile{Path: filepath.Join(dir, entry.Name()), Kind: p.Kind, Size: info.Size()})
+			break
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/offline"
+)
+
+// cleanTestDir fills a state directory with leftovers, fresh files and state
+func cleanTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("KEYWAY_STATE_DIR", dir)
+	t.Setenv("KEYWAY_OFFLINE_TTL", "24h")
+
+	old := time.Now().Add(-2 * time.Hour)
+	for name, stale := range map[string]bool{
+		".names.json.tmp-123":          true,
+		"ecs-overrides-abcd.json":      true,
+		"ecs-overrides-fresh.json":     false,
+		"helm-values-abcd.yaml":        true,
+		"gcloud-flags-abcd.yaml":       true,
+		"names.json":                   true,
+		"activity.jsonl":               true,
+		".activity.jsonl.tmp-fresh":    false,
+		"ecs-overrides-abcd.json.lock": true,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("0123456789"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if stale {
+			_ = os.Chtimes(path, old, old)
+		}
+	}
+
+	cache, _ := offline.Default()
+	_ = cache.Save("owner/repo", "production", "A=1", time.Now().Add(-48*time.Hour))
+	_ = cache.Save("owner/repo", "staging", "A=1", time.Now())
+	return dir
+}
+
+func TestRunCleanWithDeps(t *testing.T) {
+	dir := cleanTestDir(t)
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runCleanWithDeps(CleanOptions{}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{".names.json.tmp-123", "ecs-overrides-abcd.json", "helm-values-abcd.yaml", "gcloud-flags-abcd.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed", name)
+		}
+	}
+	for _, name := range []string{"ecs-overrides-fresh.json", "names.json", "activity.jsonl", ".activity.jsonl.tmp-fresh", "ecs-overrides-abcd.json.lock"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s kept, got %v", name, err)
+		}
+	}
+
+	cache, _ := offline.Default()
+	if entry, _ := cache.Load("owner/repo", "production"); entry != nil {
+		t.Error("expected the expired offline entry removed")
+	}
+	if entry, _ := cache.Load("owner/repo", "staging"); entry == nil {
+		t.Error("expected the fresh offline entry kept")
+	}
+
+	messages := strings.Join(uiMock.MessageCalls, "\n")
+	if !strings.Contains(messages, "interrupted write") || !strings.Contains(messages, "1 expired offline cache entries") {
+		t.Errorf("unexpected messages %v", uiMock.MessageCalls)
+	}
+	if len(uiMock.SuccessCalls) != 1 || !strings.HasPrefix(uiMock.SuccessCalls[0], "Freed ") {
+		t.Errorf("unexpected success %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunCleanWithDeps_DryRun(t *testing.T) {
+	dir := cleanTestDir(t)
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runCleanWithDeps(CleanOptions{DryRun: true}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ecs-overrides-abcd.json")); err != nil {
+		t.Errorf("a dry run must not remove files, got %v", err)
+	}
+	cache, _ := offline.Default()
+	if entry, _ := cache.Load("owner/repo", "production"); entry == nil {
+		t.Error("a dry run must not prune the offline cache")
+	}
+	if len(uiMock.MessageCalls) != 5 {
+		t.Errorf("expected four files and the offline cache listed, got %v", uiMock.MessageCalls)
+	}
+	if len(uiMock.OutroCalls) != 1 || !strings.HasPrefix(uiMock.OutroCalls[0], "Would free ") {
+		t.Errorf("unexpected outro %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunCleanWithDeps_NothingToClean(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runCleanWithDeps(CleanOptions{}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.SuccessCalls) != 1 || uiMock.SuccessCalls[0] != "Nothing to clean" {
+		t.Errorf("unexpected success %v", uiMock.SuccessCalls)
+	}
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ecsOverridesLimit is the maximum size of ECS task overrides
+const ecsOverridesLimit = 8192
+
+var cloudCmd = &cobra.Command{
+	Use:   "cloud",
+	Short: "Run one-off cloud tasks with vault secrets",
+}
+
+var cloudRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Start a one-off task with secrets as environment overrides",
+	Long: `Start a one-off task on a cloud provider with the environment's secrets
+passed as environment overrides, without editing override JSON by hand.
+
+Arguments after -- are passed to the provider CLI (aws or gcloud).`,
+}
+
+var cloudRunECSCmd = &cobra.Command{
+	Use:   "ecs [-- aws args...]",
+	Short: "Run an ECS task (aws ecs run-task)",
+	Long: `Run an ECS task with the environment's secrets as container environment overrides.
+
+The overrides are passed to aws through a temporary owner-only file that is removed
+afterwards. Note that ECS keeps task overrides: they are visible to anyone allowed
+to describe the task.
+
+Examples:
+  keyway cloud run ecs -e production --cluster prod --task-definition migrate --container app
+  keyway cloud run ecs -e production --cluster prod --task-definition migrate --container app -- --launch-type FARGATE`,
+	RunE: runCloudRunECS,
+}
+
+var cloudRunCloudRunCmd = &cobra.Command{
+	Use:   "cloudrun [-- gcloud args...]",
+	Short: "Execute a Cloud Run job (gcloud run jobs execute)",
+	Long: `Execute a Cloud Run job with the environment's secrets as --update-env-vars.
+
+The variables are passed to gcloud through a temporary owner-only flags file
+(--flags-file) that is removed afterwards, never on its command line.
+
+Examples:
+  keyway cloud run cloudrun -e production --job migrate --region europe-west1
+  keyway cloud run cloudrun -e production --job migrate -- --wait`,
+	RunE: runCloudRunCloudRun,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{cloudRunECSCmd, cloudRunCloudRunCmd} {
+		c.Flags().StringP("env", "e", "", "Environment name")
+		c.Flags().Bool("dry-run", false, "Print the provider command with values masked, without running it")
+		c.Flags().Bool("override", false, "Bypass keyway.toml branch and label policies for this environment (break-glass)")
+		_ = c.MarkFlagRequired("env")
+	}
+
+	cloudRunECSCmd.Flags().String("cluster", "", "ECS cluster")
+	cloudRunECSCmd.Flags().String("task-definition", "", "Task definition (family[:revision] or ARN)")
+	cloudRunECSCmd.Flags().String("container", "", "Container receiving the environment")
+	_ = cloudRunECSCmd.MarkFlagRequired("task-definition")
+	_ = cloudRunECSCmd.MarkFlagRequired("container")
+
+	cloudRunCloudRunCmd.Flags().String("job", "", "Cloud Run job name")
+	cloudRunCloudRunCmd.Flags().String("region", "", "Cloud Run region")
+	_ = cloudRunCloudRunCmd.MarkFlagRequired("job")
+
+	cloudRunCmd.AddCommand(cloudRunECSCmd)
+	cloudRunCmd.AddCommand(cloudRunCloudRunCmd)
+	cloudCmd.AddCommand(cloudRunCmd)
+}
+
+// Cloud providers supported by keyway cloud run
+const (
+	providerECS      = "ecs"
+	providerCloudRun = "cloudrun"
+)
+
+// CloudRunOptions contains the parsed flags for the cloud run commands
+type CloudRunOptions struct {
+	Provider  string
+	EnvName   string
+	DryRun    bool
+	Override  bool
+	ExtraArgs []string
+
+	// ECS
+	Cluster        string
+	TaskDefinition string
+	Container      string
+
+	// Cloud Run
+	Job    string
+	Region string
+}
+
+// exitStatusError carries the exit code of a provider CLI that failed
+type exitStatusError struct {
+	Command string
+	Code    int
+}
+
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Command, e.Code)
+}
+
+func runCloudRunECS(cmd *cobra.Command, args []string) error {
+	opts := cloudRunOptionsFromFlags(cmd, args)
+	opts.Provider = providerECS
+	opts.Cluster, _ = cmd.Flags().GetString("cluster")
+	opts.TaskDefinition, _ = cmd.Flags().GetString("task-definition")
+	opts.Container, _ = cmd.Flags().GetString("container")
+	return exitWithProviderStatus(runCloudRunWithDeps(opts, defaultDeps))
+}
+
+func runCloudRunCloudRun(cmd *cobra.Command, args []string) error {
+	opts := cloudRunOptionsFromFlags(cmd, args)
+	opts.Provider = providerCloudRun
+	opts.Job, _ = cmd.Flags().GetString("job")
+	opts.Region, _ = cmd.Flags().GetString("region")
+	return exitWithProviderStatus(runCloudRunWithDeps(opts, defaultDeps))
+}
+
+func cloudRunOptionsFromFlags(cmd *cobra.Command, args []string) CloudRunOptions {
+	opts := CloudRunOptions{ExtraArgs: args}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Override, _ = cmd.Flags().GetBool("override")
+	return opts
+}
+
+// exitWithProviderStatus exits with the provider CLI's status, like keyway run does
+func exitWithProviderStatus(err error) error {
+	var statusErr *exitStatusError
+	if errors.As(err, &statusErr) {
+		os.Exit(statusErr.Code)
+	}
+	return err
+}
+
+// runCloudRunWithDeps is the testable version of the cloud run commands
+func runCloudRunWithDeps(opts CloudRunOptions, deps *Dependencies) error {
+	deps.UI.Intro(fmt.Sprintf("cloud run %s", opts.Provider))
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	if err := enforceBranchPolicy(deps, opts.EnvName, opts.Override); err != nil {
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	client := deps.APIFactory.NewClient(token)
+
+	secrets, client, err := fetchSecrets(deps, client, repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+	dropExpiredSecrets(context.Background(), deps, client, repo, opts.EnvName, secrets)
+
+	if err := enforceLabelPolicy(deps, opts.EnvName, sortedSecretKeys(secrets), opts.Override); err != nil {
+		return err
+	}
+
+	switch opts.Provider {
+	case providerECS:
+		return runECSTask(opts, secrets, deps)
+	case providerCloudRun:
+		return runCloudRunJob(opts, secrets, deps)
+	}
+	return fmt.Errorf("unknown provider %q", opts.Provider)
+}
+
+// runECSTask runs aws ecs run-task with the secrets in a temporary overrides file
+func runECSTask(opts CloudRunOptions, secrets map[string]string, deps *Dependencies) error {
+	overrides, err := buildECSOverrides(opts.Container, secrets)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if opts.DryRun {
+		args := ecsRunTaskArgs(opts, "file://<overrides.json>")
+		masked, _ := buildECSOverrides(opts.Container, maskValues(secrets))
+		deps.UI.Message(formatCommand("aws", args))
+		deps.UI.Message(string(masked))
+		return nil
+	}
+
+	file, err := overridesFilePath("ecs-overrides", ".json")
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if err := deps.FS.WriteFile(file, overrides, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write overrides: %s", err.Error()))
+		return err
+	}
+	defer deps.FS.Remove(file)
+
+	deps.UI.Success(fmt.Sprintf("Passing %d secrets to container %s", len(secrets), opts.Container))
+	return runProviderCommand(deps, "aws", ecsRunTaskArgs(opts, "file://"+file))
+}
+
+// runCloudRunJob runs gcloud run jobs execute with the secrets as
+// --update-env-vars in a temporary flags file
+func runCloudRunJob(opts CloudRunOptions, secrets map[string]string, deps *Dependencies) error {
+	flags, err := buildCloudRunFlags(secrets)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if opts.DryRun {
+		masked, _ := buildCloudRunFlags(maskValues(secrets))
+		deps.UI.Message(formatCommand("gcloud", cloudRunExecuteArgs(opts, "<flags.yaml>")))
+		deps.UI.Message(string(masked))
+		return nil
+	}
+
+	// An empty --update-env-vars would be rejected
+	if len(secrets) == 0 {
+		return runProviderCommand(deps, "gcloud", cloudRunExecuteArgs(opts, ""))
+	}
+
+	file, err := overridesFilePath("gcloud-flags", ".yaml")
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if err := deps.FS.WriteFile(file, flags, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write flags file: %s", err.Error()))
+		return err
+	}
+	defer deps.FS.Remove(file)
+
+	deps.UI.Success(fmt.Sprintf("Passing %d secrets to job %s", len(secrets), opts.Job))
+	return runProviderCommand(deps, "gcloud", cloudRunExecuteArgs(opts, file))
+}
+
+// runProviderCommand runs the provider CLI without injecting secrets into its own environment
+func runProviderCommand(deps *Dependencies, name string, args []string) error {
+	code, err := deps.CmdRunner.RunCommandStatus(name, args, nil)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if code != 0 {
+		return &exitStatusError{Command: name, Code: code}
+	}
+	return nil
+}
+
+type ecsKeyValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ecsContainerOverride struct {
+	Name        string        `json:"name"`
+	Environment []ecsKeyValue `json:"environment"`
+}
+
+type ecsTaskOverrides struct {
+	ContainerOverrides []ecsContainerOverride `json:"containerOverrides"`
+}
+
+// buildECSOverrides returns the run-task overrides JSON for one container, sorted by key
+func buildECSOverrides(container string, secrets map[string]string) ([]byte, error) {
+	override := ecsContainerOverride{Name: container, Environment: make([]ecsKeyValue, 0, len(secrets))}
+	for _, key := range sortedSecretKeys(secrets) {
+		override.Environment = append(override.Environment, ecsKeyValue{Name: key, Value: secrets[key]})
+	}
+
+	data, err := json.Marshal(ecsTaskOverrides{ContainerOverrides: []ecsContainerOverride{override}})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > ecsOverridesLimit {
+		return nil, fmt.Errorf("overrides are %d bytes, ECS accepts at most %d; reference large values from Secrets Manager instead", len(data), ecsOverridesLimit)
+	}
+	return data, nil
+}
+
+func ecsRunTaskArgs(opts CloudRunOptions, overrides string) []string {
+	args := []string{"ecs", "run-task", "--task-definition", opts.TaskDefinition, "--overrides", overrides}
+	if opts.Cluster != "" {
+		args = append(args, "--cluster", opts.Cluster)
+	}
+	return append(args, opts.ExtraArgs...)
+}
+
+// buildCloudRunFlags renders a gcloud flags file setting --update-env-vars.
+// Values stay strings, even when they look like numbers.
+func buildCloudRunFlags(secrets map[string]string) ([]byte, error) {
+	return yaml.Marshal(map[string]map[string]string{"--update-env-vars": secrets})
+}
+
+// cloudRunExecuteArgs builds the gcloud arguments, reading the variables from
+// flagsFile unless it is empty
+func cloudRunExecuteArgs(opts CloudRunOptions, flagsFile string) []string {
+	args := []string{"run", "jobs", "execute", opts.Job}
+	if opts.Region != "" {
+		args = append(args, "--region", opts.Region)
+	}
+	if flagsFile != "" {
+		args = append(args, "--flags-file", flagsFile)
+	}
+	return append(args, opts.ExtraArgs...)
+}
+
+// overridesFilePath returns a fresh path in the owner-only state directory,
+// named prefix-<random>ext
+func overridesFilePath(prefix, ext string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return state.Path(fmt.Sprintf("%s-%s%s", prefix, hex.EncodeToString(suffix), ext))
+}
+
+// maskValues replaces every value with *** for display
+func maskValues(secrets map[string]string) map[string]string {
+	masked := make(map[string]string, len(secrets))
+	for key := range secrets {
+		masked[key] = "***"
+	}
+	return masked
+}
+
+// formatCommand renders a command line for display, quoting arguments with spaces
+func formatCommand(name string, args []string) string {
+	parts := []string{name}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestBuildECSOverrides(t *testing.T) {
+	data, err := buildECSOverrides("app", map[string]string{"B": "2", "A": `x"y`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed ecsTaskOverrides
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(parsed.ContainerOverrides) != 1 || parsed.ContainerOverrides[0].Name != "app" {
+		t.Fatalf("unexpected overrides: %s", data)
+	}
+	env := parsed.ContainerOverrides[0].Environment
+	if len(env) != 2 || env[0].Name != "A" || env[0].Value != `x"y` || env[1].Name != "B" {
+		t.Errorf("expected sorted, escaped environment, got %+v", env)
+	}
+}
+
+func TestBuildECSOverrides_TooLarge(t *testing.T) {
+	_, err := buildECSOverrides("app", map[string]string{"BIG": strings.Repeat("x", ecsOverridesLimit)})
+	if err == nil {
+		t.Error("expected size limit error")
+	}
+}
+
+func TestCloudRunExecuteArgs(t *testing.T) {
+	opts := CloudRunOptions{Job: "migrate", Region: "europe-west1", ExtraArgs: []string{"--wait"}}
+
+	args := cloudRunExecuteArgs(opts, "/state/gcloud-flags.yaml")
+
+	want := []string{"run", "jobs", "execute", "migrate", "--region", "europe-west1", "--flags-file", "/state/gcloud-flags.yaml", "--wait"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %v, got %v", want, args)
+	}
+}
+
+func TestBuildCloudRunFlags(t *testing.T) {
+	flags, err := buildCloudRunFlags(map[string]string{"B": "x|y", "A": "1,2", "PORT": "8080"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "--update-env-vars:\n    A: 1,2\n    B: x|y\n    PORT: \"8080\"\n"
+	if string(flags) != want {
+		t.Errorf("unexpected flags file:\n%s", flags)
+	}
+}
+
+func TestRunCloudRunWithDeps_ECS(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	fsMock := deps.FS.(*MockFileSystem)
+
+	var overrides string
+	cmdRunner.OnRun = func(name string, args []string) {
+		for i, arg := range args {
+			if arg == "--overrides" {
+				overrides = strings.TrimPrefix(args[i+1], "file://")
+			}
+		}
+		if !strings.Contains(string(fsMock.Written[overrides]), "secret123") {
+			t.Errorf("overrides file should hold the secrets while aws runs")
+		}
+	}
+
+	opts := CloudRunOptions{
+		Provider:       providerECS,
+		EnvName:        "production",
+		Cluster:        "prod",
+		TaskDefinition: "migrate",
+		Container:      "app",
+	}
+	if err := runCloudRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "aws" {
+		t.Errorf("expected aws, got %q", cmdRunner.LastCommand)
+	}
+	if len(cmdRunner.LastSecrets) != 0 {
+		t.Error("secrets must not be injected into the aws process")
+	}
+	if strings.Contains(strings.Join(cmdRunner.LastArgs, " "), "secret123") {
+		t.Error("secret values must not appear on the aws command line")
+	}
+	if len(fsMock.Removed) != 1 || fsMock.Removed[0] != overrides {
+		t.Errorf("expected overrides file %q to be removed, got %v", overrides, fsMock.Removed)
+	}
+}
+
+func TestRunCloudRunWithDeps_CloudRun(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	fsMock := deps.FS.(*MockFileSystem)
+
+	var flagsFile string
+	cmdRunner.OnRun = func(name string, args []string) {
+		for i, arg := range args {
+			if arg == "--flags-file" {
+				flagsFile = args[i+1]
+			}
+		}
+		if !strings.Contains(string(fsMock.Written[flagsFile]), "API_KEY: secret123") {
+			t.Errorf("flags file should hold the secrets while gcloud runs")
+		}
+	}
+
+	opts := CloudRunOptions{Provider: providerCloudRun, EnvName: "production", Job: "migrate"}
+	if err := runCloudRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "gcloud" {
+		t.Errorf("expected gcloud, got %q", cmdRunner.LastCommand)
+	}
+	if strings.Contains(strings.Join(cmdRunner.LastArgs, " "), "secret123") || len(cmdRunner.LastSecrets) != 0 {
+		t.Error("secret values must not reach gcloud's command line or environment")
+	}
+	if len(fsMock.Removed) != 1 || fsMock.Removed[0] != flagsFile {
+		t.Errorf("expected flags file %q to be removed, got %v", flagsFile, fsMock.Removed)
+	}
+}
+
+func TestRunCloudRunWithDeps_SkipsExpiredSecrets(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nTEMP_TOKEN=tmp_abc\n"}
+	apiMock.KeyMetadata = []api.KeyMetadata{
+		{Key: "API_KEY"},
+		{Key: "TEMP_TOKEN", ExpiresAt: timePtr(time.Now().Add(-time.Hour))},
+	}
+	fsMock := deps.FS.(*MockFileSystem)
+
+	var overrides string
+	cmdRunner.OnRun = func(name string, args []string) {
+		for i, arg := range args {
+			if arg == "--overrides" {
+				overrides = string(fsMock.Written[strings.TrimPrefix(args[i+1], "file://")])
+			}
+		}
+	}
+
+	opts := CloudRunOptions{Provider: providerECS, EnvName: "production", TaskDefinition: "migrate", Container: "app"}
+	if err := runCloudRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(overrides, "TEMP_TOKEN") || !strings.Contains(overrides, "API_KEY") {
+		t.Errorf("expected the expired key left out, got %s", overrides)
+	}
+	if len(uiMock.WarnCalls) == 0 || !strings.Contains(uiMock.WarnCalls[0], "TEMP_TOKEN") {
+		t.Errorf("expected an expiry warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunCloudRunWithDeps_DryRunMasksValues(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := CloudRunOptions{Provider: providerCloudRun, EnvName: "production", Job: "migrate", DryRun: true}
+	if err := runCloudRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "" {
+		t.Error("dry run should not run gcloud")
+	}
+	output := strings.Join(uiMock.MessageCalls, "\n")
+	if strings.Contains(output, "secret123") {
+		t.Error("dry run must mask values")
+	}
+	if !strings.Contains(output, "--flags-file <flags.yaml>") || !strings.Contains(output, "API_KEY: '***'") {
+		t.Errorf("expected masked command, got %q", output)
+	}
+}
+
+func TestRunCloudRunWithDeps_ExitStatus(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	cmdRunner.ExitCode = 3
+
+	opts := CloudRunOptions{Provider: providerCloudRun, EnvName: "production", Job: "migrate"}
+	err := runCloudRunWithDeps(opts, deps)
+
+	var statusErr *exitStatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != 3 {
+		t.Errorf("expected exit status 3, got %v", err)
+	}
+}
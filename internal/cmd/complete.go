@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/git"
+	"github.com/keywaysh/cli/internal/namecache"
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// namesRefreshTimeout bounds a background refresh of the name cache
+const namesRefreshTimeout = 30 * time.Second
+
+// namesRefreshCmd is spawned by shell completion when the name cache is
+// missing or stale
+var namesRefreshCmd = &cobra.Command{
+	Use:    "names-refresh",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return refreshNamesWithDeps(defaultDeps)
+	},
+}
+
+// addNameCompletions completes --env flags and environment and key arguments
+// from the name cache. Completion never calls the API: a stale cache is
+// refreshed in the background for the next completion.
+func addNameCompletions(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		addNameCompletions(sub)
+	}
+	if cmd.LocalFlags().Lookup("env") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("env", completeEnvironments)
+	}
+
+	switch cmd {
+	case diffCmd, envsCloneCmd:
+		cmd.ValidArgsFunction = completeEnvironmentArgs(2)
+	case envsDeleteCmd:
+		cmd.ValidArgsFunction = completeEnvironmentArgs(1)
+	case setCmd, secretsSetCmd, secretsGetCmd, secretsUnsetCmd, secretsCopyValueCmd:
+		cmd.ValidArgsFunction = completeKeyArg
+	}
+}
+
+func completeEnvironments(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	entry := completionNames()
+	if entry == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return entry.Environments, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEnvironmentArgs completes up to max environment arguments
+func completeEnvironmentArgs(max int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= max {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeEnvironments(cmd, args, toComplete)
+	}
+}
+
+// completeKeyArg completes the key argument of set and the secrets commands
+// with the keys of the environment the command would use
+func completeKeyArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	envName, _ := cmd.Flags().GetString("env")
+	if !cmd.Flags().Changed("env") {
+		envName = defaultEnvironment(defaultDeps, strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "), "development")
+	}
+
+	entry := completionNames()
+	if entry == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return entry.Keys[envName], cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionNames returns the cached names for a completion. Cobra does not
+// run initializers when completing, so --repo is applied here.
+func completionNames() *namecache.Entry {
+	git.SetRepoOverride(repoOverride(repoFlag))
+	return cachedNames(defaultDeps)
+}
+
+// cachedNames returns the cached names of the current repository, or nil.
+// When they are missing or stale it starts a refresh and returns what it has.
+func cachedNames(deps *Dependencies) *namecache.Entry {
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		return nil
+	}
+	path, err := namecache.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	entry, _ := namecache.Load(path, repo)
+	if entry == nil || entry.Stale(time.Now()) {
+		_ = spawnNamesRefresh(repo)
+	}
+	return entry
+}
+
+// spawnNamesRefresh refreshes the name cache of repo in a detached background process
+var spawnNamesRefresh = func(repo string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, namesRefreshCmd.Name(), "--repo", repo)
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// refreshNamesWithDeps lists the environments of the current repository and
+// the keys of each, and saves them in the name cache. It never prompts: without
+// a session it records an empty entry, so completion does not retry until the
+// entry is stale again.
+func refreshNamesWithDeps(deps *Dependencies) error {
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		return err
+	}
+	path, err := namecache.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	// Completions typed in quick succession only need one refresh
+	unlock, err := state.Lock(path+".refresh", 0)
+	if err != nil {
+		return nil
+	}
+	defer unlock()
+
+	previous, _ := namecache.Load(path, repo)
+	entry := &namecache.Entry{Keys: map[string][]string{}, RefreshedAt: time.Now().UTC()}
+	if previous != nil {
+		entry.Environments, entry.Keys = previous.Environments, previous.Keys
+	}
+
+	if deps.Auth.HasSession() {
+		if token, err := deps.Auth.EnsureLogin(); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), namesRefreshTimeout)
+			defer cancel()
+			fetchNames(ctx, deps.APIFactory.NewClient(token), repo, entry)
+		}
+	}
+	return namecache.Save(path, repo, entry)
+}
+
+// fetchNames replaces the names in entry with the vault's. Keys come from the
+// metadata endpoint only, so refreshing never pulls secret values.
+func fetchNames(ctx context.Context, client api.APIClient, repo string, entry *namecache.Entry) {
+	envs, err := client.GetVaultEnvironments(ctx, repo)
+	if err != nil {
+		return
+	}
+	keys := make(map[string][]string, len(envs))
+	for _, envName := range envs {
+		metadata, err := client.GetSecretsMetadata(ctx, repo, envName)
+		if err != nil || metadata == nil {
+			// Keep what was cached for this environment
+			if entry.Keys[envName] != nil {
+				keys[envName] = entry.Keys[envName]
+			}
+			continue
+		}
+		names := make([]string, len(metadata))
+		for i, m := range metadata {
+			names[i] = m.Key
+		}
+		keys[envName] = names
+	}
+	entry.Environments, entry.Keys = envs, keys
+}
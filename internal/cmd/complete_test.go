@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/namecache"
+)
+
+func TestRefreshNamesWithDeps_SavesEnvironmentsAndKeys(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"staging", "development"}
+	apiMock.KeyMetadataByEnv = map[string][]api.KeyMetadata{
+		"development": {{Key: "PORT"}, {Key: "API_KEY"}},
+		"staging":     {{Key: "DATABASE_URL"}},
+	}
+
+	if err := refreshNamesWithDeps(deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, _ := namecache.DefaultPath()
+	entry, err := namecache.Load(path, "owner/repo")
+	if err != nil || entry == nil {
+		t.Fatalf("expected an entry, got %+v, %v", entry, err)
+	}
+	if strings.Join(entry.Environments, ",") != "development,staging" {
+		t.Errorf("unexpected environments: %v", entry.Environments)
+	}
+	if strings.Join(entry.Keys["development"], ",") != "API_KEY,PORT" || strings.Join(entry.Keys["staging"], ",") != "DATABASE_URL" {
+		t.Errorf("unexpected keys: %v", entry.Keys)
+	}
+}
+
+func TestRefreshNamesWithDeps_NoSessionKeepsNames(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, authMock, _, _, apiMock := NewTestDeps()
+	authMock.NoSession = true
+	apiMock.VaultEnvs = []string{"production"}
+
+	path, _ := namecache.DefaultPath()
+	old := time.Now().Add(-time.Hour)
+	if err := namecache.Save(path, "owner/repo", &namecache.Entry{Environments: []string{"development"}, RefreshedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := refreshNamesWithDeps(deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry, _ := namecache.Load(path, "owner/repo")
+	if entry == nil || strings.Join(entry.Environments, ",") != "development" {
+		t.Errorf("expected the cached names to be kept, got %+v", entry)
+	}
+	if entry != nil && entry.Stale(time.Now()) {
+		t.Error("expected the entry to be marked fresh so completion does not retry at once")
+	}
+}
+
+func TestCachedNames_RefreshesOnlyWhenStale(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	var spawned []string
+	orig := spawnNamesRefresh
+	spawnNamesRefresh = func(repo string) error {
+		spawned = append(spawned, repo)
+		return nil
+	}
+	defer func() { spawnNamesRefresh = orig }()
+
+	if entry := cachedNames(deps); entry != nil {
+		t.Errorf("expected no names before a refresh, got %+v", entry)
+	}
+	if len(spawned) != 1 || spawned[0] != "owner/repo" {
+		t.Fatalf("expected a refresh of owner/repo, got %v", spawned)
+	}
+
+	path, _ := namecache.DefaultPath()
+	if err := namecache.Save(path, "owner/repo", &namecache.Entry{Environments: []string{"staging"}, RefreshedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	entry := cachedNames(deps)
+	if entry == nil || entry.Environments[0] != "staging" {
+		t.Errorf("expected the cached names, got %+v", entry)
+	}
+	if len(spawned) != 1 {
+		t.Errorf("expected no refresh of fresh names, got %v", spawned)
+	}
+}
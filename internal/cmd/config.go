@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate keyway.toml",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [FILE...]",
+	Short: "Check keyway.toml for errors",
+	Long: `Check config files for syntax errors, unknown keys (typos), invalid
+environment names, bad branch glob patterns and invalid values.
+
+Without arguments, validates the keyway.toml that applies to the current directory.
+
+Examples:
+  keyway config validate
+  keyway config validate ./keyway.toml ../other/keyway.toml`,
+	RunE: runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for keyway.toml",
+	Long: `Print the JSON Schema for keyway.toml, for editor completion and validation
+(e.g. with the Even Better TOML extension).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(config.ProjectSchema)
+		return err
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	return runConfigValidateWithDeps(args, defaultDeps)
+}
+
+// runConfigValidateWithDeps is the testable version of runConfigValidate
+func runConfigValidateWithDeps(files []string, deps *Dependencies) error {
+	deps.UI.Intro("config validate")
+
+	if len(files) == 0 {
+		file := deps.Config.FindProjectFile()
+		if file == "" {
+			deps.UI.Warn(fmt.Sprintf("No %s found", config.ProjectConfigFile))
+			return nil
+		}
+		files = []string{file}
+	}
+
+	total := 0
+	for _, file := range files {
+		data, err := deps.FS.ReadFile(file)
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Cannot read %s: %s", file, err.Error()))
+			total++
+			continue
+		}
+
+		errs := config.ValidateProject(file, data)
+		if len(errs) == 0 {
+			deps.UI.Success(fmt.Sprintf("%s is valid", deps.UI.File(file)))
+			continue
+		}
+		for _, e := range errs {
+			deps.UI.Error(e.Error())
+		}
+		total += len(errs)
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%d config error(s)", total)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunConfigValidate_NearestFile(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _ := NewTestDeps()
+	deps.Config.(*MockConfigLoader).ProjectFile = "/repo/keyway.toml"
+	fsMock.Files["/repo/keyway.toml"] = []byte("[environments.production]\nbranches = [\"main\"]\n")
+
+	if err := runConfigValidateWithDeps(nil, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected success, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunConfigValidate_NoFile(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	if err := runConfigValidateWithDeps(nil, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected a warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunConfigValidate_ReportsErrors(t *testing.T) {
+	deps, _, _, uiMock, fsMock, _ := NewTestDeps()
+	fsMock.Files["keyway.toml"] = []byte("[environments.production]\nbranchs = [\"main\"]\nenforce = \"nope\"\n")
+
+	err := runConfigValidateWithDeps([]string{"keyway.toml"}, deps)
+
+	if err == nil || !strings.Contains(err.Error(), "2 config error") {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if len(uiMock.ErrorCalls) != 2 || !strings.HasPrefix(uiMock.ErrorCalls[0], "keyway.toml:2:1:") {
+		t.Errorf("unexpected errors: %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestRunConfigValidate_Unreadable(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+
+	err := runConfigValidateWithDeps([]string{"missing.toml"}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(uiMock.ErrorCalls) != 1 {
+		t.Errorf("expected 1 error, got %v", uiMock.ErrorCalls)
+	}
+}
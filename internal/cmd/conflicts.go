@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/syncbase"
+)
+
+// Choices offered for a key changed both locally and in the vault
+const (
+	conflictKeepLocal = "Keep local value"
+	conflictKeepVault = "Keep vault value"
+	conflictEdit      = "Enter a new value"
+)
+
+// resolvePushConflicts compares the local secrets with the vault, using the
+// state recorded at the last pull or push as the common base. Keys only changed
+// in the vault take the vault's value instead of being reverted, and keys
+// changed on both sides are resolved interactively. Returns the secrets to push.
+func resolvePushConflicts(deps *Dependencies, repo, envName string, local, vault map[string]string, prune bool) (map[string]string, error) {
+	base, ok, err := deps.SyncBase.Load(repo, envName)
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not read last sync state, skipping conflict detection: %s", err.Error()))
+		return local, nil
+	}
+	if !ok {
+		return local, nil
+	}
+
+	changes := syncbase.Compare(base, local, vault)
+	if !changes.HasChanges() {
+		return local, nil
+	}
+
+	if len(changes.Conflicts) > 0 && !deps.UI.IsInteractive() {
+		deps.UI.Error(fmt.Sprintf("Changed locally and in the vault since your last pull: %s", strings.Join(changes.Conflicts, ", ")))
+		deps.UI.Message(deps.UI.Dim("Run keyway push interactively to resolve, or keyway pull to update your file"))
+		return nil, fmt.Errorf("push conflicts with vault changes")
+	}
+
+	resolved := make(map[string]string, len(local))
+	for k, v := range local {
+		resolved[k] = v
+	}
+
+	keepVault := func(key string) {
+		if value, ok := vault[key]; ok {
+			resolved[key] = value
+		} else {
+			delete(resolved, key)
+		}
+	}
+
+	if len(changes.Stale) > 0 {
+		deps.UI.Warn(fmt.Sprintf("Changed in the vault since your last pull, keeping the vault value: %s", strings.Join(changes.Stale, ", ")))
+		for _, key := range changes.Stale {
+			keepVault(key)
+		}
+	}
+
+	// With --prune, keys someone else added would be deleted without ever being seen locally
+	if prune && len(changes.RemoteAdded) > 0 {
+		deps.UI.Warn(fmt.Sprintf("Added to the vault since your last pull, not pruned: %s", strings.Join(changes.RemoteAdded, ", ")))
+		for _, key := range changes.RemoteAdded {
+			resolved[key] = vault[key]
+		}
+	}
+
+	for _, key := range changes.Conflicts {
+		prompt := fmt.Sprintf("%s was changed locally and in the vault:", key)
+		if _, inVault := vault[key]; !inVault {
+			prompt = fmt.Sprintf("%s was changed locally and deleted from the vault:", key)
+		}
+
+		choice, err := deps.UI.Select(prompt, []string{conflictKeepLocal, conflictKeepVault, conflictEdit})
+		if err != nil {
+			return nil, err
+		}
+
+		switch choice {
+		case conflictKeepVault:
+			keepVault(key)
+		case conflictEdit:
+			value, err := deps.UI.Password(fmt.Sprintf("New value for %s:", key))
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = value
+		}
+	}
+
+	return resolved, nil
+}
+
+// saveSyncBase records the vault state after a pull or push.
+// Failures only warn: conflict detection is best effort.
+func saveSyncBase(deps *Dependencies, repo, envName string, secrets map[string]string) {
+	if err := deps.SyncBase.Save(repo, envName, syncbase.NewSnapshot(secrets)); err != nil {
+		deps.UI.Warn(fmt.Sprintf("Failed to record sync state: %s", err.Error()))
+	}
+}
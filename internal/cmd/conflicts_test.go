@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/syncbase"
+)
+
+func withSyncBase(deps *Dependencies, env string, secrets map[string]string) {
+	deps.SyncBase = &MockSyncBaseStore{Snapshots: map[string]syncbase.Snapshot{
+		"owner/repo/" + env: syncbase.NewSnapshot(secrets),
+	}}
+}
+
+func TestResolvePushConflicts_NoBase(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	local := map[string]string{"A": "mine"}
+
+	got, err := resolvePushConflicts(deps, "owner/repo", "production", local, map[string]string{"A": "theirs"}, false)
+	if err != nil || got["A"] != "mine" {
+		t.Errorf("without a base local should win, got %v, %v", got, err)
+	}
+}
+
+func TestResolvePushConflicts_KeepsVaultChanges(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	withSyncBase(deps, "production", map[string]string{"A": "old", "B": "old", "GONE": "x"})
+
+	local := map[string]string{"A": "old", "B": "mine", "GONE": "x"}
+	vault := map[string]string{"A": "theirs", "B": "old"}
+
+	got, err := resolvePushConflicts(deps, "owner/repo", "production", local, vault, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["A"] != "theirs" || got["B"] != "mine" {
+		t.Errorf("unexpected result %v", got)
+	}
+	if _, ok := got["GONE"]; ok {
+		t.Error("a key deleted in the vault and untouched locally should not be re-added")
+	}
+	if local["A"] != "old" {
+		t.Error("local map should not be modified")
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning about vault changes")
+	}
+}
+
+func TestResolvePushConflicts_NonInteractiveConflict(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	withSyncBase(deps, "production", map[string]string{"A": "old"})
+
+	_, err := resolvePushConflicts(deps, "owner/repo", "production", map[string]string{"A": "mine"}, map[string]string{"A": "theirs"}, false)
+	if err == nil {
+		t.Fatal("expected conflict error")
+	}
+}
+
+func TestResolvePushConflicts_Interactive(t *testing.T) {
+	tests := []struct {
+		choice string
+		want   string
+	}{
+		{conflictKeepLocal, "mine"},
+		{conflictKeepVault, "theirs"},
+		{conflictEdit, "edited"},
+	}
+
+	for _, tt := range tests {
+		deps, _, _, uiMock, _, _ := NewTestDeps()
+		uiMock.Interactive = true
+		uiMock.SelectResult = tt.choice
+		uiMock.PasswordResult = "edited"
+		withSyncBase(deps, "production", map[string]string{"A": "old"})
+
+		got, err := resolvePushConflicts(deps, "owner/repo", "production", map[string]string{"A": "mine"}, map[string]string{"A": "theirs"}, false)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.choice, err)
+		}
+		if got["A"] != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.choice, tt.want, got["A"])
+		}
+	}
+}
+
+func TestResolvePushConflicts_PruneKeepsRemoteAdditions(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	withSyncBase(deps, "production", map[string]string{"A": "1"})
+
+	got, err := resolvePushConflicts(deps, "owner/repo", "production", map[string]string{"A": "1"}, map[string]string{"A": "1", "NEW": "n"}, true)
+	if err != nil || got["NEW"] != "n" {
+		t.Errorf("expected NEW to be kept, got %v, %v", got, err)
+	}
+}
+
+func TestRunPushWithDeps_RecordsSyncBase(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snap, ok, _ := deps.SyncBase.Load("owner/repo", "development")
+	if !ok || snap["API_KEY"] != syncbase.Digest("secret123") {
+		t.Errorf("expected the pushed state to be recorded, got %v", snap)
+	}
+}
@@ -16,14 +16,18 @@ import (
 )
 
 // Providers that use direct token auth instead of OAuth
-var tokenAuthProviders = []string{"railway"}
+var tokenAuthProviders = []string{"railway", "azure-devops", "bitbucket"}
 
 var connectCmd = &cobra.Command{
 	Use:   "connect <provider>",
-	Short: "Connect to a provider (vercel, railway)",
-	Long:  `Connect your Keyway account to a provider like Vercel or Railway for syncing secrets.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runConnect,
+	Short: "Connect to a provider (vercel, railway, azure-devops, bitbucket)",
+	Long: `Connect your Keyway account to a provider like Vercel or Railway for syncing secrets.
+
+Azure DevOps uses a personal access token with the Variable Groups (Read, create
+& manage) scope. Bitbucket uses an app password with repository and pipeline
+variable permissions.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConnect,
 }
 
 var connectionsCmd = &cobra.Command{
@@ -54,6 +58,10 @@ func getTokenCreationURL(provider string) string {
 	switch strings.ToLower(provider) {
 	case "railway":
 		return "https://railway.com/account/tokens"
+	case "azure-devops":
+		return "https://dev.azure.com/_usersSettings/tokens"
+	case "bitbucket":
+		return "https://bitbucket.org/account/settings/app-passwords/"
 	default:
 		return ""
 	}
@@ -267,7 +275,7 @@ func runConnections(cmd *cobra.Command, args []string) error {
 	if len(connections) == 0 {
 		ui.Info("No provider connections found.")
 		ui.Message(ui.Dim("Connect to a provider with: keyway connect <provider>"))
-		ui.Message(ui.Dim("Available providers: vercel, railway"))
+		ui.Message(ui.Dim("Available providers: vercel, railway, azure-devops, bitbucket"))
 		return nil
 	}
 
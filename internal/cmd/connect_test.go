@@ -12,6 +12,8 @@ func TestIsTokenAuthProvider(t *testing.T) {
 		{"railway", true},
 		{"Railway", true},
 		{"RAILWAY", true},
+		{"azure-devops", true},
+		{"Bitbucket", true},
 		{"vercel", false},
 		{"Vercel", false},
 		{"VERCEL", false},
@@ -38,6 +40,8 @@ func TestGetTokenCreationURL(t *testing.T) {
 		{"railway", "https://railway.com/account/tokens"},
 		{"Railway", "https://railway.com/account/tokens"},
 		{"RAILWAY", "https://railway.com/account/tokens"},
+		{"azure-devops", "https://dev.azure.com/_usersSettings/tokens"},
+		{"bitbucket", "https://bitbucket.org/account/settings/app-passwords/"},
 		{"vercel", ""},
 		{"unknown", ""},
 		{"", ""},
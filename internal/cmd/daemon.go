@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep vault data warm for fast repeated commands",
+	Long: `Listen on a unix socket and keep environment lists and pulled secrets in
+memory, so later commands (keyway run in a shell prompt or a tight CI loop)
+skip the round trips to the API.
+
+Commands find the daemon on its default socket and fall back to the API
+whenever it is not running, fails, or was started by another login. Cached
+values are encrypted in memory with a key that never leaves the process, and
+expire after --ttl. A push through this CLI clears the environment at once;
+changes made elsewhere show up when the entry expires.
+
+Reads served from the cache are not recorded as pulls by the API. Set
+KEYWAY_NO_DAEMON=1 to bypass the daemon for one command.
+
+Examples:
+  keyway daemon &
+  keyway daemon --ttl 30s`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	daemonCmd.Flags().String("socket", "", "Socket path (default ~/.keyway/state/daemon.sock)")
+	daemonCmd.Flags().Duration("ttl", time.Minute, "How long environments and secrets stay cached")
+}
+
+// daemonSocketName is the daemon's socket in the state directory
+const daemonSocketName = "daemon.sock"
+
+// noDaemonEnv disables the daemon lookup, for one command or inside the daemon itself
+const noDaemonEnv = "KEYWAY_NO_DAEMON"
+
+// DaemonOptions contains the parsed flags for the daemon command
+type DaemonOptions struct {
+	Socket string
+	TTL    time.Duration
+}
+
+// runDaemon is the entry point for the daemon command (uses default dependencies)
+func runDaemon(cmd *cobra.Command, args []string) error {
+	opts := DaemonOptions{}
+	opts.Socket, _ = cmd.Flags().GetString("socket")
+	opts.TTL, _ = cmd.Flags().GetDuration("ttl")
+
+	return runDaemonWithDeps(opts, defaultDeps)
+}
+
+// runDaemonWithDeps is the testable version of runDaemon
+func runDaemonWithDeps(opts DaemonOptions, deps *Dependencies) error {
+	deps.UI.Intro("daemon")
+
+	if opts.TTL <= 0 {
+		deps.UI.Error("--ttl must be positive")
+		return fmt.Errorf("invalid ttl %s", opts.TTL)
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	path := opts.Socket
+	if path == "" {
+		if path, err = state.Path(daemonSocketName); err != nil {
+			deps.UI.Error(fmt.Sprintf("Cannot locate the state directory: %s", err.Error()))
+			return err
+		}
+	}
+
+	// The daemon talks to the API itself, never to a daemon
+	os.Setenv(noDaemonEnv, "1")
+	server, err := newDaemonServer(deps.APIFactory.NewClient(token), token, opts.TTL)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	defer ln.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	deps.UI.Success(fmt.Sprintf("Listening on %s", deps.UI.File(path)))
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Caching environments and secrets for %s. Press Ctrl+C to stop.", opts.TTL)))
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			deps.UI.Error(err.Error())
+			return err
+		}
+		go serveRPC(conn, server.handle)
+	}
+}
+
+// tokenDigest identifies a login without revealing its token
+func tokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// daemonParams are the parameters of every daemon method
+type daemonParams struct {
+	Repository  string `json:"repository"`
+	Environment string `json:"environment,omitempty"`
+	// Token is the tokenDigest of the caller's login, which must be the daemon's
+	Token string `json:"token"`
+}
+
+// daemonEntry is a cached value, sealed with the daemon's key
+type daemonEntry struct {
+	sealed  []byte
+	expires time.Time
+}
+
+// daemonServer answers daemon requests from its encrypted cache, filling it
+// from the API
+type daemonServer struct {
+	client api.APIClient
+	token  string
+	ttl    time.Duration
+	now    func() time.Time
+
+	// mu serializes requests, so concurrent misses make a single API call
+	mu      sync.Mutex
+	aead    cipher.AEAD
+	entries map[string]daemonEntry
+}
+
+func newDaemonServer(client api.APIClient, token string, ttl time.Duration) (*daemonServer, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonServer{
+		client:  client,
+		token:   tokenDigest(token),
+		ttl:     ttl,
+		now:     time.Now,
+		aead:    aead,
+		entries: make(map[string]daemonEntry),
+	}, nil
+}
+
+func (s *daemonServer) handle(method string, params json.RawMessage) (interface{}, error) {
+	var p daemonParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if method == "hello" {
+		return map[string]interface{}{"name": "keyway-daemon", "protocol": serveProtocolVersion}, nil
+	}
+	if p.Token != s.token {
+		return nil, fmt.Errorf("the daemon was started by another login")
+	}
+	if p.Repository == "" {
+		return nil, fmt.Errorf("repository required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := context.Background()
+	switch method {
+	case "environments":
+		envs, err := s.cached("envs\x00"+p.Repository, func() (interface{}, error) {
+			return s.client.GetVaultEnvironments(ctx, p.Repository)
+		})
+		return map[string]json.RawMessage{"environments": envs}, err
+	case "secrets":
+		if p.Environment == "" {
+			return nil, fmt.Errorf("environment required")
+		}
+		content, err := s.cached("secrets\x00"+p.Repository+"\x00"+p.Environment, func() (interface{}, error) {
+			resp, err := s.client.PullSecrets(ctx, p.Repository, p.Environment)
+			if err != nil {
+				return nil, err
+			}
+			return resp.Content, nil
+		})
+		return map[string]json.RawMessage{"content": content}, err
+	case "invalidate":
+		s.invalidate(p.Repository, p.Environment)
+		return map[string]bool{"ok": true}, nil
+	default:
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+}
+
+// cached returns the JSON of the live entry for key, else fetches, seals and
+// stores it. Errors are never cached.
+func (s *daemonServer) cached(key string, fetch func() (interface{}, error)) (json.RawMessage, error) {
+	if entry, ok := s.entries[key]; ok && s.now().Before(entry.expires) {
+		nonceSize := s.aead.NonceSize()
+		return s.aead.Open(nil, entry.sealed[:nonceSize], entry.sealed[nonceSize:], []byte(key))
+	}
+	delete(s.entries, key)
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	s.entries[key] = daemonEntry{sealed: s.aead.Seal(nonce, nonce, data, []byte(key)), expires: s.now().Add(s.ttl)}
+	return data, nil
+}
+
+// invalidate drops the cached secrets of an environment, or everything cached
+// for the repository when env is ""
+func (s *daemonServer) invalidate(repo, env string) {
+	prefix := "secrets\x00" + repo + "\x00"
+	for key := range s.entries {
+		if key == "envs\x00"+repo || key == prefix+env || (env == "" && strings.HasPrefix(key, prefix)) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// daemonSocket returns the socket of a running daemon, "" when there is none
+// or KEYWAY_NO_DAEMON is set
+func daemonSocket() string {
+	if os.Getenv(noDaemonEnv) != "" {
+		return ""
+	}
+	path, err := state.Path(daemonSocketName)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Lstat(path); err != nil || info.Mode()&os.ModeSocket == 0 {
+		return ""
+	}
+	return path
+}
+
+// daemonAPIClient reads environments and secrets through the daemon, falling
+// back to the API on any daemon error. Writes go to the API and clear what
+// the daemon cached for them.
+type daemonAPIClient struct {
+	api.APIClient
+	socket string
+	token  string
+	// down is set after the first failure to reach the daemon
+	down bool
+}
+
+func newDaemonAPIClient(client api.APIClient, socket, token string) *daemonAPIClient {
+	return &daemonAPIClient{APIClient: client, socket: socket, token: tokenDigest(token)}
+}
+
+// daemonDialTimeout bounds the connection to a daemon that stopped responding
+const daemonDialTimeout = 200 * time.Millisecond
+
+// call sends one request to the daemon and decodes its result into v
+func (c *daemonAPIClient) call(ctx context.Context, method, repo, env string, v interface{}) error {
+	if c.down {
+		return fmt.Errorf("daemon unavailable")
+	}
+	dialer := net.Dialer{Timeout: daemonDialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", c.socket)
+	if err != nil {
+		c.down = true
+		return err
+	}
+	defer conn.Close()
+	// A miss waits for the daemon's own API call
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	params, _ := json.Marshal(daemonParams{Repository: repo, Environment: env, Token: c.token})
+	if err := json.NewEncoder(conn).Encode(rpcRequest{Method: method, Params: params}); err != nil {
+		return err
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return json.Unmarshal(resp.Result, v)
+}
+
+func (c *daemonAPIClient) GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error) {
+	var result struct {
+		Environments []string `json:"environments"`
+	}
+	if err := c.call(ctx, "environments", repoFullName, "", &result); err == nil {
+		return result.Environments, nil
+	}
+	return c.APIClient.GetVaultEnvironments(ctx, repoFullName)
+}
+
+func (c *daemonAPIClient) PullSecrets(ctx context.Context, repo, env string, keys ...string) (*api.PullSecretsResponse, error) {
+	// The daemon caches whole environments
+	if len(keys) == 0 {
+		var result api.PullSecretsResponse
+		if err := c.call(ctx, "secrets", repo, env, &result); err == nil {
+			return &result, nil
+		}
+	}
+	return c.APIClient.PullSecrets(ctx, repo, env, keys...)
+}
+
+func (c *daemonAPIClient) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
+	defer c.invalidate(ctx, repo, env)
+	return c.APIClient.PushSecrets(ctx, repo, env, secrets)
+}
+
+func (c *daemonAPIClient) PatchSecrets(ctx context.Context, repo, env string, patch api.SecretsPatch) (*api.PushSecretsResponse, error) {
+	defer c.invalidate(ctx, repo, env)
+	return c.APIClient.PatchSecrets(ctx, repo, env, patch)
+}
+
+func (c *daemonAPIClient) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	defer c.invalidate(ctx, repoFullName, name)
+	return c.APIClient.CreateEnvironment(ctx, repoFullName, name)
+}
+
+func (c *daemonAPIClient) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	defer c.invalidate(ctx, repoFullName, name)
+	return c.APIClient.DeleteEnvironment(ctx, repoFullName, name)
+}
+
+func (c *daemonAPIClient) ExecuteSync(ctx context.Context, repo string, opts api.SyncOptions) (*api.SyncResult, error) {
+	defer c.invalidate(ctx, repo, "")
+	return c.APIClient.ExecuteSync(ctx, repo, opts)
+}
+
+func (c *daemonAPIClient) TransferVault(ctx context.Context, fromRepo, toRepo string) error {
+	defer c.invalidate(ctx, fromRepo, "")
+	return c.APIClient.TransferVault(ctx, fromRepo, toRepo)
+}
+
+// invalidate clears the daemon's cache after a write, even a failed one,
+// which may have been applied before the error
+func (c *daemonAPIClient) invalidate(ctx context.Context, repo, env string) {
+	var ok map[string]bool
+	_ = c.call(ctx, "invalidate", repo, env, &ok)
+}
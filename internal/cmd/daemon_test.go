@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func daemonRequest(t *testing.T, s *daemonServer, method string, p daemonParams) (interface{}, error) {
+	t.Helper()
+	params, _ := json.Marshal(p)
+	return s.handle(method, params)
+}
+
+func TestDaemonServer_CachesUntilExpiry(t *testing.T) {
+	client := api.NewMockClient()
+	s, err := newDaemonServer(client, "tok", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	p := daemonParams{Repository: "acme/api", Environment: "production", Token: tokenDigest("tok")}
+
+	for i := 0; i < 2; i++ {
+		result, err := daemonRequest(t, s, "secrets", p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(result.(map[string]json.RawMessage)["content"]), "API_KEY=test-api-key") {
+			t.Errorf("unexpected result: %s", result)
+		}
+	}
+	if client.Calls["PullSecrets"] != 1 {
+		t.Errorf("expected a single pull, got %d", client.Calls["PullSecrets"])
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := daemonRequest(t, s, "secrets", p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Calls["PullSecrets"] != 2 {
+		t.Errorf("expected an expired entry to be pulled again, got %d pulls", client.Calls["PullSecrets"])
+	}
+}
+
+func TestDaemonServer_StoresValuesEncrypted(t *testing.T) {
+	s, _ := newDaemonServer(api.NewMockClient(), "tok", time.Minute)
+	p := daemonParams{Repository: "acme/api", Environment: "production", Token: tokenDigest("tok")}
+
+	if _, err := daemonRequest(t, s, "secrets", p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, entry := range s.entries {
+		if strings.Contains(string(entry.sealed), "test-api-key") {
+			t.Error("expected the cached value to be encrypted")
+		}
+	}
+}
+
+func TestDaemonServer_Invalidate(t *testing.T) {
+	client := api.NewMockClient()
+	s, _ := newDaemonServer(client, "tok", time.Minute)
+	p := daemonParams{Repository: "acme/api", Environment: "production", Token: tokenDigest("tok")}
+	staging := p
+	staging.Environment = "staging"
+
+	daemonRequest(t, s, "secrets", p)
+	daemonRequest(t, s, "secrets", staging)
+	daemonRequest(t, s, "environments", p)
+
+	daemonRequest(t, s, "invalidate", p)
+	daemonRequest(t, s, "secrets", p)
+	daemonRequest(t, s, "secrets", staging)
+	daemonRequest(t, s, "environments", p)
+
+	if client.Calls["PullSecrets"] != 3 {
+		t.Errorf("expected only production to be pulled again, got %d pulls", client.Calls["PullSecrets"])
+	}
+	if client.Calls["GetVaultEnvironments"] != 2 {
+		t.Errorf("expected environments to be listed again, got %d", client.Calls["GetVaultEnvironments"])
+	}
+}
+
+func TestDaemonServer_RejectsOtherLogin(t *testing.T) {
+	s, _ := newDaemonServer(api.NewMockClient(), "tok", time.Minute)
+
+	_, err := daemonRequest(t, s, "secrets", daemonParams{Repository: "acme/api", Environment: "production", Token: tokenDigest("other")})
+	if err == nil || !strings.Contains(err.Error(), "another login") {
+		t.Errorf("expected a login error, got %v", err)
+	}
+}
+
+func TestDaemonAPIClient_ThroughSocket(t *testing.T) {
+	daemonClient := api.NewMockClient()
+	s, _ := newDaemonServer(daemonClient, "tok", time.Minute)
+	path := filepath.Join(t.TempDir(), "daemon.sock")
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveRPC(conn, s.handle)
+		}
+	}()
+
+	direct := api.NewMockClient()
+	client := newDaemonAPIClient(direct, path, "tok")
+	ctx := context.Background()
+
+	resp, err := client.PullSecrets(ctx, "acme/api", "production")
+	if err != nil || !strings.Contains(resp.Content, "API_KEY") {
+		t.Fatalf("unexpected pull: %v, %v", resp, err)
+	}
+	envs, err := client.GetVaultEnvironments(ctx, "acme/api")
+	if err != nil || len(envs) != 3 {
+		t.Fatalf("unexpected environments: %v, %v", envs, err)
+	}
+	if direct.Calls["PullSecrets"] != 0 || direct.Calls["GetVaultEnvironments"] != 0 {
+		t.Errorf("expected reads to go through the daemon, got %v", direct.Calls)
+	}
+
+	// A key filter goes to the API
+	if _, err := client.PullSecrets(ctx, "acme/api", "production", "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if direct.Calls["PullSecrets"] != 1 {
+		t.Errorf("expected a filtered pull from the API, got %v", direct.Calls)
+	}
+
+	// A push clears the daemon's copy
+	if _, err := client.PushSecrets(ctx, "acme/api", "production", map[string]string{"A": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	client.PullSecrets(ctx, "acme/api", "production")
+	if daemonClient.Calls["PullSecrets"] != 2 {
+		t.Errorf("expected the daemon to pull again after a push, got %d", daemonClient.Calls["PullSecrets"])
+	}
+}
+
+func TestDaemonAPIClient_FallsBackWhenDown(t *testing.T) {
+	direct := api.NewMockClient()
+	client := newDaemonAPIClient(direct, filepath.Join(t.TempDir(), "missing.sock"), "tok")
+
+	if _, err := client.PullSecrets(context.Background(), "acme/api", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if direct.Calls["PullSecrets"] != 1 || !client.down {
+		t.Errorf("expected a direct pull and the daemon marked down, got %v", direct.Calls)
+	}
+}
+
+func TestRunDaemonWithDeps_InvalidTTL(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runDaemonWithDeps(DaemonOptions{TTL: 0}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
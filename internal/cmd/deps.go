@@ -5,7 +5,13 @@ package cmd
 // Mock implementations for testing are in mocks_test.go.
 
 import (
+	"io"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/syncbase"
 )
 
 // MonorepoInfo contains information about detected monorepo setup
@@ -21,11 +27,14 @@ type GitClient interface {
 	AddEnvToGitignore() error
 	IsGitRepository() bool
 	DetectMonorepo() MonorepoInfo
+	CurrentBranch() (string, error)
 }
 
 // AuthProvider abstracts authentication for testing
 type AuthProvider interface {
 	EnsureLogin() (string, error)
+	// HasSession reports whether EnsureLogin would return a token without prompting
+	HasSession() bool
 }
 
 // UIProvider abstracts UI operations for testing
@@ -41,6 +50,7 @@ type UIProvider interface {
 	IsInteractive() bool
 	Confirm(message string, defaultValue bool) (bool, error)
 	Select(message string, options []string) (string, error)
+	MultiSelect(message string, options []string, selected []string) ([]string, error)
 	Password(prompt string) (string, error)
 	Spin(message string, fn func() error) error
 	Value(v interface{}) string
@@ -59,6 +69,7 @@ type UIProvider interface {
 type FileSystem interface {
 	ReadFile(name string) ([]byte, error)
 	WriteFile(name string, data []byte, perm uint32) error
+	Remove(name string) error
 }
 
 // EnvHelper abstracts env file operations for testing
@@ -81,6 +92,18 @@ type APIClientFactory interface {
 // CommandRunner abstracts command execution for testing
 type CommandRunner interface {
 	RunCommand(name string, args []string, secrets map[string]string) error
+	// RunCommandStatus returns the exit code instead of exiting the process
+	RunCommandStatus(name string, args []string, secrets map[string]string) (int, error)
+	// RunCommandWithEnv starts the command from environ instead of the current environment
+	RunCommandWithEnv(name string, args []string, environ []string, secrets map[string]string) error
+	// RunCommandWithLog is RunCommandWithEnv that also writes the output, secrets masked, to logPath
+	RunCommandWithLog(name string, args []string, environ []string, secrets map[string]string, logPath string) error
+	// RunCommandCaptured runs without a terminal, writing the output with secrets masked to out
+	RunCommandCaptured(name string, args []string, environ []string, secrets map[string]string, out io.Writer) (int, error)
+	// ExecCommand replaces the keyway process with the command (runs it and exits on Windows)
+	ExecCommand(name string, args []string, environ []string, secrets map[string]string) error
+	// Chdir changes the directory the next command starts in
+	Chdir(dir string) error
 }
 
 // BrowserOpener abstracts browser operations for testing
@@ -97,11 +120,15 @@ type AuthStore interface {
 type StoredAuthInfo struct {
 	KeywayToken string
 	GitHubLogin string
+	// Location is where the session is stored: a credential store or a file
+	Location string
 }
 
 // HTTPClient abstracts HTTP operations for testing
 type HTTPClient interface {
 	Head(url string) (int, error)
+	// PostJSON posts body as application/json and returns the status code
+	PostJSON(url string, body []byte) (int, error)
 }
 
 // FileWalker abstracts directory walking for testing
@@ -121,6 +148,56 @@ type FileStat interface {
 	Stat(name string) (FileInfo, error)
 }
 
+// ClipboardProvider abstracts clipboard access for testing
+type ClipboardProvider interface {
+	Copy(text string) error
+	ScheduleClear(text string, after time.Duration) error
+}
+
+// ConfigLoader loads the project config (keyway.toml) for testing
+type ConfigLoader interface {
+	LoadProject() (*config.ProjectConfig, error)
+	FindProjectFile() string
+}
+
+// ActivityLog records local injection history for testing
+type ActivityLog interface {
+	Enabled() bool
+	Record(entry activity.Entry) error
+	Since(t time.Time) ([]activity.Entry, error)
+}
+
+// SyncBaseStore remembers the vault state last pulled or pushed, for conflict
+// detection, or last injected by keyway run, for change summaries
+type SyncBaseStore interface {
+	Load(repo, env string) (syncbase.Snapshot, bool, error)
+	Save(repo, env string, snap syncbase.Snapshot) error
+}
+
+// EnvironmentMemory remembers the environment last chosen at a prompt, per repository
+type EnvironmentMemory interface {
+	Last(repo string) string
+	Remember(repo, env string) error
+}
+
+// AccountStore manages the GitHub accounts signed in on this machine
+type AccountStore interface {
+	Accounts() ([]AccountInfo, error)
+	SwitchAccount(login string) error
+	// BindAccount makes login the account of scope, an owner/repo or an owner
+	BindAccount(scope, login string) error
+	// AccountFor returns the login bound to repo or its owner, "" if none is
+	AccountFor(repo string) string
+}
+
+// AccountInfo describes a signed-in account
+type AccountInfo struct {
+	Login   string
+	Default bool
+	// Bindings are the repositories and owners that use this account
+	Bindings []string
+}
+
 // Dependencies holds all external dependencies for commands
 type Dependencies struct {
 	Git        GitClient
@@ -135,4 +212,11 @@ type Dependencies struct {
 	Stat       FileStat
 	AuthStore  AuthStore
 	HTTP       HTTPClient
+	Clipboard  ClipboardProvider
+	Config     ConfigLoader
+	Activity   ActivityLog
+	SyncBase   SyncBaseStore
+	RunBase    SyncBaseStore
+	LastEnv    EnvironmentMemory
+	Accounts   AccountStore
 }
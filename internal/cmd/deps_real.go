@@ -12,16 +12,26 @@ package cmd
 // The testable business logic lives in the *WithDeps functions in each command file.
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"time"
 
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/auth"
+	"github.com/keywaysh/cli/internal/clipboard"
+	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/keywaysh/cli/internal/git"
 	"github.com/keywaysh/cli/internal/injector"
+	"github.com/keywaysh/cli/internal/namecache"
+	"github.com/keywaysh/cli/internal/syncbase"
 	"github.com/keywaysh/cli/internal/ui"
 	"github.com/pkg/browser"
 )
@@ -33,6 +43,7 @@ func (r *realGitClient) DetectRepo() (string, error) { return git.DetectRepo() }
 func (r *realGitClient) CheckEnvGitignore() bool     { return git.CheckEnvGitignore() }
 func (r *realGitClient) AddEnvToGitignore() error    { return git.AddEnvToGitignore() }
 func (r *realGitClient) IsGitRepository() bool       { return git.IsGitRepository() }
+func (r *realGitClient) CurrentBranch() (string, error) { return git.CurrentBranch() }
 func (r *realGitClient) DetectMonorepo() MonorepoInfo {
 	info := git.DetectMonorepo()
 	return MonorepoInfo{IsMonorepo: info.IsMonorepo, Tool: info.Tool}
@@ -42,6 +53,7 @@ func (r *realGitClient) DetectMonorepo() MonorepoInfo {
 type realAuthProvider struct{}
 
 func (r *realAuthProvider) EnsureLogin() (string, error) { return EnsureLogin() }
+func (r *realAuthProvider) HasSession() bool             { return hasSession() }
 
 // realUIProvider wraps the ui package
 type realUIProvider struct{}
@@ -61,6 +73,9 @@ func (r *realUIProvider) Confirm(message string, defaultValue bool) (bool, error
 func (r *realUIProvider) Select(message string, options []string) (string, error) {
 	return ui.Select(message, options)
 }
+func (r *realUIProvider) MultiSelect(message string, options []string, selected []string) ([]string, error) {
+	return ui.MultiSelect(message, options, selected)
+}
 func (r *realUIProvider) Password(prompt string) (string, error) {
 	return ui.Password(prompt)
 }
@@ -87,11 +102,28 @@ func (r *realFileSystem) WriteFile(name string, data []byte, perm uint32) error
 	return osWriteFile(name, data, perm)
 }
 
+func (r *realFileSystem) Remove(name string) error {
+	return osRemove(name)
+}
+
 // realAPIFactory creates real API clients
 type realAPIFactory struct{}
 
 func (r *realAPIFactory) NewClient(token string) api.APIClient {
-	return api.NewClient(token)
+	var client api.APIClient = api.NewClient(token)
+	// Anonymous reads of public vaults don't go through the daemon or the offline cache
+	if token == "" {
+		return client
+	}
+	client = withOfflineCache(client)
+	// The daemon would call the API
+	if offlineFlag {
+		return client
+	}
+	if socket := daemonSocket(); socket != "" {
+		return newDaemonAPIClient(client, socket, token)
+	}
+	return client
 }
 
 // realEnvHelper wraps the env package
@@ -117,6 +149,32 @@ func (r *realCommandRunner) RunCommand(name string, args []string, secrets map[s
 	return injector.RunCommand(name, args, secrets)
 }
 
+func (r *realCommandRunner) RunCommandStatus(name string, args []string, secrets map[string]string) (int, error) {
+	return injector.Run(name, args, secrets)
+}
+
+func (r *realCommandRunner) RunCommandWithEnv(name string, args []string, environ []string, secrets map[string]string) error {
+	return injector.RunCommandWithEnv(name, args, environ, secrets)
+}
+
+func (r *realCommandRunner) RunCommandWithLog(name string, args []string, environ []string, secrets map[string]string, logPath string) error {
+	return injector.RunCommandWithLog(name, args, environ, secrets, logPath)
+}
+
+func (r *realCommandRunner) RunCommandCaptured(name string, args []string, environ []string, secrets map[string]string, out io.Writer) (int, error) {
+	return injector.RunCaptured(name, args, environ, secrets, out)
+}
+
+func (r *realCommandRunner) ExecCommand(name string, args []string, environ []string, secrets map[string]string) error {
+	return injector.Exec(name, args, environ, secrets)
+}
+
+// Chdir changes keyway's own directory: the command is started last, and
+// exec replaces the process, so it is the directory the command inherits
+func (r *realCommandRunner) Chdir(dir string) error {
+	return os.Chdir(dir)
+}
+
 // realBrowserOpener wraps the browser package
 type realBrowserOpener struct{}
 
@@ -171,9 +229,37 @@ func (r *realAuthStore) GetAuth() (*StoredAuthInfo, error) {
 	return &StoredAuthInfo{
 		KeywayToken: storedAuth.KeywayToken,
 		GitHubLogin: storedAuth.GitHubLogin,
+		Location:    store.Location(),
 	}, nil
 }
 
+// realAccountStore wraps the auth package's accounts
+type realAccountStore struct{}
+
+func (r *realAccountStore) Accounts() ([]AccountInfo, error) {
+	accounts, err := auth.NewStore().Accounts()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]AccountInfo, len(accounts))
+	for i, a := range accounts {
+		infos[i] = AccountInfo{Login: a.Login, Default: a.Default, Bindings: a.Bindings}
+	}
+	return infos, nil
+}
+
+func (r *realAccountStore) SwitchAccount(login string) error {
+	return auth.NewStore().SwitchAccount(login)
+}
+
+func (r *realAccountStore) BindAccount(scope, login string) error {
+	return auth.NewStore().BindAccount(scope, login)
+}
+
+func (r *realAccountStore) AccountFor(repo string) string {
+	return auth.NewStore().AccountFor(repo)
+}
+
 // realHTTPClient wraps http.Client
 type realHTTPClient struct{}
 
@@ -187,6 +273,136 @@ func (r *realHTTPClient) Head(url string) (int, error) {
 	return resp.StatusCode, nil
 }
 
+func (r *realHTTPClient) PostJSON(target string, body []byte) (int, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL may be a secret (Slack webhooks): keep it out of the message
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return 0, urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// realClipboard wraps the clipboard package
+type realClipboard struct{}
+
+func (r *realClipboard) Copy(text string) error {
+	backend, err := clipboard.Detect()
+	if err != nil {
+		return err
+	}
+	return backend.Write(text)
+}
+
+// ScheduleClear starts a detached keyway process that clears the clipboard later,
+// so the current command can exit immediately. Only a digest of the value is passed
+// along, on stdin: the command line of a process is visible to every local user.
+func (r *realClipboard) ScheduleClear(text string, after time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, "secrets", "clear-clipboard", "--after", after.String())
+	cmd.SysProcAttr = detachedProcAttr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// The pipe buffers the digest until the child reads it
+	_, err = io.WriteString(stdin, clipboard.Digest(text)+"\n")
+	if closeErr := stdin.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// realConfigLoader loads keyway.toml from the working directory
+type realConfigLoader struct{}
+
+func (r *realConfigLoader) LoadProject() (*config.ProjectConfig, error) {
+	return config.LoadProject(".")
+}
+
+func (r *realConfigLoader) FindProjectFile() string {
+	return config.FindProjectFile(".")
+}
+
+// realActivityLog records to the activity file in the user config directory
+type realActivityLog struct{}
+
+func (r *realActivityLog) Enabled() bool {
+	return activity.IsEnabled()
+}
+
+func (r *realActivityLog) Record(entry activity.Entry) error {
+	path, err := activity.DefaultPath()
+	if err != nil {
+		return err
+	}
+	return activity.NewLog(path).Append(entry)
+}
+
+func (r *realActivityLog) Since(t time.Time) ([]activity.Entry, error) {
+	path, err := activity.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return activity.NewLog(path).Since(t)
+}
+
+// realSyncBaseStore keeps snapshots in a file of the state directory
+type realSyncBaseStore struct {
+	path func() (string, error)
+}
+
+func (r *realSyncBaseStore) Load(repo, env string) (syncbase.Snapshot, bool, error) {
+	path, err := r.path()
+	if err != nil {
+		return nil, false, err
+	}
+	return syncbase.Load(path, repo, env)
+}
+
+func (r *realSyncBaseStore) Save(repo, env string, snap syncbase.Snapshot) error {
+	path, err := r.path()
+	if err != nil {
+		return err
+	}
+	return syncbase.Save(path, repo, env, snap)
+}
+
+// realEnvironmentMemory keeps the last chosen environments in a file of the state directory
+type realEnvironmentMemory struct{}
+
+func (r *realEnvironmentMemory) Last(repo string) string {
+	path, err := namecache.LastEnvironmentPath()
+	if err != nil {
+		return ""
+	}
+	envName, _ := namecache.LoadLastEnvironment(path, repo)
+	return envName
+}
+
+func (r *realEnvironmentMemory) Remember(repo, env string) error {
+	path, err := namecache.LastEnvironmentPath()
+	if err != nil {
+		return err
+	}
+	return namecache.SaveLastEnvironment(path, repo, env)
+}
+
 // DefaultDeps returns the default (real) dependencies
 func DefaultDeps() *Dependencies {
 	return &Dependencies{
@@ -202,6 +418,13 @@ func DefaultDeps() *Dependencies {
 		Stat:       &realFileStat{},
 		AuthStore:  &realAuthStore{},
 		HTTP:       &realHTTPClient{},
+		Clipboard:  &realClipboard{},
+		Config:     &realConfigLoader{},
+		Activity:   &realActivityLog{},
+		SyncBase:   &realSyncBaseStore{path: syncbase.DefaultPath},
+		RunBase:    &realSyncBaseStore{path: syncbase.RunPath},
+		LastEnv:    &realEnvironmentMemory{},
+		Accounts:   &realAccountStore{},
 	}
 }
 
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+// detachedProcAttr starts background processes in their own session so they
+// survive the terminal sending signals to the keyway process group
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
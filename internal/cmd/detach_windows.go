@@ -0,0 +1,11 @@
+package cmd
+
+import "syscall"
+
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts background processes without a console so they
+// outlive the keyway process
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/hints"
+	"github.com/spf13/cobra"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev [-- command [args...]]",
+	Short: "Start the project for local development with secrets",
+	Long: `Run the daily loop in one command: check the session, pull the
+environment's secrets and start the project with them, its output streamed
+with secret values masked.
+
+The command is, in order: the one after --, [dev] command in keyway.toml,
+else docker compose up when a compose file exists, else the package.json
+dev script. The environment is --env, else default_environments.dev in
+keyway.toml, else development.
+
+Example keyway.toml:
+  [dev]
+  command = "docker compose up --build"
+
+  [default_environments]
+  dev = "staging"
+
+Examples:
+  keyway dev
+  keyway dev -e staging
+  keyway dev --cwd apps/web
+  keyway dev -- npm run storybook`,
+	RunE: runDev,
+}
+
+func init() {
+	devCmd.Flags().StringP("env", "e", "", "Environment name (default development, or default_environments.dev)")
+	devCmd.Flags().String("cwd", "", "Directory to start the project in")
+}
+
+// DevOptions contains the parsed flags for the dev command
+type DevOptions struct {
+	EnvName    string
+	EnvFlagSet bool
+	Dir        string
+	// Args is the command after --, instead of the configured or detected one
+	Args []string
+	// Output receives the command's output, secret values masked
+	Output io.Writer
+}
+
+// runDev is the entry point for the dev command (uses default dependencies)
+func runDev(cmd *cobra.Command, args []string) error {
+	opts := DevOptions{
+		EnvFlagSet: cmd.Flags().Changed("env"),
+		Args:       args,
+		Output:     os.Stdout,
+	}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Dir, _ = cmd.Flags().GetString("cwd")
+
+	return exitWithProviderStatus(runDevWithDeps(opts, defaultDeps))
+}
+
+// runDevWithDeps is the testable version of runDev
+func runDevWithDeps(opts DevOptions, deps *Dependencies) error {
+	deps.UI.Intro("dev")
+
+	command, err := devCommand(opts, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if err := verifyDevSession(deps); err != nil {
+		return err
+	}
+
+	envName := opts.EnvName
+	if !opts.EnvFlagSet {
+		envName = defaultEnvironment(deps, "dev", "development")
+	}
+	deps.UI.Step(fmt.Sprintf("Starting: %s", deps.UI.Command(strings.Join(command, " "))))
+
+	return runRunWithDeps(RunOptions{
+		EnvName:    envName,
+		EnvFlagSet: true,
+		Command:    command[0],
+		Args:       command[1:],
+		Dir:        opts.Dir,
+		Output:     opts.Output,
+	}, deps)
+}
+
+// devCommand returns the command keyway dev starts: the one given after --,
+// [dev] command in keyway.toml, else the one detected from the project files
+func devCommand(opts DevOptions, deps *Dependencies) ([]string, error) {
+	if len(opts.Args) > 0 {
+		return opts.Args, nil
+	}
+
+	if project, err := deps.Config.LoadProject(); err == nil && project.Dev.Command != "" {
+		command, err := splitArgs(project.Dev.Command)
+		if err != nil {
+			return nil, fmt.Errorf("invalid [dev] command in keyway.toml: %w", err)
+		}
+		if len(command) > 0 {
+			return command, nil
+		}
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if detected := hints.DevCommand(dir); detected != "" {
+		return splitArgs(detected)
+	}
+	return nil, fmt.Errorf("no dev command found in %s: set [dev] command in keyway.toml, or pass one after --", filepath.Clean(dir))
+}
+
+// verifyDevSession checks the session before anything starts, offering to
+// sign in again when it expired. An unreachable API only warns: the offline
+// cache may still provide the secrets.
+func verifyDevSession(deps *Dependencies) error {
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if offlineFlag {
+		return nil
+	}
+
+	validation, err := deps.APIFactory.NewClient(token).ValidateToken(context.Background())
+	if err != nil && api.IsAuthExpired(err) {
+		if token, err = handleAuthError(err, deps); err != nil {
+			return err
+		}
+		validation, err = deps.APIFactory.NewClient(token).ValidateToken(context.Background())
+	}
+	if err != nil {
+		if isUnreachable(err) {
+			deps.UI.Warn(fmt.Sprintf("Cannot verify the session: %s", err.Error()))
+			return nil
+		}
+		deps.UI.Error(fmt.Sprintf("Session expired or invalid: %s", err.Error()))
+		return err
+	}
+
+	username := validation.Username
+	if username == "" {
+		username = validation.Login
+	}
+	deps.UI.Step(fmt.Sprintf("Logged in as %s", deps.UI.Value(username)))
+	return nil
+}
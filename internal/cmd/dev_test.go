@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestRunDevWithDeps_ConfiguredCommand(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{"staging": {Content: "API_KEY=secret123"}}
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat"}
+	deps.Config = &MockConfigLoader{Project: &config.ProjectConfig{
+		Dev:                 config.DevConfig{Command: `docker compose up --build "web api"`},
+		DefaultEnvironments: map[string]string{"dev": "staging"},
+	}}
+	cmdRunner.Output = "listening\n"
+
+	var out bytes.Buffer
+	if err := runDevWithDeps(DevOptions{Output: &out}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "docker" || strings.Join(cmdRunner.LastArgs, "|") != "compose|up|--build|web api" {
+		t.Errorf("unexpected command: %s %v", cmdRunner.LastCommand, cmdRunner.LastArgs)
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Error("expected the staging secrets, from default_environments.dev")
+	}
+	if out.String() != "listening\n" {
+		t.Errorf("expected the output streamed, got %q", out.String())
+	}
+	if !strings.Contains(strings.Join(uiMock.StepCalls, "\n"), "Logged in as") {
+		t.Errorf("expected the session checked first, got %v", uiMock.StepCalls)
+	}
+}
+
+func TestRunDevWithDeps_ArgsOverride(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat"}
+	deps.Config = &MockConfigLoader{Project: &config.ProjectConfig{Dev: config.DevConfig{Command: "docker compose up"}}}
+
+	opts := DevOptions{Args: []string{"npm", "run", "storybook"}, Output: &bytes.Buffer{}}
+	if err := runDevWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastCommand != "npm" || strings.Join(cmdRunner.LastArgs, " ") != "run storybook" {
+		t.Errorf("expected the command after -- to win, got %s %v", cmdRunner.LastCommand, cmdRunner.LastArgs)
+	}
+}
+
+func TestRunDevWithDeps_DetectedCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat"}
+	deps.Stat.(*MockFileStat).Files[dir] = &MockFileInfo{FileName: filepath.Base(dir), FileIsDir: true}
+
+	if err := runDevWithDeps(DevOptions{Dir: dir, Output: &bytes.Buffer{}}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastCommand != "docker" || strings.Join(cmdRunner.LastArgs, " ") != "compose up" {
+		t.Errorf("expected docker compose up, got %s %v", cmdRunner.LastCommand, cmdRunner.LastArgs)
+	}
+	if cmdRunner.LastDir != dir {
+		t.Errorf("expected the command run in %s, got %q", dir, cmdRunner.LastDir)
+	}
+}
+
+func TestRunDevWithDeps_NoCommand(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, _ := NewTestDepsWithRunner()
+
+	err := runDevWithDeps(DevOptions{Dir: t.TempDir(), Output: &bytes.Buffer{}}, deps)
+	if err == nil || !strings.Contains(err.Error(), "[dev] command") {
+		t.Fatalf("expected a missing command error, got %v", err)
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("nothing should run")
+	}
+}
+
+func TestRunDevWithDeps_Session(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	apiMock.ValidateTokenError = &api.NetworkError{Message: "DNS lookup failed"}
+
+	opts := DevOptions{Args: []string{"npm", "run", "dev"}, Output: &bytes.Buffer{}}
+	if err := runDevWithDeps(opts, deps); err != nil {
+		t.Fatalf("an unreachable API should only warn, got %v", err)
+	}
+	if len(uiMock.WarnCalls) == 0 || cmdRunner.LastCommand != "npm" {
+		t.Errorf("expected a warning and the command run, got %v", uiMock.WarnCalls)
+	}
+
+	deps, _, _, _, cmdRunner, apiMock = NewTestDepsWithRunner()
+	apiMock.ValidateTokenError = errors.New("forbidden")
+	if err := runDevWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an invalid session to stop keyway dev")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("nothing should run without a valid session")
+	}
+}
+
+func TestRunDevWithDeps_ExitStatus(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat"}
+	cmdRunner.ExitCode = 3
+
+	err := runDevWithDeps(DevOptions{Args: []string{"npm", "run", "dev"}, Output: &bytes.Buffer{}}, deps)
+	var statusErr *exitStatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != 3 {
+		t.Errorf("expected exit status 3, got %v", err)
+	}
+}
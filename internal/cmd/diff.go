@@ -3,11 +3,17 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/keywaysh/cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -22,11 +28,25 @@ var diffCmd = &cobra.Command{
 
 When run without arguments in an interactive terminal, prompts for environment selection.
 
+With --at, an environment is compared against its own past state from the
+vault's version history: a date (2024-01-01), a timestamp (RFC 3339), a
+lookback (7d) or a version number (v12).
+
+With --show-values, each changed value is compared word by word: removed words
+are shown as [-...-] and added ones as {+...+}. Separators such as :// and @
+are kept, so you can see which part of a URL changed, while words are masked.
+
+With --exit-code, keyway diff exits with status 1 when the environments differ,
+to stop a deploy script on drift.
+
 Examples:
   keyway diff                           # Interactive selection
   keyway diff production staging
   keyway diff development production --show-values
-  keyway diff prod dev --keys-only`,
+  keyway diff prod dev --keys-only
+  keyway diff staging production --exit-code
+  keyway diff --env production --at 2024-01-01
+  keyway diff --env production --at v12 --show-values`,
 	Args: cobra.RangeArgs(0, 2),
 	RunE: runDiff,
 }
@@ -35,6 +55,9 @@ func init() {
 	diffCmd.Flags().Bool("show-values", false, "Show actual value differences (sensitive!)")
 	diffCmd.Flags().Bool("keys-only", false, "Only show key names, no status details")
 	diffCmd.Flags().Bool("json", false, "Output as JSON")
+	diffCmd.Flags().StringP("env", "e", "", "Environment to compare against its past state (with --at)")
+	diffCmd.Flags().String("at", "", "Past state to compare with: date, timestamp, lookback (7d) or version (v12)")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with status 1 when the environments differ")
 }
 
 // DiffResult represents the comparison between two environments
@@ -72,6 +95,9 @@ type DiffOptions struct {
 	ShowValues bool
 	KeysOnly   bool
 	JSONOutput bool
+	EnvName    string // Compared against its past state when At is set
+	At         string
+	ExitCode   bool
 }
 
 // runDiff is the entry point for the diff command (uses default dependencies)
@@ -80,6 +106,9 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	opts.ShowValues, _ = cmd.Flags().GetBool("show-values")
 	opts.KeysOnly, _ = cmd.Flags().GetBool("keys-only")
 	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.At, _ = cmd.Flags().GetString("at")
+	opts.ExitCode, _ = cmd.Flags().GetBool("exit-code")
 
 	if len(args) >= 1 {
 		opts.Env1 = args[0]
@@ -110,6 +139,14 @@ func runDiffWithDeps(opts DiffOptions, deps *Dependencies) error {
 	client := deps.APIFactory.NewClient(token)
 	ctx := context.Background()
 
+	if opts.At != "" {
+		return diffSnapshot(ctx, opts, deps, client, repo)
+	}
+	if opts.EnvName != "" {
+		deps.UI.Error("--env compares an environment with its past state and requires --at")
+		return fmt.Errorf("--env requires --at")
+	}
+
 	env1 := opts.Env1
 	env2 := opts.Env2
 
@@ -231,16 +268,127 @@ func runDiffWithDeps(opts DiffOptions, deps *Dependencies) error {
 	})
 
 	if opts.JSONOutput {
-		return printDiffJSON(result)
+		if err := printDiffJSON(os.Stdout, result); err != nil {
+			return err
+		}
+		return diffExitCode(opts, result)
 	}
 
 	// Display results
 	printDiffResults(result, env1, env2, opts.ShowValues, opts.KeysOnly)
 
 	deps.UI.Outro("")
+	return diffExitCode(opts, result)
+}
+
+// diffExitCode returns an error when --exit-code is set and the result has differences
+func diffExitCode(opts DiffOptions, result *DiffResult) error {
+	if !opts.ExitCode {
+		return nil
+	}
+	if n := result.Stats.Different + result.Stats.OnlyInEnv1 + result.Stats.OnlyInEnv2; n > 0 {
+		return fmt.Errorf("%s and %s differ in %d keys", result.Env1, result.Env2, n)
+	}
 	return nil
 }
 
+// diffSnapshot compares an environment with its state at opts.At
+func diffSnapshot(ctx context.Context, opts DiffOptions, deps *Dependencies, client api.APIClient, repo string) error {
+	envName := opts.EnvName
+	if envName == "" {
+		envName = opts.Env1
+	} else if opts.Env1 != "" {
+		deps.UI.Error("Give the environment either as an argument or with --env")
+		return fmt.Errorf("environment given twice")
+	}
+	if envName == "" || opts.Env2 != "" {
+		deps.UI.Error("--at compares a single environment: keyway diff --env <env> --at <when>")
+		return fmt.Errorf("one environment required with --at")
+	}
+	envName = normalizeEnvName(envName)
+
+	ref, err := parseSnapshotRef(opts.At, time.Now())
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	label := envName + "@" + opts.At
+
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Comparing %s vs %s", deps.UI.Bold(label), deps.UI.Bold(envName))))
+
+	var past, current map[string]string
+	err = deps.UI.Spin(fmt.Sprintf("Fetching %s and %s...", label, envName), func() error {
+		resp, err := client.PullSecretsSnapshot(ctx, repo, envName, ref)
+		if err != nil {
+			return err
+		}
+		past = env.Parse(resp.Content)
+
+		resp, err = client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			return err
+		}
+		current = env.Parse(resp.Content)
+		return nil
+	})
+	if err != nil {
+		if api.IsNotFound(err) {
+			deps.UI.Error(fmt.Sprintf("No version of %s at %s", envName, opts.At))
+		} else {
+			deps.UI.Error(fmt.Sprintf("Failed to fetch %s: %s", envName, err.Error()))
+		}
+		return err
+	}
+
+	result := compareSecrets(label, envName, past, current, opts.ShowValues)
+
+	analytics.Track(analytics.EventDiff, map[string]interface{}{
+		"env1":              envName,
+		"env2":              envName,
+		"snapshot":          true,
+		"differences_count": result.Stats.Different + result.Stats.OnlyInEnv1 + result.Stats.OnlyInEnv2,
+		"same_count":        result.Stats.Same,
+		"total_env1":        result.Stats.TotalEnv1,
+		"total_env2":        result.Stats.TotalEnv2,
+	})
+
+	if opts.JSONOutput {
+		if err := printDiffJSON(os.Stdout, result); err != nil {
+			return err
+		}
+		return diffExitCode(opts, result)
+	}
+
+	printDiffResults(result, label, envName, opts.ShowValues, opts.KeysOnly)
+
+	deps.UI.Outro("")
+	return diffExitCode(opts, result)
+}
+
+// parseSnapshotRef parses --at: a version (12 or v12), a date, an RFC 3339
+// timestamp or a lookback from now (7d, 36h)
+func parseSnapshotRef(s string, now time.Time) (api.SnapshotRef, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(s), "v")); err == nil {
+		if n <= 0 {
+			return api.SnapshotRef{}, fmt.Errorf("invalid version %q", s)
+		}
+		return api.SnapshotRef{Version: n}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if t.After(now) {
+				return api.SnapshotRef{}, fmt.Errorf("%s is in the future", s)
+			}
+			return api.SnapshotRef{At: t}, nil
+		}
+	}
+	if d, err := activity.ParseSince(s); err == nil {
+		return api.SnapshotRef{At: now.Add(-d)}, nil
+	}
+	return api.SnapshotRef{}, fmt.Errorf("invalid --at %q (use a date like 2024-01-01, a lookback like 7d or a version like v12)", s)
+}
+
 func normalizeEnvName(env string) string {
 	env = strings.ToLower(strings.TrimSpace(env))
 	switch env {
@@ -340,6 +488,28 @@ func maskValue(value string) string {
 	return value[:2] + strings.Repeat("*", len(value)-4) + value[len(value)-2:]
 }
 
+// renderWordDiff renders a value change word by word, git word-diff style:
+// [-removed-]{+added+}. Separators (":", "//", "@"...) stay readable so the
+// structure of URLs and DSNs shows; words are masked like maskValue.
+func renderWordDiff(before, after string) string {
+	red, green := color.New(color.FgRed), color.New(color.FgGreen)
+	var b strings.Builder
+	for _, edit := range env.DiffWords(before, after) {
+		text := edit.Text
+		if !edit.Separator {
+			text = maskValue(text)
+		}
+		switch edit.Op {
+		case env.WordDeleted:
+			text = red.Sprint("[-" + text + "-]")
+		case env.WordInserted:
+			text = green.Sprint("{+" + text + "+}")
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
 func printDiffResults(result *DiffResult, env1, env2 string, showValues, keysOnly bool) {
 	// Summary
 	if result.Stats.OnlyInEnv1 == 0 && result.Stats.OnlyInEnv2 == 0 && result.Stats.Different == 0 {
@@ -383,8 +553,7 @@ func printDiffResults(result *DiffResult, env1, env2 string, showValues, keysOnl
 				fmt.Printf("  %s\n", entry.Key)
 			} else if showValues {
 				fmt.Printf("  %s %s\n", yellow.Sprint("~"), entry.Key)
-				fmt.Printf("    %s: %s\n", env1, maskValue(entry.Value1))
-				fmt.Printf("    %s: %s\n", env2, maskValue(entry.Value2))
+				fmt.Printf("    %s\n", renderWordDiff(entry.Value1, entry.Value2))
 			} else {
 				fmt.Printf("  %s %s %s\n", yellow.Sprint("~"), entry.Key, ui.Dim(fmt.Sprintf("%s → %s", entry.Preview1, entry.Preview2)))
 			}
@@ -411,62 +580,62 @@ func printDiffResults(result *DiffResult, env1, env2 string, showValues, keysOnl
 	}
 }
 
-func printDiffJSON(result *DiffResult) error {
+func printDiffJSON(w io.Writer, result *DiffResult) error {
 	// Simple JSON output without external dependency
-	fmt.Println("{")
-	fmt.Printf("  \"env1\": %q,\n", result.Env1)
-	fmt.Printf("  \"env2\": %q,\n", result.Env2)
+	fmt.Fprintln(w, "{")
+	fmt.Fprintf(w, "  \"env1\": %q,\n", result.Env1)
+	fmt.Fprintf(w, "  \"env2\": %q,\n", result.Env2)
 
 	// OnlyInEnv1
-	fmt.Print("  \"onlyInEnv1\": [")
+	fmt.Fprint(w, "  \"onlyInEnv1\": [")
 	for i, k := range result.OnlyInEnv1 {
 		if i > 0 {
-			fmt.Print(", ")
+			fmt.Fprint(w, ", ")
 		}
-		fmt.Printf("%q", k)
+		fmt.Fprintf(w, "%q", k)
 	}
-	fmt.Println("],")
+	fmt.Fprintln(w, "],")
 
 	// OnlyInEnv2
-	fmt.Print("  \"onlyInEnv2\": [")
+	fmt.Fprint(w, "  \"onlyInEnv2\": [")
 	for i, k := range result.OnlyInEnv2 {
 		if i > 0 {
-			fmt.Print(", ")
+			fmt.Fprint(w, ", ")
 		}
-		fmt.Printf("%q", k)
+		fmt.Fprintf(w, "%q", k)
 	}
-	fmt.Println("],")
+	fmt.Fprintln(w, "],")
 
 	// Different
-	fmt.Print("  \"different\": [")
+	fmt.Fprint(w, "  \"different\": [")
 	for i, d := range result.Different {
 		if i > 0 {
-			fmt.Print(", ")
+			fmt.Fprint(w, ", ")
 		}
-		fmt.Printf("{\"key\": %q, \"preview1\": %q, \"preview2\": %q}", d.Key, d.Preview1, d.Preview2)
+		fmt.Fprintf(w, "{\"key\": %q, \"preview1\": %q, \"preview2\": %q}", d.Key, d.Preview1, d.Preview2)
 	}
-	fmt.Println("],")
+	fmt.Fprintln(w, "],")
 
 	// Same
-	fmt.Print("  \"same\": [")
+	fmt.Fprint(w, "  \"same\": [")
 	for i, k := range result.Same {
 		if i > 0 {
-			fmt.Print(", ")
+			fmt.Fprint(w, ", ")
 		}
-		fmt.Printf("%q", k)
+		fmt.Fprintf(w, "%q", k)
 	}
-	fmt.Println("],")
+	fmt.Fprintln(w, "],")
 
 	// Stats
-	fmt.Println("  \"stats\": {")
-	fmt.Printf("    \"totalEnv1\": %d,\n", result.Stats.TotalEnv1)
-	fmt.Printf("    \"totalEnv2\": %d,\n", result.Stats.TotalEnv2)
-	fmt.Printf("    \"onlyInEnv1\": %d,\n", result.Stats.OnlyInEnv1)
-	fmt.Printf("    \"onlyInEnv2\": %d,\n", result.Stats.OnlyInEnv2)
-	fmt.Printf("    \"different\": %d,\n", result.Stats.Different)
-	fmt.Printf("    \"same\": %d\n", result.Stats.Same)
-	fmt.Println("  }")
-	fmt.Println("}")
+	fmt.Fprintln(w, "  \"stats\": {")
+	fmt.Fprintf(w, "    \"totalEnv1\": %d,\n", result.Stats.TotalEnv1)
+	fmt.Fprintf(w, "    \"totalEnv2\": %d,\n", result.Stats.TotalEnv2)
+	fmt.Fprintf(w, "    \"onlyInEnv1\": %d,\n", result.Stats.OnlyInEnv1)
+	fmt.Fprintf(w, "    \"onlyInEnv2\": %d,\n", result.Stats.OnlyInEnv2)
+	fmt.Fprintf(w, "    \"different\": %d,\n", result.Stats.Different)
+	fmt.Fprintf(w, "    \"same\": %d\n", result.Stats.Same)
+	fmt.Fprintln(w, "  }")
+	fmt.Fprintln(w, "}")
 
 	return nil
 }
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate documentation of the vault's keys",
+}
+
+var docsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Write a reference of every key and the environments that have it",
+	Long: `Generate a Markdown (or HTML) table of every key in the vault, with its
+description and owner from keyway.toml and the environments that define it.
+Values are never included, so the file can be committed next to your docs.
+
+Describe keys in keyway.toml:
+
+  [keys.DATABASE_URL]
+  description = "Primary Postgres connection string"
+  owner = "@acme/platform"
+
+The output only changes when keys or their documentation change: run with
+--check in CI to fail when the committed file is out of date.
+
+Examples:
+  keyway docs generate -f docs/environment.md
+  keyway docs generate --html -f docs/environment.html
+  keyway docs generate -f docs/environment.md --check`,
+	Args: cobra.NoArgs,
+	RunE: runDocsGenerate,
+}
+
+func init() {
+	docsGenerateCmd.Flags().StringP("file", "f", "", "File to write (default: stdout)")
+	docsGenerateCmd.Flags().Bool("html", false, "Generate an HTML page instead of Markdown")
+	docsGenerateCmd.Flags().StringSliceP("env", "e", nil, "Environments to include (default: all)")
+	docsGenerateCmd.Flags().Bool("check", false, "Fail if the output file is not up to date, without writing it")
+
+	docsCmd.AddCommand(docsGenerateCmd)
+}
+
+// DocsGenerateOptions contains the parsed flags for docs generate
+type DocsGenerateOptions struct {
+	File         string
+	HTML         bool
+	Environments []string
+	Check        bool
+}
+
+// keyDoc is one row of the generated reference
+type keyDoc struct {
+	Key          string
+	Description  string
+	Owner        string
+	Environments map[string]bool
+}
+
+// runDocsGenerate is the entry point for docs generate (uses default dependencies)
+func runDocsGenerate(cmd *cobra.Command, args []string) error {
+	var opts DocsGenerateOptions
+	opts.File, _ = cmd.Flags().GetString("file")
+	opts.HTML, _ = cmd.Flags().GetBool("html")
+	opts.Environments, _ = cmd.Flags().GetStringSlice("env")
+	opts.Check, _ = cmd.Flags().GetBool("check")
+
+	return runDocsGenerateWithDeps(opts, defaultDeps)
+}
+
+// runDocsGenerateWithDeps is the testable version of runDocsGenerate
+func runDocsGenerateWithDeps(opts DocsGenerateOptions, deps *Dependencies) error {
+	if opts.Check && opts.File == "" {
+		return fmt.Errorf("--check needs the file to compare with: pass --file")
+	}
+	// Without a file the reference goes to stdout, keep it clean
+	quiet := opts.File == ""
+	if !quiet {
+		deps.UI.Intro("docs generate")
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	ctx := context.Background()
+	client := deps.APIFactory.NewClient(token)
+	envs := opts.Environments
+	if len(envs) == 0 {
+		if envs, err = environmentCandidates(ctx, deps, client, repo); err != nil {
+			return err
+		}
+	}
+
+	keysByEnv := make(map[string][]string, len(envs))
+	for _, envName := range envs {
+		keys, err := environmentKeys(ctx, deps, client, repo, envName)
+		if err != nil {
+			return err
+		}
+		keysByEnv[envName] = keys
+	}
+
+	docs := collectKeyDocs(project, envs, keysByEnv)
+	var content []byte
+	if opts.HTML {
+		content, err = renderDocsHTML(repo, envs, docs)
+	} else {
+		content = renderDocsMarkdown(repo, envs, docs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if quiet {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	if opts.Check {
+		existing, err := deps.FS.ReadFile(opts.File)
+		if err != nil || !bytes.Equal(existing, content) {
+			deps.UI.Error(fmt.Sprintf("%s is out of date", opts.File))
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Run keyway docs generate -f %s and commit the result", opts.File)))
+			return fmt.Errorf("%s is out of date", opts.File)
+		}
+		deps.UI.Success(fmt.Sprintf("%s is up to date", opts.File))
+		return nil
+	}
+
+	if err := deps.FS.WriteFile(opts.File, content, 0644); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write %s: %s", opts.File, err.Error()))
+		return err
+	}
+	deps.UI.Success(fmt.Sprintf("Documented %d keys across %d environments in %s", len(docs), len(envs), deps.UI.File(opts.File)))
+	if n := countUndocumented(docs); n > 0 {
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("%d keys have no description: add them under [keys] in keyway.toml", n)))
+	}
+	return nil
+}
+
+// environmentKeys returns the sorted key names of an environment. It prefers
+// the metadata endpoint, which returns no values and does not count as a pull.
+func environmentKeys(ctx context.Context, deps *Dependencies, client api.APIClient, repo, envName string) ([]string, error) {
+	var metadata []api.KeyMetadata
+	err := deps.UI.Spin(fmt.Sprintf("Listing %s keys...", envName), func() error {
+		var err error
+		metadata, err = client.GetSecretsMetadata(ctx, repo, envName)
+		return err
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to list %s keys: %s", envName, err.Error()))
+		return nil, err
+	}
+	if metadata != nil {
+		keys := make([]string, len(metadata))
+		for i, m := range metadata {
+			keys[i] = m.Key
+		}
+		sort.Strings(keys)
+		return keys, nil
+	}
+
+	secrets, _, err := fetchSecrets(deps, client, repo, envName)
+	if err != nil {
+		return nil, err
+	}
+	return sortedSecretKeys(secrets), nil
+}
+
+// collectKeyDocs returns a row per key present in any environment, sorted by key
+func collectKeyDocs(project *config.ProjectConfig, envs []string, keysByEnv map[string][]string) []keyDoc {
+	byKey := make(map[string]*keyDoc)
+	for _, envName := range envs {
+		for _, key := range keysByEnv[envName] {
+			doc, ok := byKey[key]
+			if !ok {
+				kc := project.KeyDoc(key)
+				doc = &keyDoc{Key: key, Description: kc.Description, Owner: kc.Owner, Environments: make(map[string]bool)}
+				byKey[key] = doc
+			}
+			doc.Environments[envName] = true
+		}
+	}
+
+	docs := make([]keyDoc, 0, len(byKey))
+	for _, doc := range byKey {
+		docs = append(docs, *doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Key < docs[j].Key })
+	return docs
+}
+
+func countUndocumented(docs []keyDoc) int {
+	n := 0
+	for _, doc := range docs {
+		if doc.Description == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// renderDocsMarkdown renders the reference as a Markdown table
+func renderDocsMarkdown(repo string, envs []string, docs []keyDoc) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Environment variables\n\n")
+	fmt.Fprintf(&b, "<!-- Generated by keyway docs generate from %s. Do not edit: describe keys in keyway.toml. -->\n\n", repo)
+
+	b.WriteString("| Key | Description | Owner |")
+	for _, envName := range envs {
+		fmt.Fprintf(&b, " %s |", markdownCell(envName))
+	}
+	b.WriteString("\n| --- | --- | --- |")
+	for range envs {
+		b.WriteString(" :---: |")
+	}
+	b.WriteString("\n")
+
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "| `%s` | %s | %s |", doc.Key, markdownCell(doc.Description), markdownCell(doc.Owner))
+		for _, envName := range envs {
+			if doc.Environments[envName] {
+				b.WriteString(" ✓ |")
+			} else {
+				b.WriteString(" |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var docsHTMLTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<!-- Generated by keyway docs generate from {{.Repo}}. Do not edit: describe keys in keyway.toml. -->
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Environment variables - {{.Repo}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 0.4rem 0.8rem; text-align: left; }
+td.env { text-align: center; }
+</style>
+</head>
+<body>
+<h1>Environment variables</h1>
+<table>
+<thead>
+<tr><th>Key</th><th>Description</th><th>Owner</th>{{range .Envs}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range $doc := .Docs}}
+<tr><td><code>{{$doc.Key}}</code></td><td>{{$doc.Description}}</td><td>{{$doc.Owner}}</td>{{range $.Envs}}<td class="env">{{if index $doc.Environments .}}✓{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// renderDocsHTML renders the reference as a standalone HTML page
+func renderDocsHTML(repo string, envs []string, docs []keyDoc) ([]byte, error) {
+	var b bytes.Buffer
+	err := docsHTMLTemplate.Execute(&b, struct {
+		Repo string
+		Envs []string
+		Docs []keyDoc
+	}{repo, envs, docs})
+	return b.Bytes(), err
+}
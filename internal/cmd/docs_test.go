@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestRunDocsGenerateWithDeps_WritesMarkdown(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "production"}
+	apiMock.KeyMetadataByEnv = map[string][]api.KeyMetadata{
+		"development": {{Key: "DEBUG"}, {Key: "DATABASE_URL"}},
+		"production":  {{Key: "DATABASE_URL"}},
+	}
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Keys: map[string]config.KeyConfig{
+		"DATABASE_URL": {Description: "Postgres | primary", Owner: "@acme/platform"},
+	}}
+
+	if err := runDocsGenerateWithDeps(DocsGenerateOptions{File: "docs/env.md"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content := string(fsMock.Written["docs/env.md"])
+	for _, want := range []string{
+		"| Key | Description | Owner | development | production |",
+		"| `DATABASE_URL` | Postgres \\| primary | @acme/platform | ✓ | ✓ |",
+		"| `DEBUG` |  |  | ✓ | |",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in:\n%s", want, content)
+		}
+	}
+	if strings.Index(content, "DATABASE_URL") > strings.Index(content, "DEBUG") {
+		t.Error("expected keys sorted")
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected success, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunDocsGenerateWithDeps_FallsBackToPull(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret-value\n"}
+
+	opts := DocsGenerateOptions{File: "env.html", HTML: true, Environments: []string{"staging"}}
+	if err := runDocsGenerateWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content := string(fsMock.Written["env.html"])
+	if !strings.Contains(content, "<code>API_KEY</code>") || !strings.Contains(content, "<th>staging</th>") {
+		t.Errorf("unexpected HTML:\n%s", content)
+	}
+	if strings.Contains(content, "secret-value") {
+		t.Error("values must not be written")
+	}
+}
+
+func TestRunDocsGenerateWithDeps_Check(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	apiMock.KeyMetadata = []api.KeyMetadata{{Key: "API_KEY"}}
+	opts := DocsGenerateOptions{File: "env.md", Environments: []string{"production"}}
+
+	if err := runDocsGenerateWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fsMock.Files["env.md"] = fsMock.Written["env.md"]
+	opts.Check = true
+	if err := runDocsGenerateWithDeps(opts, deps); err != nil {
+		t.Errorf("expected an up to date file, got %v", err)
+	}
+
+	apiMock.KeyMetadata = append(apiMock.KeyMetadata, api.KeyMetadata{Key: "NEW_KEY"})
+	delete(fsMock.Written, "env.md")
+	if err := runDocsGenerateWithDeps(opts, deps); err == nil {
+		t.Error("expected an out of date error")
+	}
+	if _, ok := fsMock.Written["env.md"]; ok {
+		t.Error("--check must not write the file")
+	}
+}
+
+func TestRunDocsGenerateWithDeps_CheckNeedsFile(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runDocsGenerateWithDeps(DocsGenerateOptions{Check: true}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
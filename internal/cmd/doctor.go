@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/background"
 	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/version"
 	"github.com/spf13/cobra"
@@ -58,16 +59,12 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	return runDoctorWithDeps(opts, defaultDeps)
 }
 
-// runDoctorWithDeps is the testable version of runDoctor
-func runDoctorWithDeps(opts DoctorOptions, deps *Dependencies) error {
-	if !opts.JSONOutput {
-		deps.UI.Intro("doctor")
-	}
-
+// doctorChecks runs the diagnostic checks, in display order
+func doctorChecks(deps *Dependencies, currentVersion string) []checkResult {
 	checks := []checkResult{}
 
 	// 1. Version check
-	versionCheck := checkVersion(opts.Version)
+	versionCheck := checkVersion(currentVersion)
 	checks = append(checks, versionCheck)
 
 	// 2. Authentication check
@@ -90,6 +87,17 @@ func runDoctorWithDeps(opts DoctorOptions, deps *Dependencies) error {
 	gitignoreCheck := checkGitignoreWithDeps(deps)
 	checks = append(checks, gitignoreCheck)
 
+	return checks
+}
+
+// runDoctorWithDeps is the testable version of runDoctor
+func runDoctorWithDeps(opts DoctorOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("doctor")
+	}
+
+	checks := doctorChecks(deps, opts.Version)
+
 	// Apply strict mode
 	if opts.Strict {
 		for i := range checks {
@@ -185,12 +193,16 @@ func checkAuthWithDeps(deps *Dependencies) checkResult {
 	if username == "" {
 		username = "user"
 	}
+	detail := fmt.Sprintf("Logged in as %s", username)
+	if storedAuth.Location != "" {
+		detail += fmt.Sprintf(" (stored in %s)", storedAuth.Location)
+	}
 
 	return checkResult{
 		ID:     "auth",
 		Name:   "Authentication",
 		Status: "pass",
-		Detail: fmt.Sprintf("Logged in as %s", username),
+		Detail: detail,
 	}
 }
 
@@ -308,7 +320,16 @@ func checkGitignoreWithDeps(deps *Dependencies) checkResult {
 }
 
 func checkVersion(currentVersion string) checkResult {
-	ctx, cancel := context.WithTimeout(context.Background(), version.CheckTimeout)
+	if !background.Enabled() {
+		return checkResult{
+			ID:     "version",
+			Name:   "CLI version",
+			Status: "pass",
+			Detail: fmt.Sprintf("%s (update check off: background requests disabled)", currentVersion),
+		}
+	}
+
+	ctx, cancel := version.CheckContext()
 	defer cancel()
 
 	info := version.CheckForUpdate(ctx, currentVersion)
@@ -3,6 +3,8 @@ package cmd
 import (
 	"errors"
 	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
 )
 
 func TestCheckResult_Structure(t *testing.T) {
@@ -215,6 +217,17 @@ func TestCheckAuthWithDeps_NotLoggedIn(t *testing.T) {
 	}
 }
 
+func TestCheckAuthWithDeps_Location(t *testing.T) {
+	deps, _, _, _, authStore, _, apiMock := NewTestDepsForDoctor()
+	authStore.StoredAuth = &StoredAuthInfo{KeywayToken: "valid-token", Location: "macOS Keychain"}
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Username: "testuser"}
+
+	result := checkAuthWithDeps(deps)
+	if result.Status != "pass" || result.Detail != "Logged in as testuser (stored in macOS Keychain)" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
 func TestCheckAuthWithDeps_ValidToken(t *testing.T) {
 	deps, _, _, _, authStore, _, apiMock := NewTestDepsForDoctor()
 
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// strictEnvs is set by the global --strict-envs flag
+var strictEnvs bool
+
+// environmentCandidates returns the environments to offer in an environment prompt.
+// When the vault's environments cannot be listed it warns and falls back to
+// fallback_environments from keyway.toml, or fails if --strict-envs is set.
+func environmentCandidates(ctx context.Context, deps *Dependencies, client api.APIClient, repo string) ([]string, error) {
+	vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
+	if err == nil && len(vaultEnvs) > 0 {
+		return vaultEnvs, nil
+	}
+
+	reason := "the vault has no environments"
+	if err != nil {
+		reason = err.Error()
+	}
+
+	if strictEnvs {
+		deps.UI.Error(fmt.Sprintf("Could not list environments: %s", reason))
+		if err == nil {
+			err = fmt.Errorf("no environments")
+		}
+		return nil, err
+	}
+
+	project, loadErr := deps.Config.LoadProject()
+	if loadErr != nil {
+		deps.UI.Warn(loadErr.Error())
+	}
+	fallback := project.EnvironmentFallback()
+
+	deps.UI.Warn(fmt.Sprintf("Could not list environments (%s), showing fallback: %s", reason, strings.Join(fallback, ", ")))
+	deps.UI.Message(deps.UI.Dim("Pass --env to choose explicitly, or --strict-envs to fail instead."))
+	return fallback, nil
+}
+
+// accessibleEnvironments removes from envs the environments the current token
+// cannot read, or cannot write when write is set, so a prompt does not offer a
+// choice that fails afterwards. Environments the API reports nothing about are
+// kept, and envs is returned as is when access cannot be checked.
+func accessibleEnvironments(ctx context.Context, deps *Dependencies, client api.APIClient, repo string, envs []string, write bool) ([]string, error) {
+	access, err := client.GetEnvironmentAccess(ctx, repo)
+	if err != nil || len(access) == 0 {
+		return envs, nil
+	}
+	denied := make(map[string]bool, len(access))
+	for _, a := range access {
+		denied[a.Name] = !a.Read || (write && !a.Write)
+	}
+
+	var allowed, hidden []string
+	for _, e := range envs {
+		if denied[e] {
+			hidden = append(hidden, e)
+		} else {
+			allowed = append(allowed, e)
+		}
+	}
+	if len(hidden) == 0 {
+		return envs, nil
+	}
+
+	verb := "read"
+	if write {
+		verb = "write to"
+	}
+	if len(allowed) == 0 {
+		deps.UI.Error(fmt.Sprintf("You cannot %s any environment of %s", verb, repo))
+		return nil, fmt.Errorf("no accessible environments")
+	}
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Hidden, you cannot %s them: %s", verb, strings.Join(hidden, ", "))))
+	return allowed, nil
+}
+
+// defaultEnvironment returns the environment that keyway.toml's
+// default_environments sets for command, else builtin
+func defaultEnvironment(deps *Dependencies, command, builtin string) string {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		return builtin
+	}
+	if envName := project.DefaultEnvironment(command); envName != "" {
+		return envName
+	}
+	return builtin
+}
+
+// resolveEnvironment returns the environment a command uses. --env wins;
+// otherwise keyway.toml's default_environments for command, else builtin, is
+// used in scripts, and an interactive terminal prompts, offering first the
+// environment last chosen for repo. The choice is remembered.
+func resolveEnvironment(ctx context.Context, deps *Dependencies, client api.APIClient, repo string, public *api.PublicVault, command, builtin string, envFlagSet bool) (string, error) {
+	if envFlagSet {
+		return builtin, nil
+	}
+	envName := defaultEnvironment(deps, command, builtin)
+	if !deps.UI.IsInteractive() {
+		return envName, nil
+	}
+
+	envs, err := readableEnvironments(ctx, deps, client, repo, public)
+	if err != nil {
+		return "", err
+	}
+	return promptEnvironment(deps, repo, "Environment:", envs, envName)
+}
+
+// promptEnvironment asks for one of envs, offering first the environment last
+// chosen for repo if it is one of them, else preferred, and remembers the choice
+func promptEnvironment(deps *Dependencies, repo, message string, envs []string, preferred string) (string, error) {
+	if last := deps.LastEnv.Last(repo); last != "" {
+		for _, e := range envs {
+			if e == last {
+				preferred = last
+				break
+			}
+		}
+	}
+
+	// Reorder to put the preferred environment first
+	for i, e := range envs {
+		if e == preferred {
+			if i > 0 {
+				envs[0], envs[i] = envs[i], envs[0]
+			}
+			break
+		}
+	}
+
+	selected, err := deps.UI.Select(message, envs)
+	if err != nil {
+		return "", err
+	}
+	// Only a convenience for the next prompt
+	_ = deps.LastEnv.Remember(repo, selected)
+	return selected, nil
+}
+
+// ensureEnvironment checks that envName exists in the vault and offers to
+// create it when it doesn't, or creates it directly when create is set
+// (--create-missing). It reports whether the environment was created.
+// When the environments cannot be listed it does nothing, so the caller's
+// own error handling applies.
+func ensureEnvironment(ctx context.Context, deps *Dependencies, client api.APIClient, repo, envName string, create bool) (bool, error) {
+	vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
+	if err != nil {
+		return false, nil
+	}
+	for _, e := range vaultEnvs {
+		if e == envName {
+			return false, nil
+		}
+	}
+
+	if !create {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error(fmt.Sprintf("Environment %s does not exist in %s", envName, repo))
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Existing: %s. Pass --create-missing to create it.", strings.Join(vaultEnvs, ", "))))
+			return false, fmt.Errorf("environment %q not found", envName)
+		}
+		confirmed, err := deps.UI.Confirm(fmt.Sprintf("Environment %s does not exist in %s. Create it?", envName, repo), true)
+		if err != nil {
+			return false, err
+		}
+		if !confirmed {
+			deps.UI.Warn("Aborted.")
+			return false, fmt.Errorf("environment %q not found", envName)
+		}
+	}
+
+	err = deps.UI.Spin("Creating environment...", func() error {
+		return client.CreateEnvironment(ctx, repo, envName)
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to create environment %s: %s", envName, err.Error()))
+		return false, err
+	}
+	deps.UI.Success(fmt.Sprintf("Created environment %s", envName))
+	return true, nil
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestEnvironmentCandidates_FromVault(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"production", "preview"}
+
+	envs, err := environmentCandidates(context.Background(), deps, apiMock, "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(envs, ",") != "production,preview" {
+		t.Errorf("unexpected environments: %v", envs)
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestEnvironmentCandidates_FallbackWarns(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvsError = errors.New("service unavailable")
+
+	envs, err := environmentCandidates(context.Background(), deps, apiMock, "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(envs, ",") != "development,staging,production" {
+		t.Errorf("unexpected fallback: %v", envs)
+	}
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "service unavailable") {
+		t.Errorf("expected a warning with the cause, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestEnvironmentCandidates_ConfiguredFallback(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvsError = errors.New("timeout")
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{FallbackEnvironments: []string{"local", "prod"}}
+
+	envs, err := environmentCandidates(context.Background(), deps, apiMock, "owner/repo")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(envs, ",") != "local,prod" {
+		t.Errorf("unexpected fallback: %v", envs)
+	}
+}
+
+func TestEnvironmentCandidates_Strict(t *testing.T) {
+	strictEnvs = true
+	defer func() { strictEnvs = false }()
+
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvsError = errors.New("timeout")
+
+	envs, err := environmentCandidates(context.Background(), deps, apiMock, "owner/repo")
+
+	if err == nil {
+		t.Fatalf("expected error, got environments %v", envs)
+	}
+	if len(uiMock.ErrorCalls) != 1 {
+		t.Errorf("expected an error message, got %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestDefaultEnvironment(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	if got := defaultEnvironment(deps, "run", "development"); got != "development" {
+		t.Errorf("expected the built-in default without config, got %q", got)
+	}
+
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{DefaultEnvironments: map[string]string{"run": "local"}}
+	if got := defaultEnvironment(deps, "run", "development"); got != "local" {
+		t.Errorf("expected the configured default, got %q", got)
+	}
+	if got := defaultEnvironment(deps, "pull", "development"); got != "development" {
+		t.Errorf("expected the built-in default for another command, got %q", got)
+	}
+}
+
+func TestRunRunWithDeps_ConfiguredDefaultEnvironment(t *testing.T) {
+	deps, _, _, _, runner, apiMock := NewTestDepsWithRunner()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{DefaultEnvironments: map[string]string{"run": "staging"}}
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{"staging": {Content: "API_KEY=staging\n"}}
+
+	if err := runRunWithDeps(RunOptions{EnvName: "development", Command: "npm"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if runner.LastSecrets["API_KEY"] != "staging" {
+		t.Errorf("expected the staging secrets, got %v", runner.LastSecrets)
+	}
+}
+
+func TestRunRunWithDeps_ConfiguredDefaultFirstInPrompt(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDepsWithRunner()
+	uiMock.Interactive = true
+	uiMock.SelectResult = "staging"
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{DefaultEnvironments: map[string]string{"run": "staging"}}
+	apiMock.VaultEnvs = []string{"development", "staging", "production"}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=x\n"}
+
+	if err := runRunWithDeps(RunOptions{EnvName: "development", Command: "npm"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.SelectOptions) != 1 || uiMock.SelectOptions[0][0] != "staging" {
+		t.Errorf("expected staging offered first, got %v", uiMock.SelectOptions)
+	}
+}
+
+func TestResolveEnvironment_OffersLastChoiceFirst(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	uiMock.Interactive = true
+	uiMock.SelectResult = "production"
+	apiMock.VaultEnvs = []string{"development", "staging", "production"}
+	memory := deps.LastEnv.(*MockEnvironmentMemory)
+	memory.LastEnvs = map[string]string{"owner/repo": "staging"}
+
+	envName, err := resolveEnvironment(context.Background(), deps, apiMock, "owner/repo", nil, "pull", "development", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if envName != "production" {
+		t.Errorf("expected the selection, got %q", envName)
+	}
+	if len(uiMock.SelectOptions) != 1 || uiMock.SelectOptions[0][0] != "staging" {
+		t.Errorf("expected the last choice offered first, got %v", uiMock.SelectOptions)
+	}
+	if memory.LastEnvs["owner/repo"] != "production" {
+		t.Errorf("expected the choice remembered, got %v", memory.LastEnvs)
+	}
+}
+
+func TestResolveEnvironment_IgnoresLastChoiceWithoutPrompt(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	deps.LastEnv.(*MockEnvironmentMemory).LastEnvs = map[string]string{"owner/repo": "production"}
+
+	envName, err := resolveEnvironment(context.Background(), deps, apiMock, "owner/repo", nil, "run", "development", false)
+	if err != nil || envName != "development" {
+		t.Errorf("expected the default in scripts, got %q, %v", envName, err)
+	}
+
+	uiMock.Interactive = true
+	envName, err = resolveEnvironment(context.Background(), deps, apiMock, "owner/repo", nil, "run", "staging", true)
+	if err != nil || envName != "staging" {
+		t.Errorf("expected --env to win, got %q, %v", envName, err)
+	}
+	if len(uiMock.SelectCalls) != 0 {
+		t.Errorf("expected no prompt, got %v", uiMock.SelectCalls)
+	}
+}
+
+func TestResolveEnvironment_HidesUnreadableEnvironments(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	uiMock.Interactive = true
+	uiMock.SelectResult = "staging"
+	apiMock.VaultEnvs = []string{"development", "staging", "production"}
+	apiMock.EnvAccess = []api.EnvironmentAccess{
+		{Name: "development", Read: true, Write: true},
+		{Name: "staging", Read: true},
+		{Name: "production"},
+	}
+
+	if _, err := resolveEnvironment(context.Background(), deps, apiMock, "owner/repo", nil, "pull", "development", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.SelectOptions) != 1 || strings.Join(uiMock.SelectOptions[0], ",") != "development,staging" {
+		t.Errorf("expected production hidden, got %v", uiMock.SelectOptions)
+	}
+}
+
+func TestAccessibleEnvironments(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	envs := []string{"development", "staging", "production"}
+	ctx := context.Background()
+
+	// Without access checks nothing is hidden
+	got, err := accessibleEnvironments(ctx, deps, apiMock, "owner/repo", envs, true)
+	if err != nil || len(got) != 3 {
+		t.Errorf("expected all environments, got %v, %v", got, err)
+	}
+	apiMock.EnvAccessError = errors.New("boom")
+	if got, _ = accessibleEnvironments(ctx, deps, apiMock, "owner/repo", envs, true); len(got) != 3 {
+		t.Errorf("expected all environments when the check fails, got %v", got)
+	}
+
+	apiMock.EnvAccessError = nil
+	apiMock.EnvAccess = []api.EnvironmentAccess{
+		{Name: "development", Read: true, Write: true},
+		{Name: "staging", Read: true},
+	}
+	got, _ = accessibleEnvironments(ctx, deps, apiMock, "owner/repo", envs, true)
+	if strings.Join(got, ",") != "development,production" {
+		t.Errorf("expected read-only staging hidden and unknown production kept, got %v", got)
+	}
+
+	apiMock.EnvAccess = []api.EnvironmentAccess{{Name: "development"}, {Name: "staging"}, {Name: "production"}}
+	if _, err := accessibleEnvironments(ctx, deps, apiMock, "owner/repo", envs, false); err == nil {
+		t.Error("expected an error without any readable environment")
+	}
+}
+
+func TestRunShowWithDeps_FlagOverridesConfiguredDefault(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{DefaultEnvironments: map[string]string{"show": "staging"}}
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{"production": {Content: "API_KEY=x\n"}}
+
+	if err := runShowWithDeps(ShowOptions{EnvName: "production", EnvFlagSet: true}, deps); err != nil {
+		t.Fatalf("expected the flag to win, got %v", err)
+	}
+}
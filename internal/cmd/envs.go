@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var envsCmd = &cobra.Command{
+	Use:   "envs",
+	Short: "List, create, delete and clone environments",
+	Long: `Manage the environments of the vault.
+
+clone copies every key of an environment into a new one. $ref: values are
+copied as references, so both environments keep following the shared value.
+
+Examples:
+  keyway envs list
+  keyway envs create preview
+  keyway envs clone staging production
+  keyway envs delete preview --yes`,
+}
+
+var envsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the environments of the vault",
+	Args:  cobra.NoArgs,
+	RunE:  runEnvsList,
+}
+
+var envsCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an empty environment",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnvsCreate,
+}
+
+var envsDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an environment and all its secrets",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnvsDelete,
+}
+
+var envsCloneCmd = &cobra.Command{
+	Use:   "clone <source> <target>",
+	Short: "Create an environment with the secrets of another",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runEnvsClone,
+}
+
+func init() {
+	envsListCmd.Flags().Bool("json", false, "Output as JSON")
+	envsDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	envsCmd.AddCommand(envsListCmd)
+	envsCmd.AddCommand(envsCreateCmd)
+	envsCmd.AddCommand(envsDeleteCmd)
+	envsCmd.AddCommand(envsCloneCmd)
+}
+
+// envNamePattern matches the environment names envs create and clone accept
+var envNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// EnvsOptions contains the parsed arguments of the envs commands
+type EnvsOptions struct {
+	Name       string
+	Source     string
+	Yes        bool
+	JSONOutput bool
+}
+
+// runEnvsList is the entry point for envs list (uses default dependencies)
+func runEnvsList(cmd *cobra.Command, args []string) error {
+	var opts EnvsOptions
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runEnvsListWithDeps(opts, os.Stdout, defaultDeps)
+}
+
+// runEnvsListWithDeps is the testable version of runEnvsList
+func runEnvsListWithDeps(opts EnvsOptions, w io.Writer, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("envs list")
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	envs, err := listEnvironments(deps, client, repo)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSONOutput {
+		if envs == nil {
+			envs = []string{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(envs)
+	}
+
+	if len(envs) == 0 {
+		deps.UI.Message("No environments yet.")
+		deps.UI.Message(deps.UI.Dim("Create one with: keyway envs create <name>"))
+		return nil
+	}
+
+	// Annotate environments with the token's access when the API can tell
+	access, _ := client.GetEnvironmentAccess(context.Background(), repo)
+	accessByEnv := make(map[string]api.EnvironmentAccess, len(access))
+	for _, a := range access {
+		accessByEnv[a.Name] = a
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, name := range envs {
+		a, ok := accessByEnv[name]
+		switch {
+		case !ok:
+			fmt.Fprintf(tw, "  %s\n", name)
+		case a.Read && a.Write:
+			fmt.Fprintf(tw, "  %s\t%s\n", name, deps.UI.Dim("read, write"))
+		case a.Read:
+			fmt.Fprintf(tw, "  %s\t%s\n", name, deps.UI.Dim("read only"))
+		default:
+			fmt.Fprintf(tw, "  %s\t%s\n", name, deps.UI.Dim("no access"))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	deps.UI.Outro(fmt.Sprintf("%d environments", len(envs)))
+	return nil
+}
+
+// runEnvsCreate is the entry point for envs create (uses default dependencies)
+func runEnvsCreate(cmd *cobra.Command, args []string) error {
+	return runEnvsCreateWithDeps(EnvsOptions{Name: args[0]}, defaultDeps)
+}
+
+// runEnvsCreateWithDeps is the testable version of runEnvsCreate
+func runEnvsCreateWithDeps(opts EnvsOptions, deps *Dependencies) error {
+	deps.UI.Intro("envs create")
+
+	if err := validateEnvName(deps, opts.Name); err != nil {
+		return err
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	envs, err := listEnvironments(deps, client, repo)
+	if err != nil {
+		return err
+	}
+	if hasEnvironment(envs, opts.Name) {
+		deps.UI.Error(fmt.Sprintf("Environment %s already exists in %s", opts.Name, repo))
+		return fmt.Errorf("environment %q already exists", opts.Name)
+	}
+
+	err = deps.UI.Spin("Creating environment...", func() error {
+		return client.CreateEnvironment(context.Background(), repo, opts.Name)
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to create environment %s: %s", opts.Name, err.Error()))
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Created environment %s", opts.Name))
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Add secrets with: keyway set KEY=value --env %s", opts.Name)))
+	return nil
+}
+
+// runEnvsDelete is the entry point for envs delete (uses default dependencies)
+func runEnvsDelete(cmd *cobra.Command, args []string) error {
+	opts := EnvsOptions{Name: args[0]}
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+
+	return runEnvsDeleteWithDeps(opts, defaultDeps)
+}
+
+// runEnvsDeleteWithDeps is the testable version of runEnvsDelete
+func runEnvsDeleteWithDeps(opts EnvsOptions, deps *Dependencies) error {
+	deps.UI.Intro("envs delete")
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	envs, err := listEnvironments(deps, client, repo)
+	if err != nil {
+		return err
+	}
+	if !hasEnvironment(envs, opts.Name) {
+		deps.UI.Error(fmt.Sprintf("Environment %s does not exist in %s", opts.Name, repo))
+		return fmt.Errorf("environment %q not found", opts.Name)
+	}
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Delete %s and all its secrets? This cannot be undone.", opts.Name), false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	err = deps.UI.Spin("Deleting environment...", func() error {
+		return client.DeleteEnvironment(context.Background(), repo, opts.Name)
+	})
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Deleted environment %s", opts.Name))
+	return nil
+}
+
+// runEnvsClone is the entry point for envs clone (uses default dependencies)
+func runEnvsClone(cmd *cobra.Command, args []string) error {
+	return runEnvsCloneWithDeps(EnvsOptions{Source: args[0], Name: args[1]}, defaultDeps)
+}
+
+// runEnvsCloneWithDeps is the testable version of runEnvsClone. The target
+// must not exist yet, so cloning never overwrites secrets.
+func runEnvsCloneWithDeps(opts EnvsOptions, deps *Dependencies) error {
+	deps.UI.Intro("envs clone")
+
+	if err := validateEnvName(deps, opts.Name); err != nil {
+		return err
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	envs, err := listEnvironments(deps, client, repo)
+	if err != nil {
+		return err
+	}
+	if !hasEnvironment(envs, opts.Source) {
+		deps.UI.Error(fmt.Sprintf("Environment %s does not exist in %s", opts.Source, repo))
+		return fmt.Errorf("environment %q not found", opts.Source)
+	}
+	if hasEnvironment(envs, opts.Name) {
+		deps.UI.Error(fmt.Sprintf("Environment %s already exists in %s", opts.Name, repo))
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Compare them with: keyway diff %s %s", opts.Source, opts.Name)))
+		return fmt.Errorf("environment %q already exists", opts.Name)
+	}
+
+	secrets, client, err := fetchStoredSecrets(deps, client, repo, opts.Source)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	err = deps.UI.Spin("Creating environment...", func() error {
+		return client.CreateEnvironment(ctx, repo, opts.Name)
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to create environment %s: %s", opts.Name, err.Error()))
+		return err
+	}
+
+	if len(secrets) > 0 {
+		err = deps.UI.Spin("Pushing to vault...", func() error {
+			_, pushErr := client.PushSecrets(ctx, repo, opts.Name, secrets)
+			return pushErr
+		})
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Created %s but copying the secrets failed: %s", opts.Name, err.Error()))
+			return err
+		}
+	}
+
+	deps.UI.Success(fmt.Sprintf("Cloned %s into %s (%d keys)", opts.Source, opts.Name, len(secrets)))
+	return nil
+}
+
+// listEnvironments fetches the environments of the vault
+func listEnvironments(deps *Dependencies, client api.APIClient, repo string) ([]string, error) {
+	var envs []string
+	err := deps.UI.Spin("Fetching environments...", func() error {
+		var fetchErr error
+		envs, fetchErr = client.GetVaultEnvironments(context.Background(), repo)
+		return fetchErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, err
+	}
+	return envs, nil
+}
+
+// validateEnvName rejects names the vault would not be able to use in paths
+func validateEnvName(deps *Dependencies, name string) error {
+	if !envNamePattern.MatchString(name) {
+		deps.UI.Error("Environment names must start with a letter or digit and contain only letters, digits, '.', '_' and '-'")
+		return fmt.Errorf("invalid environment name %q", name)
+	}
+	return nil
+}
+
+// hasEnvironment reports whether name is one of envs
+func hasEnvironment(envs []string, name string) bool {
+	for _, e := range envs {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunEnvsListWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "staging", "production"}
+
+	var out bytes.Buffer
+	if err := runEnvsListWithDeps(EnvsOptions{JSONOutput: true}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[\n  \"development\",\n  \"staging\",\n  \"production\"\n]" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunEnvsListWithDeps_Access(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "production"}
+	apiMock.EnvAccess = []api.EnvironmentAccess{
+		{Name: "development", Read: true, Write: true},
+		{Name: "production"},
+	}
+
+	var out bytes.Buffer
+	if err := runEnvsListWithDeps(EnvsOptions{}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "read, write") || !strings.Contains(out.String(), "no access") {
+		t.Errorf("expected environments annotated with access, got %q", out.String())
+	}
+}
+
+func TestRunEnvsCreateWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development"}
+
+	if err := runEnvsCreateWithDeps(EnvsOptions{Name: "preview"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apiMock.CreatedEnvs) != 1 || apiMock.CreatedEnvs[0] != "preview" {
+		t.Errorf("expected preview created, got %v", apiMock.CreatedEnvs)
+	}
+
+	for _, name := range []string{"development", "bad name", "-x"} {
+		apiMock.CreatedEnvs = nil
+		if err := runEnvsCreateWithDeps(EnvsOptions{Name: name}, deps); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+		if apiMock.CreatedEnvs != nil {
+			t.Errorf("expected nothing created for %q, got %v", name, apiMock.CreatedEnvs)
+		}
+	}
+}
+
+func TestRunEnvsDeleteWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "preview"}
+
+	// Non-interactive without --yes refuses
+	if err := runEnvsDeleteWithDeps(EnvsOptions{Name: "preview"}, deps); err == nil {
+		t.Fatal("expected an error without --yes")
+	}
+
+	// Declining the prompt deletes nothing
+	uiMock.Interactive = true
+	if err := runEnvsDeleteWithDeps(EnvsOptions{Name: "preview"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.DeletedEnvs != nil {
+		t.Errorf("expected nothing deleted, got %v", apiMock.DeletedEnvs)
+	}
+
+	uiMock.ConfirmResult = true
+	if err := runEnvsDeleteWithDeps(EnvsOptions{Name: "preview"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apiMock.DeletedEnvs) != 1 || apiMock.DeletedEnvs[0] != "preview" {
+		t.Errorf("expected preview deleted, got %v", apiMock.DeletedEnvs)
+	}
+
+	if err := runEnvsDeleteWithDeps(EnvsOptions{Name: "missing", Yes: true}, deps); err == nil {
+		t.Error("expected an error for a missing environment")
+	}
+}
+
+func TestRunEnvsCloneWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"staging", "production"}
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{
+		"staging": {Content: "API_KEY=abc\nDB=$ref:shared/DB"},
+	}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	if err := runEnvsCloneWithDeps(EnvsOptions{Source: "staging", Name: "preview"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apiMock.CreatedEnvs) != 1 || apiMock.CreatedEnvs[0] != "preview" {
+		t.Errorf("expected preview created, got %v", apiMock.CreatedEnvs)
+	}
+	pushed := apiMock.PushedSecrets
+	if pushed["API_KEY"] != "abc" || pushed["DB"] != "$ref:shared/DB" {
+		t.Errorf("expected the keys copied with references kept, got %v", pushed)
+	}
+}
+
+func TestRunEnvsCloneWithDeps_ExistingTarget(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"staging", "production"}
+
+	if err := runEnvsCloneWithDeps(EnvsOptions{Source: "staging", Name: "production"}, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if apiMock.CreatedEnvs != nil || apiMock.PushedSecrets != nil {
+		t.Errorf("expected nothing changed, got created %v pushed %v", apiMock.CreatedEnvs, apiMock.PushedSecrets)
+	}
+}
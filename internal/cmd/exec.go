@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- command [args...]",
+	Short: "Like run, but replace keyway with the command",
+	Long: `Inject secrets like keyway run, then replace the keyway process with the
+command instead of waiting for it. The command keeps keyway's process ID, so
+signals sent by a CI runner, a process supervisor or docker stop reach it
+directly, and its exit status is the job's exit status.
+
+Takes every keyway run flag except --log-output. On Windows, where a process
+cannot be replaced, keyway waits for the command, forwards Ctrl+C and exits
+with its status.
+
+keyway run also forwards SIGINT, SIGTERM and SIGHUP and exits with the
+command's status (128+N when a signal N killed it); use exec when nothing
+should stand between the command and its parent.
+
+Examples:
+  keyway exec --env production -- ./server
+  keyway exec --env ci -- npm test`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExecCmd,
+}
+
+// runExecCmd is the entry point for the exec command (uses default dependencies)
+func runExecCmd(cmd *cobra.Command, args []string) error {
+	opts, err := runOptionsFromFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	opts.Exec = true
+
+	return runRunWithDeps(opts, defaultDeps)
+}
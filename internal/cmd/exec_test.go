@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunRunWithDeps_Exec(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "./server", Args: []string{"--port", "80"}, Exec: true}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cmdRunner.Execed {
+		t.Error("expected the command to replace keyway")
+	}
+	if cmdRunner.LastCommand != "./server" || len(cmdRunner.LastArgs) != 2 || cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Errorf("unexpected exec: %s %v %v", cmdRunner.LastCommand, cmdRunner.LastArgs, cmdRunner.LastSecrets)
+	}
+}
+
+func TestRunRunWithDeps_ExecRejectsLogOutput(t *testing.T) {
+	deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "./server", Exec: true, LogOutput: "run.log"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("expected nothing to run")
+	}
+}
+
+func TestExecCmd_SharesRunFlags(t *testing.T) {
+	for _, name := range []string{"env", "only", "profile", "overlay", "sandbox", "inherit-env"} {
+		if execCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected exec to take --%s", name)
+		}
+	}
+	if execCmd.Flags().Lookup("log-output") != nil {
+		t.Error("expected exec not to take --log-output")
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var execInitCmd = &cobra.Command{
+	Use:   "exec-init [flags] -- command [args...]",
+	Short: "Container entrypoint: pull secrets, then exec the command",
+	Long: `Pull an environment's secrets with a service token and replace this
+process with the command, secrets in its environment. Meant as a container
+ENTRYPOINT: the command becomes the container's main process and receives
+its signals directly.
+
+The token is read from --token-file, then KEYWAY_TOKEN_FILE, then
+KEYWAY_TOKEN, then ` + defaultTokenFile + ` (a Docker or Kubernetes
+secret mount). Use an API key with the read:secrets scope. KEYWAY_TOKEN is
+not passed to the command.
+
+Without a .git directory in the image, pass the repository with --repo or
+GITHUB_REPOSITORY.
+
+Examples:
+  ENTRYPOINT ["keyway", "exec-init", "--env", "production", "--"]
+  CMD ["node", "server.js"]
+
+  keyway exec-init --repo acme/api --token-file /run/secrets/keyway -- ./server`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExecInit,
+}
+
+// defaultTokenFile is where Docker and Kubernetes mount a secret named keyway_token
+const defaultTokenFile = "/run/secrets/keyway_token"
+
+func init() {
+	execInitCmd.Flags().StringP("env", "e", "", "Environment name (default $KEYWAY_ENV, else production)")
+	execInitCmd.Flags().String("token-file", "", "File containing the service token")
+}
+
+// ExecInitOptions contains the parsed flags for the exec-init command
+type ExecInitOptions struct {
+	EnvName   string
+	TokenFile string
+	Command   string
+	Args      []string
+}
+
+// runExecInit is the entry point for the exec-init command (uses default dependencies)
+func runExecInit(cmd *cobra.Command, args []string) error {
+	opts := ExecInitOptions{Command: args[0], Args: args[1:]}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.TokenFile, _ = cmd.Flags().GetString("token-file")
+
+	return runExecInitWithDeps(opts, defaultDeps)
+}
+
+// runExecInitWithDeps is the testable version of runExecInit
+func runExecInitWithDeps(opts ExecInitOptions, deps *Dependencies) error {
+	envName := opts.EnvName
+	if envName == "" {
+		if envName, _ = lookupHostEnv("KEYWAY_ENV"); envName == "" {
+			envName = defaultEnvironment(deps, "exec-init", "production")
+		}
+	}
+
+	token, err := execInitToken(opts, deps)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("No repository detected: pass --repo owner/repo or set GITHUB_REPOSITORY")
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	secrets, client, err := fetchSecrets(deps, client, repo, envName)
+	if err != nil {
+		return err
+	}
+	dropExpiredSecrets(context.Background(), deps, client, repo, envName, secrets)
+	if err := checkCaseCollisions(deps, secrets, runtime.GOOS == "windows"); err != nil {
+		return err
+	}
+
+	environ, err := inheritedEnviron(inheritDefault, osEnviron())
+	if err != nil {
+		return err
+	}
+	if err := deps.CmdRunner.ExecCommand(opts.Command, opts.Args, environ, secrets); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// execInitToken reads the service token: an explicit token file, KEYWAY_TOKEN,
+// then the default secret mount
+func execInitToken(opts ExecInitOptions, deps *Dependencies) (string, error) {
+	path := opts.TokenFile
+	if path == "" {
+		path, _ = lookupHostEnv("KEYWAY_TOKEN_FILE")
+	}
+	if path == "" {
+		if token, _ := lookupHostEnv("KEYWAY_TOKEN"); token != "" {
+			return token, nil
+		}
+		if data, err := deps.FS.ReadFile(defaultTokenFile); err == nil {
+			return tokenFromFile(defaultTokenFile, data)
+		}
+		return "", fmt.Errorf("no token: mount one at %s, or set --token-file, KEYWAY_TOKEN_FILE or KEYWAY_TOKEN", defaultTokenFile)
+	}
+
+	data, err := deps.FS.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read token file %s: %w", path, err)
+	}
+	return tokenFromFile(path, data)
+}
+
+func tokenFromFile(path string, data []byte) (string, error) {
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
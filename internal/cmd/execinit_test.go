@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// withHostEnv makes lookupHostEnv read from vars for the duration of the test
+func withHostEnv(t *testing.T, vars map[string]string) {
+	t.Helper()
+	original := lookupHostEnv
+	t.Cleanup(func() { lookupHostEnv = original })
+	lookupHostEnv = func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestRunExecInitWithDeps_TokenFile(t *testing.T) {
+	withHostEnv(t, map[string]string{"KEYWAY_ENV": "staging"})
+	deps, _, _, _, runner, apiMock := NewTestDepsWithRunner()
+	deps.FS = &MockFileSystem{Files: map[string][]byte{"/run/secrets/keyway": []byte("kw_token\n")}}
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{"staging": {Content: "API_KEY=secret\n"}}
+
+	original := osEnviron
+	defer func() { osEnviron = original }()
+	osEnviron = func() []string { return []string{"PATH=/usr/bin", "KEYWAY_TOKEN=leak"} }
+
+	opts := ExecInitOptions{TokenFile: "/run/secrets/keyway", Command: "node", Args: []string{"server.js"}}
+	if err := runExecInitWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if token := deps.APIFactory.(*MockAPIFactory).LastToken; token != "kw_token" {
+		t.Errorf("expected the token from the file, got %q", token)
+	}
+	if runner.LastCommand != "node" || len(runner.LastArgs) != 1 || runner.LastSecrets["API_KEY"] != "secret" {
+		t.Errorf("unexpected exec: %s %v %v", runner.LastCommand, runner.LastArgs, runner.LastSecrets)
+	}
+	if strings.Join(runner.LastEnviron, " ") != "PATH=/usr/bin" {
+		t.Errorf("KEYWAY_TOKEN should not reach the command, got %v", runner.LastEnviron)
+	}
+}
+
+func TestRunExecInitWithDeps_NoToken(t *testing.T) {
+	withHostEnv(t, nil)
+	deps, _, _, uiMock, runner, _ := NewTestDepsWithRunner()
+
+	err := runExecInitWithDeps(ExecInitOptions{Command: "node"}, deps)
+	if err == nil || !strings.Contains(err.Error(), defaultTokenFile) {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+	if len(uiMock.ErrorCalls) != 1 {
+		t.Errorf("expected one error, got %v", uiMock.ErrorCalls)
+	}
+	if runner.LastCommand != "" {
+		t.Error("command should not run without a token")
+	}
+}
+
+func TestExecInitToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		vars  map[string]string
+		files map[string][]byte
+		flag  string
+		want  string
+	}{
+		{"flag wins", map[string]string{"KEYWAY_TOKEN": "env"}, map[string][]byte{"/t": []byte("file")}, "/t", "file"},
+		{"token file variable", map[string]string{"KEYWAY_TOKEN_FILE": "/t", "KEYWAY_TOKEN": "env"}, map[string][]byte{"/t": []byte(" file ")}, "", "file"},
+		{"token variable", map[string]string{"KEYWAY_TOKEN": "env"}, map[string][]byte{defaultTokenFile: []byte("mounted")}, "", "env"},
+		{"default mount", nil, map[string][]byte{defaultTokenFile: []byte("mounted")}, "", "mounted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withHostEnv(t, tt.vars)
+			deps, _, _, _, _, _ := NewTestDeps()
+			deps.FS = &MockFileSystem{Files: tt.files}
+
+			got, err := execInitToken(ExecInitOptions{TokenFile: tt.flag}, deps)
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecInitToken_EmptyFile(t *testing.T) {
+	withHostEnv(t, nil)
+	deps, _, _, _, _, _ := NewTestDeps()
+	deps.FS = &MockFileSystem{Files: map[string][]byte{"/t": []byte("\n")}}
+
+	if _, err := execInitToken(ExecInitOptions{TokenFile: "/t"}, deps); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("expected an empty file error, got %v", err)
+	}
+}
+
+func TestRunExecInitWithDeps_ExecError(t *testing.T) {
+	withHostEnv(t, map[string]string{"KEYWAY_TOKEN": "kw_token"})
+	deps, _, _, _, runner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret\n"}
+	runner.RunError = errors.New("failed to start command: not found")
+
+	if err := runExecInitWithDeps(ExecInitOptions{Command: "missing"}, deps); err == nil {
+		t.Fatal("expected the exec error")
+	}
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var secretsExpiringCmd = &cobra.Command{
+	Use:   "expiring",
+	Short: "List secrets that expire soon",
+	Long: `List keys set with a TTL (keyway set KEY --ttl 24h) that expire within
+--within, and those already expired, which keyway run no longer injects.
+
+All environments are listed unless --env is given.
+
+Examples:
+  keyway secrets expiring
+  keyway secrets expiring --env production --within 30d
+  keyway secrets expiring --json`,
+	Args: cobra.NoArgs,
+	RunE: runSecretsExpiring,
+}
+
+func init() {
+	secretsExpiringCmd.Flags().StringP("env", "e", "", "Only list this environment")
+	secretsExpiringCmd.Flags().String("within", "7d", "How far ahead to look (e.g. 12h, 7d)")
+	secretsExpiringCmd.Flags().Bool("json", false, "Output as JSON")
+
+	secretsCmd.AddCommand(secretsExpiringCmd)
+}
+
+// ExpiringOptions contains the parsed flags for the secrets expiring command
+type ExpiringOptions struct {
+	EnvName    string
+	Within     string
+	JSONOutput bool
+}
+
+// expiringKey is a key with a TTL
+type expiringKey struct {
+	Environment string    `json:"environment"`
+	Key         string    `json:"key"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Expired     bool      `json:"expired"`
+}
+
+// runSecretsExpiring is the entry point for the secrets expiring command (uses default dependencies)
+func runSecretsExpiring(cmd *cobra.Command, args []string) error {
+	opts := ExpiringOptions{}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Within, _ = cmd.Flags().GetString("within")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runSecretsExpiringWithDeps(opts, defaultDeps)
+}
+
+// runSecretsExpiringWithDeps is the testable version of runSecretsExpiring
+func runSecretsExpiringWithDeps(opts ExpiringOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("secrets expiring")
+	}
+
+	within, err := activity.ParseSince(opts.Within)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	envs := []string{opts.EnvName}
+	if opts.EnvName == "" {
+		if envs, err = client.GetVaultEnvironments(ctx, repo); err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to list environments: %s", err.Error()))
+			return err
+		}
+	}
+
+	now := time.Now()
+	var keys []expiringKey
+	for _, envName := range envs {
+		metadata, err := client.GetSecretsMetadata(ctx, repo, envName)
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to fetch key details of %s: %s", envName, err.Error()))
+			return err
+		}
+		keys = append(keys, expiringKeys(envName, metadata, now, within)...)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].ExpiresAt.Before(keys[j].ExpiresAt) })
+
+	if opts.JSONOutput {
+		if keys == nil {
+			keys = []expiringKey{}
+		}
+		output, err := json.MarshalIndent(keys, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(keys) == 0 {
+		deps.UI.Message(fmt.Sprintf("No secrets expire within %s", opts.Within))
+		return nil
+	}
+
+	fmt.Println()
+	printExpiringTable(os.Stdout, keys, now)
+	deps.UI.Outro(fmt.Sprintf("%d keys", len(keys)))
+	return nil
+}
+
+// expiringKeys returns the keys of an environment that expire before now+within,
+// including those already expired
+func expiringKeys(envName string, metadata []api.KeyMetadata, now time.Time, within time.Duration) []expiringKey {
+	var keys []expiringKey
+	for _, m := range metadata {
+		if m.ExpiresAt == nil || m.ExpiresAt.IsZero() || m.ExpiresAt.After(now.Add(within)) {
+			continue
+		}
+		keys = append(keys, expiringKey{
+			Environment: envName,
+			Key:         m.Key,
+			ExpiresAt:   *m.ExpiresAt,
+			Expired:     !m.ExpiresAt.After(now),
+		})
+	}
+	return keys
+}
+
+func printExpiringTable(w io.Writer, keys []expiringKey, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  ENVIRONMENT\tKEY\tEXPIRES")
+	for _, k := range keys {
+		expiresAt := k.ExpiresAt
+		fmt.Fprintf(tw, "  %s\t%s\t%s (%s)\n", k.Environment, k.Key, formatTimestamp(&expiresAt), formatExpiry(k.ExpiresAt, now))
+	}
+	_ = tw.Flush()
+}
+
+// dropExpiredSecrets removes the keys whose TTL has passed from secrets and
+// warns about them. It returns the removed keys. Expiry is best effort: when
+// the API doesn't expose key metadata, nothing is removed.
+func dropExpiredSecrets(ctx context.Context, deps *Dependencies, client api.APIClient, repo, envName string, secrets map[string]string) []string {
+	metadata, err := client.GetSecretsMetadata(ctx, repo, envName)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	var dropped, details []string
+	for _, k := range expiringKeys(envName, metadata, now, 0) {
+		if _, ok := secrets[k.Key]; !ok {
+			continue
+		}
+		delete(secrets, k.Key)
+		dropped = append(dropped, k.Key)
+		details = append(details, fmt.Sprintf("%s (%s)", k.Key, formatExpiry(k.ExpiresAt, now)))
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	deps.UI.Warn(fmt.Sprintf("Skipped %d expired secret(s): %s", len(dropped), strings.Join(details, ", ")))
+	deps.UI.Message(deps.UI.Dim("Set a new value with keyway set KEY --ttl <duration>"))
+	return dropped
+}
+
+// formatExpiry describes an expiry relative to now: "in 5h", "expired 2d ago"
+func formatExpiry(expiresAt, now time.Time) string {
+	if expiresAt.After(now) {
+		return "in " + roughDuration(expiresAt.Sub(now))
+	}
+	return fmt.Sprintf("expired %s ago", roughDuration(now.Sub(expiresAt)))
+}
+
+// roughDuration rounds d to minutes, hours or days
+func roughDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
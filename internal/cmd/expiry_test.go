@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestExpiringKeys(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	metadata := []api.KeyMetadata{
+		{Key: "PERMANENT"},
+		{Key: "EXPIRED", ExpiresAt: timePtr(now.Add(-2 * time.Hour))},
+		{Key: "SOON", ExpiresAt: timePtr(now.Add(3 * time.Hour))},
+		{Key: "LATER", ExpiresAt: timePtr(now.Add(30 * 24 * time.Hour))},
+	}
+
+	keys := expiringKeys("production", metadata, now, 7*24*time.Hour)
+
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %v", keys)
+	}
+	if keys[0].Key != "EXPIRED" || !keys[0].Expired || keys[0].Environment != "production" {
+		t.Errorf("unexpected first key: %+v", keys[0])
+	}
+	if keys[1].Key != "SOON" || keys[1].Expired {
+		t.Errorf("unexpected second key: %+v", keys[1])
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expiresAt time.Time
+		want      string
+	}{
+		{now.Add(30 * time.Second), "in <1m"},
+		{now.Add(45 * time.Minute), "in 45m"},
+		{now.Add(5 * time.Hour), "in 5h"},
+		{now.Add(72 * time.Hour), "in 3d"},
+		{now.Add(-2 * time.Hour), "expired 2h ago"},
+	}
+	for _, tt := range tests {
+		if got := formatExpiry(tt.expiresAt, now); got != tt.want {
+			t.Errorf("formatExpiry(%s) = %q, want %q", tt.expiresAt.Sub(now), got, tt.want)
+		}
+	}
+}
+
+func TestRunRunWithDeps_SkipsExpiredSecrets(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nTEMP_TOKEN=tmp_abc"}
+	apiMock.KeyMetadata = []api.KeyMetadata{
+		{Key: "API_KEY"},
+		{Key: "TEMP_TOKEN", ExpiresAt: timePtr(time.Now().Add(-time.Hour))},
+	}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, ok := cmdRunner.LastSecrets["TEMP_TOKEN"]; ok {
+		t.Error("expired secret should not be injected")
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Errorf("expected API_KEY to be injected, got %v", cmdRunner.LastSecrets)
+	}
+	if len(uiMock.WarnCalls) == 0 || uiMock.WarnCalls[0] != "Skipped 1 expired secret(s): TEMP_TOKEN (expired 1h ago)" {
+		t.Errorf("expected an expiry warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunSetWithDeps_TTL(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "TEMP_TOKEN", Value: "tmp_abc", EnvName: "development", EnvFlagSet: true, TTL: "1d"}
+	before := time.Now()
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expiresAt := apiMock.ExpirySet["TEMP_TOKEN"]
+	if expiresAt == nil {
+		t.Fatal("expected the expiry to be set")
+	}
+	if d := expiresAt.Sub(before); d < 24*time.Hour || d > 24*time.Hour+time.Minute {
+		t.Errorf("expected expiry in 24h, got %s", d)
+	}
+}
+
+func TestRunSetWithDeps_InvalidTTL(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	opts := SetOptions{Key: "TEMP_TOKEN", Value: "tmp_abc", EnvName: "development", EnvFlagSet: true, TTL: "soon"}
+	if err := runSetWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for an invalid TTL")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("nothing should be pushed")
+	}
+}
+
+func TestRunSecretsExpiringWithDeps_AllEnvironments(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "production"}
+	apiMock.KeyMetadataByEnv = map[string][]api.KeyMetadata{
+		"development": {{Key: "DEV_TOKEN", ExpiresAt: timePtr(time.Now().Add(time.Hour))}},
+		"production":  {{Key: "PROD_TOKEN", ExpiresAt: timePtr(time.Now().Add(-time.Hour))}, {Key: "API_KEY"}},
+	}
+
+	if err := runSecretsExpiringWithDeps(ExpiringOptions{Within: "7d"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.OutroCalls) != 1 || uiMock.OutroCalls[0] != "2 keys" {
+		t.Errorf("expected 2 expiring keys, got %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunSecretsExpiringWithDeps_None(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.KeyMetadata = []api.KeyMetadata{{Key: "API_KEY"}}
+
+	if err := runSecretsExpiringWithDeps(ExpiringOptions{EnvName: "production", Within: "7d"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.MessageCalls) == 0 || uiMock.MessageCalls[len(uiMock.MessageCalls)-1] != "No secrets expire within 7d" {
+		t.Errorf("unexpected output: %v", uiMock.MessageCalls)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// feedbackIssueURL is where new CLI issues are filed
+const feedbackIssueURL = "https://github.com/keywaysh/cli/issues/new"
+
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "Report a bug or suggest an improvement",
+	Long: `Open a GitHub issue prefilled with what helps us reproduce a problem: the
+CLI version, OS, install method, doctor checks and the last command recorded
+by the activity log (when KEYWAY_ACTIVITY_LOG=1).
+
+Nothing is sent until you submit the issue, and the report never contains
+secret values, key names, your GitHub login, repository names or command
+arguments. Use --print to review or paste it yourself.
+
+Examples:
+  keyway feedback
+  keyway feedback --title "pull hangs behind a proxy"
+  keyway feedback --print`,
+	Args: cobra.NoArgs,
+	RunE: runFeedback,
+}
+
+func init() {
+	feedbackCmd.Flags().String("title", "", "Issue title")
+	feedbackCmd.Flags().Bool("print", false, "Print the report instead of opening the browser")
+}
+
+// FeedbackOptions contains the parsed flags for the feedback command
+type FeedbackOptions struct {
+	Title   string
+	Print   bool
+	Version string
+}
+
+// runFeedback is the entry point for the feedback command (uses default dependencies)
+func runFeedback(cmd *cobra.Command, args []string) error {
+	opts := FeedbackOptions{Version: rootCmd.Version}
+	opts.Title, _ = cmd.Flags().GetString("title")
+	opts.Print, _ = cmd.Flags().GetBool("print")
+
+	return runFeedbackWithDeps(opts, defaultDeps)
+}
+
+// runFeedbackWithDeps is the testable version of runFeedback
+func runFeedbackWithDeps(opts FeedbackOptions, deps *Dependencies) error {
+	if opts.Print {
+		fmt.Println(feedbackReport(deps, opts.Version))
+		return nil
+	}
+
+	deps.UI.Intro("feedback")
+
+	var report string
+	_ = deps.UI.Spin("Running checks...", func() error {
+		report = feedbackReport(deps, opts.Version)
+		return nil
+	})
+
+	issueURL := feedbackURL(opts.Title, report)
+	if err := deps.Browser.OpenURL(issueURL); err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not open the browser: %s", err.Error()))
+		deps.UI.Message("Open this URL to file the issue:")
+		deps.UI.Message(issueURL)
+		return nil
+	}
+
+	deps.UI.Success("Opened a prefilled issue in your browser")
+	deps.UI.Message(deps.UI.Dim("Describe what happened, review the details, then submit."))
+	return nil
+}
+
+// feedbackURL returns the new issue URL with the title and body prefilled
+func feedbackURL(title, body string) string {
+	query := url.Values{}
+	if title != "" {
+		query.Set("title", title)
+	}
+	query.Set("body", body)
+	return feedbackIssueURL + "?" + query.Encode()
+}
+
+// feedbackReport builds the issue body: a template for the description
+// followed by sanitized diagnostics
+func feedbackReport(deps *Dependencies, currentVersion string) string {
+	var b strings.Builder
+	b.WriteString("## What happened\n\n<!-- What did you run, what did you expect, what happened instead? -->\n\n")
+
+	b.WriteString("## Environment\n\n")
+	fmt.Fprintf(&b, "- Version: %s\n", currentVersion)
+	fmt.Fprintf(&b, "- OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "- Install method: %s\n", version.DetectInstallMethod())
+	if config.GetAPIURL() != config.DefaultAPIURL {
+		b.WriteString("- API: custom KEYWAY_API_URL\n")
+	}
+
+	b.WriteString("\n## Doctor\n\n")
+	for _, c := range doctorChecks(deps, currentVersion) {
+		c = sanitizeCheck(c)
+		fmt.Fprintf(&b, "- %s %s: %s\n", c.Status, c.Name, c.Detail)
+	}
+
+	if entry, ok := lastActivity(deps); ok {
+		b.WriteString("\n## Last command\n\n")
+		fmt.Fprintf(&b, "- %s, %s environment, %d keys, %s\n", redactedActivityCommand(entry), entry.Environment, len(entry.Keys), entry.Time.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// sanitizeCheck removes the details of a doctor check that identify the user
+// or the project
+func sanitizeCheck(c checkResult) checkResult {
+	if c.Status != "pass" {
+		return c
+	}
+	switch c.ID {
+	case "auth":
+		c.Detail = "Logged in"
+	case "github":
+		c.Detail = "Repository detected"
+	case "network":
+		c.Detail = "Connected"
+	}
+	return c
+}
+
+// lastActivity returns the most recent activity log entry of the past week
+func lastActivity(deps *Dependencies) (activity.Entry, bool) {
+	if !deps.Activity.Enabled() {
+		return activity.Entry{}, false
+	}
+	entries, err := deps.Activity.Since(time.Now().Add(-7 * 24 * time.Hour))
+	if err != nil || len(entries) == 0 {
+		return activity.Entry{}, false
+	}
+	return entries[len(entries)-1], true
+}
+
+// redactedActivityCommand describes an entry without arguments or file names,
+// which may contain secrets or project details
+func redactedActivityCommand(entry activity.Entry) string {
+	if entry.Action == activity.ActionRun && entry.Command != "" {
+		return fmt.Sprintf("keyway run -- %s ...", strings.Fields(entry.Command)[0])
+	}
+	return "keyway " + entry.Action
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/activity"
+)
+
+func TestRunFeedbackWithDeps_OpensPrefilledIssue(t *testing.T) {
+	deps, _, uiMock, _, authStore, _, apiMock := NewTestDepsForDoctor()
+	authStore.StoredAuth = &StoredAuthInfo{KeywayToken: "kw_token", GitHubLogin: "octocat"}
+	apiMock.ValidateTokenResponse.Username = "octocat"
+	deps.Activity = &MockActivityLog{IsEnabled: true, Entries: []activity.Entry{{
+		Time:        time.Now().Add(-time.Hour),
+		Action:      activity.ActionRun,
+		Repository:  "owner/repo",
+		Environment: "production",
+		Command:     "psql postgres://user:hunter2@db",
+		Keys:        []string{"DATABASE_URL", "API_KEY"},
+	}}}
+
+	if err := runFeedbackWithDeps(FeedbackOptions{Title: "pull hangs", Version: "dev"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	opened := deps.Browser.(*MockBrowserOpener).LastURL
+	if !strings.HasPrefix(opened, feedbackIssueURL+"?") {
+		t.Fatalf("expected the new issue URL, got %q", opened)
+	}
+	parsed, err := url.Parse(opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("title") != "pull hangs" {
+		t.Errorf("expected the title, got %q", query.Get("title"))
+	}
+	body := query.Get("body")
+	for _, want := range []string{"## Environment", "- Version: dev", "## Doctor", "keyway run -- psql ..., production environment, 2 keys"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the report:\n%s", want, body)
+		}
+	}
+	for _, leak := range []string{"hunter2", "octocat", "owner/repo", "DATABASE_URL"} {
+		if strings.Contains(body, leak) {
+			t.Errorf("report leaks %q:\n%s", leak, body)
+		}
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected a success message")
+	}
+}
+
+func TestRunFeedbackWithDeps_BrowserFails(t *testing.T) {
+	deps, _, uiMock, _, _, _, _ := NewTestDepsForDoctor()
+	deps.Browser.(*MockBrowserOpener).OpenError = errors.New("no display")
+
+	if err := runFeedbackWithDeps(FeedbackOptions{Version: "dev"}, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 {
+		t.Errorf("expected a warning, got %v", uiMock.WarnCalls)
+	}
+	if last := uiMock.MessageCalls[len(uiMock.MessageCalls)-1]; !strings.HasPrefix(last, feedbackIssueURL) {
+		t.Errorf("expected the URL to be printed, got %q", last)
+	}
+}
+
+func TestSanitizeCheck(t *testing.T) {
+	if got := sanitizeCheck(checkResult{ID: "github", Status: "pass", Detail: "owner/repo"}); got.Detail != "Repository detected" {
+		t.Errorf("unexpected detail: %q", got.Detail)
+	}
+	if got := sanitizeCheck(checkResult{ID: "envfile", Status: "warn", Detail: "No .env file found"}); got.Detail != "No .env file found" {
+		t.Errorf("warnings should be kept, got %q", got.Detail)
+	}
+}
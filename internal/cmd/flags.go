@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var flagsCmd = &cobra.Command{
+	Use:   "flags",
+	Short: "Manage feature flags stored next to your secrets",
+	Long: `Manage feature flags as keys of an environment. A flag is a key starting with
+FLAG_ (flag_prefix in keyway.toml), so keyway run injects it like any other key:
+flags enable NEW_CHECKOUT sets FLAG_NEW_CHECKOUT=true.
+
+Examples:
+  keyway flags list --env production
+  keyway flags enable NEW_CHECKOUT --env staging
+  keyway flags disable NEW_CHECKOUT --env production`,
+}
+
+var flagsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the flags of an environment and their values",
+	Args:  cobra.NoArgs,
+	RunE:  runFlagsList,
+}
+
+var flagsEnableCmd = &cobra.Command{
+	Use:   "enable <NAME>",
+	Short: "Set a flag to true",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFlagsToggle(true),
+}
+
+var flagsDisableCmd = &cobra.Command{
+	Use:   "disable <NAME>",
+	Short: "Set a flag to false",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFlagsToggle(false),
+}
+
+func init() {
+	for _, c := range []*cobra.Command{flagsListCmd, flagsEnableCmd, flagsDisableCmd} {
+		c.Flags().StringP("env", "e", "development", "Environment name")
+		flagsCmd.AddCommand(c)
+	}
+	flagsListCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// FlagsOptions contains the parsed flags for the flags commands
+type FlagsOptions struct {
+	Name       string
+	Enabled    bool
+	EnvName    string
+	JSONOutput bool
+}
+
+// runFlagsList is the entry point for flags list (uses default dependencies)
+func runFlagsList(cmd *cobra.Command, args []string) error {
+	var opts FlagsOptions
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runFlagsListWithDeps(opts, os.Stdout, defaultDeps)
+}
+
+// runFlagsListWithDeps is the testable version of runFlagsList
+func runFlagsListWithDeps(opts FlagsOptions, w io.Writer, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("flags list")
+	}
+
+	prefix := flagPrefix(deps)
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	if !opts.JSONOutput {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	secrets, _, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	flags := make(map[string]string)
+	for key, value := range secrets {
+		if name, ok := strings.CutPrefix(key, prefix); ok && name != "" {
+			flags[name] = value
+		}
+	}
+
+	if opts.JSONOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(flags)
+	}
+
+	if len(flags) == 0 {
+		deps.UI.Message(fmt.Sprintf("No flags in %s (keys starting with %s)", opts.EnvName, prefix))
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, name := range sortedSecretKeys(flags) {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, flags[name])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	deps.UI.Outro(fmt.Sprintf("%d flags", len(flags)))
+	return nil
+}
+
+// runFlagsToggle returns the entry point for flags enable or disable (uses default dependencies)
+func runFlagsToggle(enabled bool) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		opts := FlagsOptions{Name: args[0], Enabled: enabled}
+		opts.EnvName, _ = cmd.Flags().GetString("env")
+
+		return runFlagsToggleWithDeps(opts, defaultDeps)
+	}
+}
+
+// runFlagsToggleWithDeps is the testable version of flags enable and disable
+func runFlagsToggleWithDeps(opts FlagsOptions, deps *Dependencies) error {
+	command, value := "flags disable", "false"
+	if opts.Enabled {
+		command, value = "flags enable", "true"
+	}
+	deps.UI.Intro(command)
+
+	// Accept the name with or without the prefix
+	prefix := flagPrefix(deps)
+	key := opts.Name
+	if !strings.HasPrefix(key, prefix) {
+		key = prefix + key
+	}
+	if !isValidKeyName(key) {
+		deps.UI.Error("Flag names must contain only alphanumeric characters and underscores")
+		return fmt.Errorf("invalid flag name %q", opts.Name)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	vaultSecrets, client, err := fetchStoredSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+	if vaultSecrets[key] == value {
+		deps.UI.Info(fmt.Sprintf("%s is already %s in %s", key, value, opts.EnvName))
+		return nil
+	}
+
+	ctx := context.Background()
+	vaultSecrets[key] = value
+	err = deps.UI.Spin("Pushing to vault...", func() error {
+		_, pushErr := client.PushSecrets(ctx, repo, opts.EnvName, vaultSecrets)
+		return pushErr
+	})
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Set %s=%s in %s", key, value, opts.EnvName))
+	return nil
+}
+
+// flagPrefix returns the key prefix of feature flags from keyway.toml
+func flagPrefix(deps *Dependencies) string {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		return config.DefaultFlagPrefix
+	}
+	return project.FlagKeyPrefix()
+}
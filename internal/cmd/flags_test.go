@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestRunFlagsListWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "FLAG_NEW_CHECKOUT=true\nFLAG_THEME=dark\nAPI_KEY=secret"}
+
+	var out bytes.Buffer
+	if err := runFlagsListWithDeps(FlagsOptions{EnvName: "production"}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "NEW_CHECKOUT") || !strings.Contains(out.String(), "dark") {
+		t.Errorf("expected the flags listed, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "API_KEY") || strings.Contains(out.String(), "secret") {
+		t.Errorf("expected other keys left out, got %q", out.String())
+	}
+}
+
+func TestRunFlagsToggleWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "FEATURE_BETA=false\nDB=$ref:shared/DB"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{FlagPrefix: "FEATURE_"}
+
+	if err := runFlagsToggleWithDeps(FlagsOptions{Name: "BETA", Enabled: true, EnvName: "staging"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pushed := apiMock.PushedSecrets
+	if pushed["FEATURE_BETA"] != "true" || pushed["DB"] != "$ref:shared/DB" {
+		t.Errorf("unexpected pushed secrets: %v", pushed)
+	}
+
+	// The full key works too, and setting the current value pushes nothing
+	apiMock.PushedSecrets = nil
+	if err := runFlagsToggleWithDeps(FlagsOptions{Name: "FEATURE_BETA", Enabled: false, EnvName: "staging"}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Errorf("expected nothing pushed, got %v", apiMock.PushedSecrets)
+	}
+	if len(uiMock.InfoCalls) != 1 {
+		t.Errorf("expected an already-set message, got %v", uiMock.InfoCalls)
+	}
+}
+
+func TestRunFlagsToggleWithDeps_InvalidName(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	if err := runFlagsToggleWithDeps(FlagsOptions{Name: "new-checkout", Enabled: true, EnvName: "staging"}, deps); err == nil {
+		t.Fatal("expected an error")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected nothing pushed")
+	}
+}
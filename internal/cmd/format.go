@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// formatFlagUsage is the help text of the --format flag of show and pull
+const formatFlagUsage = "Print each key with a Go template: template='{{.Key}}={{.Value}}'"
+
+// templateFuncs are the functions available to --format templates
+var templateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseOutputFormat parses a --format value. The only format is
+// template=<Go template>, executed once per key.
+func parseOutputFormat(format string) (*template.Template, error) {
+	text, ok := strings.CutPrefix(format, "template=")
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q: use --format template='{{.Key}}={{.Value}}'", format)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("empty template")
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// secretItem is the data of a --format template for a key and its value
+type secretItem struct {
+	Key   string
+	Value string
+}
+
+// executeOutputFormat writes tmpl for item, followed by a newline unless the
+// template already ends with one
+func executeOutputFormat(w io.Writer, tmpl *template.Template, item interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, item); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
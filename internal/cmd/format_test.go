@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr string
+	}{
+		{"template={{.Key}}={{.Value}}", ""},
+		{"json", "unsupported format"},
+		{"template=", "empty template"},
+		{"template={{.Key", "invalid template"},
+	}
+	for _, tt := range tests {
+		_, err := parseOutputFormat(tt.format)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("parseOutputFormat(%q) unexpected error: %v", tt.format, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("parseOutputFormat(%q) error = %v, want %q", tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExecuteOutputFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		item   interface{}
+		want   string
+	}{
+		{"template={{.Key}}={{.Value}}", secretItem{Key: "A", Value: "1"}, "A=1\n"},
+		{"template=export {{.Key}}={{quote .Value}}", secretItem{Key: "A", Value: `say "hi"`}, "export A=\"say \\\"hi\\\"\"\n"},
+		{"template={{lower .Key}}: {{json .Value}}\n", secretItem{Key: "DB_URL", Value: "x"}, "db_url: \"x\"\n"},
+		{"template={{.Key}} {{.LastPulledBy}}", api.KeyMetadata{Key: "A", LastPulledBy: "alice"}, "A alice\n"},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseOutputFormat(tt.format)
+		if err != nil {
+			t.Fatalf("parseOutputFormat(%q): %v", tt.format, err)
+		}
+		var buf bytes.Buffer
+		if err := executeOutputFormat(&buf, tmpl, tt.item); err != nil {
+			t.Fatalf("executeOutputFormat(%q): %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("executeOutputFormat(%q) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestExecuteOutputFormat_UnknownField(t *testing.T) {
+	tmpl, err := parseOutputFormat("template={{.Value}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// show lists keys without their values
+	now := time.Now()
+	if err := executeOutputFormat(&bytes.Buffer{}, tmpl, api.KeyMetadata{Key: "A", CreatedAt: &now}); err == nil {
+		t.Error("expected an error for a field keys don't have")
+	}
+}
+
+func TestRunPullWithDeps_Format(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "B=2\nA=1\n"}
+
+	opts := PullOptions{EnvName: "production", EnvFlagSet: true, File: ".env", Format: "template={{.Key}}={{.Value}}"}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := fsMock.Written[".env"]; ok {
+		t.Error("expected no file to be written")
+	}
+	if len(uiMock.IntroCalls) != 0 {
+		t.Errorf("expected no UI output, got intro %v", uiMock.IntroCalls)
+	}
+}
+
+func TestRunPullWithDeps_FormatWithEncryptFor(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	opts := PullOptions{EnvName: "production", Format: "template={{.Key}}", EncryptFor: []string{"age1xyz"}}
+	if err := runPullWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRunShowWithDeps_FormatWithJSON(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runShowWithDeps(ShowOptions{EnvName: "production", JSONOutput: true, Format: "template={{.Key}}"}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRunShowWithDeps_FormatTemplateError(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1\n"}
+
+	if err := runShowWithDeps(ShowOptions{EnvName: "production", Format: "template={{.Value}}"}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(uiMock.ErrorCalls) != 1 {
+		t.Errorf("expected the template error to be shown, got %v", uiMock.ErrorCalls)
+	}
+}
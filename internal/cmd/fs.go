@@ -11,3 +11,6 @@ var osReadFile = os.ReadFile
 var osWriteFile = func(name string, data []byte, perm uint32) error {
 	return os.WriteFile(name, data, os.FileMode(perm))
 }
+
+// osRemove wraps os.Remove
+var osRemove = os.Remove
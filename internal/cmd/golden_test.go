@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// Golden files pin the output scripts rely on. A failing golden test means
+// the output changed: if that is intended and allowed by the output
+// compatibility policy in the README, rewrite the files with
+//
+//	go test ./internal/cmd -run Golden -update
+//
+// and review the diff of testdata/golden like any other change.
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// assertGolden compares got with testdata/golden/<name>.golden
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file, create it with -update: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (rerun with -update if the change is intended)\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+// assertGoldenJSON is assertGolden for JSON documents, which must also parse
+func assertGoldenJSON(t *testing.T, name string, got []byte) {
+	t.Helper()
+	if !json.Valid(got) {
+		t.Errorf("invalid JSON output:\n%s", got)
+	}
+	assertGolden(t, name, got)
+}
+
+// goldenTime returns a fixed date, with timestamps printed in UTC
+func goldenTime(t *testing.T) time.Time {
+	t.Helper()
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+	return time.Date(2026, 3, 14, 9, 26, 0, 0, time.UTC)
+}
+
+func goldenDiffResult() *DiffResult {
+	return compareSecrets("staging", "production",
+		map[string]string{"API_KEY": "sk_test_123", "DEBUG": "true", "PORT": "3000"},
+		map[string]string{"API_KEY": "sk_live_456", "PORT": "3000", "SENTRY_DSN": "https://sentry.example"},
+		false)
+}
+
+func TestGolden_DiffJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := printDiffJSON(&out, goldenDiffResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "diff.json", out.Bytes())
+}
+
+func TestGolden_PullJSON(t *testing.T) {
+	var out bytes.Buffer
+	err := printPullJSON(&out, pullResult{
+		Repository:  "owner/repo",
+		Environment: "production",
+		File:        ".env",
+		Keys:        []string{"API_KEY", "PORT"},
+		Added:       []string{"API_KEY"},
+		LocalOnly:   []string{"LOCAL_ONLY"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "pull.json", out.Bytes())
+}
+
+func TestGolden_Show(t *testing.T) {
+	now := goldenTime(t)
+	pulled := now.Add(2 * time.Hour)
+	keys := []api.KeyMetadata{
+		{Key: "API_KEY", CreatedAt: &now, UpdatedAt: &now, LastPulledAt: &pulled, LastPulledBy: "octocat"},
+		{Key: "PORT", CreatedAt: &now},
+	}
+
+	var table bytes.Buffer
+	printShowTable(&table, keys, true)
+	assertGolden(t, "show.txt", table.Bytes())
+
+	var out bytes.Buffer
+	if err := printShowJSON(&out, keys); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "show.json", out.Bytes())
+}
+
+func TestGolden_Stats(t *testing.T) {
+	now := goldenTime(t)
+	stats := &VaultStats{
+		Repository: "owner/repo",
+		Environments: []EnvironmentStats{
+			{Name: "development", Keys: 12, Bytes: 2048, LastPulledAt: &now, TopPullers: []api.Puller{{Login: "octocat", Pulls: 7}}},
+			{Name: "production", Keys: 9, Bytes: 512, LastPushedAt: &now},
+			{Name: "staging", Error: "not found"},
+		},
+		TotalKeys:  21,
+		TotalBytes: 2560,
+	}
+
+	var table bytes.Buffer
+	printStatsTable(&table, stats, true)
+	assertGolden(t, "stats.txt", table.Bytes())
+
+	var out bytes.Buffer
+	if err := printStatsJSON(&out, stats); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "stats.json", out.Bytes())
+}
+
+func TestGolden_SecretsExpiring(t *testing.T) {
+	now := goldenTime(t)
+	keys := []expiringKey{
+		{Environment: "production", Key: "DEPLOY_TOKEN", ExpiresAt: now.Add(-time.Hour), Expired: true},
+		{Environment: "staging", Key: "API_KEY", ExpiresAt: now.Add(72 * time.Hour)},
+	}
+
+	var table bytes.Buffer
+	printExpiringTable(&table, keys, now)
+	assertGolden(t, "secrets-expiring.txt", table.Bytes())
+}
+
+func TestGolden_EnvsList(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "staging", "production"}
+
+	var out bytes.Buffer
+	if err := runEnvsListWithDeps(EnvsOptions{JSONOutput: true}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "envs-list.json", out.Bytes())
+
+	apiMock.EnvAccess = []api.EnvironmentAccess{
+		{Name: "development", Read: true, Write: true},
+		{Name: "staging", Read: true},
+		{Name: "production"},
+	}
+	var table bytes.Buffer
+	if err := runEnvsListWithDeps(EnvsOptions{}, &table, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "envs-list.txt", table.Bytes())
+}
+
+func TestGolden_FlagsList(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "FLAG_NEW_CHECKOUT=true\nFLAG_THEME=dark\nAPI_KEY=secret"}
+
+	var out bytes.Buffer
+	if err := runFlagsListWithDeps(FlagsOptions{EnvName: "production"}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGolden(t, "flags-list.txt", out.Bytes())
+
+	out.Reset()
+	if err := runFlagsListWithDeps(FlagsOptions{EnvName: "production", JSONOutput: true}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "flags-list.json", out.Bytes())
+}
+
+func TestGolden_WhoamiJSON(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat", Username: "octocat", Plan: "pro"}
+
+	var out bytes.Buffer
+	if err := runWhoamiWithDeps(true, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenJSON(t, "whoami.json", out.Bytes())
+}
+
+func TestGolden_JSONError(t *testing.T) {
+	var out bytes.Buffer
+	writeJSONError(&out, fmt.Errorf("pull failed: %w", &api.APIError{
+		StatusCode: 404,
+		Detail:     "Vault not found",
+		RequestID:  "req_123",
+		Method:     "GET",
+		Path:       "/v1/secrets/pull",
+	}))
+	assertGoldenJSON(t, "error-api.json", out.Bytes())
+
+	out.Reset()
+	writeJSONError(&out, errors.New("no GitHub remote found"))
+	assertGoldenJSON(t, "error.json", out.Bytes())
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var helmCmd = &cobra.Command{
+	Use:   "helm [flags] -- helm args...",
+	Short: "Run helm with vault secrets as a values file",
+	Long: `Run helm with the environment's secrets in a values file, so charts can
+read them without a values file committed to the repository.
+
+The secrets are written under --values-key (default: secrets, i.e.
+.Values.secrets.DATABASE_URL) to a temporary owner-only file, passed to helm
+with --values after your arguments, so they win over your own values files,
+and removed once helm exits. Values are never passed on the helm command line.
+
+Put helm's own flags after --.`,
+	Example: `  keyway helm -e production upgrade myapp ./chart
+  keyway helm -e production -- upgrade --install myapp ./chart -f values.prod.yaml
+  keyway helm -e staging --values-key env -- template myapp ./chart`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runHelm,
+}
+
+func init() {
+	helmCmd.Flags().StringP("env", "e", "", "Environment name")
+	helmCmd.Flags().String("values-key", "secrets", "Values key the secrets are nested under (empty: top level)")
+	helmCmd.Flags().StringSlice("only", nil, "Pass only these keys (e.g. DATABASE_URL,REDIS_URL)")
+	helmCmd.Flags().Bool("dry-run", false, "Print the helm command and values with values masked, without running it")
+	helmCmd.Flags().Bool("override", false, "Bypass keyway.toml branch and label policies for this environment (break-glass)")
+	_ = helmCmd.MarkFlagRequired("env")
+}
+
+// HelmOptions contains the parsed flags for the helm command
+type HelmOptions struct {
+	EnvName   string
+	ValuesKey string
+	Only      []string
+	DryRun    bool
+	Override  bool
+	Args      []string
+}
+
+func runHelm(cmd *cobra.Command, args []string) error {
+	opts := HelmOptions{Args: args}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.ValuesKey, _ = cmd.Flags().GetString("values-key")
+	opts.Only, _ = cmd.Flags().GetStringSlice("only")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Override, _ = cmd.Flags().GetBool("override")
+	return exitWithProviderStatus(runHelmWithDeps(opts, defaultDeps))
+}
+
+// runHelmWithDeps is the testable version of runHelm
+func runHelmWithDeps(opts HelmOptions, deps *Dependencies) error {
+	deps.UI.Intro("helm")
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	if err := enforceBranchPolicy(deps, opts.EnvName, opts.Override); err != nil {
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	secrets, client, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+	if only := parseOnlyKeys(opts.Only); len(only) > 0 {
+		if err := selectKeys(secrets, only); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	}
+	dropExpiredSecrets(context.Background(), deps, client, repo, opts.EnvName, secrets)
+	if err := enforceLabelPolicy(deps, opts.EnvName, sortedSecretKeys(secrets), opts.Override); err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		values, err := buildHelmValues(opts.ValuesKey, maskValues(secrets))
+		if err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		deps.UI.Message(formatCommand("helm", helmArgs(opts, "<values.yaml>")))
+		deps.UI.Message(string(values))
+		return nil
+	}
+
+	values, err := buildHelmValues(opts.ValuesKey, secrets)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	file, err := overridesFilePath("helm-values", ".yaml")
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if err := deps.FS.WriteFile(file, values, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write values: %s", err.Error()))
+		return err
+	}
+	defer deps.FS.Remove(file)
+
+	deps.UI.Success(fmt.Sprintf("Passing %d secrets to helm", len(secrets)))
+	return runProviderCommand(deps, "helm", helmArgs(opts, file))
+}
+
+// buildHelmValues renders the secrets as a values file, nested under key
+// unless it is empty. Values stay strings, even when they look like numbers.
+func buildHelmValues(key string, secrets map[string]string) ([]byte, error) {
+	var values interface{} = secrets
+	if key != "" {
+		values = map[string]map[string]string{key: secrets}
+	}
+	return yaml.Marshal(values)
+}
+
+// helmArgs appends the values file last, so the vault wins over other values files
+func helmArgs(opts HelmOptions, valuesFile string) []string {
+	args := append([]string{}, opts.Args...)
+	return append(args, "--values", valuesFile)
+}
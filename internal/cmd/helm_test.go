@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunHelmWithDeps(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nPORT=8080\n"}
+	fsMock := deps.FS.(*MockFileSystem)
+
+	var values string
+	cmdRunner.OnRun = func(name string, args []string) {
+		values = args[len(args)-1]
+		want := "secrets:\n    API_KEY: secret123\n    PORT: \"8080\"\n"
+		if got := string(fsMock.Written[values]); got != want {
+			t.Errorf("unexpected values file while helm runs:\n%s", got)
+		}
+	}
+
+	opts := HelmOptions{EnvName: "production", ValuesKey: "secrets", Args: []string{"upgrade", "myapp", "./chart", "-f", "values.yaml"}}
+	if err := runHelmWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "helm" {
+		t.Errorf("expected helm, got %q", cmdRunner.LastCommand)
+	}
+	if got := strings.Join(cmdRunner.LastArgs, " "); got != "upgrade myapp ./chart -f values.yaml --values "+values {
+		t.Errorf("expected the values file last, got %s", got)
+	}
+	if strings.Contains(strings.Join(cmdRunner.LastArgs, " "), "secret123") || len(cmdRunner.LastSecrets) != 0 {
+		t.Error("secret values must not reach helm's command line or environment")
+	}
+	if len(fsMock.Removed) != 1 || fsMock.Removed[0] != values {
+		t.Errorf("expected values file %q to be removed, got %v", values, fsMock.Removed)
+	}
+}
+
+func TestRunHelmWithDeps_DryRunMasksValues(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nREDIS_URL=redis://cache\n"}
+
+	opts := HelmOptions{EnvName: "production", Only: []string{"API_KEY"}, DryRun: true, Args: []string{"template", "myapp", "./chart"}}
+	if err := runHelmWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastCommand != "" {
+		t.Error("dry run should not run helm")
+	}
+	output := strings.Join(uiMock.MessageCalls, "\n")
+	if strings.Contains(output, "secret123") || strings.Contains(output, "REDIS_URL") {
+		t.Errorf("dry run must mask values and honor --only, got %q", output)
+	}
+	if !strings.Contains(output, "helm template myapp ./chart --values <values.yaml>") || !strings.Contains(output, "API_KEY: '***'") {
+		t.Errorf("expected the masked command and values, got %q", output)
+	}
+}
+
+func TestBuildHelmValues_TopLevel(t *testing.T) {
+	values, err := buildHelmValues("", map[string]string{"B": "true", "A": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(values) != "A: x\nB: \"true\"\n" {
+		t.Errorf("unexpected values:\n%s", values)
+	}
+}
+
+func TestRunHelmWithDeps_SkipsExpiredSecrets(t *testing.T) {
+	t.Setenv("KEYWAY_STATE_DIR", t.TempDir())
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nTEMP_TOKEN=tmp_abc\n"}
+	apiMock.KeyMetadata = []api.KeyMetadata{
+		{Key: "API_KEY"},
+		{Key: "TEMP_TOKEN", ExpiresAt: timePtr(time.Now().Add(-time.Hour))},
+	}
+	fsMock := deps.FS.(*MockFileSystem)
+
+	var values string
+	cmdRunner.OnRun = func(name string, args []string) {
+		values = string(fsMock.Written[args[len(args)-1]])
+	}
+
+	opts := HelmOptions{EnvName: "production", ValuesKey: "secrets", Args: []string{"upgrade", "myapp", "./chart"}}
+	if err := runHelmWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(values, "TEMP_TOKEN") || !strings.Contains(values, "API_KEY") {
+		t.Errorf("expected the expired key left out, got:\n%s", values)
+	}
+	if len(uiMock.WarnCalls) == 0 || !strings.Contains(uiMock.WarnCalls[0], "TEMP_TOKEN") {
+		t.Errorf("expected an expiry warning, got %v", uiMock.WarnCalls)
+	}
+}
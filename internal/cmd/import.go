@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured formats accepted by secrets import
+const (
+	importFormatJSON = "json"
+	importFormatYAML = "yaml"
+)
+
+var secretsImportJSONCmd = &cobra.Command{
+	Use:   "import-json <FILE>",
+	Short: "Import a JSON file as flat secrets",
+	Long: `Import a JSON config file into an environment, flattening nested objects
+into env keys: {"database": {"host": "db"}} becomes DATABASE__HOST=db.
+
+Imported keys are merged into the environment; other secrets are kept.
+Pass --select to choose which of them to import.
+
+Arrays (--arrays):
+  index  LIST__0=a, LIST__1=b (default)
+  json   LIST=["a","b"]
+  join   LIST=a,b (arrays of scalars only)
+
+Examples:
+  keyway secrets import-json config.json --preview
+  keyway secrets import-json config.json -e staging --separator _ --prefix APP`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsImport(importFormatJSON),
+}
+
+var secretsImportYAMLCmd = &cobra.Command{
+	Use:   "import-yaml <FILE>",
+	Short: "Import a YAML file as flat secrets",
+	Long: `Import a YAML config file into an environment, flattening nested mappings
+into env keys: database.host becomes DATABASE__HOST.
+
+Imported keys are merged into the environment; other secrets are kept.
+Pass --select to choose which of them to import.
+Arrays are handled like import-json (see --arrays).
+
+Examples:
+  keyway secrets import-yaml config.yaml --preview
+  keyway secrets import-yaml config.yaml -e production --arrays json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsImport(importFormatYAML),
+}
+
+func init() {
+	for _, c := range []*cobra.Command{secretsImportJSONCmd, secretsImportYAMLCmd} {
+		c.Flags().StringP("env", "e", "development", "Environment name")
+		c.Flags().String("separator", "__", "Separator between nested key segments")
+		c.Flags().String("arrays", env.ArraysIndex, "Array handling: "+strings.Join(env.ArrayModes(), ", "))
+		c.Flags().String("prefix", "", "Prefix added to every key")
+		c.Flags().Bool("preview", false, "Show the resulting keys without importing")
+		c.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+		c.Flags().Bool("select", false, "Choose interactively which new and changed keys to import")
+		secretsCmd.AddCommand(c)
+	}
+}
+
+// ImportOptions contains the parsed flags for the secrets import commands
+type ImportOptions struct {
+	File       string
+	Format     string
+	EnvName    string
+	EnvFlagSet bool
+	Separator  string
+	Arrays     string
+	Prefix     string
+	Preview    bool
+	Yes        bool
+	Select     bool
+}
+
+func runSecretsImport(format string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		opts := ImportOptions{File: args[0], Format: format, EnvFlagSet: cmd.Flags().Changed("env")}
+		opts.EnvName, _ = cmd.Flags().GetString("env")
+		opts.Separator, _ = cmd.Flags().GetString("separator")
+		opts.Arrays, _ = cmd.Flags().GetString("arrays")
+		opts.Prefix, _ = cmd.Flags().GetString("prefix")
+		opts.Preview, _ = cmd.Flags().GetBool("preview")
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		opts.Select, _ = cmd.Flags().GetBool("select")
+
+		return runSecretsImportWithDeps(opts, defaultDeps)
+	}
+}
+
+// runSecretsImportWithDeps is the testable version of the secrets import commands
+func runSecretsImportWithDeps(opts ImportOptions, deps *Dependencies) error {
+	deps.UI.Intro("secrets import-" + opts.Format)
+
+	if opts.Select && !opts.Preview && !deps.UI.IsInteractive() {
+		deps.UI.Error("--select needs an interactive terminal")
+		return fmt.Errorf("--select needs an interactive terminal")
+	}
+
+	if !isValidArraysMode(opts.Arrays) {
+		deps.UI.Error(fmt.Sprintf("--arrays must be one of: %s", strings.Join(env.ArrayModes(), ", ")))
+		return fmt.Errorf("invalid arrays mode %q", opts.Arrays)
+	}
+	if opts.Separator == "" || !isValidKeyName(opts.Separator) {
+		deps.UI.Error("--separator must contain only alphanumeric characters and underscores")
+		return fmt.Errorf("invalid separator %q", opts.Separator)
+	}
+
+	data, err := deps.FS.ReadFile(opts.File)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to read %s: %s", opts.File, err.Error()))
+		return err
+	}
+
+	imported, err := flattenStructured(data, opts)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("%s: %s", opts.File, err.Error()))
+		return err
+	}
+	if len(imported) == 0 {
+		deps.UI.Warn(fmt.Sprintf("No values found in %s", opts.File))
+		return nil
+	}
+
+	keys := sortedSecretKeys(imported)
+	for _, key := range keys {
+		if !isValidKeyName(key) {
+			deps.UI.Error(fmt.Sprintf("Invalid key %q in %s", key, opts.File))
+			return fmt.Errorf("invalid key %q", key)
+		}
+	}
+
+	if opts.Preview {
+		deps.UI.Message(fmt.Sprintf("%d keys from %s:", len(keys), opts.File))
+		for _, key := range keys {
+			deps.UI.DiffAdded(key)
+		}
+		deps.UI.Outro("Preview only, nothing was imported")
+		return nil
+	}
+
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "secrets import-"+opts.Format, opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	vaultSecrets, client, err := fetchStoredSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	diff := env.CalculatePushDiff(imported, vaultSecrets)
+	if len(diff.Added) == 0 && len(diff.Changed) == 0 {
+		deps.UI.Info("No changes detected")
+		return nil
+	}
+
+	if opts.Select {
+		changed := append(append([]string{}, diff.Added...), diff.Changed...)
+		sort.Strings(changed)
+		selected, err := deps.UI.MultiSelect("Keys to import", changed, changed)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			deps.UI.Warn("No keys selected, nothing was imported")
+			return nil
+		}
+		picked := make(map[string]string, len(selected))
+		for _, key := range selected {
+			picked[key] = imported[key]
+		}
+		imported = picked
+		keys = selected
+		diff = env.CalculatePushDiff(imported, vaultSecrets)
+	}
+
+	deps.UI.Message("")
+	deps.UI.Message("Will be imported to vault:")
+	for _, key := range diff.Added {
+		deps.UI.DiffAdded(key)
+	}
+	for _, key := range diff.Changed {
+		deps.UI.DiffChanged(key)
+	}
+	deps.UI.Message("")
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Import %d keys from %s to %s?", len(keys), opts.File, opts.EnvName), true)
+		if !confirm {
+			deps.UI.Warn("Import aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	// Pushing replaces the environment, so send the vault secrets with the imported ones on top
+	merged := make(map[string]string, len(vaultSecrets)+len(imported))
+	for k, v := range vaultSecrets {
+		merged[k] = v
+	}
+	for k, v := range imported {
+		merged[k] = v
+	}
+
+	// The upload is a single idempotent request: retry it, there is no partial state to resume
+	ctx := context.Background()
+	err = deps.UI.Spin("Uploading secrets...", func() error {
+		return api.WithRetry(ctx, func() error {
+			_, pushErr := client.PushSecrets(ctx, repo, opts.EnvName, merged)
+			return pushErr
+		})
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Imported %d keys into %s (+%d created, ~%d updated)", len(diff.Added)+len(diff.Changed), opts.EnvName, len(diff.Added), len(diff.Changed)))
+	return nil
+}
+
+// flattenStructured decodes a JSON or YAML document and flattens it into env keys
+func flattenStructured(data []byte, opts ImportOptions) (map[string]string, error) {
+	var doc interface{}
+	switch opts.Format {
+	case importFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // keep numbers exactly as written
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case importFormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		doc = yamlValue(&node)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	return env.Flatten(doc, env.FlattenOptions{
+		Separator: opts.Separator,
+		Arrays:    opts.Arrays,
+		Prefix:    strings.ToUpper(opts.Prefix),
+	})
+}
+
+// yamlValue converts a YAML node like a JSON decode with UseNumber would,
+// keeping scalars as written (1.10 stays "1.10", not 1.1)
+func yamlValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			m[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		list := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			list[i] = yamlValue(item)
+		}
+		return list
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!int", "!!float":
+		if json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value)
+		}
+	case "!!bool":
+		if b, err := strconv.ParseBool(node.Value); err == nil {
+			return b
+		}
+	}
+	return node.Value
+}
+
+func isValidArraysMode(mode string) bool {
+	for _, m := range env.ArrayModes() {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
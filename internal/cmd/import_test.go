@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestFlattenStructured_YAMLKeepsScalarsAsWritten(t *testing.T) {
+	data := []byte("version: 1.10\ndatabase:\n  host: db\n  port: 5432\n  enabled: yes\nhosts: &h\n  - a\n  - b\nmirror: *h\n")
+
+	got, err := flattenStructured(data, ImportOptions{Format: importFormatYAML, Separator: "__", Arrays: "join"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"VERSION":           "1.10",
+		"DATABASE__HOST":    "db",
+		"DATABASE__PORT":    "5432",
+		"DATABASE__ENABLED": "yes",
+		"HOSTS":             "a,b",
+		"MIRROR":            "a,b",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestFlattenStructured_InvalidJSON(t *testing.T) {
+	if _, err := flattenStructured([]byte("{"), ImportOptions{Format: importFormatJSON, Separator: "__", Arrays: "index"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestRunSecretsImportWithDeps_Preview(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	fsMock.Files["config.json"] = []byte(`{"database": {"host": "db"}}`)
+
+	opts := ImportOptions{File: "config.json", Format: importFormatJSON, Separator: "__", Arrays: "index", Preview: true}
+	if err := runSecretsImportWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(uiMock.DiffAddedCalls) != 1 || uiMock.DiffAddedCalls[0] != "DATABASE__HOST" {
+		t.Errorf("expected preview of DATABASE__HOST, got %v", uiMock.DiffAddedCalls)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("preview should not push")
+	}
+}
+
+func TestRunSecretsImportWithDeps_MergesIntoVault(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	fsMock.Files["config.yaml"] = []byte("database:\n  host: new\n  port: 5432\n")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DATABASE__HOST=old\nOTHER=kept"}
+
+	opts := ImportOptions{File: "config.yaml", Format: importFormatYAML, EnvName: "staging", Separator: "__", Arrays: "index", Yes: true}
+	if err := runSecretsImportWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pushed := apiMock.PushedSecrets
+	if pushed["DATABASE__HOST"] != "new" || pushed["DATABASE__PORT"] != "5432" || pushed["OTHER"] != "kept" {
+		t.Errorf("unexpected pushed secrets: %v", pushed)
+	}
+}
+
+func TestRunSecretsImportWithDeps_KeepsSharedRefs(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	fsMock.Files["config.yaml"] = []byte("database:\n  port: 5432\n")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=$ref:shared/DSN"}
+	apiMock.SharedValues = map[string]string{"DSN": "https://sentry.io/1"}
+
+	opts := ImportOptions{File: "config.yaml", Format: importFormatYAML, EnvName: "staging", Separator: "__", Arrays: "index", Yes: true}
+	if err := runSecretsImportWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pushed := apiMock.PushedSecrets
+	if pushed["SENTRY_DSN"] != "$ref:shared/DSN" || pushed["DATABASE__PORT"] != "5432" {
+		t.Errorf("expected the shared reference kept, got %v", pushed)
+	}
+}
+
+func TestRunSecretsImportWithDeps_Select(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	uiMock.Interactive = true
+	uiMock.MultiSelectResult = []string{"DATABASE__PORT"}
+	fsMock.Files["config.yaml"] = []byte("database:\n  host: new\n  port: 5432\n")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DATABASE__HOST=old"}
+
+	opts := ImportOptions{File: "config.yaml", Format: importFormatYAML, EnvName: "staging", Separator: "__", Arrays: "index", Yes: true, Select: true}
+	if err := runSecretsImportWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pushed := apiMock.PushedSecrets
+	if pushed["DATABASE__HOST"] != "old" || pushed["DATABASE__PORT"] != "5432" {
+		t.Errorf("expected only DATABASE__PORT imported, got %v", pushed)
+	}
+
+	uiMock.MultiSelectResult = []string{}
+	apiMock.PushedSecrets = nil
+	if err := runSecretsImportWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected nothing imported when no key is selected")
+	}
+}
+
+func TestRunSecretsImportWithDeps_InvalidOptions(t *testing.T) {
+	deps, _, _, _, fsMock, _ := NewTestDeps()
+	fsMock.Files["c.json"] = []byte(`{"": "x"}`)
+
+	tests := []ImportOptions{
+		{File: "c.json", Format: importFormatJSON, Separator: "__", Arrays: "flat"},
+		{File: "c.json", Format: importFormatJSON, Separator: ".", Arrays: "index"},
+		{File: "c.json", Format: importFormatJSON, Separator: "__", Arrays: "index", Preview: true},
+	}
+	for _, opts := range tests {
+		if err := runSecretsImportWithDeps(opts, deps); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		} else if strings.TrimSpace(err.Error()) == "" {
+			t.Error("expected error message")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// Modes for keyway run --inherit-env; any other value is a list of names
+const (
+	inheritDefault = "default"
+	inheritAll     = "all"
+	inheritNone    = "none"
+)
+
+// inheritDenyList is removed from the parent environment by default: keyway's
+// own credential and variables that inject code into the child process
+var inheritDenyList = []string{
+	"KEYWAY_TOKEN",
+	"LD_PRELOAD",
+	"LD_AUDIT",
+	"DYLD_INSERT_LIBRARIES",
+}
+
+// osEnviron wraps os.Environ
+var osEnviron = os.Environ
+
+// inheritedEnviron filters the parent environment (KEY=VALUE entries) for the
+// wrapped process. mode is default, all, none or a comma-separated list of
+// names that may use glob patterns (e.g. PATH,HOME,LC_*).
+func inheritedEnviron(mode string, environ []string) ([]string, error) {
+	var keep func(name string) bool
+
+	switch mode {
+	case "", inheritDefault:
+		keep = func(name string) bool { return !matchesAnyName(name, inheritDenyList) }
+	case inheritAll:
+		return environ, nil
+	case inheritNone:
+		return []string{}, nil
+	default:
+		patterns, err := parseInheritList(mode)
+		if err != nil {
+			return nil, err
+		}
+		keep = func(name string) bool { return matchesAnyName(name, patterns) }
+	}
+
+	kept := make([]string, 0, len(environ))
+	for _, entry := range environ {
+		name := strings.SplitN(entry, "=", 2)[0]
+		if name != "" && keep(name) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+// parseInheritList validates a comma-separated list of names or glob patterns
+func parseInheritList(list string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q in --inherit-env", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("--inherit-env must be %s, %s, %s or a list of names", inheritDefault, inheritAll, inheritNone)
+	}
+	return patterns, nil
+}
+
+// matchesAnyName reports whether name matches one of the patterns.
+// Names are case-insensitive on Windows, like its environment.
+func matchesAnyName(name string, patterns []string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, pattern := range patterns {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
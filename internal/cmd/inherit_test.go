@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+var parentEnviron = []string{
+	"PATH=/usr/bin",
+	"HOME=/home/dev",
+	"LC_ALL=C",
+	"KEYWAY_TOKEN=kw_secret",
+	"LD_PRELOAD=/tmp/hook.so",
+	"DATABASE_URL=postgres://drift",
+}
+
+func TestInheritedEnviron(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"default", []string{"PATH=/usr/bin", "HOME=/home/dev", "LC_ALL=C", "DATABASE_URL=postgres://drift"}},
+		{"", []string{"PATH=/usr/bin", "HOME=/home/dev", "LC_ALL=C", "DATABASE_URL=postgres://drift"}},
+		{"all", parentEnviron},
+		{"none", []string{}},
+		{"PATH, LC_*", []string{"PATH=/usr/bin", "LC_ALL=C"}},
+	}
+
+	for _, tt := range tests {
+		got, err := inheritedEnviron(tt.mode, parentEnviron)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.mode, err)
+			continue
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%q: expected %v, got %v", tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestInheritedEnviron_InvalidList(t *testing.T) {
+	for _, mode := range []string{",", "PATH,[A-"} {
+		if _, err := inheritedEnviron(mode, parentEnviron); err == nil {
+			t.Errorf("%q: expected error", mode)
+		}
+	}
+}
+
+func TestRunRunWithDeps_InheritEnv(t *testing.T) {
+	original := osEnviron
+	osEnviron = func() []string { return parentEnviron }
+	defer func() { osEnviron = original }()
+
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "env", InheritEnv: "default"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, entry := range cmdRunner.LastEnviron {
+		if strings.HasPrefix(entry, "KEYWAY_TOKEN=") || strings.HasPrefix(entry, "LD_PRELOAD=") {
+			t.Errorf("%s should not be inherited by default", entry)
+		}
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Error("secrets should still be injected")
+	}
+}
+
+func TestRunRunWithDeps_InvalidInheritEnv(t *testing.T) {
+	deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "env", InheritEnv: ","}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command should not run")
+	}
+}
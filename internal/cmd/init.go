@@ -123,7 +123,7 @@ func runInitWithDeps(opts InitOptions, deps *Dependencies) error {
 	}
 
 	// Vault doesn't exist (404) or other error - check if we should create it
-	if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode != 404 {
+	if apiErr, ok := err.(*api.APIError); ok && !apiErr.NotFound() {
 		// Non-404 error (403, 500, etc.) - don't try to create
 		deps.UI.Error(err.Error())
 		return err
@@ -146,7 +146,7 @@ func runInitWithDeps(opts InitOptions, deps *Dependencies) error {
 	if err != nil {
 		if apiErr, ok := err.(*api.APIError); ok {
 			// Handle auth errors (expired token)
-			if apiErr.StatusCode == 401 {
+			if apiErr.AuthExpired() {
 				newToken, authErr := handleAuthError(err, deps)
 				if authErr != nil {
 					return authErr
@@ -169,7 +169,7 @@ func runInitWithDeps(opts InitOptions, deps *Dependencies) error {
 			}
 
 			// Already exists (409 Conflict)
-			if apiErr.StatusCode == 409 {
+			if apiErr.Conflict() {
 				deps.UI.Success("Already initialized!")
 
 				// Still try to add badge if not present
@@ -189,7 +189,7 @@ func runInitWithDeps(opts InitOptions, deps *Dependencies) error {
 			}
 
 			// Check if trial is available (from structured error response)
-			if apiErr.StatusCode == 403 && apiErr.TrialInfo != nil && apiErr.TrialInfo.Eligible && deps.UI.IsInteractive() {
+			if apiErr.Forbidden() && apiErr.TrialInfo != nil && apiErr.TrialInfo.Eligible && deps.UI.IsInteractive() {
 				trialInfo := apiErr.TrialInfo
 				deps.UI.Warn("This repository belongs to an organization on the Free plan")
 				deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Private organization repos require a Team plan, but you can start a %d-day free trial.", trialInfo.DaysAvailable)))
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// jsonError is written to stdout instead of the help when a command run with
+// --json or --output json fails, so scripts get the request ID to quote in support tickets
+type jsonError struct {
+	Message   string `json:"message"`
+	Status    int    `json:"status,omitempty"`
+	Type      string `json:"type,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Method    string `json:"method,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+}
+
+// jsonRequested reports whether cmd was run with --json or --output json
+func jsonRequested(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
+	}
+	if outputFlag == outputJSON {
+		return true
+	}
+	flag := cmd.Flags().Lookup("json")
+	return flag != nil && flag.Value.String() == "true"
+}
+
+// newJSONError describes err, with the correlation data of API errors
+func newJSONError(err error) jsonError {
+	apiErr, ok := api.AsAPIError(err)
+	if !ok {
+		return jsonError{Message: err.Error()}
+	}
+	return jsonError{
+		Message:   apiErr.Message(),
+		Status:    apiErr.StatusCode,
+		Type:      apiErr.Type,
+		RequestID: apiErr.RequestID,
+		Method:    apiErr.Method,
+		Path:      apiErr.Path,
+		Instance:  apiErr.Instance,
+	}
+}
+
+// writeJSONError writes {"error": {...}} for err
+func writeJSONError(w io.Writer, err error) {
+	data, marshalErr := json.MarshalIndent(map[string]jsonError{"error": newJSONError(err)}, "", "  ")
+	if marshalErr != nil {
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
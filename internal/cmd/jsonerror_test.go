@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func TestWriteJSONError_APIError(t *testing.T) {
+	err := fmt.Errorf("pull failed: %w", &api.APIError{
+		StatusCode: 500,
+		Detail:     "Decryption failed",
+		RequestID:  "req_123",
+		Method:     "GET",
+		Path:       "/v1/secrets/pull",
+	})
+
+	var buf bytes.Buffer
+	writeJSONError(&buf, err)
+
+	var out struct {
+		Error jsonError `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := jsonError{Message: "Decryption failed", Status: 500, RequestID: "req_123", Method: "GET", Path: "/v1/secrets/pull"}
+	if out.Error != want {
+		t.Errorf("expected %+v, got %+v", want, out.Error)
+	}
+}
+
+func TestWriteJSONError_PlainError(t *testing.T) {
+	var buf bytes.Buffer
+	writeJSONError(&buf, errors.New("no GitHub remote found"))
+
+	if got := buf.String(); got != "{\n  \"error\": {\n    \"message\": \"no GitHub remote found\"\n  }\n}\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestJSONRequested(t *testing.T) {
+	cmd := &cobra.Command{Use: "stats"}
+	cmd.Flags().Bool("json", false, "")
+
+	if jsonRequested(cmd) {
+		t.Error("expected false without --json")
+	}
+	_ = cmd.Flags().Set("json", "true")
+	if !jsonRequested(cmd) {
+		t.Error("expected true with --json")
+	}
+	if jsonRequested(&cobra.Command{Use: "push"}) || jsonRequested(nil) {
+		t.Error("expected false for commands without --json")
+	}
+}
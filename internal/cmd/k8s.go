@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Kubernetes integration",
+}
+
+var k8sSecretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Print an environment as a Kubernetes Secret manifest",
+	Long: `Print the secrets of an environment as a Kubernetes Secret manifest
+(type Opaque, base64 data). Nothing is written to disk: pipe it to kubectl.
+
+Pods read the Secret with envFrom.secretRef, so values never appear on a
+kubectl command line. Re-apply after a change in the vault; pods pick up the
+new values when they restart.`,
+	Example: `  keyway k8s secret --env production --name app-secrets | kubectl apply -f -
+  keyway k8s secret --env staging --name api --namespace staging --only DATABASE_URL,REDIS_URL`,
+	Args: cobra.NoArgs,
+	RunE: runK8sSecret,
+}
+
+func init() {
+	k8sSecretCmd.Flags().StringP("env", "e", "development", "Environment name")
+	k8sSecretCmd.Flags().String("name", "", "Name of the Secret (required)")
+	k8sSecretCmd.Flags().StringP("namespace", "n", "", "Namespace of the Secret (default: kubectl's current namespace)")
+	k8sSecretCmd.Flags().StringSlice("only", nil, "Include only these keys (e.g. DATABASE_URL,REDIS_URL)")
+	k8sSecretCmd.Flags().Bool("override", false, "Bypass keyway.toml branch and label policies for this environment (break-glass)")
+	_ = k8sSecretCmd.MarkFlagRequired("name")
+
+	k8sCmd.AddCommand(k8sSecretCmd)
+}
+
+// K8sSecretOptions contains the parsed flags for k8s secret
+type K8sSecretOptions struct {
+	EnvName   string
+	Name      string
+	Namespace string
+	Only      []string
+	Override  bool
+}
+
+// k8sSecret is the manifest k8s secret prints
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type k8sMetadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+var (
+	// k8sNamePattern is a DNS subdomain name, required for Secret names and namespaces
+	k8sNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// k8sKeyPattern is what Kubernetes accepts as a Secret data key
+	k8sKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+func runK8sSecret(cmd *cobra.Command, args []string) error {
+	var opts K8sSecretOptions
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Name, _ = cmd.Flags().GetString("name")
+	opts.Namespace, _ = cmd.Flags().GetString("namespace")
+	opts.Only, _ = cmd.Flags().GetStringSlice("only")
+	opts.Override, _ = cmd.Flags().GetBool("override")
+	return runK8sSecretWithDeps(opts, os.Stdout, defaultDeps)
+}
+
+// runK8sSecretWithDeps is the testable version of runK8sSecret. The manifest
+// goes to w, so it must not be mixed with UI output.
+func runK8sSecretWithDeps(opts K8sSecretOptions, w io.Writer, deps *Dependencies) error {
+	if err := validateK8sName("Secret name", opts.Name); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if opts.Namespace != "" {
+		if err := validateK8sName("namespace", opts.Namespace); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, _, err := ensureReadToken(context.Background(), deps, repo)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if err := enforceBranchPolicy(deps, opts.EnvName, opts.Override); err != nil {
+		return err
+	}
+
+	secrets, client, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+	if only := parseOnlyKeys(opts.Only); len(only) > 0 {
+		if err := selectKeys(secrets, only); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	}
+	// Like keyway run, expired keys are left out
+	dropExpiredSecrets(context.Background(), deps, client, repo, opts.EnvName, secrets)
+	keys := sortedSecretKeys(secrets)
+	if err := enforceLabelPolicy(deps, opts.EnvName, keys, opts.Override); err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, key := range keys {
+		if !k8sKeyPattern.MatchString(key) {
+			invalid = append(invalid, key)
+		}
+	}
+	if len(invalid) > 0 {
+		err := fmt.Errorf("not valid Secret keys (letters, digits, '-', '_' and '.' only): %s", strings.Join(invalid, ", "))
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	manifest, err := renderK8sSecret(opts, repo, secrets)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if _, err := w.Write(manifest); err != nil {
+		return err
+	}
+
+	recordActivity(deps, activity.Entry{
+		Action:      activity.ActionPull,
+		Repository:  repo,
+		Environment: opts.EnvName,
+		Keys:        keys,
+	})
+	return nil
+}
+
+// renderK8sSecret builds the Secret manifest, with data keys sorted
+func renderK8sSecret(opts K8sSecretOptions, repo string, secrets map[string]string) ([]byte, error) {
+	secret := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sMetadata{
+			Name:      opts.Name,
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "keyway"},
+			Annotations: map[string]string{
+				"keyway.sh/repository":  repo,
+				"keyway.sh/environment": opts.EnvName,
+			},
+		},
+		Type: "Opaque",
+		Data: make(map[string]string, len(secrets)),
+	}
+	for key, value := range secrets {
+		secret.Data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(secret); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// validateK8sName checks a Secret name or namespace before kubectl rejects it
+func validateK8sName(what, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s is required", what)
+	}
+	if len(name) > 253 || !k8sNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid %s %q: use lowercase letters, digits, '-' and '.'", what, name)
+	}
+	return nil
+}
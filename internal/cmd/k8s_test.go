@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunK8sSecretWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "REDIS_URL=redis://cache\nAPI_KEY=secret\n"}
+	var out bytes.Buffer
+
+	opts := K8sSecretOptions{EnvName: "production", Name: "app-secrets", Namespace: "web"}
+	if err := runK8sSecretWithDeps(opts, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secrets
+  namespace: web
+  labels:
+    app.kubernetes.io/managed-by: keyway
+  annotations:
+    keyway.sh/environment: production
+    keyway.sh/repository: owner/repo
+type: Opaque
+data:
+  API_KEY: c2VjcmV0
+  REDIS_URL: cmVkaXM6Ly9jYWNoZQ==
+`
+	if out.String() != want {
+		t.Errorf("unexpected manifest:\n%s", out.String())
+	}
+}
+
+func TestRunK8sSecretWithDeps_Only(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "REDIS_URL=redis://cache\nAPI_KEY=secret\n"}
+	var out bytes.Buffer
+
+	opts := K8sSecretOptions{EnvName: "production", Name: "api", Only: []string{"API_KEY"}}
+	if err := runK8sSecretWithDeps(opts, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "REDIS_URL") || strings.Contains(out.String(), "namespace:") {
+		t.Errorf("expected only API_KEY and no namespace, got:\n%s", out.String())
+	}
+}
+
+func TestRunK8sSecretWithDeps_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    K8sSecretOptions
+		content string
+		want    string
+	}{
+		{"missing name", K8sSecretOptions{}, "A=1\n", "Secret name is required"},
+		{"invalid name", K8sSecretOptions{Name: "App_Secrets"}, "A=1\n", "invalid Secret name"},
+		{"invalid namespace", K8sSecretOptions{Name: "app", Namespace: "-web"}, "A=1\n", "invalid namespace"},
+		{"invalid key", K8sSecretOptions{Name: "app"}, "A=1\nMY KEY=2\n", "not valid Secret keys"},
+		{"missing key", K8sSecretOptions{Name: "app", Only: []string{"B"}}, "A=1\n", "not found in vault: B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _, uiMock, _, apiMock := NewTestDeps()
+			apiMock.PullResponse = &api.PullSecretsResponse{Content: tt.content}
+			var out bytes.Buffer
+
+			tt.opts.EnvName = "production"
+			err := runK8sSecretWithDeps(tt.opts, &out, deps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q, got %v", tt.want, err)
+			}
+			if out.Len() != 0 {
+				t.Errorf("expected no manifest, got:\n%s", out.String())
+			}
+			if len(uiMock.ErrorCalls) == 0 {
+				t.Error("expected an error message")
+			}
+		})
+	}
+}
+
+func TestRunK8sSecretWithDeps_SkipsExpiredSecrets(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret\nTEMP_TOKEN=tmp_abc\n"}
+	apiMock.KeyMetadata = []api.KeyMetadata{
+		{Key: "API_KEY"},
+		{Key: "TEMP_TOKEN", ExpiresAt: timePtr(time.Now().Add(-time.Hour))},
+	}
+	var out bytes.Buffer
+
+	if err := runK8sSecretWithDeps(K8sSecretOptions{EnvName: "production", Name: "app"}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "TEMP_TOKEN") || !strings.Contains(out.String(), "API_KEY") {
+		t.Errorf("expected the expired key left out, got:\n%s", out.String())
+	}
+	if len(uiMock.WarnCalls) == 0 || !strings.Contains(uiMock.WarnCalls[0], "TEMP_TOKEN") {
+		t.Errorf("expected an expiry warning, got %v", uiMock.WarnCalls)
+	}
+}
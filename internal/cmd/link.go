@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link",
+	Short: "Point this project at a renamed or transferred repository",
+	Long: `Re-associate this project with its vault after the GitHub repository was
+renamed or transferred to another organization.
+
+If a vault exists for the new repository, keyway link records it in keyway.toml
+so every command uses it, whatever the git remote says. If the vault is still
+under the old name, keyway link offers to move it to the new repository first.
+Access is verified before anything is written.
+
+Examples:
+  keyway link --to neworg/newname
+  keyway link --to https://github.com/neworg/newname --yes`,
+	Args: cobra.NoArgs,
+	RunE: runLink,
+}
+
+func init() {
+	linkCmd.Flags().String("to", "", "New repository (owner/repo or GitHub URL)")
+	linkCmd.Flags().BoolP("yes", "y", false, "Move the vault without confirmation when needed")
+	_ = linkCmd.MarkFlagRequired("to")
+}
+
+// LinkOptions contains the parsed flags for the link command
+type LinkOptions struct {
+	To  string
+	Yes bool
+}
+
+// runLink is the entry point for the link command (uses default dependencies)
+func runLink(cmd *cobra.Command, args []string) error {
+	opts := LinkOptions{}
+	opts.To, _ = cmd.Flags().GetString("to")
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+
+	return runLinkWithDeps(opts, defaultDeps)
+}
+
+// runLinkWithDeps is the testable version of runLink
+func runLinkWithDeps(opts LinkOptions, deps *Dependencies) error {
+	deps.UI.Intro("link")
+
+	target, err := git.NormalizeRepo(opts.To)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Invalid repository %q (use owner/repo)", opts.To))
+		return err
+	}
+
+	// The current repository is only needed to move a vault left under the old name
+	current, _ := deps.Git.DetectRepo()
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	exists, err := client.CheckVaultExists(ctx, target)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Could not check the vault of %s: %s", target, err.Error()))
+		return err
+	}
+
+	if !exists {
+		if err := moveVault(ctx, deps, client, current, target, opts.Yes); err != nil {
+			return err
+		}
+	}
+
+	// Verify access before pointing the project at the vault
+	envs, err := client.GetVaultEnvironments(ctx, target)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("No access to the vault of %s: %s", target, err.Error()))
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Vault: %s %s", deps.UI.Value(target), deps.UI.Dim(fmt.Sprintf("(%d environments)", len(envs)))))
+
+	var content []byte
+	file := deps.Config.FindProjectFile()
+	if file == "" {
+		file = config.ProjectConfigFile
+	} else if content, err = deps.FS.ReadFile(file); err != nil {
+		deps.UI.Error(fmt.Sprintf("Cannot read %s: %s", file, err.Error()))
+		return err
+	}
+	if err := deps.FS.WriteFile(file, config.SetRepository(content, target), 0644); err != nil {
+		deps.UI.Error(fmt.Sprintf("Cannot write %s: %s", file, err.Error()))
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Linked to %s", target))
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Saved in %s; commit it so your team follows the move", file)))
+	return nil
+}
+
+// moveVault transfers the vault of the current repository to target, after
+// confirmation. It fails when there is no vault to move.
+func moveVault(ctx context.Context, deps *Dependencies, client api.APIClient, current, target string, yes bool) error {
+	if current == "" || current == target {
+		deps.UI.Error(fmt.Sprintf("No vault found for %s", target))
+		deps.UI.Message(deps.UI.Dim("Run keyway link from a clone of the old repository to move its vault, or keyway init to create one"))
+		return fmt.Errorf("vault not found")
+	}
+
+	exists, err := client.CheckVaultExists(ctx, current)
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Could not check the vault of %s: %s", current, err.Error()))
+		return err
+	}
+	if !exists {
+		deps.UI.Error(fmt.Sprintf("No vault found for %s or %s", target, current))
+		return fmt.Errorf("vault not found")
+	}
+
+	if !yes {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error(fmt.Sprintf("The vault is still under %s", current))
+			deps.UI.Message(deps.UI.Dim("Pass --yes to move it"))
+			return fmt.Errorf("confirmation required")
+		}
+		confirmed, err := deps.UI.Confirm(fmt.Sprintf("Move the vault of %s to %s?", current, target), true)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			deps.UI.Warn("Aborted.")
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	err = deps.UI.Spin("Moving vault...", func() error {
+		return client.TransferVault(ctx, current, target)
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to move the vault: %s", err.Error()))
+		return err
+	}
+	deps.UI.Success(fmt.Sprintf("Moved vault %s → %s", current, target))
+	return nil
+}
+
+// repoOverride returns the --repo flag, else the repository recorded in
+// keyway.toml by keyway link
+func repoOverride(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	project, err := defaultDeps.Config.LoadProject()
+	if err != nil || project == nil {
+		return ""
+	}
+	return project.Repository
+}
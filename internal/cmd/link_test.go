@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunLinkWithDeps_VaultAlreadyUnderNewName(t *testing.T) {
+	deps, _, _, uiMock, fs, apiMock := NewTestDeps()
+	apiMock.VaultsExisting = map[string]bool{"neworg/app": true}
+	apiMock.VaultEnvs = []string{"development", "production"}
+	deps.Config.(*MockConfigLoader).ProjectFile = "keyway.toml"
+	fs.Files["keyway.toml"] = []byte("[alias]\ndev = \"run\"\n")
+
+	err := runLinkWithDeps(LinkOptions{To: "https://github.com/neworg/app.git"}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.TransferredTo != "" {
+		t.Error("an existing vault must not be moved")
+	}
+	if got := string(fs.Written["keyway.toml"]); got != "repository = \"neworg/app\"\n[alias]\ndev = \"run\"\n" {
+		t.Errorf("unexpected keyway.toml:\n%s", got)
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected a success message")
+	}
+}
+
+func TestRunLinkWithDeps_MovesVaultFromOldName(t *testing.T) {
+	deps, gitMock, _, _, fs, apiMock := NewTestDeps()
+	gitMock.Repo = "oldorg/app"
+	apiMock.VaultsExisting = map[string]bool{"oldorg/app": true}
+
+	err := runLinkWithDeps(LinkOptions{To: "neworg/app", Yes: true}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.TransferredFrom != "oldorg/app" || apiMock.TransferredTo != "neworg/app" {
+		t.Errorf("expected the vault moved, got %q -> %q", apiMock.TransferredFrom, apiMock.TransferredTo)
+	}
+	if got := string(fs.Written["keyway.toml"]); got != "repository = \"neworg/app\"\n" {
+		t.Errorf("expected a new keyway.toml, got:\n%s", got)
+	}
+}
+
+func TestRunLinkWithDeps_MoveNeedsConfirmation(t *testing.T) {
+	deps, gitMock, _, uiMock, fs, apiMock := NewTestDeps()
+	gitMock.Repo = "oldorg/app"
+	apiMock.VaultsExisting = map[string]bool{"oldorg/app": true}
+
+	err := runLinkWithDeps(LinkOptions{To: "neworg/app"}, deps)
+
+	if err == nil {
+		t.Fatal("expected an error without --yes in a non-interactive session")
+	}
+	if apiMock.TransferredTo != "" || len(fs.Written) != 0 {
+		t.Error("nothing must change without confirmation")
+	}
+	if !strings.Contains(strings.Join(uiMock.MessageCalls, "\n"), "--yes") {
+		t.Errorf("expected a hint about --yes, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunLinkWithDeps_NoVault(t *testing.T) {
+	deps, gitMock, _, uiMock, fs, apiMock := NewTestDeps()
+	gitMock.Repo = "oldorg/app"
+	apiMock.VaultsExisting = map[string]bool{}
+
+	err := runLinkWithDeps(LinkOptions{To: "neworg/app", Yes: true}, deps)
+
+	if err == nil {
+		t.Fatal("expected an error when no vault exists")
+	}
+	if len(fs.Written) != 0 {
+		t.Error("keyway.toml must not be written")
+	}
+	if len(uiMock.ErrorCalls) == 0 || !strings.Contains(uiMock.ErrorCalls[0], "No vault found for neworg/app or oldorg/app") {
+		t.Errorf("unexpected errors: %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestRunLinkWithDeps_InvalidRepository(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runLinkWithDeps(LinkOptions{To: "not-a-repo"}, deps); err == nil {
+		t.Fatal("expected an error for an invalid repository")
+	}
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/hints"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check secrets against your framework's conventions",
+	Long: `Detect the project's frameworks (Next.js, Vite, Rails, Django) and check
+the secrets against their conventions:
+
+  - NEXT_PUBLIC_* and VITE_* keys are bundled into client code: a value that
+    looks like a credential, or a name like *_SECRET, is readable by anyone
+  - Rails' SECRET_KEY_BASE should be a long random value (bin/rails secret)
+  - Django's generated development key (django-insecure-...) and DEBUG should
+    not reach production
+
+The local env file is checked unless --env names a vault environment.
+
+Examples:
+  keyway lint
+  keyway lint --file .env.local
+  keyway lint --env production --strict`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringP("file", "f", ".env", "Env file to check")
+	lintCmd.Flags().StringP("env", "e", "", "Check this vault environment instead of the local file")
+	lintCmd.Flags().Bool("strict", false, "Exit with an error when there are findings")
+	lintCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// LintOptions contains the parsed flags for the lint command
+type LintOptions struct {
+	File       string
+	EnvName    string
+	Strict     bool
+	JSONOutput bool
+	// Dir is the project directory the frameworks are detected in
+	Dir string
+}
+
+// lintFinding is a key that breaks a framework convention
+type lintFinding struct {
+	Key       string `json:"key"`
+	Framework string `json:"framework"`
+	Message   string `json:"message"`
+}
+
+// runLint is the entry point for the lint command (uses default dependencies)
+func runLint(cmd *cobra.Command, args []string) error {
+	opts := LintOptions{Dir: "."}
+	opts.File, _ = cmd.Flags().GetString("file")
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Strict, _ = cmd.Flags().GetBool("strict")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runLintWithDeps(opts, defaultDeps)
+}
+
+// runLintWithDeps is the testable version of runLint
+func runLintWithDeps(opts LintOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("lint")
+	}
+
+	frameworks := hints.DetectFrameworks(opts.Dir)
+	if len(frameworks) == 0 {
+		if opts.JSONOutput {
+			fmt.Println("[]")
+			return nil
+		}
+		deps.UI.Message("No Next.js, Vite, Rails or Django project detected, nothing to check")
+		return nil
+	}
+
+	secrets, source, err := lintSecrets(opts, deps)
+	if err != nil {
+		return err
+	}
+
+	findings := lintFrameworkConventions(frameworks, secrets, opts.EnvName)
+
+	if opts.JSONOutput {
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+		output, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+	} else {
+		names := make([]string, len(frameworks))
+		for i, f := range frameworks {
+			names[i] = f.Name
+		}
+		deps.UI.Step(fmt.Sprintf("Checking %s against %s conventions", source, strings.Join(names, ", ")))
+		for _, f := range findings {
+			deps.UI.Warn(fmt.Sprintf("%s: %s", f.Key, f.Message))
+		}
+		if len(findings) == 0 {
+			deps.UI.Success("No issues found")
+		} else {
+			deps.UI.Outro(fmt.Sprintf("%d issue(s)", len(findings)))
+		}
+	}
+
+	if opts.Strict && len(findings) > 0 {
+		return fmt.Errorf("%d lint issue(s)", len(findings))
+	}
+	return nil
+}
+
+// lintSecrets reads the vault environment when one is given, else the local file
+func lintSecrets(opts LintOptions, deps *Dependencies) (map[string]string, string, error) {
+	if opts.EnvName == "" {
+		data, err := deps.FS.ReadFile(opts.File)
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Cannot read %s: %s", opts.File, err.Error()))
+			return nil, "", err
+		}
+		return env.Parse(string(data)), opts.File, nil
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return nil, "", err
+	}
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, "", err
+	}
+	secrets, _, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return nil, "", err
+	}
+	return secrets, opts.EnvName, nil
+}
+
+// publicSecretNames are name fragments of values that must stay on the server
+var publicSecretNames = []string{"SECRET", "PASSWORD", "PASSWD", "PRIVATE", "SERVICE_ROLE"}
+
+// publicSafePatterns are scan patterns for values designed to be public
+var publicSafePatterns = map[string]bool{"Stripe Publishable Key": true}
+
+// lintFrameworkConventions returns the keys of secrets that break the conventions
+// of the frameworks, sorted by key. envName is "" for a local file.
+func lintFrameworkConventions(frameworks []hints.Framework, secrets map[string]string, envName string) []lintFinding {
+	var findings []lintFinding
+	for _, key := range sortedSecretKeys(secrets) {
+		value := secrets[key]
+		for _, framework := range frameworks {
+			if message := lintKey(framework, key, value, envName); message != "" {
+				findings = append(findings, lintFinding{Key: key, Framework: framework.Name, Message: message})
+			}
+		}
+	}
+	return findings
+}
+
+func lintKey(framework hints.Framework, key, value, envName string) string {
+	if framework.PublicPrefix != "" && strings.HasPrefix(key, framework.PublicPrefix) {
+		for _, pattern := range secretPatterns {
+			if !publicSafePatterns[pattern.Name] && pattern.Regex.MatchString(value) {
+				return fmt.Sprintf("holds a %s, but %s exposes %s* keys to the browser", pattern.Name, framework.Name, framework.PublicPrefix)
+			}
+		}
+		name := strings.TrimPrefix(key, framework.PublicPrefix)
+		for _, fragment := range publicSecretNames {
+			if strings.Contains(name, fragment) {
+				return fmt.Sprintf("looks like a secret, but %s exposes %s* keys to the browser", framework.Name, framework.PublicPrefix)
+			}
+		}
+	}
+
+	switch framework {
+	case hints.Rails:
+		if key == "SECRET_KEY_BASE" && len(value) < 64 {
+			return "is shorter than 64 characters; generate one with bin/rails secret"
+		}
+	case hints.Django:
+		if (key == "SECRET_KEY" || key == "DJANGO_SECRET_KEY") && strings.HasPrefix(value, "django-insecure-") {
+			return "is Django's generated development key; replace it with a random value"
+		}
+		if (key == "DEBUG" || key == "DJANGO_DEBUG") && normalizeEnvName(envName) == "production" && isTruthy(value) {
+			return "is enabled in production, which shows stack traces and settings to visitors"
+		}
+	}
+	return ""
+}
+
+// isTruthy reports whether an env value means true
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/auth"
+	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/git"
+	"github.com/keywaysh/cli/internal/offline"
 	"github.com/keywaysh/cli/internal/ui"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
@@ -19,27 +22,71 @@ import (
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with GitHub via Keyway",
-	Long:  `Authenticate with GitHub using the device flow or a personal access token.`,
-	RunE:  runLogin,
+	Long: `Authenticate with GitHub using the device flow or a personal access token.
+
+In CI, --oidc exchanges the job's identity token for a short-lived session, so
+no static KEYWAY_TOKEN has to be stored. Setting KEYWAY_OIDC=<provider> does the
+same for every command without running keyway login first.
+
+Over SSH, or on Linux without a display, no browser is opened: open the
+printed URL on any device and enter the code. --no-browser does the same
+anywhere.
+
+Examples:
+  keyway login
+  keyway login --no-browser
+  keyway login --token
+  keyway login --oidc github-actions`,
+	RunE: runLogin,
 }
 
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Clear stored Keyway credentials",
-	RunE:  runLogout,
+	Long: `Sign out of the default account. When you are signed in with several
+accounts (see keyway account), the next one becomes the default; --all signs
+out of every account.`,
+	RunE: runLogout,
 }
 
 func init() {
 	loginCmd.Flags().Bool("token", false, "Authenticate using a GitHub fine-grained PAT")
+	loginCmd.Flags().String("oidc", "", "Exchange a CI identity token for a session (github-actions, gitlab)")
+	loginCmd.Flags().BoolVar(&noBrowserFlag, "no-browser", false, "Print the login URL and code without opening a browser")
+	logoutCmd.Flags().Bool("all", false, "Sign out of every account")
+}
+
+// noBrowserFlag is set by keyway login --no-browser
+var noBrowserFlag bool
+
+// shouldOpenBrowser reports whether the device login can open a browser on
+// this machine: not over SSH, where it would start on the remote host, nor on
+// Linux without a display, where xdg-open may start a text browser in the terminal
+func shouldOpenBrowser(getenv func(string) string, goos string) bool {
+	if noBrowserFlag {
+		return false
+	}
+	for _, name := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if getenv(name) != "" {
+			return false
+		}
+	}
+	if goos == "linux" && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	return true
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
 	ui.Intro("login")
 
 	useToken, _ := cmd.Flags().GetBool("token")
+	oidcProvider, _ := cmd.Flags().GetString("oidc")
 
 	var err error
-	if useToken {
+	if oidcProvider != "" {
+		err = runOIDCLogin(oidcProvider)
+	} else if useToken {
 		err = runTokenLogin()
 	} else {
 		_, err = RunDeviceLogin()
@@ -104,13 +151,17 @@ func RunDeviceLogin() (string, error) {
 	}
 
 	ui.Step(fmt.Sprintf("Code: %s", ui.Bold(start.UserCode)))
-	ui.Message(ui.Dim(fmt.Sprintf("Open: %s", verifyURL)))
-	ui.Message(ui.Dim("If the browser doesn't open, copy the URL above and paste it in your browser."))
-
-	// Try to open browser (in goroutine to avoid blocking in headless/CLI environments)
-	go func() {
-		_ = browser.OpenURL(verifyURL)
-	}()
+	if shouldOpenBrowser(os.Getenv, runtime.GOOS) {
+		ui.Message(ui.Dim(fmt.Sprintf("Open: %s", verifyURL)))
+		ui.Message(ui.Dim("If the browser doesn't open, copy the URL above and paste it in your browser."))
+
+		// Try to open browser (in goroutine to avoid blocking in headless/CLI environments)
+		go func() {
+			_ = browser.OpenURL(verifyURL)
+		}()
+	} else {
+		ui.Message(fmt.Sprintf("Open %s on any device with a browser and enter the code above.", ui.Link(verifyURL)))
+	}
 
 	pollInterval := time.Duration(start.Interval) * time.Second
 	if pollInterval < 3*time.Second {
@@ -151,6 +202,9 @@ func RunDeviceLogin() (string, error) {
 				return fmt.Errorf("login code expired")
 			case "denied":
 				return fmt.Errorf("login denied")
+			case "slow_down":
+				// RFC 8628: poll 5 seconds less often from now on
+				pollInterval += 5 * time.Second
 			}
 			// status == "pending", continue polling
 		}
@@ -255,17 +309,84 @@ func runTokenLogin() error {
 	return nil
 }
 
+func runOIDCLogin(providerName string) error {
+	resp, err := exchangeOIDCToken(defaultDeps, providerName)
+	if err != nil {
+		return err
+	}
+
+	store := auth.NewStore()
+	if err := store.SaveAuth(resp.KeywayToken, resp.Subject, resp.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	analytics.Track(analytics.EventLogin, map[string]interface{}{
+		"method":   "oidc",
+		"provider": providerName,
+	})
+
+	if resp.Subject != "" {
+		ui.Success(fmt.Sprintf("Logged in as %s via %s OIDC", ui.Value(resp.Subject), providerName))
+	} else {
+		ui.Success(fmt.Sprintf("Logged in via %s OIDC", providerName))
+	}
+	return nil
+}
+
+// exchangeOIDCToken fetches the CI provider's identity token and trades it
+// for a short-lived Keyway token
+func exchangeOIDCToken(deps *Dependencies, providerName string) (*api.OIDCExchangeResponse, error) {
+	provider, err := auth.GetOIDCProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	idToken, err := provider.IDToken(ctx, config.GetAPIURL())
+	if err != nil {
+		return nil, err
+	}
+
+	client := deps.APIFactory.NewClient("")
+	resp, err := client.ExchangeOIDCToken(ctx, provider.Name(), idToken)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC token exchange failed: %w", err)
+	}
+	if resp == nil || resp.KeywayToken == "" {
+		return nil, fmt.Errorf("OIDC token exchange returned no token")
+	}
+	return resp, nil
+}
+
 func runLogout(cmd *cobra.Command, args []string) error {
 	ui.Intro("logout")
 
+	all, _ := cmd.Flags().GetBool("all")
 	store := auth.NewStore()
-	if err := store.ClearAuth(); err != nil {
+	var next string
+	var err error
+	if all {
+		err = store.RemoveAllAccounts()
+	} else if current, getErr := store.GetAuth(); getErr == nil && current != nil && current.GitHubLogin != "" {
+		next, err = store.RemoveAccount(current.GitHubLogin)
+	} else {
+		err = store.ClearAuth()
+	}
+	if err != nil {
 		ui.Error(err.Error())
 		return err
 	}
 
+	// Secrets cached for offline use belong to the session
+	if cache, err := offline.Default(); err == nil {
+		_ = cache.Clear()
+	}
+
 	ui.Success("Logged out of Keyway")
-	ui.Message(ui.Dim(fmt.Sprintf("Auth cache cleared: %s", store.GetConfigPath())))
+	ui.Message(ui.Dim(fmt.Sprintf("Auth cache cleared: %s", store.Location())))
+	if next != "" {
+		ui.Info(fmt.Sprintf("Still signed in as %s, now the default account", ui.Value("@"+next)))
+	}
 
 	return nil
 }
@@ -277,9 +398,18 @@ func EnsureLogin() (string, error) {
 		return token, nil
 	}
 
-	// Check stored auth
+	// In CI, exchange the job's identity token instead of using a stored session
+	if provider := os.Getenv("KEYWAY_OIDC"); provider != "" {
+		resp, err := exchangeOIDCToken(defaultDeps, provider)
+		if err != nil {
+			return "", err
+		}
+		return resp.KeywayToken, nil
+	}
+
+	// Check stored auth, of the account this repository uses
 	store := auth.NewStore()
-	storedAuth, err := store.GetAuth()
+	storedAuth, err := sessionForRepo(store)
 	if err == nil && storedAuth != nil && storedAuth.KeywayToken != "" {
 		return storedAuth.KeywayToken, nil
 	}
@@ -297,6 +427,15 @@ func EnsureLogin() (string, error) {
 	return RunDeviceLogin()
 }
 
+// hasSession reports whether a token is available without logging in
+func hasSession() bool {
+	if os.Getenv("KEYWAY_TOKEN") != "" || os.Getenv("KEYWAY_OIDC") != "" {
+		return true
+	}
+	storedAuth, err := auth.NewStore().GetAuth()
+	return err == nil && storedAuth != nil && storedAuth.KeywayToken != ""
+}
+
 // Helper functions to avoid importing strings package
 func trimSpace(s string) string {
 	start := 0
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/auth"
 )
 
 func TestTrimSpace(t *testing.T) {
@@ -149,3 +153,72 @@ func TestGetRepoIdsWithFallbackAndDeps_ValidRepo(t *testing.T) {
 		t.Logf("got result: %+v", result)
 	}
 }
+
+func TestExchangeOIDCToken_Success(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.OIDCExchangeResponse = &api.OIDCExchangeResponse{KeywayToken: "kw_short_lived", Subject: "repo:owner/repo"}
+	t.Setenv(auth.GitLabIDTokenEnv, "gitlab-id-token")
+
+	resp, err := exchangeOIDCToken(deps, "gitlab")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.KeywayToken != "kw_short_lived" {
+		t.Errorf("unexpected token %q", resp.KeywayToken)
+	}
+	if apiMock.OIDCExchangeToken != "gitlab-id-token" {
+		t.Errorf("expected the identity token to be exchanged, got %q", apiMock.OIDCExchangeToken)
+	}
+}
+
+func TestExchangeOIDCToken_Errors(t *testing.T) {
+	t.Setenv(auth.GitLabIDTokenEnv, "gitlab-id-token")
+
+	deps, _, _, _, _, _ := NewTestDeps()
+	if _, err := exchangeOIDCToken(deps, "jenkins"); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.OIDCExchangeError = &api.APIError{StatusCode: 403, Detail: "repository not allowed"}
+	if _, err := exchangeOIDCToken(deps, "gitlab"); err == nil || !strings.Contains(err.Error(), "repository not allowed") {
+		t.Errorf("expected exchange error, got %v", err)
+	}
+
+	deps, _, _, _, _, apiMock = NewTestDeps()
+	apiMock.OIDCExchangeResponse = &api.OIDCExchangeResponse{}
+	if _, err := exchangeOIDCToken(deps, "gitlab"); err == nil {
+		t.Error("expected error when no token is returned")
+	}
+}
+
+func TestShouldOpenBrowser(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		goos string
+		want bool
+	}{
+		{"desktop linux", map[string]string{"DISPLAY": ":0"}, "linux", true},
+		{"wayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "linux", true},
+		{"headless linux", map[string]string{}, "linux", false},
+		{"ssh session", map[string]string{"DISPLAY": ":0", "SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, "linux", false},
+		{"ssh into a mac", map[string]string{"SSH_TTY": "/dev/ttys001"}, "darwin", false},
+		{"mac", map[string]string{}, "darwin", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := shouldOpenBrowser(getenv, tt.goos); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	noBrowserFlag = true
+	defer func() { noBrowserFlag = false }()
+	if shouldOpenBrowser(func(string) string { return "" }, "darwin") {
+		t.Error("expected --no-browser to win")
+	}
+}
@@ -3,10 +3,24 @@ package cmd
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
 
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/syncbase"
 )
 
+// TestMain keeps retried uploads from sleeping through the real backoff
+func TestMain(m *testing.M) {
+	api.RetryBaseWait = 0
+	os.Exit(m.Run())
+}
+
 // MockGitClient is a mock implementation of GitClient
 type MockGitClient struct {
 	Repo             string
@@ -15,6 +29,8 @@ type MockGitClient struct {
 	AddGitignoreErr  error
 	IsGitRepo        bool
 	Monorepo         MonorepoInfo
+	Branch           string
+	BranchError      error
 }
 
 func (m *MockGitClient) DetectRepo() (string, error) {
@@ -37,16 +53,152 @@ func (m *MockGitClient) DetectMonorepo() MonorepoInfo {
 	return m.Monorepo
 }
 
+func (m *MockGitClient) CurrentBranch() (string, error) {
+	return m.Branch, m.BranchError
+}
+
+// MockConfigLoader is a mock implementation of ConfigLoader
+type MockConfigLoader struct {
+	Project     *config.ProjectConfig
+	Error       error
+	ProjectFile string
+}
+
+func (m *MockConfigLoader) LoadProject() (*config.ProjectConfig, error) {
+	if m.Project == nil && m.Error == nil {
+		return &config.ProjectConfig{}, nil
+	}
+	return m.Project, m.Error
+}
+
+func (m *MockConfigLoader) FindProjectFile() string {
+	return m.ProjectFile
+}
+
+// MockActivityLog implements ActivityLog for testing
+type MockActivityLog struct {
+	IsEnabled   bool
+	Entries     []activity.Entry
+	RecordError error
+	SinceError  error
+	SinceCalls  []time.Time
+}
+
+func (m *MockActivityLog) Enabled() bool {
+	return m.IsEnabled
+}
+
+func (m *MockActivityLog) Record(entry activity.Entry) error {
+	if m.RecordError != nil {
+		return m.RecordError
+	}
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+func (m *MockActivityLog) Since(t time.Time) ([]activity.Entry, error) {
+	m.SinceCalls = append(m.SinceCalls, t)
+	if m.SinceError != nil {
+		return nil, m.SinceError
+	}
+	var entries []activity.Entry
+	for _, e := range m.Entries {
+		if !e.Time.Before(t) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// MockEnvironmentMemory implements EnvironmentMemory for testing
+type MockEnvironmentMemory struct {
+	LastEnvs map[string]string // keyed by repo
+}
+
+func (m *MockEnvironmentMemory) Last(repo string) string {
+	return m.LastEnvs[repo]
+}
+
+func (m *MockEnvironmentMemory) Remember(repo, env string) error {
+	if m.LastEnvs == nil {
+		m.LastEnvs = make(map[string]string)
+	}
+	m.LastEnvs[repo] = env
+	return nil
+}
+
+// MockAccountStore implements AccountStore for testing
+type MockAccountStore struct {
+	List        []AccountInfo
+	ListError   error
+	SwitchError error
+	Switched    []string
+	Bindings    map[string]string // scope to login
+}
+
+func (m *MockAccountStore) Accounts() ([]AccountInfo, error) {
+	return m.List, m.ListError
+}
+
+func (m *MockAccountStore) SwitchAccount(login string) error {
+	m.Switched = append(m.Switched, login)
+	return m.SwitchError
+}
+
+func (m *MockAccountStore) BindAccount(scope, login string) error {
+	if m.Bindings == nil {
+		m.Bindings = make(map[string]string)
+	}
+	m.Bindings[scope] = login
+	return nil
+}
+
+func (m *MockAccountStore) AccountFor(repo string) string {
+	if login := m.Bindings[repo]; login != "" {
+		return login
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	return m.Bindings[owner]
+}
+
+// MockSyncBaseStore implements SyncBaseStore for testing
+type MockSyncBaseStore struct {
+	Snapshots map[string]syncbase.Snapshot // keyed by repo/env
+	LoadError error
+	SaveError error
+}
+
+func (m *MockSyncBaseStore) Load(repo, env string) (syncbase.Snapshot, bool, error) {
+	snap, ok := m.Snapshots[repo+"/"+env]
+	return snap, ok, m.LoadError
+}
+
+func (m *MockSyncBaseStore) Save(repo, env string, snap syncbase.Snapshot) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	if m.Snapshots == nil {
+		m.Snapshots = make(map[string]syncbase.Snapshot)
+	}
+	m.Snapshots[repo+"/"+env] = snap
+	return nil
+}
+
 // MockAuthProvider is a mock implementation of AuthProvider
 type MockAuthProvider struct {
-	Token string
-	Error error
+	Token     string
+	Error     error
+	NoSession bool // HasSession reports no token, as on a machine never logged in
 }
 
 func (m *MockAuthProvider) EnsureLogin() (string, error) {
 	return m.Token, m.Error
 }
 
+func (m *MockAuthProvider) HasSession() bool {
+	return !m.NoSession
+}
+
 // MockUIProvider is a mock implementation of UIProvider
 type MockUIProvider struct {
 	Interactive     bool
@@ -54,6 +206,9 @@ type MockUIProvider struct {
 	ConfirmError    error
 	SelectResult    string
 	SelectError     error
+	// MultiSelectResult is returned by MultiSelect; nil keeps the initial selection
+	MultiSelectResult []string
+	MultiSelectError  error
 	PasswordResult  string
 	PasswordError   error
 	SpinError       error
@@ -69,6 +224,9 @@ type MockUIProvider struct {
 	MessageCalls     []string
 	ConfirmCalls     []string
 	SelectCalls      []string
+	SelectOptions    [][]string
+	MultiSelectCalls   []string
+	MultiSelectOptions [][]string
 	PasswordCalls    []string
 	DiffAddedCalls   []string
 	DiffChangedCalls []string
@@ -91,8 +249,20 @@ func (m *MockUIProvider) Confirm(message string, defaultValue bool) (bool, error
 }
 func (m *MockUIProvider) Select(message string, options []string) (string, error) {
 	m.SelectCalls = append(m.SelectCalls, message)
+	m.SelectOptions = append(m.SelectOptions, options)
 	return m.SelectResult, m.SelectError
 }
+func (m *MockUIProvider) MultiSelect(message string, options []string, selected []string) ([]string, error) {
+	m.MultiSelectCalls = append(m.MultiSelectCalls, message)
+	m.MultiSelectOptions = append(m.MultiSelectOptions, options)
+	if m.MultiSelectError != nil {
+		return nil, m.MultiSelectError
+	}
+	if m.MultiSelectResult == nil {
+		return selected, nil
+	}
+	return m.MultiSelectResult, nil
+}
 func (m *MockUIProvider) Password(prompt string) (string, error) {
 	m.PasswordCalls = append(m.PasswordCalls, prompt)
 	return m.PasswordResult, m.PasswordError
@@ -120,6 +290,7 @@ type MockFileSystem struct {
 	WriteError error
 	ReadError  error
 	Written    map[string][]byte
+	Removed    []string
 }
 
 func NewMockFileSystem() *MockFileSystem {
@@ -147,25 +318,77 @@ func (m *MockFileSystem) WriteFile(name string, data []byte, perm uint32) error
 	return nil
 }
 
+func (m *MockFileSystem) Remove(name string) error {
+	m.Removed = append(m.Removed, name)
+	delete(m.Written, name)
+	return nil
+}
+
 // MockAPIClient is a mock implementation of api.APIClient
 type MockAPIClient struct {
 	VaultEnvs                          []string
 	VaultEnvsError                     error
 	PullResponse                       *api.PullSecretsResponse
+	PullResponses                      map[string]*api.PullSecretsResponse // Per-environment responses, takes precedence over PullResponse
 	PullError                          error
+	PullKeys                           []string // Captures the key filter of the last PullSecrets call
+	SnapshotResponse                   *api.PullSecretsResponse
+	SnapshotError                      error
+	SnapshotRef                        *api.SnapshotRef // Captures the last PullSecretsSnapshot call
 	PushResponse                       *api.PushSecretsResponse
 	PushError                          error
 	PushedSecrets                      map[string]string // Captures secrets sent in PushSecrets call
+	PushErrors                         []error           // Returned by successive PushSecrets calls before PushError
+	PushCalls                          int
+	PatchSupported                     bool              // PatchSecrets answers 404 unless set
+	Patch                              *api.SecretsPatch // Captures the last PatchSecrets call
+	PatchCalls                         int
+	PatchErrors                        []error // Returned in order by PatchSecrets, before PushError
+	OnPatch                            func()  // Called on each PatchSecrets, e.g. to apply it to PullResponse
 	InitResponse                       *api.InitVaultResponse
 	InitError                          error
 	VaultExists                        bool
 	VaultExistsError                   error
 	VaultDetails                       *api.VaultDetails
 	VaultDetailsError                  error
+	OIDCExchangeResponse               *api.OIDCExchangeResponse
+	OIDCExchangeError                  error
+	OIDCExchangeToken                  string // Captures the identity token sent to ExchangeOIDCToken
 	ValidateTokenResponse              *api.ValidateTokenResponse
 	ValidateTokenError                 error
 	CheckGitHubAppInstallationResponse *api.GitHubAppInstallationStatus
 	CheckGitHubAppInstallationError    error
+	VaultActivity                      *api.VaultActivity
+	VaultActivityError                 error
+	PublicVault                        *api.PublicVault
+	PublicVaultError                   error
+	VaultsExisting                     map[string]bool // Per-repository CheckVaultExists results, takes precedence over VaultExists
+	TransferError                      error
+	TransferredFrom                    string // Captures TransferVault calls
+	TransferredTo                      string
+	CreateEnvError                     error
+	CreatedEnvs                        []string // Captures CreateEnvironment calls
+	DeleteEnvError                     error
+	DeletedEnvs                        []string // Captures DeleteEnvironment calls
+	EnvAccess                          []api.EnvironmentAccess
+	EnvAccessError                     error
+	KeyMetadata                        []api.KeyMetadata
+	KeyMetadataError                   error
+	KeyMetadataByEnv                   map[string][]api.KeyMetadata // Per-environment metadata, takes precedence over KeyMetadata
+	ExpiryError                        error
+	ExpirySet                          map[string]*time.Time // Captures SetSecretExpiry calls by key
+	Webhooks                           []api.Webhook
+	WebhooksError                      error
+	WebhookCreated                     *api.CreateWebhookRequest // Captures CreateWebhook calls
+	WebhooksDeleted                    []string
+	WebhookDelivery                    *api.WebhookDelivery
+	APIKeyCreated                      *api.CreateAPIKeyRequest // Captures CreateAPIKey calls
+	APIKeyError                        error
+	SharedValues                       map[string]string
+	SharedValuesError                  error
+	SharedSetError                     error
+	SharedSet                          map[string]string // Captures SetSharedValue calls
+	SharedDeleted                      []string
 }
 
 func (m *MockAPIClient) StartDeviceLogin(ctx context.Context, repository string, repoIds *api.RepoIds) (*api.DeviceStartResponse, error) {
@@ -174,6 +397,10 @@ func (m *MockAPIClient) StartDeviceLogin(ctx context.Context, repository string,
 func (m *MockAPIClient) PollDeviceLogin(ctx context.Context, deviceCode string) (*api.DevicePollResponse, error) {
 	return nil, nil
 }
+func (m *MockAPIClient) ExchangeOIDCToken(ctx context.Context, provider, idToken string) (*api.OIDCExchangeResponse, error) {
+	m.OIDCExchangeToken = idToken
+	return m.OIDCExchangeResponse, m.OIDCExchangeError
+}
 func (m *MockAPIClient) ValidateToken(ctx context.Context) (*api.ValidateTokenResponse, error) {
 	return m.ValidateTokenResponse, m.ValidateTokenError
 }
@@ -187,21 +414,143 @@ func (m *MockAPIClient) InitVault(ctx context.Context, repoFullName string) (*ap
 	return m.InitResponse, m.InitError
 }
 func (m *MockAPIClient) CheckVaultExists(ctx context.Context, repoFullName string) (bool, error) {
+	if m.VaultsExisting != nil {
+		return m.VaultsExisting[repoFullName], m.VaultExistsError
+	}
 	return m.VaultExists, m.VaultExistsError
 }
+func (m *MockAPIClient) TransferVault(ctx context.Context, fromRepo, toRepo string) error {
+	m.TransferredFrom, m.TransferredTo = fromRepo, toRepo
+	return m.TransferError
+}
+func (m *MockAPIClient) CreateEnvironment(ctx context.Context, repoFullName, name string) error {
+	if m.CreateEnvError != nil {
+		return m.CreateEnvError
+	}
+	m.CreatedEnvs = append(m.CreatedEnvs, name)
+	return nil
+}
+func (m *MockAPIClient) DeleteEnvironment(ctx context.Context, repoFullName, name string) error {
+	if m.DeleteEnvError != nil {
+		return m.DeleteEnvError
+	}
+	m.DeletedEnvs = append(m.DeletedEnvs, name)
+	return nil
+}
+func (m *MockAPIClient) GetEnvironmentAccess(ctx context.Context, repoFullName string) ([]api.EnvironmentAccess, error) {
+	return m.EnvAccess, m.EnvAccessError
+}
 func (m *MockAPIClient) GetVaultDetails(ctx context.Context, repoFullName string) (*api.VaultDetails, error) {
 	return m.VaultDetails, m.VaultDetailsError
 }
 func (m *MockAPIClient) GetVaultEnvironments(ctx context.Context, repoFullName string) ([]string, error) {
 	return m.VaultEnvs, m.VaultEnvsError
 }
+func (m *MockAPIClient) PatchSecrets(ctx context.Context, repo, env string, patch api.SecretsPatch) (*api.PushSecretsResponse, error) {
+	if !m.PatchSupported {
+		return nil, &api.APIError{StatusCode: 404, Detail: "Not found"}
+	}
+	m.Patch = &patch
+	m.PatchCalls++
+	if m.OnPatch != nil {
+		m.OnPatch()
+	}
+	if len(m.PatchErrors) > 0 {
+		err := m.PatchErrors[0]
+		m.PatchErrors = m.PatchErrors[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.PushResponse, m.PushError
+}
+
 func (m *MockAPIClient) PushSecrets(ctx context.Context, repo, env string, secrets map[string]string) (*api.PushSecretsResponse, error) {
 	m.PushedSecrets = secrets
+	m.PushCalls++
+	if len(m.PushErrors) > 0 {
+		err := m.PushErrors[0]
+		m.PushErrors = m.PushErrors[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
 	return m.PushResponse, m.PushError
 }
-func (m *MockAPIClient) PullSecrets(ctx context.Context, repo, env string) (*api.PullSecretsResponse, error) {
+func (m *MockAPIClient) GetVaultActivity(ctx context.Context, repoFullName string) (*api.VaultActivity, error) {
+	return m.VaultActivity, m.VaultActivityError
+}
+func (m *MockAPIClient) GetPublicVault(ctx context.Context, repoFullName string) (*api.PublicVault, error) {
+	return m.PublicVault, m.PublicVaultError
+}
+func (m *MockAPIClient) PullSecrets(ctx context.Context, repo, env string, keys ...string) (*api.PullSecretsResponse, error) {
+	m.PullKeys = keys
+	if m.PullResponses != nil {
+		if resp, ok := m.PullResponses[env]; ok {
+			return resp, m.PullError
+		}
+		return nil, &api.APIError{StatusCode: 404, Detail: "Environment not found"}
+	}
 	return m.PullResponse, m.PullError
 }
+func (m *MockAPIClient) PullSecretsSnapshot(ctx context.Context, repo, env string, ref api.SnapshotRef) (*api.PullSecretsResponse, error) {
+	m.SnapshotRef = &ref
+	return m.SnapshotResponse, m.SnapshotError
+}
+func (m *MockAPIClient) GetSecretsMetadata(ctx context.Context, repo, env string) ([]api.KeyMetadata, error) {
+	if metadata, ok := m.KeyMetadataByEnv[env]; ok {
+		return metadata, m.KeyMetadataError
+	}
+	return m.KeyMetadata, m.KeyMetadataError
+}
+func (m *MockAPIClient) SetSecretExpiry(ctx context.Context, repo, env, key string, expiresAt *time.Time) error {
+	if m.ExpiryError != nil {
+		return m.ExpiryError
+	}
+	if m.ExpirySet == nil {
+		m.ExpirySet = make(map[string]*time.Time)
+	}
+	m.ExpirySet[key] = expiresAt
+	return nil
+}
+func (m *MockAPIClient) ListWebhooks(ctx context.Context, repoFullName string) ([]api.Webhook, error) {
+	return m.Webhooks, m.WebhooksError
+}
+func (m *MockAPIClient) CreateWebhook(ctx context.Context, repoFullName string, req api.CreateWebhookRequest) (*api.Webhook, error) {
+	if m.WebhooksError != nil {
+		return nil, m.WebhooksError
+	}
+	m.WebhookCreated = &req
+	return &api.Webhook{ID: "wh_1", URL: req.URL, Events: req.Events, Environments: req.Environments, Secret: "whsec_abc"}, nil
+}
+func (m *MockAPIClient) DeleteWebhook(ctx context.Context, repoFullName, id string) error {
+	m.WebhooksDeleted = append(m.WebhooksDeleted, id)
+	return m.WebhooksError
+}
+func (m *MockAPIClient) TestWebhook(ctx context.Context, repoFullName, id string) (*api.WebhookDelivery, error) {
+	return m.WebhookDelivery, m.WebhooksError
+}
+func (m *MockAPIClient) CreateAPIKey(ctx context.Context, req api.CreateAPIKeyRequest) (*api.APIKey, error) {
+	if m.APIKeyError != nil {
+		return nil, m.APIKeyError
+	}
+	m.APIKeyCreated = &req
+	return &api.APIKey{ID: "key_1", Name: req.Name, Scopes: req.Scopes, Repositories: req.Repositories, ExpiresAt: req.ExpiresAt, Token: "kw_machine_abc"}, nil
+}
+func (m *MockAPIClient) GetSharedValues(ctx context.Context, repoFullName string) (map[string]string, error) {
+	return m.SharedValues, m.SharedValuesError
+}
+func (m *MockAPIClient) SetSharedValue(ctx context.Context, repoFullName, name, value string) error {
+	if m.SharedSet == nil {
+		m.SharedSet = make(map[string]string)
+	}
+	m.SharedSet[name] = value
+	return m.SharedSetError
+}
+func (m *MockAPIClient) DeleteSharedValue(ctx context.Context, repoFullName, name string) error {
+	m.SharedDeleted = append(m.SharedDeleted, name)
+	return m.SharedSetError
+}
 func (m *MockAPIClient) GetProviders(ctx context.Context) ([]api.Provider, error) {
 	return nil, nil
 }
@@ -238,10 +587,12 @@ func (m *MockAPIClient) StartOrganizationTrial(ctx context.Context, orgLogin str
 
 // MockAPIFactory creates mock API clients
 type MockAPIFactory struct {
-	Client api.APIClient
+	Client    api.APIClient
+	LastToken string // Token of the last NewClient call
 }
 
 func (m *MockAPIFactory) NewClient(token string) api.APIClient {
+	m.LastToken = token
 	return m.Client
 }
 
@@ -268,6 +619,15 @@ type MockCommandRunner struct {
 	LastCommand   string
 	LastArgs      []string
 	LastSecrets   map[string]string
+	LastEnviron   []string // nil unless RunCommandWithEnv was used
+	LastLogPath   string
+	LastDir       string // set by Chdir
+	Execed        bool // set by ExecCommand
+	ExitCode      int
+	// Output is written to out by RunCommandCaptured
+	Output string
+	// OnRun is called with the arguments while the command "runs"
+	OnRun func(name string, args []string)
 }
 
 func (m *MockCommandRunner) RunCommand(name string, args []string, secrets map[string]string) error {
@@ -277,6 +637,43 @@ func (m *MockCommandRunner) RunCommand(name string, args []string, secrets map[s
 	return m.RunError
 }
 
+func (m *MockCommandRunner) RunCommandWithEnv(name string, args []string, environ []string, secrets map[string]string) error {
+	m.LastEnviron = environ
+	return m.RunCommand(name, args, secrets)
+}
+
+func (m *MockCommandRunner) RunCommandWithLog(name string, args []string, environ []string, secrets map[string]string, logPath string) error {
+	m.LastLogPath = logPath
+	return m.RunCommandWithEnv(name, args, environ, secrets)
+}
+
+func (m *MockCommandRunner) RunCommandStatus(name string, args []string, secrets map[string]string) (int, error) {
+	m.LastCommand = name
+	m.LastArgs = args
+	m.LastSecrets = secrets
+	if m.OnRun != nil {
+		m.OnRun(name, args)
+	}
+	return m.ExitCode, m.RunError
+}
+
+func (m *MockCommandRunner) RunCommandCaptured(name string, args []string, environ []string, secrets map[string]string, out io.Writer) (int, error) {
+	m.LastEnviron = environ
+	_, _ = io.WriteString(out, m.Output)
+	return m.RunCommandStatus(name, args, secrets)
+}
+
+func (m *MockCommandRunner) ExecCommand(name string, args []string, environ []string, secrets map[string]string) error {
+	m.Execed = true
+	m.LastEnviron = environ
+	return m.RunCommand(name, args, secrets)
+}
+
+func (m *MockCommandRunner) Chdir(dir string) error {
+	m.LastDir = dir
+	return nil
+}
+
 // MockBrowserOpener is a mock implementation of BrowserOpener
 type MockBrowserOpener struct {
 	OpenError error
@@ -302,12 +699,25 @@ func (m *MockAuthStore) GetAuth() (*StoredAuthInfo, error) {
 type MockHTTPClient struct {
 	StatusCode int
 	HeadError  error
+	PostError  error
+	Posts      []MockHTTPPost
+}
+
+// MockHTTPPost records a PostJSON call
+type MockHTTPPost struct {
+	URL  string
+	Body string
 }
 
 func (m *MockHTTPClient) Head(url string) (int, error) {
 	return m.StatusCode, m.HeadError
 }
 
+func (m *MockHTTPClient) PostJSON(url string, body []byte) (int, error) {
+	m.Posts = append(m.Posts, MockHTTPPost{URL: url, Body: string(body)})
+	return m.StatusCode, m.PostError
+}
+
 // MockFileInfo is a mock implementation of FileInfo
 type MockFileInfo struct {
 	FileName  string
@@ -365,6 +775,29 @@ func (m *MockFileStat) Stat(name string) (FileInfo, error) {
 	return nil, errors.New("file not found")
 }
 
+// MockClipboard is a mock implementation of ClipboardProvider
+type MockClipboard struct {
+	CopyError      error
+	ScheduleError  error
+	Copied         string
+	ClearScheduled bool
+	ClearAfter     time.Duration
+}
+
+func (m *MockClipboard) Copy(text string) error {
+	if m.CopyError != nil {
+		return m.CopyError
+	}
+	m.Copied = text
+	return nil
+}
+
+func (m *MockClipboard) ScheduleClear(text string, after time.Duration) error {
+	m.ClearScheduled = true
+	m.ClearAfter = after
+	return m.ScheduleError
+}
+
 // NewTestDeps creates a Dependencies with all mocks for testing
 func NewTestDeps() (*Dependencies, *MockGitClient, *MockAuthProvider, *MockUIProvider, *MockFileSystem, *MockAPIClient) {
 	git := &MockGitClient{
@@ -384,6 +817,11 @@ func NewTestDeps() (*Dependencies, *MockGitClient, *MockAuthProvider, *MockUIPro
 	stat := NewMockFileStat()
 	authStore := &MockAuthStore{}
 	httpClient := &MockHTTPClient{StatusCode: 200}
+	clipboard := &MockClipboard{}
+	configLoader := &MockConfigLoader{}
+	activityLog := &MockActivityLog{}
+	syncBase := &MockSyncBaseStore{}
+	runBase := &MockSyncBaseStore{}
 
 	deps := &Dependencies{
 		Git:        git,
@@ -398,6 +836,13 @@ func NewTestDeps() (*Dependencies, *MockGitClient, *MockAuthProvider, *MockUIPro
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Clipboard:  clipboard,
+		Config:     configLoader,
+		Activity:   activityLog,
+		SyncBase:   syncBase,
+		RunBase:    runBase,
+		LastEnv:    &MockEnvironmentMemory{},
+		Accounts:   &MockAccountStore{},
 	}
 
 	return deps, git, auth, ui, fs, apiClient
@@ -422,6 +867,11 @@ func NewTestDepsWithEnv() (*Dependencies, *MockGitClient, *MockAuthProvider, *Mo
 	stat := NewMockFileStat()
 	authStore := &MockAuthStore{}
 	httpClient := &MockHTTPClient{StatusCode: 200}
+	clipboard := &MockClipboard{}
+	configLoader := &MockConfigLoader{}
+	activityLog := &MockActivityLog{}
+	syncBase := &MockSyncBaseStore{}
+	runBase := &MockSyncBaseStore{}
 
 	deps := &Dependencies{
 		Git:        git,
@@ -436,6 +886,13 @@ func NewTestDepsWithEnv() (*Dependencies, *MockGitClient, *MockAuthProvider, *Mo
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Clipboard:  clipboard,
+		Config:     configLoader,
+		Activity:   activityLog,
+		SyncBase:   syncBase,
+		RunBase:    runBase,
+		LastEnv:    &MockEnvironmentMemory{},
+		Accounts:   &MockAccountStore{},
 	}
 
 	return deps, git, auth, ui, fs, envHelper, apiClient
@@ -460,6 +917,11 @@ func NewTestDepsWithRunner() (*Dependencies, *MockGitClient, *MockAuthProvider,
 	stat := NewMockFileStat()
 	authStore := &MockAuthStore{}
 	httpClient := &MockHTTPClient{StatusCode: 200}
+	clipboard := &MockClipboard{}
+	configLoader := &MockConfigLoader{}
+	activityLog := &MockActivityLog{}
+	syncBase := &MockSyncBaseStore{}
+	runBase := &MockSyncBaseStore{}
 
 	deps := &Dependencies{
 		Git:        git,
@@ -474,6 +936,13 @@ func NewTestDepsWithRunner() (*Dependencies, *MockGitClient, *MockAuthProvider,
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Clipboard:  clipboard,
+		Config:     configLoader,
+		Activity:   activityLog,
+		SyncBase:   syncBase,
+		RunBase:    runBase,
+		LastEnv:    &MockEnvironmentMemory{},
+		Accounts:   &MockAccountStore{},
 	}
 
 	return deps, git, auth, ui, cmdRunner, apiClient
@@ -508,6 +977,11 @@ func NewTestDepsForDoctor() (*Dependencies, *MockGitClient, *MockUIProvider, *Mo
 		},
 	}
 	httpClient := &MockHTTPClient{StatusCode: 200}
+	clipboard := &MockClipboard{}
+	configLoader := &MockConfigLoader{}
+	activityLog := &MockActivityLog{}
+	syncBase := &MockSyncBaseStore{}
+	runBase := &MockSyncBaseStore{}
 
 	deps := &Dependencies{
 		Git:        git,
@@ -522,6 +996,13 @@ func NewTestDepsForDoctor() (*Dependencies, *MockGitClient, *MockUIProvider, *Mo
 		Stat:       stat,
 		AuthStore:  authStore,
 		HTTP:       httpClient,
+		Clipboard:  clipboard,
+		Config:     configLoader,
+		Activity:   activityLog,
+		SyncBase:   syncBase,
+		RunBase:    runBase,
+		LastEnv:    &MockEnvironmentMemory{},
+		Accounts:   &MockAccountStore{},
 	}
 
 	return deps, git, ui, stat, authStore, httpClient, apiClient
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/keywaysh/cli/internal/config"
+)
+
+// defaultNotifyMessage is the message of targets that don't set one
+const defaultNotifyMessage = "{{.Actor}} ran keyway {{.Event}} on {{.Repository}} ({{.Environment}}): {{.Summary}}"
+
+// NotifyEvent is a change to the vault, as sent to keyway.toml notify targets.
+// It names the keys that changed, never their values.
+type NotifyEvent struct {
+	Event       string    `json:"event"`
+	Repository  string    `json:"repository"`
+	Environment string    `json:"environment"`
+	User        string    `json:"user,omitempty"`
+	Added       []string  `json:"added"`
+	Changed     []string  `json:"changed"`
+	Removed     []string  `json:"removed"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Actor is who made the change, for messages
+func (e NotifyEvent) Actor() string {
+	if e.User == "" {
+		return "Someone"
+	}
+	return "@" + e.User
+}
+
+// Summary lists the changed keys, e.g. "added API_KEY; removed OLD_TOKEN"
+func (e NotifyEvent) Summary() string {
+	var parts []string
+	for _, group := range []struct {
+		verb string
+		keys []string
+	}{{"added", e.Added}, {"changed", e.Changed}, {"removed", e.Removed}} {
+		if len(group.keys) > 0 {
+			parts = append(parts, group.verb+" "+strings.Join(group.keys, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// notifyChange tells the keyway.toml notify targets interested in event about
+// it. Failures are warnings: the change is already in the vault.
+func notifyChange(deps *Dependencies, event NotifyEvent) {
+	if len(event.Added)+len(event.Changed)+len(event.Removed) == 0 {
+		return
+	}
+	project, err := deps.Config.LoadProject()
+	if err != nil || len(project.Notify) == 0 {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.User == "" && deps.AuthStore != nil {
+		if stored, err := deps.AuthStore.GetAuth(); err == nil && stored != nil {
+			event.User = stored.GitHubLogin
+		}
+	}
+	// Empty lists rather than null in payloads
+	for _, keys := range []*[]string{&event.Added, &event.Changed, &event.Removed} {
+		*keys = append([]string{}, *keys...)
+		sort.Strings(*keys)
+	}
+
+	for _, target := range project.Notify {
+		if !target.Wants(event.Event, event.Environment) {
+			continue
+		}
+		if err := sendNotification(deps, target, event); err != nil {
+			deps.UI.Warn(fmt.Sprintf("Could not notify %s: %s", notifyTargetName(target), err.Error()))
+		}
+	}
+}
+
+// sendNotification posts event to target
+func sendNotification(deps *Dependencies, target config.NotifyConfig, event NotifyEvent) error {
+	var missing []string
+	targetURL := strings.TrimSpace(os.Expand(target.URL, func(name string) string {
+		value, _ := lookupHostEnv(name)
+		if value == "" {
+			missing = append(missing, name)
+		}
+		return value
+	}))
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is not set", strings.Join(missing, ", "))
+	}
+
+	message, err := notifyMessage(target, event)
+	if err != nil {
+		return err
+	}
+
+	var payload interface{}
+	switch target.Type {
+	case config.NotifySlack:
+		payload = map[string]string{"text": message}
+	case config.NotifyHTTP:
+		payload = struct {
+			NotifyEvent
+			Message string `json:"message"`
+		}{event, message}
+	default:
+		return fmt.Errorf("unknown type %q (use %s or %s)", target.Type, config.NotifySlack, config.NotifyHTTP)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	status, err := deps.HTTP.PostJSON(targetURL, body)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("HTTP %d", status)
+	}
+	return nil
+}
+
+// notifyMessage renders the message template of target
+func notifyMessage(target config.NotifyConfig, event NotifyEvent) (string, error) {
+	text := target.Message
+	if text == "" {
+		text = defaultNotifyMessage
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid message template: %w", err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, event); err != nil {
+		return "", fmt.Errorf("invalid message template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// notifyTargetName names a target in warnings without its URL, which may be a secret
+func notifyTargetName(target config.NotifyConfig) string {
+	if strings.HasPrefix(target.URL, "$") {
+		return target.URL
+	}
+	if u, err := url.Parse(target.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return target.Type + " target"
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func notifyTestDeps(targets ...config.NotifyConfig) (*Dependencies, *MockUIProvider, *MockHTTPClient) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Notify: targets}
+	deps.AuthStore.(*MockAuthStore).StoredAuth = &StoredAuthInfo{GitHubLogin: "octocat"}
+	return deps, uiMock, deps.HTTP.(*MockHTTPClient)
+}
+
+func TestNotifyChange_Slack(t *testing.T) {
+	withHostEnv(t, map[string]string{"SLACK_WEBHOOK_URL": "https://hooks.slack.com/services/T0/B0/xyz"})
+	deps, _, httpMock := notifyTestDeps(config.NotifyConfig{Type: config.NotifySlack, URL: "${SLACK_WEBHOOK_URL}"})
+
+	notifyChange(deps, NotifyEvent{Event: "push", Repository: "owner/repo", Environment: "production", Added: []string{"B", "A"}, Removed: []string{"OLD"}})
+
+	if len(httpMock.Posts) != 1 || httpMock.Posts[0].URL != "https://hooks.slack.com/services/T0/B0/xyz" {
+		t.Fatalf("expected one post to the expanded URL, got %+v", httpMock.Posts)
+	}
+	var body map[string]string
+	if err := json.Unmarshal([]byte(httpMock.Posts[0].Body), &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := "@octocat ran keyway push on owner/repo (production): added A, B; removed OLD"; body["text"] != want {
+		t.Errorf("expected %q, got %q", want, body["text"])
+	}
+}
+
+func TestNotifyChange_HTTPPayload(t *testing.T) {
+	deps, _, httpMock := notifyTestDeps(config.NotifyConfig{Type: config.NotifyHTTP, URL: "https://example.com/hook", Message: "{{.Event}} {{.Environment}}"})
+
+	notifyChange(deps, NotifyEvent{Event: "set", Repository: "owner/repo", Environment: "staging", Changed: []string{"API_KEY"}})
+
+	if len(httpMock.Posts) != 1 {
+		t.Fatalf("expected one post, got %+v", httpMock.Posts)
+	}
+	var payload struct {
+		Event   string   `json:"event"`
+		User    string   `json:"user"`
+		Added   []string `json:"added"`
+		Changed []string `json:"changed"`
+		Message string   `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(httpMock.Posts[0].Body), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != "set" || payload.User != "octocat" || payload.Message != "set staging" || len(payload.Changed) != 1 {
+		t.Errorf("unexpected payload %s", httpMock.Posts[0].Body)
+	}
+	if payload.Added == nil {
+		t.Error("expected an empty list rather than null")
+	}
+}
+
+func TestNotifyChange_Filters(t *testing.T) {
+	deps, _, httpMock := notifyTestDeps(
+		config.NotifyConfig{Type: config.NotifyHTTP, URL: "https://example.com/a", Events: []string{"push"}},
+		config.NotifyConfig{Type: config.NotifyHTTP, URL: "https://example.com/b", Environments: []string{"production"}},
+	)
+
+	notifyChange(deps, NotifyEvent{Event: "set", Environment: "staging", Added: []string{"A"}})
+	notifyChange(deps, NotifyEvent{Event: "push", Environment: "staging"})
+
+	if len(httpMock.Posts) != 0 {
+		t.Errorf("expected no posts, got %+v", httpMock.Posts)
+	}
+}
+
+func TestNotifyChange_FailureWarns(t *testing.T) {
+	withHostEnv(t, map[string]string{})
+	deps, uiMock, httpMock := notifyTestDeps(
+		config.NotifyConfig{Type: config.NotifySlack, URL: "${SLACK_WEBHOOK_URL}"},
+		config.NotifyConfig{Type: config.NotifyHTTP, URL: "https://example.com/hook?token=abc"},
+	)
+	httpMock.PostError = errors.New("connection refused")
+
+	notifyChange(deps, NotifyEvent{Event: "unset", Environment: "development", Removed: []string{"A"}})
+
+	warnings := strings.Join(uiMock.WarnCalls, "\n")
+	if !strings.Contains(warnings, "${SLACK_WEBHOOK_URL}: SLACK_WEBHOOK_URL is not set") {
+		t.Errorf("expected the unset variable reported, got %q", warnings)
+	}
+	if !strings.Contains(warnings, "example.com: connection refused") || strings.Contains(warnings, "token=abc") {
+		t.Errorf("expected the host only, got %q", warnings)
+	}
+}
+
+func TestRunPushWithDeps_Notifies(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Notify: []config.NotifyConfig{{Type: config.NotifyHTTP, URL: "https://example.com/hook"}}}
+	fsMock.Files[".env"] = []byte("API_KEY=secret123\nDB_URL=postgres://new")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DB_URL=postgres://old\nLEGACY=x"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	posts := deps.HTTP.(*MockHTTPClient).Posts
+	if len(posts) != 1 {
+		t.Fatalf("expected one notification, got %+v", posts)
+	}
+	body := posts[0].Body
+	if !strings.Contains(body, `"added":["API_KEY"]`) || !strings.Contains(body, `"changed":["DB_URL"]`) || !strings.Contains(body, `"removed":[]`) {
+		t.Errorf("unexpected payload %s", body)
+	}
+	if strings.Contains(body, "secret123") || strings.Contains(body, "postgres") {
+		t.Errorf("payload must not contain values: %s", body)
+	}
+}
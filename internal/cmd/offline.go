@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/offline"
+)
+
+// offlineFlag is set by the global --offline flag
+var offlineFlag bool
+
+// offlineSharedEnv is the cache entry of a vault's shared values. Environment
+// names never start with $.
+const offlineSharedEnv = "$shared"
+
+// offlineAPIClient saves every pulled environment in the offline cache, and
+// reads it back when the API is unreachable, or instead of calling the API
+// with --offline. Entries older than ttl are never used.
+type offlineAPIClient struct {
+	api.APIClient
+	cache *offline.Cache
+	ttl   time.Duration
+	// only is set by --offline: the API is never called
+	only bool
+	now  func() time.Time
+	// warn tells the user cached values are being used
+	warn func(message string)
+}
+
+func newOfflineAPIClient(client api.APIClient, cache *offline.Cache, ttl time.Duration, only bool, warn func(string)) *offlineAPIClient {
+	return &offlineAPIClient{APIClient: client, cache: cache, ttl: ttl, only: only, now: time.Now, warn: warn}
+}
+
+func (c *offlineAPIClient) PullSecrets(ctx context.Context, repo, envName string, keys ...string) (*api.PullSecretsResponse, error) {
+	var err error
+	if !c.only {
+		var resp *api.PullSecretsResponse
+		resp, err = c.APIClient.PullSecrets(ctx, repo, envName, keys...)
+		if err == nil {
+			// A subset of the keys must not replace the whole environment
+			if len(keys) == 0 {
+				_ = c.cache.Save(repo, envName, resp.Content, c.now())
+			}
+			return resp, nil
+		}
+		if !isUnreachable(err) {
+			return nil, err
+		}
+	}
+
+	entry, loadErr := c.load(repo, envName, err)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	content := entry.Content
+	if len(keys) > 0 {
+		content = onlyKeysContent(content, keys)
+	}
+	return &api.PullSecretsResponse{Content: content}, nil
+}
+
+func (c *offlineAPIClient) GetSharedValues(ctx context.Context, repoFullName string) (map[string]string, error) {
+	var err error
+	if !c.only {
+		var shared map[string]string
+		shared, err = c.APIClient.GetSharedValues(ctx, repoFullName)
+		if err == nil {
+			if data, marshalErr := json.Marshal(shared); marshalErr == nil {
+				_ = c.cache.Save(repoFullName, offlineSharedEnv, string(data), c.now())
+			}
+			return shared, nil
+		}
+		if !isUnreachable(err) {
+			return nil, err
+		}
+	}
+
+	entry, loadErr := c.load(repoFullName, offlineSharedEnv, err)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	var shared map[string]string
+	if err := json.Unmarshal([]byte(entry.Content), &shared); err != nil {
+		return nil, err
+	}
+	return shared, nil
+}
+
+// load returns the live cache entry of an environment and warns that it is
+// used. apiErr is the error of the API call it replaces, nil with --offline.
+func (c *offlineAPIClient) load(repo, envName string, apiErr error) (*offline.Entry, error) {
+	what := "secrets of " + envName
+	if envName == offlineSharedEnv {
+		what = "shared values"
+	}
+
+	entry, err := c.cache.Load(repo, envName)
+	if err != nil || entry == nil || c.now().Sub(entry.SavedAt) > c.ttl {
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("offline: no %s of %s cached in the last %s", what, repo, c.ttl)
+	}
+
+	age := c.now().Sub(entry.SavedAt).Round(time.Minute)
+	if apiErr != nil {
+		c.warn(fmt.Sprintf("API unreachable (%s): using %s cached %s ago", apiErr.Error(), what, age))
+	} else {
+		c.warn(fmt.Sprintf("Offline: using %s cached %s ago", what, age))
+	}
+	return entry, nil
+}
+
+// isUnreachable reports whether err means the API could not be reached, as
+// opposed to an answer such as 401 or 404
+func isUnreachable(err error) bool {
+	var netErr *api.NetworkError
+	return errors.As(err, &netErr) && !netErr.Permanent
+}
+
+// onlyKeysContent keeps the lines of env content that set one of keys
+func onlyKeysContent(content string, keys []string) string {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		for key := range env.Parse(line) {
+			if wanted[key] {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withOfflineCache wraps client with the offline cache. It is opt-in: without
+// KEYWAY_OFFLINE_TTL or offline.ttl nothing is cached, and --offline alone
+// only reads what an earlier run cached.
+func withOfflineCache(client api.APIClient) api.APIClient {
+	ttl, _ := config.OfflineTTL()
+	if ttl <= 0 {
+		if !offlineFlag {
+			return client
+		}
+		ttl = config.DefaultOfflineTTL
+	}
+	cache, err := offline.Default()
+	if err != nil {
+		return client
+	}
+	return newOfflineAPIClient(client, cache, ttl, offlineFlag, func(message string) {
+		defaultDeps.UI.Warn(message)
+	})
+}
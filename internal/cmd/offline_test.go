@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/offline"
+)
+
+func newTestOfflineClient(t *testing.T, apiMock *MockAPIClient, only bool) (*offlineAPIClient, *[]string) {
+	dir := t.TempDir()
+	cache := offline.New(filepath.Join(dir, "offline.json"), filepath.Join(dir, "offline.key"))
+	var warnings []string
+	client := newOfflineAPIClient(apiMock, cache, time.Hour, only, func(message string) {
+		warnings = append(warnings, message)
+	})
+	return client, &warnings
+}
+
+func TestOfflineAPIClient_ServesCacheWhenUnreachable(t *testing.T) {
+	apiMock := &MockAPIClient{PullResponse: &api.PullSecretsResponse{Content: "API_KEY=abc\nPORT=3000"}}
+	client, warnings := newTestOfflineClient(t, apiMock, false)
+	ctx := context.Background()
+
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiMock.PullResponse = nil
+	apiMock.PullError = &api.NetworkError{Message: "DNS lookup failed"}
+	resp, err := client.PullSecrets(ctx, "owner/repo", "production")
+	if err != nil {
+		t.Fatalf("expected the cached content, got %v", err)
+	}
+	if resp.Content != "API_KEY=abc\nPORT=3000" {
+		t.Errorf("unexpected content: %q", resp.Content)
+	}
+	if len(*warnings) != 1 || !strings.Contains((*warnings)[0], "API unreachable") {
+		t.Errorf("expected a warning, got %v", *warnings)
+	}
+
+	// Only the requested keys
+	resp, err = client.PullSecrets(ctx, "owner/repo", "production", "PORT")
+	if err != nil || resp.Content != "PORT=3000" {
+		t.Errorf("expected only PORT, got %q, %v", resp.Content, err)
+	}
+
+	// Nothing cached for this environment
+	if _, err := client.PullSecrets(ctx, "owner/repo", "staging"); !errors.Is(err, apiMock.PullError) {
+		t.Errorf("expected the API error, got %v", err)
+	}
+}
+
+func TestOfflineAPIClient_KeepsAPIErrors(t *testing.T) {
+	apiMock := &MockAPIClient{PullResponse: &api.PullSecretsResponse{Content: "API_KEY=abc"}}
+	client, _ := newTestOfflineClient(t, apiMock, false)
+	ctx := context.Background()
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The API answered: access may have been revoked
+	apiMock.PullError = &api.APIError{StatusCode: 403, Detail: "Forbidden"}
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err == nil {
+		t.Error("expected the API error")
+	}
+}
+
+func TestOfflineAPIClient_Offline(t *testing.T) {
+	apiMock := &MockAPIClient{
+		PullResponse: &api.PullSecretsResponse{Content: "DB=$ref:shared/DB"},
+		SharedValues: map[string]string{"DB": "postgres://"},
+	}
+	online, _ := newTestOfflineClient(t, apiMock, false)
+	ctx := context.Background()
+	if _, err := online.PullSecrets(ctx, "owner/repo", "production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := online.GetSharedValues(ctx, "owner/repo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DB=changed"}
+	client := newOfflineAPIClient(apiMock, online.cache, time.Hour, true, func(string) {})
+	resp, err := client.PullSecrets(ctx, "owner/repo", "production")
+	if err != nil || resp.Content != "DB=$ref:shared/DB" {
+		t.Errorf("expected the cached content without calling the API, got %q, %v", resp, err)
+	}
+	shared, err := client.GetSharedValues(ctx, "owner/repo")
+	if err != nil || shared["DB"] != "postgres://" {
+		t.Errorf("expected the cached shared values, got %v, %v", shared, err)
+	}
+
+	// Entries older than the TTL are not used
+	client.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := client.PullSecrets(ctx, "owner/repo", "production"); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("expected an expired entry refused, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategies for keyway run --on-conflict, applied when a key has different
+// values in the vault and in an overlay file or the shell environment
+const (
+	// conflictUser lets overlays win over the vault, and the vault win over the shell
+	conflictUser = "user"
+	// conflictVault keeps vault values; overlays only add new keys
+	conflictVault = "vault"
+	// conflictError refuses to run
+	conflictError = "error"
+)
+
+// parseConflictStrategy validates an --on-conflict value ("" is user)
+func parseConflictStrategy(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", conflictUser:
+		return conflictUser, nil
+	case conflictVault:
+		return conflictVault, nil
+	case conflictError:
+		return conflictError, nil
+	}
+	return "", fmt.Errorf("invalid --on-conflict %q (use user, vault or error)", value)
+}
+
+// ConflictError lists the keys set to different values by two sources
+type ConflictError struct {
+	Source string // "overlay <file>" or "shell environment"
+	Keys   []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s conflicts with the vault on %s (use --on-conflict user or vault to choose)", e.Source, strings.Join(e.Keys, ", "))
+}
+
+// shellConflicts returns the sorted names of inherited variables that an
+// injected secret replaces with a different value
+func shellConflicts(environ []string, secrets map[string]string) []string {
+	var keys []string
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		if secret, injected := secrets[name]; injected && secret != value {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveShellConflicts applies the strategy to inherited variables shadowed
+// by injected secrets. Injected values always win; the decision is reported.
+func resolveShellConflicts(deps *Dependencies, environ []string, secrets map[string]string, strategy string) error {
+	keys := shellConflicts(environ, secrets)
+	if len(keys) == 0 {
+		return nil
+	}
+	if strategy == conflictError {
+		return &ConflictError{Source: "shell environment", Keys: keys}
+	}
+	deps.UI.Step(fmt.Sprintf("Shell variables replaced by injected values: %s", strings.Join(keys, ", ")))
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseOnlyKeys normalizes --only values: trimmed, deduplicated and sorted
+func parseOnlyKeys(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var keys []string
+	for _, value := range values {
+		key := strings.TrimSpace(value)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// selectKeys removes every secret not listed in keys, in place. The server
+// may not support filtered pulls, so the selection is always applied locally.
+// It fails when a requested key is not in the vault.
+func selectKeys(secrets map[string]string, keys []string) error {
+	wanted := make(map[string]bool, len(keys))
+	var missing []string
+	for _, key := range keys {
+		wanted[key] = true
+		if _, ok := secrets[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("not found in vault: %s", strings.Join(missing, ", "))
+	}
+	for key := range secrets {
+		if !wanted[key] {
+			delete(secrets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of the global --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFlag is set by the global --output flag
+var outputFlag = outputText
+
+// applyOutputFlag validates --output. With json, it turns on the --json flag
+// of cmd, and replaces the UI so stdout only carries the JSON document.
+func applyOutputFlag(cmd *cobra.Command, deps *Dependencies, stderr io.Writer) error {
+	switch outputFlag {
+	case outputText:
+		return nil
+	case outputJSON:
+		if flag := cmd.Flags().Lookup("json"); flag != nil && !flag.Changed {
+			if err := flag.Value.Set("true"); err != nil {
+				return err
+			}
+		}
+		deps.UI = &jsonUIProvider{UIProvider: deps.UI, stderr: stderr}
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: use text or json", outputFlag)
+	}
+}
+
+// jsonUIProvider is the UI of --output json: progress output is dropped,
+// warnings and errors go to stderr, and nothing prompts.
+type jsonUIProvider struct {
+	UIProvider
+	stderr io.Writer
+}
+
+func (u *jsonUIProvider) Intro(command string)   {}
+func (u *jsonUIProvider) Outro(message string)   {}
+func (u *jsonUIProvider) Success(message string) {}
+func (u *jsonUIProvider) Info(message string)    {}
+func (u *jsonUIProvider) Step(message string)    {}
+func (u *jsonUIProvider) Message(message string) {}
+func (u *jsonUIProvider) DiffAdded(key string)   {}
+func (u *jsonUIProvider) DiffChanged(key string) {}
+func (u *jsonUIProvider) DiffRemoved(key string) {}
+func (u *jsonUIProvider) DiffKept(key string)    {}
+
+func (u *jsonUIProvider) Error(message string) { fmt.Fprintf(u.stderr, "✗ %s\n", message) }
+func (u *jsonUIProvider) Warn(message string)  { fmt.Fprintf(u.stderr, "⚠ %s\n", message) }
+
+func (u *jsonUIProvider) IsInteractive() bool { return false }
+
+func (u *jsonUIProvider) Confirm(message string, defaultValue bool) (bool, error) {
+	return false, errNoPromptInJSON
+}
+
+func (u *jsonUIProvider) Select(message string, options []string) (string, error) {
+	return "", errNoPromptInJSON
+}
+
+func (u *jsonUIProvider) MultiSelect(message string, options []string, selected []string) ([]string, error) {
+	return nil, errNoPromptInJSON
+}
+
+func (u *jsonUIProvider) Password(prompt string) (string, error) {
+	return "", errNoPromptInJSON
+}
+
+func (u *jsonUIProvider) Spin(message string, fn func() error) error { return fn() }
+
+func (u *jsonUIProvider) Value(v interface{}) string { return fmt.Sprint(v) }
+func (u *jsonUIProvider) File(path string) string    { return path }
+func (u *jsonUIProvider) Link(url string) string     { return url }
+func (u *jsonUIProvider) Command(cmd string) string  { return cmd }
+func (u *jsonUIProvider) Bold(text string) string    { return text }
+func (u *jsonUIProvider) Dim(text string) string     { return text }
+
+// errNoPromptInJSON is returned by prompts with --output json
+var errNoPromptInJSON = errors.New("cannot prompt with --output json: pass the value as a flag")
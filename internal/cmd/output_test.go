@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestApplyOutputFlag(t *testing.T) {
+	t.Cleanup(func() { outputFlag = outputText })
+
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	cmd := &cobra.Command{Use: "list"}
+	cmd.Flags().Bool("json", false, "")
+
+	if err := applyOutputFlag(cmd, deps, &bytes.Buffer{}); err != nil || deps.UI != uiMock {
+		t.Fatalf("expected text output to change nothing, got %v", err)
+	}
+
+	outputFlag = "yaml"
+	if err := applyOutputFlag(cmd, deps, &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	outputFlag = outputJSON
+	var stderr bytes.Buffer
+	if err := applyOutputFlag(cmd, deps, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !jsonRequested(cmd) {
+		t.Error("expected the command's --json flag set")
+	}
+	if deps.UI.IsInteractive() {
+		t.Error("expected no prompts with --output json")
+	}
+	if _, err := deps.UI.Select("Environment:", []string{"development"}); err == nil {
+		t.Error("expected prompts to fail")
+	}
+
+	deps.UI.Intro("list")
+	deps.UI.Success("done")
+	deps.UI.Error("boom")
+	if len(uiMock.SuccessCalls) != 0 {
+		t.Errorf("expected progress output dropped, got %v", uiMock.SuccessCalls)
+	}
+	if !strings.Contains(stderr.String(), "boom") {
+		t.Errorf("expected errors on stderr, got %q", stderr.String())
+	}
+}
+
+func TestPrintPullJSON(t *testing.T) {
+	var out bytes.Buffer
+	err := printPullJSON(&out, pullResult{Repository: "owner/repo", Environment: "staging", File: ".env", Keys: []string{"API_KEY"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{`"environment": "staging"`, `"keys": [`, `"API_KEY"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %s in %s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "added") {
+		t.Errorf("expected empty lists left out, got %s", out.String())
+	}
+}
+
+func TestNoFlagShadowsGlobalFlags(t *testing.T) {
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+			if global := rootCmd.PersistentFlags().Lookup(f.Name); global != nil {
+				t.Errorf("%s: --%s shadows the global --%s flag", c.CommandPath(), f.Name, global.Name)
+			}
+			if f.Shorthand != "" {
+				if global := rootCmd.PersistentFlags().ShorthandLookup(f.Shorthand); global != nil {
+					t.Errorf("%s: -%s shadows the global --%s flag", c.CommandPath(), f.Shorthand, global.Name)
+				}
+			}
+		})
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
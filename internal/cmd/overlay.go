@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// OverlayResult reports how an overlay file changed the injected secrets
+type OverlayResult struct {
+	File       string
+	Overridden []string // Keys whose vault value was replaced
+	Kept       []string // Keys whose vault value was kept (--on-conflict vault)
+	Added      []string // Keys not present in the vault
+}
+
+// applyOverlay merges a local env file on top of secrets, in place. Keys
+// present in vault with a different value are resolved by strategy; with
+// conflictError nothing is merged. The file is only read; nothing is cached
+// or written back.
+func applyOverlay(deps *Dependencies, secrets, vault map[string]string, file, strategy string) (*OverlayResult, error) {
+	data, err := deps.FS.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read overlay %s: %w", file, err)
+	}
+
+	overlay := env.Parse(string(data))
+	result := &OverlayResult{File: file, Overridden: []string{}, Kept: []string{}, Added: []string{}}
+	for key, value := range overlay {
+		vaultValue, inVault := vault[key]
+		switch {
+		case !inVault:
+			result.Added = append(result.Added, key)
+		case strategy == conflictVault && value != vaultValue:
+			result.Kept = append(result.Kept, key)
+		default:
+			result.Overridden = append(result.Overridden, key)
+		}
+	}
+	sort.Strings(result.Overridden)
+	sort.Strings(result.Kept)
+	sort.Strings(result.Added)
+
+	if strategy == conflictError {
+		var conflicts []string
+		for _, key := range result.Overridden {
+			if overlay[key] != vault[key] {
+				conflicts = append(conflicts, key)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, &ConflictError{Source: "overlay " + file, Keys: conflicts}
+		}
+	}
+
+	for _, key := range append(result.Overridden, result.Added...) {
+		secrets[key] = overlay[key]
+	}
+	return result, nil
+}
+
+// reportOverlay prints which keys an overlay changed (never values)
+func reportOverlay(deps *Dependencies, result *OverlayResult) {
+	deps.UI.Step(fmt.Sprintf("Overlay: %s %s", deps.UI.File(result.File), deps.UI.Dim("(this run only)")))
+	if len(result.Overridden) > 0 {
+		deps.UI.Message(fmt.Sprintf("  overrides vault: %s", strings.Join(result.Overridden, ", ")))
+	}
+	if len(result.Kept) > 0 {
+		deps.UI.Message(fmt.Sprintf("  ignored, vault wins: %s", strings.Join(result.Kept, ", ")))
+	}
+	if len(result.Added) > 0 {
+		deps.UI.Message(fmt.Sprintf("  adds: %s", strings.Join(result.Added, ", ")))
+	}
+	if len(result.Overridden) == 0 && len(result.Kept) == 0 && len(result.Added) == 0 {
+		deps.UI.Message(deps.UI.Dim("  no variables"))
+	}
+}
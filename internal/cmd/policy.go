@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// enforceBranchPolicy checks the keyway.toml branch rule for envName against the
+// current git branch. Returns an error when the rule blocks and override is false.
+func enforceBranchPolicy(deps *Dependencies, envName string, override bool) error {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	policy := project.BranchPolicy(envName)
+	if policy == nil {
+		return nil
+	}
+
+	branch, err := deps.Git.CurrentBranch()
+	if err == nil && policy.Allows(branch) {
+		return nil
+	}
+
+	var reason string
+	if err != nil {
+		reason = fmt.Sprintf("Branch policy: %s (%s)", policy.Describe(), err.Error())
+	} else {
+		reason = fmt.Sprintf("Branch policy: %s (current branch: %s)", policy.Describe(), branch)
+	}
+
+	if override {
+		deps.UI.Warn(reason)
+		deps.UI.Warn("Continuing because --override was passed")
+		return nil
+	}
+
+	if policy.Warn {
+		deps.UI.Warn(reason)
+		return nil
+	}
+
+	deps.UI.Error(reason)
+	deps.UI.Message(deps.UI.Dim("Switch branch, or pass --override for break-glass access."))
+	return fmt.Errorf("branch policy violation")
+}
+
+// enforceLabelPolicy checks the keys about to be injected against the labels the
+// environment denies in keyway.toml. Returns an error on violations unless override is true.
+func enforceLabelPolicy(deps *Dependencies, envName string, keys []string, override bool) error {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	violations := project.LabelViolations(envName, keys)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(violations))
+	for i, v := range violations {
+		names[i] = v.String()
+	}
+	reason := fmt.Sprintf("Label policy: %s does not allow %s", envName, strings.Join(names, ", "))
+
+	if override {
+		deps.UI.Warn(reason)
+		deps.UI.Warn("Continuing because --override was passed")
+		return nil
+	}
+
+	deps.UI.Error(reason)
+	deps.UI.Message(deps.UI.Dim("Remove these keys from the environment, or pass --override for break-glass access."))
+	return fmt.Errorf("label policy violation")
+}
+
+// enforceSchema checks an environment's secrets against the keys keyway.toml
+// marks required or typed. Returns an error on a violation unless skip is set.
+func enforceSchema(deps *Dependencies, envName string, secrets map[string]string, skip bool) error {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	violations := project.SchemaViolations(envName, secrets, func(value string) bool { return env.RefName(value) != "" })
+	if len(violations) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(violations))
+	for i, v := range violations {
+		names[i] = v.String()
+	}
+	reason := fmt.Sprintf("Schema: in %s, %s", envName, strings.Join(names, "; "))
+
+	if skip {
+		deps.UI.Warn(reason)
+		deps.UI.Warn("Continuing because --skip-validation was passed")
+		return nil
+	}
+
+	deps.UI.Error(reason)
+	deps.UI.Message(deps.UI.Dim("Fix these keys, or pass --skip-validation."))
+	return fmt.Errorf("schema violation")
+}
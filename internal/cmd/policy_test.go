@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func productionOnMain() *config.ProjectConfig {
+	return &config.ProjectConfig{
+		Environments: map[string]config.EnvironmentConfig{
+			"production": {Branches: []string{"main"}},
+			"staging":    {Branches: []string{"main", "develop"}, Enforce: config.EnforceWarn},
+		},
+	}
+}
+
+func TestEnforceBranchPolicy_NoRule(t *testing.T) {
+	deps, gitMock, _, _, _, _ := NewTestDeps()
+	gitMock.Branch = "feature/x"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	if err := enforceBranchPolicy(deps, "development", false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnforceBranchPolicy_AllowedBranch(t *testing.T) {
+	deps, gitMock, _, uiMock, _, _ := NewTestDeps()
+	gitMock.Branch = "main"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	if err := enforceBranchPolicy(deps, "production", false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no warnings, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestEnforceBranchPolicy_Blocked(t *testing.T) {
+	deps, gitMock, _, uiMock, _, _ := NewTestDeps()
+	gitMock.Branch = "feature/x"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	err := enforceBranchPolicy(deps, "production", false)
+
+	if err == nil {
+		t.Fatal("expected branch policy violation")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestEnforceBranchPolicy_Override(t *testing.T) {
+	deps, gitMock, _, uiMock, _, _ := NewTestDeps()
+	gitMock.Branch = "feature/x"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	if err := enforceBranchPolicy(deps, "production", true); err != nil {
+		t.Fatalf("override should allow, got %v", err)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("override should be loud")
+	}
+}
+
+func TestEnforceBranchPolicy_WarnMode(t *testing.T) {
+	deps, gitMock, _, uiMock, _, _ := NewTestDeps()
+	gitMock.Branch = "feature/x"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	if err := enforceBranchPolicy(deps, "staging", false); err != nil {
+		t.Fatalf("warn mode should not block, got %v", err)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning")
+	}
+}
+
+func TestEnforceBranchPolicy_UnknownBranchBlocks(t *testing.T) {
+	deps, gitMock, _, _, _, _ := NewTestDeps()
+	gitMock.BranchError = errors.New("detached HEAD")
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+
+	if err := enforceBranchPolicy(deps, "production", false); err == nil {
+		t.Error("expected an error when the branch cannot be determined")
+	}
+}
+
+func TestEnforceBranchPolicy_ConfigError(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Error: errors.New("invalid keyway.toml")}
+
+	if err := enforceBranchPolicy(deps, "production", false); err == nil {
+		t.Error("expected config error")
+	}
+}
+
+func TestRunRunWithDeps_BranchPolicyBlocks(t *testing.T) {
+	deps, gitMock, _, _, _, _ := NewTestDeps()
+	gitMock.Branch = "feature/x"
+	deps.Config = &MockConfigLoader{Project: productionOnMain()}
+	runner := deps.CmdRunner.(*MockCommandRunner)
+
+	err := runRunWithDeps(RunOptions{EnvName: "production", EnvFlagSet: true, Command: "echo"}, deps)
+
+	if err == nil {
+		t.Fatal("expected branch policy violation")
+	}
+	if runner.LastCommand != "" {
+		t.Error("command must not run when the policy blocks")
+	}
+}
+
+func paymentDeniedInDevelopment() *config.ProjectConfig {
+	return &config.ProjectConfig{
+		Labels: map[string][]string{"payment": {"STRIPE_*"}, "pii": {"DATABASE_URL"}},
+		Environments: map[string]config.EnvironmentConfig{
+			"development": {DenyLabels: []string{"payment"}},
+		},
+	}
+}
+
+func TestEnforceLabelPolicy_Violation(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Project: paymentDeniedInDevelopment()}
+
+	err := enforceLabelPolicy(deps, "development", []string{"DATABASE_URL", "STRIPE_SECRET_KEY"}, false)
+
+	if err == nil {
+		t.Fatal("expected label policy violation")
+	}
+	if len(uiMock.ErrorCalls) != 1 || !strings.Contains(uiMock.ErrorCalls[0], "STRIPE_SECRET_KEY (payment)") {
+		t.Errorf("unexpected error output: %v", uiMock.ErrorCalls)
+	}
+	if strings.Contains(uiMock.ErrorCalls[0], "DATABASE_URL") {
+		t.Errorf("pii is not denied in development: %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestEnforceLabelPolicy_AllowedEnvironment(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Project: paymentDeniedInDevelopment()}
+
+	if err := enforceLabelPolicy(deps, "production", []string{"STRIPE_SECRET_KEY"}, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnforceLabelPolicy_Override(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Project: paymentDeniedInDevelopment()}
+
+	if err := enforceLabelPolicy(deps, "development", []string{"STRIPE_SECRET_KEY"}, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) != 2 {
+		t.Errorf("expected override warnings, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunRunWithDeps_LabelPolicyBlocksInjection(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	deps.Config = &MockConfigLoader{Project: paymentDeniedInDevelopment()}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "STRIPE_SECRET_KEY=sk_live_x\nPORT=3000"}
+
+	err := runRunWithDeps(RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}, deps)
+
+	if err == nil {
+		t.Fatal("expected label policy violation")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run on a policy violation")
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// allocatePort is swapped in tests
+var allocatePort = freeLocalPort
+
+// freeLocalPort asks the OS for an unused TCP port on the loopback interface.
+// The port is released before the command starts, so another process could
+// take it in between; this is good enough for local development.
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// applyPortEnv allocates a distinct free port for each name and sets it in secrets,
+// overriding vault values. Returns the assigned ports in the order given.
+func applyPortEnv(secrets map[string]string, names []string) ([]string, error) {
+	assigned := make([]string, 0, len(names))
+	used := make(map[int]bool, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !isValidKeyName(name) {
+			return nil, fmt.Errorf("invalid --port-env name %q", name)
+		}
+
+		// Listeners are closed right away, so the OS may hand out the same port twice
+		var port int
+		for attempt := 0; attempt < 5; attempt++ {
+			p, err := allocatePort()
+			if err != nil {
+				return nil, fmt.Errorf("cannot allocate a port for %s: %w", name, err)
+			}
+			if !used[p] {
+				port = p
+				break
+			}
+		}
+		if port == 0 {
+			return nil, fmt.Errorf("cannot allocate a distinct port for %s", name)
+		}
+
+		used[port] = true
+		secrets[name] = strconv.Itoa(port)
+		assigned = append(assigned, fmt.Sprintf("%s=%d", name, port))
+	}
+
+	return assigned, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func stubPorts(t *testing.T, ports ...int) {
+	t.Helper()
+	old := allocatePort
+	i := 0
+	allocatePort = func() (int, error) {
+		if i >= len(ports) {
+			return 0, errors.New("no more ports")
+		}
+		p := ports[i]
+		i++
+		return p, nil
+	}
+	t.Cleanup(func() { allocatePort = old })
+}
+
+func TestFreeLocalPort(t *testing.T) {
+	port, err := freeLocalPort()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port <= 0 || port > 65535 {
+		t.Errorf("invalid port %d", port)
+	}
+}
+
+func TestApplyPortEnv_DistinctPorts(t *testing.T) {
+	stubPorts(t, 4000, 4000, 4001)
+	secrets := map[string]string{"PORT": "3000"}
+
+	assigned, err := applyPortEnv(secrets, []string{"PORT", "DB_PORT"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secrets["PORT"] != "4000" || secrets["DB_PORT"] != "4001" {
+		t.Errorf("unexpected ports: %v", secrets)
+	}
+	if len(assigned) != 2 || assigned[0] != "PORT=4000" {
+		t.Errorf("unexpected assigned: %v", assigned)
+	}
+}
+
+func TestApplyPortEnv_InvalidName(t *testing.T) {
+	if _, err := applyPortEnv(map[string]string{}, []string{"BAD-NAME"}); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
+
+func TestRunRunWithDeps_PortEnv(t *testing.T) {
+	stubPorts(t, 51234)
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "PORT=3000\nAPI_KEY=x"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", PortEnv: []string{"PORT"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastSecrets["PORT"] != strconv.Itoa(51234) {
+		t.Errorf("allocated port should win over the vault, got %q", cmdRunner.LastSecrets["PORT"])
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "x" {
+		t.Error("vault secrets should still be injected")
+	}
+}
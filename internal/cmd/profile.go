@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// packageInitAt is recorded when this package is initialized, after all of its imports
+var packageInitAt = time.Now()
+
+// startupPhase is a named span of the startup profile
+type startupPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// startupProfile records timing breakdowns for --profile-startup
+type startupProfile struct {
+	enabled bool
+	begin   time.Time
+	last    time.Time
+	phases  []startupPhase
+}
+
+// newStartupProfile creates a profile starting at package init time
+func newStartupProfile(enabled bool) *startupProfile {
+	return &startupProfile{enabled: enabled, begin: packageInitAt, last: packageInitAt}
+}
+
+// Mark closes the current phase under the given name
+func (p *startupProfile) Mark(name string) {
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	p.phases = append(p.phases, startupPhase{name: name, duration: now.Sub(p.last)})
+	p.last = now
+}
+
+// Print writes the breakdown to w
+func (p *startupProfile) Print(w io.Writer) {
+	if !p.enabled {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  %s\n", bold("Startup profile:"))
+	for _, phase := range p.phases {
+		fmt.Fprintf(w, "    %-14s %s\n", phase.name, formatPhaseDuration(phase.duration))
+	}
+	fmt.Fprintf(w, "    %-14s %s\n", "total", formatPhaseDuration(p.last.Sub(p.begin)))
+	fmt.Fprintf(w, "  %s\n", dim("Package init before main: GODEBUG=inittrace=1 keyway ..."))
+}
+
+func formatPhaseDuration(d time.Duration) string {
+	return fmt.Sprintf("%8.2fms", float64(d.Microseconds())/1000)
+}
+
+// profileStartupRequested checks os.Args directly, since the profile must
+// start before cobra parses flags
+func profileStartupRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--profile-startup" || arg == "--profile-startup=true" {
+			return true
+		}
+	}
+	return false
+}
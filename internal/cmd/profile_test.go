@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProfileStartupRequested(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"absent", []string{"pull"}, false},
+		{"flag", []string{"pull", "--profile-startup"}, true},
+		{"explicit true", []string{"--profile-startup=true", "pull"}, true},
+		{"after separator belongs to child", []string{"run", "--", "node", "--profile-startup"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileStartupRequested(tt.args); got != tt.want {
+				t.Errorf("profileStartupRequested(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartupProfile_Disabled(t *testing.T) {
+	p := newStartupProfile(false)
+	p.Mark("init")
+
+	var buf bytes.Buffer
+	p.Print(&buf)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output when disabled, got %q", buf.String())
+	}
+}
+
+func TestStartupProfile_Enabled(t *testing.T) {
+	p := newStartupProfile(true)
+	p.Mark("init")
+	p.Mark("run")
+
+	var buf bytes.Buffer
+	p.Print(&buf)
+
+	out := buf.String()
+	for _, want := range []string{"init", "run", "total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %q", want, out)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// ensureReadToken returns the token for commands that only read secrets.
+// Without a session, a vault its owner made public (sample apps, tutorials)
+// is read anonymously: the token is empty and the public vault is returned.
+// Otherwise, or if the API doesn't support anonymous reads, it logs in.
+func ensureReadToken(ctx context.Context, deps *Dependencies, repo string) (string, *api.PublicVault, error) {
+	if !deps.Auth.HasSession() {
+		public, err := deps.APIFactory.NewClient("").GetPublicVault(ctx, repo)
+		if err == nil && public != nil {
+			deps.UI.Step(fmt.Sprintf("Public vault: reading anonymously %s", deps.UI.Dim("(read-only, no login)")))
+			return "", public, nil
+		}
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	return token, nil, err
+}
+
+// readableEnvironments lists the environments to choose from: the public ones
+// when reading anonymously, else those of the vault the token can read
+func readableEnvironments(ctx context.Context, deps *Dependencies, client api.APIClient, repo string, public *api.PublicVault) ([]string, error) {
+	if public != nil {
+		if len(public.Environments) == 0 {
+			deps.UI.Error("This public vault has no public environments")
+			return nil, fmt.Errorf("no public environments")
+		}
+		return append([]string(nil), public.Environments...), nil
+	}
+	envs, err := environmentCandidates(ctx, deps, client, repo)
+	if err != nil {
+		return nil, err
+	}
+	return accessibleEnvironments(ctx, deps, client, repo, envs, false)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunRunWithDeps_PublicVaultWithoutLogin(t *testing.T) {
+	deps, _, authMock, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	authMock.NoSession = true
+	authMock.Error = errors.New("login required")
+	apiMock.PublicVault = &api.PublicVault{Public: true, Environments: []string{"development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "STRIPE_KEY=sk_test_demo"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token := deps.APIFactory.(*MockAPIFactory).LastToken; token != "" {
+		t.Errorf("expected an anonymous client, got token %q", token)
+	}
+	if cmdRunner.LastSecrets["STRIPE_KEY"] != "sk_test_demo" {
+		t.Errorf("unexpected secrets: %v", cmdRunner.LastSecrets)
+	}
+	if !strings.Contains(strings.Join(uiMock.StepCalls, "\n"), "Public vault") {
+		t.Errorf("expected anonymous access to be reported, got %v", uiMock.StepCalls)
+	}
+}
+
+func TestRunRunWithDeps_PrivateVaultWithoutLogin(t *testing.T) {
+	deps, _, authMock, _, cmdRunner, _ := NewTestDepsWithRunner()
+	authMock.NoSession = true
+	authMock.Error = errors.New("login required")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err == nil || err.Error() != "login required" {
+		t.Fatalf("expected the login error, got %v", err)
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run")
+	}
+}
+
+func TestRunRunWithDeps_SessionSkipsPublicCheck(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDepsWithRunner()
+	apiMock.PublicVault = &api.PublicVault{Public: true, Environments: []string{"development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token := deps.APIFactory.(*MockAPIFactory).LastToken; token == "" {
+		t.Error("a logged in user should use their session")
+	}
+}
+
+func TestRunPullWithDeps_PublicVaultListsPublicEnvironments(t *testing.T) {
+	deps, _, authMock, uiMock, fsMock, apiMock := NewTestDeps()
+	authMock.NoSession = true
+	uiMock.Interactive = true
+	uiMock.SelectResult = "demo"
+	apiMock.PublicVault = &api.PublicVault{Public: true, Environments: []string{"demo"}}
+	apiMock.VaultEnvs = []string{"production", "demo"}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+
+	if err := runPullWithDeps(PullOptions{File: ".env", Yes: true}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.SelectOptions) == 0 || strings.Join(uiMock.SelectOptions[0], ",") != "demo" {
+		t.Errorf("expected only public environments offered, got %v", uiMock.SelectOptions)
+	}
+	if _, ok := fsMock.Written[".env"]; !ok {
+		t.Error("expected .env written")
+	}
+}
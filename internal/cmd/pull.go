@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/encrypt"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/spf13/cobra"
 )
@@ -14,8 +20,22 @@ import (
 var pullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "Download secrets from the vault to an env file",
-	Long:  `Download secrets from the Keyway vault and save them to a local .env file.`,
-	RunE:  runPull,
+	Long: `Download secrets from the Keyway vault and save them to a local .env file.
+
+With --encrypt-for, the file is encrypted for the given age (age1...) or SSH
+public keys instead, so it can be stored or transferred at rest. Any recipient
+decrypts it with age -d.
+
+With --format, nothing is written: each key is printed to stdout with a Go
+template, {{.Key}} and {{.Value}}, and the functions quote, json, upper and
+lower.
+
+Pulling an environment that doesn't exist yet offers to create it (empty);
+--create-missing creates it without asking (CI).`,
+	Example: `  keyway pull --env production
+  keyway pull --env production --encrypt-for age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p -f prod.env.age
+  keyway pull --env production --format template='export {{.Key}}={{quote .Value}}'`,
+	RunE: runPull,
 }
 
 func init() {
@@ -23,15 +43,43 @@ func init() {
 	pullCmd.Flags().StringP("file", "f", ".env", "Env file to write to")
 	pullCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	pullCmd.Flags().Bool("force", false, "Replace entire file instead of merging")
+	pullCmd.Flags().Bool("override", false, "Bypass keyway.toml branch and label policies for this environment (break-glass)")
+	pullCmd.Flags().StringArray("encrypt-for", nil, "Encrypt the file for an age or SSH public key (repeatable, writes .env.age by default)")
+	pullCmd.Flags().Bool("armor", false, "With --encrypt-for, write ASCII-armored output")
+	pullCmd.Flags().Bool("create-missing", false, "Create the environment if it doesn't exist, without asking")
+	pullCmd.Flags().Bool("skip-validation", false, "Pull even if the environment breaks the keyway.toml key schema")
+	pullCmd.Flags().String("format", "", formatFlagUsage+" (prints instead of writing the file)")
 }
 
 // PullOptions contains the parsed flags for the pull command
 type PullOptions struct {
-	EnvName    string
-	File       string
-	Yes        bool
-	Force      bool
-	EnvFlagSet bool
+	EnvName       string
+	File          string
+	Yes           bool
+	Force         bool
+	EnvFlagSet    bool
+	Override      bool
+	EncryptFor    []string
+	Armor         bool
+	CreateMissing bool
+	// Format prints the secrets with a template instead of writing the file
+	Format         string
+	SkipValidation bool
+	// JSONOutput prints a summary for scripts (--output json)
+	JSONOutput bool
+}
+
+// pullResult is the summary pull prints with --output json. It never
+// contains values.
+type pullResult struct {
+	Repository  string   `json:"repository"`
+	Environment string   `json:"environment"`
+	File        string   `json:"file"`
+	Encrypted   bool     `json:"encrypted,omitempty"`
+	Keys        []string `json:"keys"`
+	Added       []string `json:"added,omitempty"`
+	Changed     []string `json:"changed,omitempty"`
+	LocalOnly   []string `json:"localOnly,omitempty"`
 }
 
 // runPull is the entry point for the pull command (uses default dependencies)
@@ -43,12 +91,26 @@ func runPull(cmd *cobra.Command, args []string) error {
 	opts.File, _ = cmd.Flags().GetString("file")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
 	opts.Force, _ = cmd.Flags().GetBool("force")
+	opts.Override, _ = cmd.Flags().GetBool("override")
+	opts.EncryptFor, _ = cmd.Flags().GetStringArray("encrypt-for")
+	opts.Armor, _ = cmd.Flags().GetBool("armor")
+	opts.CreateMissing, _ = cmd.Flags().GetBool("create-missing")
+	opts.Format, _ = cmd.Flags().GetString("format")
+	opts.SkipValidation, _ = cmd.Flags().GetBool("skip-validation")
+	opts.JSONOutput = outputFlag == outputJSON
+	if len(opts.EncryptFor) > 0 && !cmd.Flags().Changed("file") {
+		opts.File += ".age"
+	}
 
 	return runPullWithDeps(opts, defaultDeps)
 }
 
 // runPullWithDeps is the testable version of runPull
 func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
+	if opts.Format != "" {
+		return runPullFormatted(opts, deps)
+	}
+
 	deps.UI.Intro("pull")
 
 	// Check gitignore
@@ -71,48 +133,26 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 	}
 	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
 
-	token, err := deps.Auth.EnsureLogin()
+	ctx := context.Background()
+	token, public, err := ensureReadToken(ctx, deps, repo)
 	if err != nil {
 		deps.UI.Error(err.Error())
 		return err
 	}
 
 	client := deps.APIFactory.NewClient(token)
-	ctx := context.Background()
 
-	envName := opts.EnvName
-
-	// Prompt for environment if not specified
-	if !opts.EnvFlagSet && deps.UI.IsInteractive() {
-		// Fetch available environments
-		vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
-		if err != nil || len(vaultEnvs) == 0 {
-			vaultEnvs = []string{"development", "staging", "production"}
-		}
-
-		// Find default index
-		defaultIdx := 0
-		for i, e := range vaultEnvs {
-			if e == "development" {
-				defaultIdx = i
-				break
-			}
-		}
-
-		// Reorder to put default first
-		if defaultIdx > 0 {
-			vaultEnvs[0], vaultEnvs[defaultIdx] = vaultEnvs[defaultIdx], vaultEnvs[0]
-		}
-
-		selected, err := deps.UI.Select("Environment:", vaultEnvs)
-		if err != nil {
-			return err
-		}
-		envName = selected
+	envName, err := resolveEnvironment(ctx, deps, client, repo, public, "pull", opts.EnvName, opts.EnvFlagSet)
+	if err != nil {
+		return err
 	}
 
 	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
 
+	if err := enforceBranchPolicy(deps, envName, opts.Override); err != nil {
+		return err
+	}
+
 	// Track pull event
 	analytics.Track(analytics.EventPull, map[string]interface{}{
 		"repoFullName": repo,
@@ -121,12 +161,15 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 
 	var vaultContent string
 	err = deps.UI.Spin("Downloading secrets...", func() error {
-		resp, err := client.PullSecrets(ctx, repo, envName)
-		if err != nil {
-			return err
-		}
-		vaultContent = resp.Content
-		return nil
+		// Pulling is idempotent: retry on rate limits, 5xx and network failures
+		return api.WithRetry(ctx, func() error {
+			resp, err := client.PullSecrets(ctx, repo, envName)
+			if err != nil {
+				return err
+			}
+			vaultContent = resp.Content
+			return nil
+		})
 	})
 
 	if err != nil {
@@ -147,6 +190,17 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 				return nil
 			})
 		}
+		// The environment may not exist yet: anonymous readers can't create it
+		if err != nil && api.IsNotFound(err) && public == nil {
+			created, ensureErr := ensureEnvironment(ctx, deps, client, repo, envName, opts.CreateMissing)
+			if ensureErr != nil {
+				return ensureErr
+			}
+			if created {
+				// A new environment is empty
+				vaultContent, err = "", nil
+			}
+		}
 		if err != nil {
 			analytics.Track(analytics.EventError, map[string]interface{}{
 				"command": "pull",
@@ -164,6 +218,12 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		}
 	}
 
+	rawContent := vaultContent
+	vaultContent, err = resolveSharedRefs(ctx, deps, client, repo, vaultContent)
+	if err != nil {
+		return err
+	}
+
 	// Tip about keyway run (Zero-Trust)
 	if deps.UI.IsInteractive() {
 		deps.UI.Message("")
@@ -173,8 +233,40 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 	}
 
 	vaultSecrets := env.Parse(vaultContent)
+	if err := enforceLabelPolicy(deps, envName, sortedSecretKeys(vaultSecrets), opts.Override); err != nil {
+		return err
+	}
+	if err := enforceSchema(deps, envName, vaultSecrets, opts.SkipValidation); err != nil {
+		return err
+	}
 	envFilePath := filepath.Join(".", opts.File)
 
+	if len(opts.EncryptFor) > 0 {
+		written, err := writeEncryptedPull(opts, deps, envFilePath, vaultContent)
+		if err != nil || !written {
+			return err
+		}
+		recordActivity(deps, activity.Entry{
+			Action:      activity.ActionPull,
+			Repository:  repo,
+			Environment: envName,
+			VaultDigest: contentDigest(rawContent),
+			File:        opts.File,
+			Keys:        sortedSecretKeys(vaultSecrets),
+		})
+		deps.UI.Outro("Secrets encrypted!")
+		if opts.JSONOutput {
+			return printPullJSON(os.Stdout, pullResult{
+				Repository:  repo,
+				Environment: envName,
+				File:        opts.File,
+				Encrypted:   true,
+				Keys:        sortedSecretKeys(vaultSecrets),
+			})
+		}
+		return nil
+	}
+
 	// Read existing local file if it exists
 	var localSecrets map[string]string
 	localExists := false
@@ -255,6 +347,16 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 		return err
 	}
 
+	saveSyncBase(deps, repo, envName, env.Parse(rawContent))
+	recordActivity(deps, activity.Entry{
+		Action:      activity.ActionPull,
+		Repository:  repo,
+		Environment: envName,
+		VaultDigest: contentDigest(rawContent),
+		File:        opts.File,
+		Keys:        sortedSecretKeys(vaultSecrets),
+	})
+
 	lines := env.CountLines(finalContent)
 	deps.UI.Success(fmt.Sprintf("Secrets downloaded to %s", deps.UI.File(opts.File)))
 	deps.UI.Message(fmt.Sprintf("Variables: %s", deps.UI.Value(lines)))
@@ -265,5 +367,122 @@ func runPullWithDeps(opts PullOptions, deps *Dependencies) error {
 
 	deps.UI.Outro("Secrets synced!")
 
+	if opts.JSONOutput {
+		result := pullResult{
+			Repository:  repo,
+			Environment: envName,
+			File:        opts.File,
+			Keys:        sortedSecretKeys(vaultSecrets),
+		}
+		if localExists {
+			result.Added, result.Changed, result.LocalOnly = diff.Added, diff.Changed, diff.LocalOnly
+		}
+		return printPullJSON(os.Stdout, result)
+	}
+	return nil
+}
+
+// printPullJSON writes the --output json summary of a pull
+func printPullJSON(w io.Writer, result pullResult) error {
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(output))
+	return nil
+}
+
+// runPullFormatted prints the secrets with the --format template, sorted by
+// key. It never prompts and writes nothing to disk.
+func runPullFormatted(opts PullOptions, deps *Dependencies) error {
+	if len(opts.EncryptFor) > 0 {
+		return fmt.Errorf("--format and --encrypt-for cannot be used together")
+	}
+	tmpl, err := parseOutputFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, _, err := ensureReadToken(context.Background(), deps, repo)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	envName := opts.EnvName
+	if !opts.EnvFlagSet {
+		envName = defaultEnvironment(deps, "pull", envName)
+	}
+	if err := enforceBranchPolicy(deps, envName, opts.Override); err != nil {
+		return err
+	}
+
+	secrets, _, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, envName)
+	if err != nil {
+		return err
+	}
+	keys := sortedSecretKeys(secrets)
+	if err := enforceLabelPolicy(deps, envName, keys, opts.Override); err != nil {
+		return err
+	}
+	if err := enforceSchema(deps, envName, secrets, opts.SkipValidation); err != nil {
+		return err
+	}
+
+	// Render everything first: a template error must not leave partial output
+	var out bytes.Buffer
+	for _, key := range keys {
+		if err := executeOutputFormat(&out, tmpl, secretItem{Key: key, Value: secrets[key]}); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	}
+	if _, err := os.Stdout.Write(out.Bytes()); err != nil {
+		return err
+	}
+
+	recordActivity(deps, activity.Entry{
+		Action:      activity.ActionPull,
+		Repository:  repo,
+		Environment: envName,
+		Keys:        keys,
+	})
 	return nil
 }
+
+// writeEncryptedPull replaces the file with the vault content encrypted for the
+// recipients. Nothing is merged: the plaintext never touches the disk.
+func writeEncryptedPull(opts PullOptions, deps *Dependencies, path, content string) (bool, error) {
+	encrypted, err := encrypt.Encrypt([]byte(content), opts.EncryptFor, opts.Armor)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return false, err
+	}
+
+	if _, err := deps.FS.ReadFile(path); err == nil {
+		if !opts.Yes && deps.UI.IsInteractive() {
+			confirm, _ := deps.UI.Confirm(fmt.Sprintf("Replace %s?", opts.File), true)
+			if !confirm {
+				deps.UI.Warn("Pull aborted.")
+				return false, nil
+			}
+		} else if !opts.Yes {
+			return false, fmt.Errorf("file %s exists - use --yes to confirm", opts.File)
+		}
+	}
+
+	if err := deps.FS.WriteFile(path, encrypted, 0600); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to write file: %s", err.Error()))
+		return false, err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Secrets encrypted to %s for %d recipient(s)", deps.UI.File(opts.File), len(opts.EncryptFor)))
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Decrypt with: age -d -i <identity> %s", opts.File)))
+	return true, nil
+}
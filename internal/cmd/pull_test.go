@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/env"
 )
 
@@ -467,3 +471,110 @@ func TestRunPullWithDeps_APIErrorWithUpgradeURL(t *testing.T) {
 		t.Error("expected UI.Message to be called for upgrade URL")
 	}
 }
+
+func TestRunPullWithDeps_EncryptFor(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	fsMock.Files["prod.env.age"] = []byte("old")
+	identity, _ := age.GenerateX25519Identity()
+
+	opts := PullOptions{
+		EnvName:    "production",
+		EnvFlagSet: true,
+		File:       "prod.env.age",
+		Yes:        true,
+		EncryptFor: []string{identity.Recipient().String()},
+	}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written := fsMock.Written["prod.env.age"]
+	if len(written) == 0 || strings.Contains(string(written), "secret123") {
+		t.Fatalf("expected encrypted output, got %q", written)
+	}
+	r, err := age.Decrypt(bytes.NewReader(written), identity)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	plain, _ := io.ReadAll(r)
+	if string(plain) != "API_KEY=secret123" {
+		t.Errorf("expected vault content, got %q", plain)
+	}
+}
+
+func TestRunPullWithDeps_EncryptForInvalidRecipient(t *testing.T) {
+	deps, _, _, _, fsMock, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := PullOptions{EnvName: "production", EnvFlagSet: true, File: ".env.age", Yes: true, EncryptFor: []string{"gpg:0xDEADBEEF"}}
+	if err := runPullWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fsMock.Written) != 0 {
+		t.Error("nothing should be written")
+	}
+}
+
+func TestRunPullWithDeps_OffersToCreateMissingEnvironment(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	uiMock.Interactive = true
+	uiMock.ConfirmResult = true
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Environment not found"}
+	apiMock.VaultEnvs = []string{"development", "production"}
+
+	opts := PullOptions{EnvName: "staging", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.ConfirmCalls) == 0 || uiMock.ConfirmCalls[0] != "Environment staging does not exist in owner/repo. Create it?" {
+		t.Errorf("expected a create prompt, got %v", uiMock.ConfirmCalls)
+	}
+	if len(apiMock.CreatedEnvs) != 1 || apiMock.CreatedEnvs[0] != "staging" {
+		t.Errorf("expected staging to be created, got %v", apiMock.CreatedEnvs)
+	}
+	if _, ok := fsMock.Written[".env"]; !ok {
+		t.Error("expected the (empty) env file to be written")
+	}
+}
+
+func TestRunPullWithDeps_NotFoundForExistingEnvironment(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Not found"}
+	apiMock.VaultEnvs = []string{"development"}
+
+	opts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true, CreateMissing: true}
+	if err := runPullWithDeps(opts, deps); err == nil {
+		t.Fatal("expected the API error")
+	}
+	if len(apiMock.CreatedEnvs) != 0 {
+		t.Errorf("an existing environment must not be created, got %v", apiMock.CreatedEnvs)
+	}
+}
+
+func TestRunPullWithDeps_SchemaViolation(t *testing.T) {
+	deps, _, _, uiMock, fsMock, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DEBUG=perhaps\n"}
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Keys: map[string]config.KeyConfig{
+		"DEBUG": {Type: "bool"},
+	}}
+
+	opts := PullOptions{EnvName: "production", EnvFlagSet: true, File: ".env", Yes: true}
+	if err := runPullWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := fsMock.Written[".env"]; ok {
+		t.Error("expected no file to be written")
+	}
+	if len(uiMock.ErrorCalls) != 1 || !strings.Contains(uiMock.ErrorCalls[0], "DEBUG is not a valid bool") {
+		t.Errorf("unexpected errors: %v", uiMock.ErrorCalls)
+	}
+
+	opts.SkipValidation = true
+	if err := runPullWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected --skip-validation to pull, got %v", err)
+	}
+	if _, ok := fsMock.Written[".env"]; !ok {
+		t.Error("expected the file to be written")
+	}
+}
@@ -3,20 +3,33 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/syncbase"
 	"github.com/spf13/cobra"
 )
 
 var pushCmd = &cobra.Command{
 	Use:   "push",
 	Short: "Upload secrets from an env file to the vault",
-	Long:  `Upload secrets from a local .env file to the Keyway vault.`,
-	RunE:  runPush,
+	Long: `Upload secrets from a local .env file to the Keyway vault.
+
+Keys changed in the vault since your last pull or push from this machine are
+not reverted: values you did not edit locally keep the vault's version, and
+keys edited on both sides are resolved interactively (keep local, keep vault
+or enter a new value). Non-interactive pushes fail on such conflicts.
+
+Pushing to an environment that doesn't exist yet offers to create it;
+--create-missing creates it without asking (CI).
+
+--select lists the keys that would change, all checked, so you can uncheck
+the ones to leave as they are in the vault.`,
+	RunE: runPush,
 }
 
 func init() {
@@ -24,15 +37,21 @@ func init() {
 	pushCmd.Flags().StringP("file", "f", "", "Env file to push")
 	pushCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	pushCmd.Flags().Bool("prune", false, "Remove secrets from vault that are not in local file")
+	pushCmd.Flags().Bool("create-missing", false, "Create the environment if it doesn't exist, without asking")
+	pushCmd.Flags().Bool("skip-validation", false, "Push even if the result breaks the keyway.toml key schema")
+	pushCmd.Flags().Bool("select", false, "Choose interactively which changed keys to push (and remove with --prune)")
 }
 
 // PushOptions contains the parsed flags for the push command
 type PushOptions struct {
-	EnvName    string
-	File       string
-	Yes        bool
-	Prune      bool
-	EnvFlagSet bool
+	EnvName        string
+	File           string
+	Yes            bool
+	Prune          bool
+	EnvFlagSet     bool
+	CreateMissing  bool
+	SkipValidation bool
+	Select         bool
 }
 
 // runPush is the entry point for the push command (uses default dependencies)
@@ -44,6 +63,9 @@ func runPush(cmd *cobra.Command, args []string) error {
 	opts.File, _ = cmd.Flags().GetString("file")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
 	opts.Prune, _ = cmd.Flags().GetBool("prune")
+	opts.CreateMissing, _ = cmd.Flags().GetBool("create-missing")
+	opts.SkipValidation, _ = cmd.Flags().GetBool("skip-validation")
+	opts.Select, _ = cmd.Flags().GetBool("select")
 
 	return runPushWithDeps(opts, defaultDeps)
 }
@@ -52,6 +74,11 @@ func runPush(cmd *cobra.Command, args []string) error {
 func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 	deps.UI.Intro("push")
 
+	if opts.Select && !deps.UI.IsInteractive() {
+		deps.UI.Error("--select needs an interactive terminal")
+		return fmt.Errorf("--select needs an interactive terminal")
+	}
+
 	// Check gitignore
 	if !deps.Git.CheckEnvGitignore() {
 		deps.UI.Warn(".env files are not in .gitignore - secrets may be committed")
@@ -163,9 +190,9 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 	// Prompt for environment if not specified
 	if !opts.EnvFlagSet && deps.UI.IsInteractive() {
 		// Fetch available environments
-		vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
-		if err != nil || len(vaultEnvs) == 0 {
-			vaultEnvs = []string{"development", "staging", "production"}
+		vaultEnvs, err := environmentCandidates(ctx, deps, client, repo)
+		if err != nil {
+			return err
 		}
 
 		// Find current env in list or add it
@@ -180,6 +207,9 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		if !found && derivedEnv != "" {
 			vaultEnvs = append([]string{derivedEnv}, vaultEnvs...)
 		}
+		if vaultEnvs, err = accessibleEnvironments(ctx, deps, client, repo, vaultEnvs, true); err != nil {
+			return err
+		}
 
 		// Put derived env first
 		for i, e := range vaultEnvs {
@@ -202,12 +232,14 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 
 	// Fetch current vault state to show preview
 	var vaultSecrets map[string]string
+	notFound := false
 	err = deps.UI.Spin("Fetching current vault state...", func() error {
 		resp, err := client.PullSecrets(ctx, repo, envName)
 		if err != nil {
 			// Vault might not exist yet, that's ok
-			if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+			if api.IsNotFound(err) {
 				vaultSecrets = make(map[string]string)
+				notFound = true
 				return nil
 			}
 			return err
@@ -228,8 +260,9 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 			err = deps.UI.Spin("Fetching current vault state...", func() error {
 				resp, err := client.PullSecrets(ctx, repo, envName)
 				if err != nil {
-					if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+					if api.IsNotFound(err) {
 						vaultSecrets = make(map[string]string)
+						notFound = true
 						return nil
 					}
 					return err
@@ -248,9 +281,36 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		}
 	}
 
+	// The environment may not exist yet: create it rather than failing the upload
+	if notFound {
+		if _, err := ensureEnvironment(ctx, deps, client, repo, envName, opts.CreateMissing); err != nil {
+			return err
+		}
+	}
+
+	// A pulled file holds resolved shared values: keep their references
+	secrets, err = keepSharedRefs(ctx, deps, client, repo, secrets, vaultSecrets)
+	if err != nil {
+		return err
+	}
+
+	// Don't silently revert what others changed in the vault since the last pull
+	secrets, err = resolvePushConflicts(deps, repo, envName, secrets, vaultSecrets, opts.Prune)
+	if err != nil {
+		return err
+	}
+
 	// Calculate and show diff
 	diff := env.CalculatePushDiff(secrets, vaultSecrets)
 
+	if opts.Select && diff.HasChanges() {
+		secrets, err = selectPushChanges(deps, diff, secrets, vaultSecrets, opts.Prune)
+		if err != nil {
+			return err
+		}
+		diff = env.CalculatePushDiff(secrets, vaultSecrets)
+	}
+
 	// When --prune is NOT set, merge vault secrets into local (additive mode)
 	// This preserves vault-only secrets instead of deleting them
 	secretsToSend := secrets
@@ -298,6 +358,11 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		deps.UI.Info("No changes detected")
 	}
 
+	// Check what the environment will hold, not only the local file
+	if err := enforceSchema(deps, envName, secretsToSend, opts.SkipValidation); err != nil {
+		return err
+	}
+
 	// Confirm
 	if !opts.Yes && deps.UI.IsInteractive() {
 		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Push %d secrets from %s to %s?", len(secrets), file, repo), true)
@@ -317,11 +382,29 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 	})
 
 	var resp *api.PushSecretsResponse
-	err = deps.UI.Spin("Uploading secrets...", func() error {
-		var err error
-		resp, err = client.PushSecrets(ctx, repo, envName, secretsToSend)
-		return err
-	})
+	// An existing environment only needs the changed keys. A patch is applied
+	// atomically, but it is guarded by the digests of the values it replaces:
+	// when a response is lost, the retry of an applied patch gets a 409 (see
+	// below). Servers without delta support get the whole set.
+	patch := pushPatch(diff, secrets, vaultSecrets, opts.Prune)
+	usePatch := !notFound && !patch.IsEmpty()
+	patchAttempts := 0
+	upload := func() error {
+		return api.WithRetry(ctx, func() error {
+			var err error
+			if usePatch {
+				patchAttempts++
+				resp, err = client.PatchSecrets(ctx, repo, envName, patch)
+				if !api.IsPatchUnsupported(err) {
+					return err
+				}
+				usePatch = false
+			}
+			resp, err = client.PushSecrets(ctx, repo, envName, secretsToSend)
+			return err
+		})
+	}
+	err = deps.UI.Spin("Uploading secrets...", upload)
 
 	if err != nil {
 		// Handle auth errors (expired token)
@@ -332,17 +415,22 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 			}
 			// Retry with new token
 			client = deps.APIFactory.NewClient(newToken)
-			err = deps.UI.Spin("Uploading secrets...", func() error {
-				var pushErr error
-				resp, pushErr = client.PushSecrets(ctx, repo, envName, secretsToSend)
-				return pushErr
-			})
+			err = deps.UI.Spin("Uploading secrets...", upload)
+		}
+		if apiErr, ok := api.AsAPIError(err); ok && apiErr.Conflict() && patchAttempts > 1 && patchApplied(ctx, client, repo, envName, patch) {
+			// An earlier attempt was applied, only its response was lost
+			resp, err = &api.PushSecretsResponse{Success: true, Message: "Secrets pushed"}, nil
 		}
 		if err != nil {
 			analytics.Track(analytics.EventError, map[string]interface{}{
 				"command": "push",
 				"error":   err.Error(),
 			})
+			if apiErr, ok := api.AsAPIError(err); ok && apiErr.Conflict() {
+				deps.UI.Error(fmt.Sprintf("%s changed in the vault while pushing, nothing was uploaded", envName))
+				deps.UI.Message(deps.UI.Dim("Run keyway push again to review the new values"))
+				return err
+			}
 			if apiErr, ok := err.(*api.APIError); ok {
 				deps.UI.Error(apiErr.Error())
 				if apiErr.UpgradeURL != "" {
@@ -359,6 +447,13 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		}
 	}
 
+	saveSyncBase(deps, repo, envName, secretsToSend)
+
+	pushed := NotifyEvent{Event: "push", Repository: repo, Environment: envName, Added: diff.Added, Changed: diff.Changed}
+	if opts.Prune {
+		pushed.Removed = diff.Removed
+	}
+
 	deps.UI.Success(resp.Message)
 	if resp.Stats != nil {
 		parts := []string{}
@@ -376,8 +471,89 @@ func runPushWithDeps(opts PushOptions, deps *Dependencies) error {
 		}
 	}
 
+	notifyChange(deps, pushed)
+
 	dashboardURL := fmt.Sprintf("%s/vaults/%s", config.GetDashboardURL(), repo)
 	deps.UI.Outro(fmt.Sprintf("Dashboard: %s", deps.UI.Link(dashboardURL)))
 
 	return nil
 }
+
+// selectPushChanges asks which changed keys to push and returns secrets with
+// the unchecked ones as they are in the vault
+func selectPushChanges(deps *Dependencies, diff *env.PushDiff, secrets, vaultSecrets map[string]string, prune bool) (map[string]string, error) {
+	keys := append(append([]string{}, diff.Added...), diff.Changed...)
+	if prune {
+		keys = append(keys, diff.Removed...)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return secrets, nil
+	}
+
+	selected, err := deps.UI.MultiSelect("Keys to push", keys, keys)
+	if err != nil {
+		return nil, err
+	}
+	picked := make(map[string]bool, len(selected))
+	for _, key := range selected {
+		picked[key] = true
+	}
+
+	result := make(map[string]string, len(secrets))
+	for k, v := range secrets {
+		result[k] = v
+	}
+	for _, key := range keys {
+		if picked[key] {
+			continue
+		}
+		if v, ok := vaultSecrets[key]; ok {
+			result[key] = v
+		} else {
+			delete(result, key)
+		}
+	}
+	return result, nil
+}
+
+// patchApplied reports whether the vault holds what patch sets and deletes
+func patchApplied(ctx context.Context, client api.APIClient, repo, envName string, patch api.SecretsPatch) bool {
+	resp, err := client.PullSecrets(ctx, repo, envName)
+	if err != nil {
+		return false
+	}
+	vault := env.Parse(resp.Content)
+	for key, value := range patch.Set {
+		if current, ok := vault[key]; !ok || current != value {
+			return false
+		}
+	}
+	for _, key := range patch.Delete {
+		if _, ok := vault[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pushPatch returns the delta from vaultSecrets to secrets: added and changed
+// keys, and removed ones when pruning, each with the digest of the vault value
+// it replaces
+func pushPatch(diff *env.PushDiff, secrets, vaultSecrets map[string]string, prune bool) api.SecretsPatch {
+	patch := api.SecretsPatch{Set: map[string]string{}, Base: map[string]string{}}
+	for _, key := range append(append([]string{}, diff.Added...), diff.Changed...) {
+		patch.Set[key] = secrets[key]
+		patch.Base[key] = ""
+		if value, ok := vaultSecrets[key]; ok {
+			patch.Base[key] = syncbase.Digest(value)
+		}
+	}
+	if prune {
+		for _, key := range diff.Removed {
+			patch.Delete = append(patch.Delete, key)
+			patch.Base[key] = syncbase.Digest(vaultSecrets[key])
+		}
+	}
+	return patch
+}
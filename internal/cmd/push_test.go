@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/syncbase"
 )
 
 func TestRunPushWithDeps_Success(t *testing.T) {
@@ -746,3 +750,351 @@ func TestRunPushWithDeps_NoVaultOnlySecrets_NoPruneWarning(t *testing.T) {
 		t.Error("did not expect prune warning when there are no vault-only secrets")
 	}
 }
+
+func TestRunPushWithDeps_RetriesTemporaryFailure(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushErrors = []error{&api.APIError{StatusCode: 503}}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if apiMock.PushCalls != 2 {
+		t.Errorf("expected 2 push attempts, got %d", apiMock.PushCalls)
+	}
+}
+
+func TestRunPushWithDeps_DoesNotRetryPermanentFailure(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushError = &api.APIError{StatusCode: 400, Detail: "Invalid key"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if apiMock.PushCalls != 1 {
+		t.Errorf("expected a single push attempt, got %d", apiMock.PushCalls)
+	}
+}
+
+func TestRunPushWithDeps_MissingEnvironmentFailsWithoutCreateMissing(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "staging"}}
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Environment not found"}
+	apiMock.VaultEnvs = []string{"development", "production"}
+
+	opts := PushOptions{EnvName: "staging", File: ".env", Yes: true, EnvFlagSet: true}
+	err := runPushWithDeps(opts, deps)
+	if err == nil || err.Error() != `environment "staging" not found` {
+		t.Fatalf("expected missing environment error, got %v", err)
+	}
+	if apiMock.PushCalls != 0 || len(apiMock.CreatedEnvs) != 0 {
+		t.Error("nothing should be created or pushed")
+	}
+	if len(uiMock.MessageCalls) == 0 || !strings.Contains(uiMock.MessageCalls[len(uiMock.MessageCalls)-1], "--create-missing") {
+		t.Errorf("expected a --create-missing hint, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunPushWithDeps_CreateMissing(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=secret123")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "staging"}}
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Environment not found"}
+	apiMock.VaultEnvs = []string{"development", "production"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "staging", File: ".env", Yes: true, EnvFlagSet: true, CreateMissing: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.CreatedEnvs) != 1 || apiMock.CreatedEnvs[0] != "staging" {
+		t.Errorf("expected staging to be created, got %v", apiMock.CreatedEnvs)
+	}
+	if apiMock.PushCalls != 1 {
+		t.Errorf("expected one push, got %d", apiMock.PushCalls)
+	}
+}
+
+func TestRunPushWithDeps_SendsOnlyChangedKeys(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value\nDB_URL=same\nNEW_KEY=added")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value\nDB_URL=same\nVAULT_ONLY=kept"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	apiMock.PatchSupported = true
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.PushCalls != 0 {
+		t.Errorf("expected no full push, got %d", apiMock.PushCalls)
+	}
+	if apiMock.Patch == nil {
+		t.Fatal("expected a patch")
+	}
+	patch := apiMock.Patch
+	if len(patch.Set) != 2 || patch.Set["API_KEY"] != "new_value" || patch.Set["NEW_KEY"] != "added" {
+		t.Errorf("unexpected set: %v", patch.Set)
+	}
+	if len(patch.Delete) != 0 {
+		t.Errorf("expected no deletions without --prune, got %v", patch.Delete)
+	}
+	if patch.Base["API_KEY"] != syncbase.Digest("old_value") || patch.Base["NEW_KEY"] != "" {
+		t.Errorf("unexpected base: %v", patch.Base)
+	}
+}
+
+func TestRunPushWithDeps_PatchWithPruneDeletes(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=same")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=same\nVAULT_ONLY=removed"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	apiMock.PatchSupported = true
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true, Prune: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if apiMock.Patch == nil || len(apiMock.Patch.Set) != 0 {
+		t.Fatalf("expected a patch with no values, got %+v", apiMock.Patch)
+	}
+	if len(apiMock.Patch.Delete) != 1 || apiMock.Patch.Delete[0] != "VAULT_ONLY" {
+		t.Errorf("expected VAULT_ONLY deleted, got %v", apiMock.Patch.Delete)
+	}
+	if apiMock.Patch.Base["VAULT_ONLY"] != syncbase.Digest("removed") {
+		t.Errorf("unexpected base: %v", apiMock.Patch.Base)
+	}
+}
+
+func TestRunPushWithDeps_SelectLeavesUncheckedKeys(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	uiMock.Interactive = true
+	uiMock.MultiSelectResult = []string{"NEW_KEY"}
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value\nNEW_KEY=added")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value\nGONE=kept"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true, Prune: true, Select: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(uiMock.MultiSelectOptions) != 1 || strings.Join(uiMock.MultiSelectOptions[0], ",") != "API_KEY,GONE,NEW_KEY" {
+		t.Errorf("unexpected options: %v", uiMock.MultiSelectOptions)
+	}
+	pushed := apiMock.PushedSecrets
+	if len(pushed) != 3 || pushed["API_KEY"] != "old_value" || pushed["NEW_KEY"] != "added" || pushed["GONE"] != "kept" {
+		t.Errorf("expected only NEW_KEY to change, got %v", pushed)
+	}
+}
+
+func TestRunPushWithDeps_SelectNonInteractive(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true, Select: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if apiMock.PushCalls != 0 {
+		t.Error("expected no push")
+	}
+}
+
+func TestRunPushWithDeps_PatchConflict(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value"}
+	apiMock.PushError = &api.APIError{StatusCode: 409, Detail: "Conflict"}
+	apiMock.PatchSupported = true
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if apiMock.PushCalls != 0 {
+		t.Errorf("expected no full push after a conflict, got %d", apiMock.PushCalls)
+	}
+	if len(uiMock.ErrorCalls) == 0 || !strings.Contains(uiMock.ErrorCalls[0], "changed in the vault") {
+		t.Errorf("expected conflict error, got %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestRunPushWithDeps_RetriedPatchAlreadyApplied(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value"}
+	apiMock.PatchSupported = true
+	// The server applies a patch only when every key still matches its base
+	// digest, and answers 409 otherwise, even to a patch it already applied.
+	// The first response is lost.
+	apiMock.OnPatch = func() {
+		vault := env.Parse(apiMock.PullResponse.Content)
+		for key, base := range apiMock.Patch.Base {
+			if value, ok := vault[key]; (ok && syncbase.Digest(value) != base) || (!ok && base != "") {
+				apiMock.PatchErrors = []error{&api.APIError{StatusCode: 409, Detail: "Conflict"}}
+				return
+			}
+		}
+		for key, value := range apiMock.Patch.Set {
+			vault[key] = value
+		}
+		var lines []string
+		for key, value := range vault {
+			lines = append(lines, key+"="+value)
+		}
+		apiMock.PullResponse = &api.PullSecretsResponse{Content: strings.Join(lines, "\n")}
+		apiMock.PatchErrors = []error{&api.APIError{StatusCode: 503}}
+	}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected the applied patch to succeed, got %v", err)
+	}
+	if apiMock.PatchCalls != 2 || len(uiMock.ErrorCalls) != 0 {
+		t.Errorf("expected a retried patch without error, got %d calls, %v", apiMock.PatchCalls, uiMock.ErrorCalls)
+	}
+}
+
+func TestRunPushWithDeps_RetriedPatchConflict(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value"}
+	apiMock.PatchSupported = true
+	// Someone else changed the key between the two attempts
+	apiMock.OnPatch = func() { apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=theirs"} }
+	apiMock.PatchErrors = []error{&api.APIError{StatusCode: 503}, &api.APIError{StatusCode: 409, Detail: "Conflict"}}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected a conflict")
+	}
+	if len(uiMock.ErrorCalls) == 0 || !strings.Contains(uiMock.ErrorCalls[0], "changed in the vault") {
+		t.Errorf("expected conflict error, got %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestRunPushWithDeps_PullRoundTripKeepsSharedRefs(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value\nSENTRY_DSN=$ref:shared/DSN"}
+	apiMock.SharedValues = map[string]string{"DSN": "https://sentry.io/1"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	pullOpts := PullOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPullWithDeps(pullOpts, deps); err != nil {
+		t.Fatalf("pull: unexpected error: %v", err)
+	}
+	pulled := string(fsMock.Written[".env"])
+	if !strings.Contains(pulled, "SENTRY_DSN=https://sentry.io/1") {
+		t.Fatalf("expected the pulled file to hold the resolved value, got %q", pulled)
+	}
+
+	fsMock.Files[".env"] = []byte(strings.Replace(pulled, "old_value", "new_value", 1))
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+
+	pushOpts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(pushOpts, deps); err != nil {
+		t.Fatalf("push: unexpected error: %v", err)
+	}
+	if apiMock.PushedSecrets["SENTRY_DSN"] != "$ref:shared/DSN" || apiMock.PushedSecrets["API_KEY"] != "new_value" {
+		t.Errorf("expected the shared reference kept, got %v", apiMock.PushedSecrets)
+	}
+}
+
+func TestRunPushWithDeps_PatchUnsupportedFallsBackToFullPush(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("API_KEY=new_value")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "development"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=old_value\nVAULT_ONLY=kept"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := PushOptions{EnvName: "development", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushCalls != 1 || apiMock.PushedSecrets["VAULT_ONLY"] != "kept" {
+		t.Errorf("expected a full push with vault-only keys, got %d calls: %v", apiMock.PushCalls, apiMock.PushedSecrets)
+	}
+}
+
+func TestRunPushWithDeps_SchemaViolation(t *testing.T) {
+	deps, _, _, uiMock, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	fsMock.Files[".env"] = []byte("PORT=http")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "production"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Keys: map[string]config.KeyConfig{
+		"PORT":         {Type: "port"},
+		"DATABASE_URL": {Required: true},
+	}}
+
+	opts := PushOptions{EnvName: "production", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if apiMock.PushCalls != 0 {
+		t.Error("expected nothing to be pushed")
+	}
+	if len(uiMock.ErrorCalls) != 1 || !strings.Contains(uiMock.ErrorCalls[0], "DATABASE_URL is required but missing; PORT is not a valid port") {
+		t.Errorf("unexpected errors: %v", uiMock.ErrorCalls)
+	}
+
+	opts.SkipValidation = true
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected --skip-validation to push, got %v", err)
+	}
+	if apiMock.PushCalls != 1 {
+		t.Errorf("expected a push, got %d", apiMock.PushCalls)
+	}
+}
+
+func TestRunPushWithDeps_SchemaCountsVaultOnlyKeys(t *testing.T) {
+	deps, _, _, _, fsMock, envMock, apiMock := NewTestDepsWithEnv()
+
+	// Without --prune the vault keeps DATABASE_URL, so the result is valid
+	fsMock.Files[".env"] = []byte("PORT=8080")
+	envMock.Candidates = []EnvCandidate{{File: ".env", Env: "production"}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DATABASE_URL=postgres://db/app"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Keys: map[string]config.KeyConfig{
+		"DATABASE_URL": {Required: true},
+	}}
+
+	opts := PushOptions{EnvName: "production", File: ".env", Yes: true, EnvFlagSet: true}
+	if err := runPushWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	opts.Prune = true
+	if err := runPushWithDeps(opts, deps); err == nil {
+		t.Fatal("expected pruning DATABASE_URL to break the schema")
+	}
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var refsCmd = &cobra.Command{
+	Use:   "refs",
+	Short: "Manage shared values referenced by environments",
+	Long: `Manage vault-level shared values.
+
+A secret whose value is $ref:shared/NAME is replaced by the shared value NAME
+when it is pulled or injected, so values common to several environments are
+defined once.
+
+Examples:
+  keyway refs list
+  keyway refs set SENTRY_DSN=https://...
+  keyway set SENTRY_DSN '$ref:shared/SENTRY_DSN' -e production
+  keyway refs unset SENTRY_DSN`,
+}
+
+var refsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List shared value names",
+	Args:  cobra.NoArgs,
+	RunE:  runRefsList,
+}
+
+var refsSetCmd = &cobra.Command{
+	Use:   "set <NAME>[=VALUE]",
+	Short: "Create or update a shared value",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runRefsSet,
+}
+
+var refsUnsetCmd = &cobra.Command{
+	Use:   "unset <NAME>",
+	Short: "Delete a shared value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRefsUnset,
+}
+
+func init() {
+	refsCmd.AddCommand(refsListCmd)
+	refsCmd.AddCommand(refsSetCmd)
+	refsCmd.AddCommand(refsUnsetCmd)
+}
+
+func runRefsList(cmd *cobra.Command, args []string) error {
+	return runRefsListWithDeps(defaultDeps)
+}
+
+// runRefsListWithDeps is the testable version of runRefsList
+func runRefsListWithDeps(deps *Dependencies) error {
+	deps.UI.Intro("refs list")
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	var shared map[string]string
+	err = deps.UI.Spin("Fetching shared values...", func() error {
+		var fetchErr error
+		shared, fetchErr = client.GetSharedValues(context.Background(), repo)
+		return fetchErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if len(shared) == 0 {
+		deps.UI.Message("No shared values yet.")
+		deps.UI.Message(deps.UI.Dim("Create one with: keyway refs set NAME=VALUE"))
+		return nil
+	}
+
+	names := make([]string, 0, len(shared))
+	for name := range shared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		deps.UI.Message(fmt.Sprintf("  %s %s", name, deps.UI.Dim(env.RefPrefix+name)))
+	}
+	deps.UI.Outro(fmt.Sprintf("%d shared values", len(names)))
+	return nil
+}
+
+func runRefsSet(cmd *cobra.Command, args []string) error {
+	name, value := args[0], ""
+	if strings.Contains(name, "=") {
+		parts := strings.SplitN(name, "=", 2)
+		name, value = parts[0], parts[1]
+	} else if len(args) > 1 {
+		value = args[1]
+	}
+	return runRefsSetWithDeps(name, value, defaultDeps)
+}
+
+// runRefsSetWithDeps is the testable version of runRefsSet
+func runRefsSetWithDeps(name, value string, deps *Dependencies) error {
+	deps.UI.Intro("refs set")
+
+	if !isValidKeyName(name) {
+		deps.UI.Error("Name must contain only alphanumeric characters and underscores")
+		return fmt.Errorf("invalid name format")
+	}
+
+	if value == "" {
+		if !deps.UI.IsInteractive() {
+			deps.UI.Error("Value is required in non-interactive mode")
+			return fmt.Errorf("value is required")
+		}
+		var err error
+		value, err = deps.UI.Password(fmt.Sprintf("Enter value for %s:", name))
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			deps.UI.Error("Value cannot be empty")
+			return fmt.Errorf("value cannot be empty")
+		}
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	err = deps.UI.Spin("Saving shared value...", func() error {
+		return client.SetSharedValue(context.Background(), repo, name, value)
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Saved %s", name))
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Reference it with: %s%s", env.RefPrefix, name)))
+	return nil
+}
+
+func runRefsUnset(cmd *cobra.Command, args []string) error {
+	return runRefsUnsetWithDeps(args[0], defaultDeps)
+}
+
+// runRefsUnsetWithDeps is the testable version of runRefsUnset
+func runRefsUnsetWithDeps(name string, deps *Dependencies) error {
+	deps.UI.Intro("refs unset")
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	err = deps.UI.Spin("Deleting shared value...", func() error {
+		return client.DeleteSharedValue(context.Background(), repo, name)
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Deleted %s", name))
+	deps.UI.Message(deps.UI.Dim("Environments still referencing it will fail to resolve on pull."))
+	return nil
+}
+
+// refsSetup detects the repository and returns an authenticated client
+func refsSetup(deps *Dependencies) (string, api.APIClient, error) {
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return "", nil, err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return "", nil, err
+	}
+
+	return repo, deps.APIFactory.NewClient(token), nil
+}
+
+// resolveSharedRefs replaces $ref:shared/NAME values in env content.
+// The shared values are only fetched when the content contains references.
+func resolveSharedRefs(ctx context.Context, deps *Dependencies, client api.APIClient, repo, content string) (string, error) {
+	if !env.HasRefs(env.Parse(content)) {
+		return content, nil
+	}
+
+	var shared map[string]string
+	err := deps.UI.Spin("Resolving shared values...", func() error {
+		var fetchErr error
+		shared, fetchErr = client.GetSharedValues(ctx, repo)
+		return fetchErr
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to fetch shared values: %s", err.Error()))
+		return "", err
+	}
+
+	resolved, missing := env.ResolveRefsInContent(content, shared)
+	if len(missing) > 0 {
+		deps.UI.Error(fmt.Sprintf("Unresolved shared references: %s", strings.Join(missing, ", ")))
+		deps.UI.Message(deps.UI.Dim("Create them with: keyway refs set NAME=VALUE"))
+		return "", fmt.Errorf("unresolved shared references")
+	}
+
+	return resolved, nil
+}
+
+// keepSharedRefs puts the vault's $ref:shared/ values back where the local
+// secrets hold what they resolve to, as keyway pull writes them, so pushing
+// a pulled file does not unlink keys from their shared values
+func keepSharedRefs(ctx context.Context, deps *Dependencies, client api.APIClient, repo string, local, vault map[string]string) (map[string]string, error) {
+	var linked []string
+	for key, value := range vault {
+		if localValue, ok := local[key]; ok && localValue != value && env.RefName(value) != "" {
+			linked = append(linked, key)
+		}
+	}
+	if len(linked) == 0 {
+		return local, nil
+	}
+
+	var shared map[string]string
+	err := deps.UI.Spin("Resolving shared values...", func() error {
+		var fetchErr error
+		shared, fetchErr = client.GetSharedValues(ctx, repo)
+		return fetchErr
+	})
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to fetch shared values: %s", err.Error()))
+		return nil, err
+	}
+
+	kept := make(map[string]string, len(local))
+	for k, v := range local {
+		kept[k] = v
+	}
+	for _, key := range linked {
+		if sharedValue, ok := shared[env.RefName(vault[key])]; ok && local[key] == sharedValue {
+			kept[key] = vault[key]
+		}
+	}
+	return kept, nil
+}
+
+// isValidKeyName returns true for names made of letters, digits and underscores
+func isValidKeyName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !((c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestResolveSharedRefs_NoRefsSkipsFetch(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.SharedValuesError = errors.New("should not be called")
+
+	content, err := resolveSharedRefs(context.Background(), deps, apiMock, "owner/repo", "A=1\n")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "A=1\n" {
+		t.Errorf("content changed: %q", content)
+	}
+}
+
+func TestResolveSharedRefs_Resolves(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.SharedValues = map[string]string{"DSN": "https://sentry.io/1"}
+
+	content, err := resolveSharedRefs(context.Background(), deps, apiMock, "owner/repo", "SENTRY_DSN=$ref:shared/DSN\n")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "SENTRY_DSN=https://sentry.io/1\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestResolveSharedRefs_Missing(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.SharedValues = map[string]string{}
+
+	_, err := resolveSharedRefs(context.Background(), deps, apiMock, "owner/repo", "SENTRY_DSN=$ref:shared/DSN\n")
+
+	if err == nil {
+		t.Fatal("expected error for unresolved reference")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunRunWithDeps_ResolvesSharedRefs(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "SENTRY_DSN=$ref:shared/DSN\nA=1"}
+	apiMock.SharedValues = map[string]string{"DSN": "https://sentry.io/1"}
+	runner := deps.CmdRunner.(*MockCommandRunner)
+
+	err := runRunWithDeps(RunOptions{EnvName: "development", EnvFlagSet: true, Command: "echo"}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runner.LastSecrets["SENTRY_DSN"] != "https://sentry.io/1" {
+		t.Errorf("expected resolved value to be injected, got %q", runner.LastSecrets["SENTRY_DSN"])
+	}
+}
+
+func TestRunRefsSetWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+
+	err := runRefsSetWithDeps("SENTRY_DSN", "https://sentry.io/1", deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiMock.SharedSet["SENTRY_DSN"] != "https://sentry.io/1" {
+		t.Errorf("expected shared value to be saved, got %v", apiMock.SharedSet)
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected Success to be called")
+	}
+}
+
+func TestRunRefsSetWithDeps_InvalidName(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+
+	if err := runRefsSetWithDeps("BAD-NAME", "x", deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(apiMock.SharedSet) != 0 {
+		t.Error("nothing should be saved")
+	}
+}
+
+func TestRunRefsSetWithDeps_ValueRequiredNonInteractive(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	if err := runRefsSetWithDeps("SENTRY_DSN", "", deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRunRefsListWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.SharedValues = map[string]string{"B": "2", "A": "1"}
+
+	if err := runRefsListWithDeps(deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, msg := range uiMock.MessageCalls {
+		if msg == "1" || msg == "2" {
+			t.Errorf("values must not be printed: %q", msg)
+		}
+	}
+	if len(uiMock.MessageCalls) != 2 {
+		t.Errorf("expected 2 lines, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunRefsUnsetWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+
+	if err := runRefsUnsetWithDeps("SENTRY_DSN", deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(apiMock.SharedDeleted) != 1 || apiMock.SharedDeleted[0] != "SENTRY_DSN" {
+		t.Errorf("unexpected deletes: %v", apiMock.SharedDeleted)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+)
+
+// Injection sources recorded in the report
+const (
+	sourceVault    = "vault"
+	sourceShared   = "shared"
+	sourceOverlay  = "overlay"
+	sourcePort     = "port"
+	sourceProfile  = "profile"
+	sourceAutoload = "autoload"
+)
+
+// lookupHostEnv is swapped in tests
+var lookupHostEnv = os.LookupEnv
+
+// InjectionReport is the audit artifact written by --report. It never contains values.
+type InjectionReport struct {
+	Repository  string        `json:"repository"`
+	Environment string        `json:"environment"`
+	VaultDigest string        `json:"vaultDigest"` // sha256 of the pulled environment content
+	Command     string        `json:"command"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Keys        []InjectedKey `json:"keys"`
+}
+
+// InjectedKey describes where an injected variable came from
+type InjectedKey struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Origin      string `json:"origin,omitempty"`      // Overlay or autoloaded file, profile name for profile overrides
+	ShadowsHost bool   `json:"shadowsHost,omitempty"` // The host environment had the same variable
+}
+
+// keySources tracks the source of each injected key
+type keySources map[string]InjectedKey
+
+func (s keySources) set(name, source, origin string) {
+	s[name] = InjectedKey{Name: name, Source: source, Origin: origin}
+}
+
+// newInjectionReport builds a report from the tracked sources, sorted by key
+func newInjectionReport(repo, envName, vaultContent, command string, sources keySources) *InjectionReport {
+	report := &InjectionReport{
+		Repository:  repo,
+		Environment: envName,
+		VaultDigest: contentDigest(vaultContent),
+		Command:     command,
+		GeneratedAt: time.Now().UTC(),
+		Keys:        make([]InjectedKey, 0, len(sources)),
+	}
+
+	for _, key := range sources {
+		if _, ok := lookupHostEnv(key.Name); ok {
+			key.ShadowsHost = true
+		}
+		report.Keys = append(report.Keys, key)
+	}
+	sort.Slice(report.Keys, func(i, j int) bool { return report.Keys[i].Name < report.Keys[j].Name })
+
+	return report
+}
+
+// contentDigest identifies a version of environment content without revealing it
+func contentDigest(content string) string {
+	digest := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(digest[:])
+}
+
+// writeInjectionReport writes the report as JSON with owner-only permissions
+func writeInjectionReport(deps *Dependencies, path string, report *InjectionReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return deps.FS.WriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestNewInjectionReport(t *testing.T) {
+	original := lookupHostEnv
+	defer func() { lookupHostEnv = original }()
+	lookupHostEnv = func(key string) (string, bool) { return "", key == "PATH_LIKE" }
+
+	sources := keySources{}
+	sources.set("B_KEY", sourceVault, "")
+	sources.set("A_KEY", sourceOverlay, "local.env")
+	sources.set("PATH_LIKE", sourceVault, "")
+
+	report := newInjectionReport("owner/repo", "production", "B_KEY=1", "./deploy.sh", sources)
+
+	if report.Repository != "owner/repo" || report.Environment != "production" || report.Command != "./deploy.sh" {
+		t.Errorf("unexpected header: %+v", report)
+	}
+	if len(report.VaultDigest) != 64 {
+		t.Errorf("expected sha256 digest, got %q", report.VaultDigest)
+	}
+	if len(report.Keys) != 3 || report.Keys[0].Name != "A_KEY" {
+		t.Fatalf("keys should be sorted, got %+v", report.Keys)
+	}
+	if report.Keys[0].Origin != "local.env" {
+		t.Errorf("expected overlay origin, got %+v", report.Keys[0])
+	}
+	if !report.Keys[2].ShadowsHost || report.Keys[1].ShadowsHost {
+		t.Errorf("unexpected shadowsHost flags: %+v", report.Keys)
+	}
+}
+
+func TestRunRunWithDeps_Report(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nDSN=$ref:shared/DSN\nDB_URL=postgres://vault"}
+	apiMock.SharedValues = map[string]string{"DSN": "https://sentry.io/1"}
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["local.env"] = []byte("DB_URL=postgres://localhost")
+
+	opts := RunOptions{
+		EnvName:    "development",
+		EnvFlagSet: true,
+		Command:    "npm",
+		Overlays:   []string{"local.env"},
+		ReportFile: "injection.json",
+	}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastCommand != "npm" {
+		t.Error("command should still run")
+	}
+
+	data, ok := fs.Written["injection.json"]
+	if !ok {
+		t.Fatal("expected report to be written")
+	}
+	for _, value := range []string{"secret123", "postgres://", "sentry.io"} {
+		if strings.Contains(string(data), value) {
+			t.Errorf("report must not contain values, found %q", value)
+		}
+	}
+
+	var report InjectionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	want := map[string]string{"API_KEY": sourceVault, "DSN": sourceShared, "DB_URL": sourceOverlay}
+	for _, key := range report.Keys {
+		if want[key.Name] != key.Source {
+			t.Errorf("%s: source %q, want %q", key.Name, key.Source, want[key.Name])
+		}
+	}
+	if len(report.Keys) != 3 {
+		t.Errorf("expected 3 keys, got %d", len(report.Keys))
+	}
+}
+
+func TestRunRunWithDeps_ReportWriteError(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+	deps.FS.(*MockFileSystem).WriteError = errors.New("read-only filesystem")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", ReportFile: "injection.json"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected error")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run without the requested audit report")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
@@ -2,14 +2,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/fatih/color"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/auth"
 	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/git"
+	"github.com/keywaysh/cli/internal/hints"
 	"github.com/keywaysh/cli/internal/ui"
 	"github.com/keywaysh/cli/internal/version"
 	"github.com/pkg/browser"
@@ -22,6 +25,24 @@ var (
 	cyan = color.New(color.FgCyan).SprintFunc()
 )
 
+// repoFlag holds the global --repo flag
+var repoFlag string
+
+// noRetryFlag holds the global --no-retry flag
+var noRetryFlag bool
+
+// applyRetries sets how many times idempotent API requests are retried, from
+// KEYWAY_RETRIES unless --no-retry is set. An invalid KEYWAY_RETRIES keeps
+// the default and is returned.
+func applyRetries() error {
+	retries, err := config.Retries()
+	if noRetryFlag {
+		retries = 0
+	}
+	api.SetRetries(retries)
+	return err
+}
+
 var rootCmd = &cobra.Command{
 	Use:           "keyway",
 	Short:         "Sync secrets with your team and infra",
@@ -109,19 +130,19 @@ func runActionMenu(cmd *cobra.Command, token string) error {
 	if err != nil {
 		// Check error type
 		if apiErr, ok := err.(*api.APIError); ok {
-			switch apiErr.StatusCode {
-			case 401:
+			switch {
+			case apiErr.AuthExpired():
 				// Token expired: clear and prompt re-login
 				store := auth.NewStore()
 				_ = store.ClearAuth()
 				ui.Warn("Session expired")
 				ui.Message(ui.Dim("Run: keyway login"))
 				return err
-			case 403:
+			case apiErr.Forbidden():
 				ui.Error("Permission denied")
 				ui.Message(ui.Dim("You don't have access to this repository's vault."))
 				return err
-			case 404:
+			case apiErr.NotFound():
 				// Vault doesn't exist: run init flow
 				ui.Message("")
 				ui.Message("No vault found for this repository. Let's set one up!")
@@ -181,6 +202,16 @@ func printCustomHelp(cmd *cobra.Command) {
 	fmt.Printf("  %s  %s\n", bold("keyway"), dim("— Sync secrets with your team and infra"))
 	fmt.Println()
 
+	// Suggestions for the project in the working directory
+	if tips := hints.For(".", 3); len(tips) > 0 {
+		fmt.Printf("  %s\n", bold("For this project:"))
+		for _, tip := range tips {
+			fmt.Printf("    %s\n", cyan(tip.Command))
+			fmt.Printf("      %s\n", dim(tip.Description))
+		}
+		fmt.Println()
+	}
+
 	// Core Commands
 	fmt.Printf("  %s\n", bold("Core Commands:"))
 	fmt.Printf("    %s           %s\n", cyan("keyway init"), "Initialize vault for this repo")
@@ -188,6 +219,7 @@ func printCustomHelp(cmd *cobra.Command) {
 	fmt.Printf("    %s           %s\n", cyan("keyway pull"), "Download secrets from vault")
 	fmt.Printf("    %s            %s\n", cyan("keyway set"), "Set a single secret in vault")
 	fmt.Printf("    %s            %s\n", cyan("keyway run"), "Run command with injected secrets (Zero-Trust)")
+	fmt.Printf("    %s            %s\n", cyan("keyway dev"), "Start the project with secrets, output masked")
 	fmt.Printf("    %s           %s\n", cyan("keyway login"), "Sign in with GitHub")
 	fmt.Println()
 
@@ -201,9 +233,33 @@ func printCustomHelp(cmd *cobra.Command) {
 
 	// Utilities
 	fmt.Printf("  %s\n", bold("Utilities:"))
+	fmt.Printf("    %s           %s\n", cyan("keyway envs"), "List, create, delete and clone environments")
 	fmt.Printf("    %s           %s\n", cyan("keyway diff"), "Compare secrets between environments")
+	fmt.Printf("    %s        %s\n", cyan("keyway secrets"), "Set, get, unset and list individual secrets")
+	fmt.Printf("    %s           %s\n", cyan("keyway show"), "List keys and when they were last pulled")
+	fmt.Printf("    %s          %s\n", cyan("keyway flags"), "Turn feature flags on and off per environment")
+	fmt.Printf("    %s          %s\n", cyan("keyway stats"), "Show vault statistics per environment")
+	fmt.Printf("    %s           %s\n", cyan("keyway refs"), "Manage shared values across environments")
+	fmt.Printf("    %s         %s\n", cyan("keyway config"), "Validate keyway.toml")
+	fmt.Printf("    %s           %s\n", cyan("keyway docs"), "Generate a reference of the vault's keys")
+	fmt.Printf("    %s           %s\n", cyan("keyway link"), "Follow a renamed or transferred repository")
+	fmt.Printf("    %s       %s\n", cyan("keyway activity"), "Show local injection history")
+	fmt.Printf("    %s          %s\n", cyan("keyway cloud"), "Run one-off cloud tasks with secrets")
+	fmt.Printf("    %s            %s\n", cyan("keyway k8s"), "Render secrets as a Kubernetes Secret manifest")
+	fmt.Printf("    %s           %s\n", cyan("keyway helm"), "Run helm with secrets as a values file")
+	fmt.Printf("    %s          %s\n", cyan("keyway serve"), "Local socket for desktop apps (Docker Desktop)")
+	fmt.Printf("    %s         %s\n", cyan("keyway daemon"), "Keep secrets warm for fast repeated commands")
+	fmt.Printf("    %s       %s\n", cyan("keyway webhooks"), "Notify your services of vault events")
+	fmt.Printf("    %s          %s\n", cyan("keyway token"), "Create machine tokens for CI (KEYWAY_TOKEN)")
+	fmt.Printf("    %s           %s\n", cyan("keyway exec"), "Run, replacing keyway with the command")
+	fmt.Printf("    %s      %s\n", cyan("keyway exec-init"), "Container entrypoint with secrets injected")
 	fmt.Printf("    %s           %s\n", cyan("keyway scan"), "Scan codebase for leaked secrets")
+	fmt.Printf("    %s           %s\n", cyan("keyway lint"), "Check secrets against framework conventions")
 	fmt.Printf("    %s         %s\n", cyan("keyway doctor"), "Check your setup")
+	fmt.Printf("    %s          %s\n", cyan("keyway clean"), "Remove files keyway left behind")
+	fmt.Printf("    %s       %s\n", cyan("keyway feedback"), "Report a bug with diagnostics prefilled")
+	fmt.Printf("    %s         %s\n", cyan("keyway whoami"), "Show the account you are logged in with")
+	fmt.Printf("    %s        %s\n", cyan("keyway account"), "Switch between GitHub accounts, per repository")
 	fmt.Printf("    %s         %s\n", cyan("keyway logout"), "Clear stored credentials")
 	fmt.Println()
 
@@ -222,40 +278,103 @@ func printCustomHelp(cmd *cobra.Command) {
 func Execute(ver string) error {
 	rootCmd.Version = ver
 
-	// Start non-blocking version check
-	updateChan := make(chan *version.UpdateInfo, 1)
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), version.CheckTimeout)
-		defer cancel()
-		info := version.CheckForUpdate(ctx, ver)
-		updateChan <- info
-	}()
+	profile := newStartupProfile(profileStartupRequested(os.Args[1:]))
+	defer profile.Print(os.Stderr)
+	profile.Mark("init")
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		profile.Mark("parse")
+		// Invalid values fall back to the defaults; say so instead of ignoring them
+		yellow := color.New(color.FgYellow).SprintFunc()
+		if _, err := config.DefaultAPITimeouts.ApplyEnv(); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", yellow("!"), err)
+		}
+		if _, err := config.OfflineTTL(); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", yellow("!"), err)
+		}
+		if err := applyRetries(); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", yellow("!"), err)
+		}
+		if _, err := auth.CredentialStoreMode(); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", yellow("!"), err)
+		}
+		return applyOutputFlag(cmd, defaultDeps, os.Stderr)
+	}
+
+	addUnknownSubcommandErrors(rootCmd)
+	addNameCompletions(rootCmd)
 
 	// Execute the command
-	err := rootCmd.Execute()
+	var executed *cobra.Command
+	args, err := expandArgFiles(os.Args[1:], defaultDeps.FS.ReadFile)
+	if err == nil {
+		rootCmd.SetArgs(args)
+		err = applyConfigAlias(rootCmd, args, defaultDeps)
+	}
+	if err == nil {
+		executed, err = rootCmd.ExecuteC()
+	}
+	profile.Mark("run")
+
+	if err != nil && jsonRequested(executed) {
+		writeJSONError(os.Stdout, err)
+		return err
+	}
 
 	// Display error and help for unknown commands
 	if err != nil {
 		red := color.New(color.FgRed).SprintFunc()
 		fmt.Fprintf(os.Stderr, "\n  %s %s\n", red("Error:"), err)
+		var upgradeErr *api.UpgradeRequiredError
+		if errors.As(err, &upgradeErr) {
+			fmt.Fprintf(os.Stderr, "  Upgrade with: %s\n\n", version.GetUpdateCommand(version.DetectInstallMethod()))
+			return err
+		}
 		fmt.Println()
 		printCustomHelp(rootCmd)
 		return err
 	}
 
-	// Display update notice if available (non-blocking receive)
-	select {
-	case info := <-updateChan:
-		if info != nil && info.Available {
-			displayUpdateNotice(info)
-		}
-	default:
-		// Check not complete, don't wait
+	// Only read the cache here: the GitHub request never delays a command.
+	// A stale cache is refreshed by a detached process for the next run.
+	info, stale := version.CheckCachedUpdate(ver)
+	if stale {
+		_ = spawnUpdateCheck()
+	}
+	profile.Mark("update check")
+
+	if info != nil && info.Available {
+		displayUpdateNotice(info)
 	}
 
 	return nil
 }
 
+// spawnUpdateCheck refreshes the version cache in a detached background process
+func spawnUpdateCheck() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, updateCheckCmd.Name())
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// updateCheckCmd is spawned by Execute when the version cache is stale
+var updateCheckCmd = &cobra.Command{
+	Use:    "update-check",
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := version.CheckContext()
+		defer cancel()
+		return version.RefreshCache(ctx)
+	},
+}
+
 func displayUpdateNotice(info *version.UpdateInfo) {
 	yellow := color.New(color.FgYellow).SprintFunc()
 	fmt.Println()
@@ -277,6 +396,7 @@ func init() {
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(setCmd)
 	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(connectCmd)
 	rootCmd.AddCommand(connectionsCmd)
 	rootCmd.AddCommand(disconnectCmd)
@@ -285,4 +405,53 @@ func init() {
 	rootCmd.AddCommand(diffCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(devCmd)
+	rootCmd.AddCommand(secretsCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(refsCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(activityCmd)
+	rootCmd.AddCommand(cloudCmd)
+	rootCmd.AddCommand(k8sCmd)
+	rootCmd.AddCommand(helmCmd)
+	rootCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(webhooksCmd)
+	rootCmd.AddCommand(tokenCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(execInitCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(feedbackCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(flagsCmd)
+	rootCmd.AddCommand(envsCmd)
+	rootCmd.AddCommand(whoamiCmd)
+	rootCmd.AddCommand(accountCmd)
+	rootCmd.AddCommand(sandboxExecCmd)
+	rootCmd.AddCommand(updateCheckCmd)
+	rootCmd.AddCommand(namesRefreshCmd)
+
+	rootCmd.SetFlagErrorFunc(flagErrorWithSuggestion)
+
+	// keyway help and keyway --help show the same overview as the bare command
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if cmd == rootCmd {
+			printCustomHelp(cmd)
+			return
+		}
+		defaultHelp(cmd, args)
+	})
+
+	rootCmd.PersistentFlags().StringVar(&repoFlag, "repo", "", "GitHub repository (owner/repo), overrides detection from git")
+	rootCmd.PersistentFlags().StringVar(&outputFlag, "output", outputText, "Output format: text or json (for scripts)")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "Read secrets from the offline cache without calling the API")
+	rootCmd.PersistentFlags().BoolVar(&noRetryFlag, "no-retry", false, "Fail on the first network error, 429 or 5xx instead of retrying")
+	rootCmd.PersistentFlags().BoolVar(&strictEnvs, "strict-envs", false, "Fail instead of offering fallback environments when they cannot be listed")
+	cobra.OnInitialize(func() { git.SetRepoOverride(repoOverride(repoFlag)) })
+
+	rootCmd.PersistentFlags().Bool("profile-startup", false, "Print startup timing breakdown to stderr")
+	_ = rootCmd.PersistentFlags().MarkHidden("profile-startup")
 }
@@ -3,10 +3,17 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
 	"github.com/keywaysh/cli/internal/env"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var runCmd = &cobra.Command{
@@ -14,20 +21,84 @@ var runCmd = &cobra.Command{
 	Short: "Inject secrets into a command",
 	Long:  `Run a command with secrets injected into the environment.
 Secrets are fetched from the vault and injected directly into the process memory.
-They are never written to disk.
+They are never written to disk, unless you enable the offline cache
+(KEYWAY_OFFLINE_TTL), which keeps them encrypted in the state directory.
 
 This is particularly useful for:
 - Running local development servers without .env files
 - CI/CD pipelines
-- Using AI agents (Claude Code, Gemini CLI, Codex) safely: the agent runs the command but cannot see the secrets on disk.`,
+- Using AI agents (Claude Code, Gemini CLI, Codex) safely: the agent runs the command but cannot see the secrets on disk.
+
+Without a Keyway session, vaults made public by their owner (sample apps,
+tutorials) are read anonymously.
+
+Each run names the vault keys changed since the previous run of the same
+environment on this machine. Only digests of the values are stored locally.
+
+--log-output also writes the command's stdout and stderr to a file, with secret
+values masked, while streaming them to the terminal. The command's output is
+then a pipe rather than a terminal, so it may disable colors.
+
+--sandbox (Linux) runs the command in user and mount namespaces where keyway's
+credentials and state directories are empty and /tmp is private, so a script
+handed secrets cannot also take the session (when the session is in the Secret
+Service, the D-Bus session bus is hidden too). --no-network additionally leaves
+it without network access. Unprivileged user namespaces must be enabled.
+
+--cwd runs the command in another directory; the repository and keyway.toml
+are still found from the current one. --autoload reads the env files
+frameworks load from the command's directory (.env, .env.local, .env.<env>,
+.env.<env>.local, later files winning; .env.local is skipped for test) and
+only adds the keys the vault doesn't set: vault values always win.
+
+--on-conflict decides which value is injected when a key is set differently in
+the vault and in an overlay file or the shell environment:
+  user   overlays win over the vault (default)
+  vault  vault values win; overlays only add new keys
+  error  refuse to run and list the conflicting keys
+Injected values always replace shell variables of the same name, except with
+error. Every decision is reported by key name, never by value.`,
 	Example: `  keyway run --env development -- npm run dev
   keyway run --env development -- python3 main.py
-  keyway run --env production -- ./deploy.sh`,
+  keyway run --env production -- ./deploy.sh
+  keyway run --overlay ./overrides.env -- npm run dev
+  keyway run --overlay ./overrides.env --on-conflict error -- npm test
+  keyway run --only DATABASE_URL,REDIS_URL -- npm run migrate
+  keyway run --env production --profile worker -- node worker.js
+  keyway run --port-env PORT,DB_PORT -- npm run dev
+  keyway run --transform TLS_CERT=base64decode -- ./server
+  keyway run --cwd ./apps/web --autoload -- npm run dev
+  keyway run --inherit-env none -- ./server
+  keyway run --inherit-env PATH,HOME,LC_* -- npm test
+  keyway run --env production --report injection.json -- ./deploy.sh
+  keyway run --log-output run.log -- npm test
+  keyway run --sandbox --no-network -- node untrusted-script.js`,
 	RunE: runRunCmd,
 }
 
 func init() {
 	runCmd.Flags().StringP("env", "e", "development", "Environment name")
+	runCmd.Flags().Bool("override", false, "Bypass keyway.toml branch and label policies for this environment (break-glass)")
+	runCmd.Flags().String("report", "", "Write a JSON report of injected keys and their sources (no values)")
+	runCmd.Flags().StringSlice("only", nil, "Inject only these vault keys, and request only them from the API (e.g. DATABASE_URL,REDIS_URL)")
+	runCmd.Flags().String("profile", "", "Inject the keys and overrides of a keyway.toml profile (e.g. worker)")
+	runCmd.Flags().StringArray("overlay", nil, "Env file merged over vault secrets for this run only (repeatable, later files win)")
+	runCmd.Flags().StringArray("transform", nil, "Transform a value before injection, e.g. TLS_CERT=base64decode (repeatable)")
+	runCmd.Flags().StringSlice("port-env", nil, "Allocate a free local port for each variable (e.g. PORT,DB_PORT)")
+	runCmd.Flags().String("on-conflict", conflictUser, "When the vault and an overlay or shell variable disagree: user, vault or error")
+	runCmd.Flags().String("log-output", "", "Also write the command's output to this file, with secret values masked")
+	runCmd.Flags().Bool("sandbox", false, "Linux: hide keyway's credentials and /tmp from the command")
+	runCmd.Flags().Bool("no-network", false, "Linux: also deny the command network access (implies --sandbox)")
+	runCmd.Flags().String("cwd", "", "Run the command in this directory")
+	runCmd.Flags().Bool("autoload", false, "Load .env, .env.local, .env.<env> and .env.<env>.local of the command's directory beneath vault secrets")
+	runCmd.Flags().String("inherit-env", inheritDefault, "Parent variables passed to the command: default (all but KEYWAY_TOKEN and preload variables), all, none, or a list like PATH,HOME,LC_*")
+
+	// exec takes run's flags, except the output log that needs keyway running
+	runCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != "log-output" {
+			execCmd.Flags().AddFlag(f)
+		}
+	})
 }
 
 // RunOptions contains the parsed flags for the run command
@@ -36,12 +107,41 @@ type RunOptions struct {
 	EnvFlagSet bool
 	Command    string
 	Args       []string
+	Override   bool
+	Only       []string
+	Profile    string
+	Overlays   []string
+	ReportFile string
+	PortEnv    []string
+	Transforms []string
+	InheritEnv string
+	LogOutput  string
+	OnConflict string
+	Sandbox    bool
+	NoNetwork  bool
+	// Dir is the directory the command runs in, the current one when empty
+	Dir      string
+	Autoload bool
+	// Exec replaces keyway with the command instead of waiting for it
+	Exec bool
+	// Output receives the command's stdout and stderr, secret values masked,
+	// instead of the terminal. A failing command returns its exit status.
+	Output io.Writer
 }
 
 // runRunCmd is the entry point for the run command (uses default dependencies)
 func runRunCmd(cmd *cobra.Command, args []string) error {
+	opts, err := runOptionsFromFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	return runRunWithDeps(opts, defaultDeps)
+}
+
+// runOptionsFromFlags parses the flags run shares with exec
+func runOptionsFromFlags(cmd *cobra.Command, args []string) (RunOptions, error) {
 	if len(args) == 0 {
-		return fmt.Errorf("command required")
+		return RunOptions{}, fmt.Errorf("command required")
 	}
 
 	opts := RunOptions{
@@ -50,12 +150,59 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		Args:       args[1:],
 	}
 	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Override, _ = cmd.Flags().GetBool("override")
+	opts.Only, _ = cmd.Flags().GetStringSlice("only")
+	opts.Profile, _ = cmd.Flags().GetString("profile")
+	opts.Overlays, _ = cmd.Flags().GetStringArray("overlay")
+	opts.ReportFile, _ = cmd.Flags().GetString("report")
+	opts.PortEnv, _ = cmd.Flags().GetStringSlice("port-env")
+	opts.Transforms, _ = cmd.Flags().GetStringArray("transform")
+	opts.InheritEnv, _ = cmd.Flags().GetString("inherit-env")
+	opts.LogOutput, _ = cmd.Flags().GetString("log-output")
+	opts.OnConflict, _ = cmd.Flags().GetString("on-conflict")
+	opts.Sandbox, _ = cmd.Flags().GetBool("sandbox")
+	opts.NoNetwork, _ = cmd.Flags().GetBool("no-network")
+	opts.Dir, _ = cmd.Flags().GetString("cwd")
+	opts.Autoload, _ = cmd.Flags().GetBool("autoload")
 
-	return runRunWithDeps(opts, defaultDeps)
+	return opts, nil
 }
 
 // runRunWithDeps is the testable version of runRun
 func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
+	if opts.Exec && opts.LogOutput != "" {
+		deps.UI.Error("--log-output needs keyway to stay between the command and the terminal: use keyway run")
+		return fmt.Errorf("--log-output is not supported by exec")
+	}
+	if (opts.Sandbox || opts.NoNetwork) && runtime.GOOS != "linux" {
+		deps.UI.Error("--sandbox is only supported on Linux")
+		return fmt.Errorf("sandbox unsupported on %s", runtime.GOOS)
+	}
+	environ, err := inheritedEnviron(opts.InheritEnv, osEnviron())
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	strategy, err := parseConflictStrategy(opts.OnConflict)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	if opts.Dir != "" {
+		if info, err := deps.Stat.Stat(opts.Dir); err != nil || !info.IsDir() {
+			deps.UI.Error(fmt.Sprintf("--cwd %s is not a directory", opts.Dir))
+			return fmt.Errorf("not a directory: %s", opts.Dir)
+		}
+	}
+	only := parseOnlyKeys(opts.Only)
+	var profile config.ProfileConfig
+	if opts.Profile != "" {
+		if profile, err = loadRunProfile(deps, opts.Profile); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+	}
+
 	// 1. Detect Repo
 	repo, err := deps.Git.DetectRepo()
 	if err != nil {
@@ -63,8 +210,9 @@ func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
 		return err
 	}
 
-	// 2. Ensure Login
-	token, err := deps.Auth.EnsureLogin()
+	// 2. Ensure Login (public vaults are read anonymously)
+	ctx := context.Background()
+	token, public, err := ensureReadToken(ctx, deps, repo)
 	if err != nil {
 		deps.UI.Error(err.Error())
 		return err
@@ -72,65 +220,245 @@ func runRunWithDeps(opts RunOptions, deps *Dependencies) error {
 
 	// 3. Setup Client
 	client := deps.APIFactory.NewClient(token)
-	ctx := context.Background()
 
 	// 4. Determine Environment
-	envName := opts.EnvName
+	envName, err := resolveEnvironment(ctx, deps, client, repo, public, "run", opts.EnvName, opts.EnvFlagSet)
+	if err != nil {
+		return err
+	}
+
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
+
+	if err := enforceBranchPolicy(deps, envName, opts.Override); err != nil {
+		return err
+	}
+
+	// 5. Fetch Secrets
+	var vaultContent string
+	fetch := func() error {
+		return deps.UI.Spin("Fetching secrets...", func() error {
+			// Pulling is idempotent: retry on rate limits, 5xx and network failures
+			return api.WithRetry(ctx, func() error {
+				resp, err := client.PullSecrets(ctx, repo, envName, only...)
+				if err != nil {
+					return err
+				}
+				vaultContent = resp.Content
+				return nil
+			})
+		})
+	}
+	err = fetch()
 
-	if !opts.EnvFlagSet && deps.UI.IsInteractive() {
-		// Fetch available environments
-		vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
-		if err != nil || len(vaultEnvs) == 0 {
-			vaultEnvs = []string{"development", "staging", "production"}
+	// Handle auth errors (expired token)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
 		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = fetch()
+	}
 
-		// Find default index (development)
-		defaultIdx := 0
-		for i, e := range vaultEnvs {
-			if e == "development" {
-				defaultIdx = i
-				break
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	sources := keySources{}
+	for key, value := range env.Parse(vaultContent) {
+		if env.RefName(value) != "" {
+			sources.set(key, sourceShared, "")
+		} else {
+			sources.set(key, sourceVault, "")
+		}
+	}
+	rawContent := vaultContent
+
+	vaultContent, err = resolveSharedRefs(ctx, deps, client, repo, vaultContent)
+	if err != nil {
+		return err
+	}
+
+	// 6. Parse Secrets
+	secrets := env.Parse(vaultContent)
+	if len(only) > 0 {
+		if err := selectKeys(secrets, only); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		for key := range sources {
+			if _, ok := secrets[key]; !ok {
+				delete(sources, key)
 			}
 		}
+		deps.UI.Step(fmt.Sprintf("Only: %s", strings.Join(only, ", ")))
+	}
+	if opts.Profile != "" {
+		if unmatched := profile.Select(secrets); len(unmatched) > 0 {
+			deps.UI.Warn(fmt.Sprintf("Profile %s: no key matches %s", opts.Profile, strings.Join(unmatched, ", ")))
+		}
+		for key := range sources {
+			if _, ok := secrets[key]; !ok {
+				delete(sources, key)
+			}
+		}
+	}
+	for _, key := range dropExpiredSecrets(ctx, deps, client, repo, envName, secrets) {
+		delete(sources, key)
+	}
+	vault := make(map[string]string, len(secrets))
+	for key, value := range secrets {
+		vault[key] = value
+	}
+	// Like --only, a profile run only compares and records the keys it selects
+	changesScope := only
+	if len(changesScope) == 0 {
+		changesScope = profile.Keys
+	}
+	reportRunChanges(deps, repo, envName, vault, changesScope)
 
-		// Reorder to put default first
-		if defaultIdx > 0 {
-			vaultEnvs[0], vaultEnvs[defaultIdx] = vaultEnvs[defaultIdx], vaultEnvs[0]
+	if opts.Profile != "" {
+		overrides := applyProfileOverrides(secrets, profile)
+		for _, key := range overrides {
+			sources.set(key, sourceProfile, opts.Profile)
 		}
+		deps.UI.Step(fmt.Sprintf("Profile: %s (%d keys, %d overrides)", opts.Profile, len(secrets), len(overrides)))
+	}
 
-		selected, err := deps.UI.Select("Environment:", vaultEnvs)
+	if opts.Autoload {
+		dir := opts.Dir
+		if dir == "" {
+			dir = "."
+		}
+		result, err := applyAutoload(deps, secrets, dir, envName)
 		if err != nil {
+			deps.UI.Error(err.Error())
 			return err
 		}
-		envName = selected
+		reportAutoload(deps, result)
+		for _, key := range result.Added {
+			sources.set(key, sourceAutoload, result.Origins[key])
+		}
 	}
 
-	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(envName)))
-
-	// 5. Fetch Secrets
-	var vaultContent string
-	err = deps.UI.Spin("Fetching secrets...", func() error {
-		resp, err := client.PullSecrets(ctx, repo, envName)
+	// Overlays are applied in memory only and never persisted
+	for _, file := range opts.Overlays {
+		result, err := applyOverlay(deps, secrets, vault, file, strategy)
 		if err != nil {
+			deps.UI.Error(err.Error())
 			return err
 		}
-		vaultContent = resp.Content
-		return nil
-	})
+		reportOverlay(deps, result)
+		for _, key := range append(result.Overridden, result.Added...) {
+			sources.set(key, sourceOverlay, file)
+		}
+	}
 
-	if err != nil {
-		if apiErr, ok := err.(*api.APIError); ok {
-			deps.UI.Error(apiErr.Error())
-		} else {
+	if err := applyTransforms(deps, secrets, opts.Transforms); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	// Ports are allocated last so they win over vault values and overlays
+	if len(opts.PortEnv) > 0 {
+		assigned, err := applyPortEnv(secrets, opts.PortEnv)
+		if err != nil {
 			deps.UI.Error(err.Error())
+			return err
 		}
+		for _, name := range opts.PortEnv {
+			sources.set(strings.TrimSpace(name), sourcePort, "")
+		}
+		deps.UI.Step(fmt.Sprintf("Ports: %s", strings.Join(assigned, ", ")))
+	}
+
+	if err := resolveShellConflicts(deps, environ, secrets, strategy); err != nil {
+		deps.UI.Error(err.Error())
 		return err
 	}
 
-	// 6. Parse Secrets
-	secrets := env.Parse(vaultContent)
+	if err := checkCaseCollisions(deps, secrets, runtime.GOOS == "windows"); err != nil {
+		return err
+	}
+
+	if err := enforceLabelPolicy(deps, envName, sortedSecretKeys(secrets), opts.Override); err != nil {
+		return err
+	}
+
+	// The report is written before exec: RunCommand exits with the child's status
+	if opts.ReportFile != "" {
+		report := newInjectionReport(repo, envName, rawContent, opts.Command, sources)
+		if err := writeInjectionReport(deps, opts.ReportFile, report); err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to write report: %s", err.Error()))
+			return err
+		}
+		deps.UI.Step(fmt.Sprintf("Report: %s", deps.UI.File(opts.ReportFile)))
+	}
+
+	recordActivity(deps, activity.Entry{
+		Action:      activity.ActionRun,
+		Repository:  repo,
+		Environment: envName,
+		VaultDigest: contentDigest(rawContent),
+		Command:     strings.Join(append([]string{opts.Command}, opts.Args...), " "),
+		Keys:        sortedSecretKeys(secrets),
+	})
+
 	deps.UI.Success(fmt.Sprintf("Injected %d secrets", len(secrets)))
+	if opts.InheritEnv != "" && opts.InheritEnv != inheritDefault {
+		deps.UI.Step(fmt.Sprintf("Inherited: %d variables from the shell (--inherit-env %s)", len(environ), opts.InheritEnv))
+	}
 
 	// 7. Execute Command
-	return deps.CmdRunner.RunCommand(opts.Command, opts.Args, secrets)
+	command, args := opts.Command, opts.Args
+	if opts.Sandbox || opts.NoNetwork {
+		if command, args, err = sandboxedCommand(opts.NoNetwork, command, args); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		sandbox := "credentials and /tmp hidden"
+		if opts.NoNetwork {
+			sandbox += ", no network"
+		}
+		deps.UI.Step(fmt.Sprintf("Sandbox: %s", sandbox))
+	}
+	logOutput := opts.LogOutput
+	if opts.Dir != "" {
+		// The log path is relative to where keyway was started, not to --cwd
+		if logOutput != "" {
+			if logOutput, err = filepath.Abs(logOutput); err != nil {
+				deps.UI.Error(err.Error())
+				return err
+			}
+		}
+		if err := deps.CmdRunner.Chdir(opts.Dir); err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		deps.UI.Step(fmt.Sprintf("Directory: %s", deps.UI.File(opts.Dir)))
+	}
+	if opts.Exec {
+		return deps.CmdRunner.ExecCommand(command, args, environ, secrets)
+	}
+	if opts.Output != nil {
+		code, err := deps.CmdRunner.RunCommandCaptured(command, args, environ, secrets, opts.Output)
+		if err != nil {
+			deps.UI.Error(err.Error())
+			return err
+		}
+		if code != 0 {
+			return &exitStatusError{Command: command, Code: code}
+		}
+		return nil
+	}
+	if logOutput != "" {
+		deps.UI.Step(fmt.Sprintf("Log: %s (secret values masked)", deps.UI.File(opts.LogOutput)))
+		return deps.CmdRunner.RunCommandWithLog(command, args, environ, secrets, logOutput)
+	}
+	return deps.CmdRunner.RunCommandWithEnv(command, args, environ, secrets)
 }
\ No newline at end of file
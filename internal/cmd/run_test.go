@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/keywaysh/cli/internal/api"
@@ -235,3 +237,283 @@ func TestRunRunWithDeps_MultipleArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestRunRunWithDeps_Overlay(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=vault\nDB_URL=postgres://vault"}
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["overrides.env"] = []byte("DB_URL=postgres://localhost\nDEBUG=1\n")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"overrides.env"}}
+	err := runRunWithDeps(opts, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastSecrets["DB_URL"] != "postgres://localhost" {
+		t.Errorf("overlay should win, got %q", cmdRunner.LastSecrets["DB_URL"])
+	}
+	if cmdRunner.LastSecrets["DEBUG"] != "1" || cmdRunner.LastSecrets["API_KEY"] != "vault" {
+		t.Errorf("unexpected secrets: %v", cmdRunner.LastSecrets)
+	}
+	if len(fs.Written) != 0 {
+		t.Error("overlay must never be persisted")
+	}
+
+	reported := strings.Join(uiMock.MessageCalls, "\n")
+	if !strings.Contains(reported, "overrides vault: DB_URL") || !strings.Contains(reported, "adds: DEBUG") {
+		t.Errorf("expected precedence report, got:\n%s", reported)
+	}
+	if strings.Contains(reported, "localhost") {
+		t.Error("overlay values must not be printed")
+	}
+}
+
+func TestRunRunWithDeps_OverlayOrder(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=vault"}
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["one.env"] = []byte("A=one")
+	fs.Files["two.env"] = []byte("A=two")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"one.env", "two.env"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastSecrets["A"] != "two" {
+		t.Errorf("later overlay should win, got %q", cmdRunner.LastSecrets["A"])
+	}
+}
+
+func TestRunRunWithDeps_OverlayMissing(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=vault"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"missing.env"}}
+	err := runRunWithDeps(opts, deps)
+
+	if err == nil {
+		t.Fatal("expected error for missing overlay")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunRunWithDeps_LogOutput(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Args: []string{"test"}, LogOutput: "run.log"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdRunner.LastLogPath != "run.log" {
+		t.Errorf("expected output logged to run.log, got %q", cmdRunner.LastLogPath)
+	}
+	if cmdRunner.LastSecrets["API_KEY"] != "secret123" {
+		t.Error("expected secrets to be passed for injection and masking")
+	}
+}
+
+func TestRunRunWithDeps_Cwd(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+	deps.Stat.(*MockFileStat).Files["apps/web"] = &MockFileInfo{FileName: "web", FileIsDir: true}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Dir: "apps/web", LogOutput: "run.log"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastDir != "apps/web" {
+		t.Errorf("expected the command run in apps/web, got %q", cmdRunner.LastDir)
+	}
+	if !filepath.IsAbs(cmdRunner.LastLogPath) || filepath.Base(cmdRunner.LastLogPath) != "run.log" {
+		t.Errorf("expected the log path kept relative to the starting directory, got %q", cmdRunner.LastLogPath)
+	}
+}
+
+func TestRunRunWithDeps_CwdMissing(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, _ := NewTestDepsWithRunner()
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Dir: "nowhere"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+	if cmdRunner.LastCommand != "" || len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected an error and no command run")
+	}
+}
+
+func TestRunRunWithDeps_Autoload(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=vault"}
+	stat := deps.Stat.(*MockFileStat)
+	fs := deps.FS.(*MockFileSystem)
+	for name, content := range map[string]string{
+		"web/.env":                   "API_KEY=local\nPORT=3000\nDEBUG=0",
+		"web/.env.local":             "DEBUG=1",
+		"web/.env.development.local": "PORT=4000",
+	} {
+		stat.Files[name] = &MockFileInfo{FileName: filepath.Base(name)}
+		fs.Files[name] = []byte(content)
+	}
+	stat.Files["web"] = &MockFileInfo{FileName: "web", FileIsDir: true}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Dir: "web", Autoload: true}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"API_KEY": "vault", "PORT": "4000", "DEBUG": "1"}
+	for key, value := range want {
+		if cmdRunner.LastSecrets[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, cmdRunner.LastSecrets[key])
+		}
+	}
+	if reported := strings.Join(uiMock.MessageCalls, "\n"); !strings.Contains(reported, "adds: DEBUG, PORT") {
+		t.Errorf("expected the added keys reported, got:\n%s", reported)
+	}
+}
+
+func TestAutoloadFileNames(t *testing.T) {
+	if got := strings.Join(autoloadFileNames("production"), " "); got != ".env .env.local .env.production .env.production.local" {
+		t.Errorf("unexpected files: %s", got)
+	}
+	if got := strings.Join(autoloadFileNames("test"), " "); got != ".env .env.test .env.test.local" {
+		t.Errorf("expected .env.local skipped for test, got %s", got)
+	}
+}
+
+func TestRunRunWithDeps_OnConflictVault(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=vault\nDB_URL=postgres://vault"}
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["overrides.env"] = []byte("DB_URL=postgres://localhost\nDEBUG=1\n")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"overrides.env"}, OnConflict: "vault"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastSecrets["DB_URL"] != "postgres://vault" {
+		t.Errorf("vault should win, got %q", cmdRunner.LastSecrets["DB_URL"])
+	}
+	if cmdRunner.LastSecrets["DEBUG"] != "1" {
+		t.Errorf("overlay should still add new keys, got %v", cmdRunner.LastSecrets)
+	}
+	if reported := strings.Join(uiMock.MessageCalls, "\n"); !strings.Contains(reported, "ignored, vault wins: DB_URL") {
+		t.Errorf("expected the decision to be reported, got:\n%s", reported)
+	}
+}
+
+func TestRunRunWithDeps_OnConflictErrorOverlay(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=vault\nB=same"}
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["overrides.env"] = []byte("A=local\nB=same\n")
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"overrides.env"}, OnConflict: "error"}
+	err := runRunWithDeps(opts, deps)
+
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a ConflictError, got %v", err)
+	}
+	if strings.Join(conflict.Keys, ",") != "A" {
+		t.Errorf("only keys with different values conflict, got %v", conflict.Keys)
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run")
+	}
+	if len(uiMock.ErrorCalls) == 0 || strings.Contains(uiMock.ErrorCalls[0], "local") {
+		t.Errorf("expected an error naming keys only, got %v", uiMock.ErrorCalls)
+	}
+}
+
+func TestRunRunWithDeps_OnConflictShell(t *testing.T) {
+	original := osEnviron
+	osEnviron = func() []string { return []string{"PATH=/usr/bin", "API_KEY=from-shell", "SAME=1"} }
+	defer func() { osEnviron = original }()
+
+	t.Run("injected values win and are reported", func(t *testing.T) {
+		deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+		apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=vault\nSAME=1"}
+
+		opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm"}
+		if err := runRunWithDeps(opts, deps); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cmdRunner.LastSecrets["API_KEY"] != "vault" {
+			t.Errorf("expected the vault value, got %q", cmdRunner.LastSecrets["API_KEY"])
+		}
+		steps := strings.Join(uiMock.StepCalls, "\n")
+		if !strings.Contains(steps, "Shell variables replaced by injected values: API_KEY") || strings.Contains(steps, "SAME") {
+			t.Errorf("expected API_KEY reported, got:\n%s", steps)
+		}
+	})
+
+	t.Run("error refuses to run", func(t *testing.T) {
+		deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+		apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=vault\nSAME=1"}
+
+		opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", OnConflict: "error"}
+		err := runRunWithDeps(opts, deps)
+		if err == nil || !strings.Contains(err.Error(), "shell environment conflicts with the vault on API_KEY") {
+			t.Fatalf("expected a shell conflict error, got %v", err)
+		}
+		if cmdRunner.LastCommand != "" {
+			t.Error("command must not run")
+		}
+	})
+}
+
+func TestRunRunWithDeps_OnConflictInvalid(t *testing.T) {
+	deps, _, _, _, cmdRunner, _ := NewTestDepsWithRunner()
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", OnConflict: "shell"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for an invalid strategy")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run")
+	}
+}
+
+func TestRunRunWithDeps_Only(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	// The server ignored the filter: the selection is applied locally too
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=a\nDB_URL=b\nOTHER=c"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Only: []string{"DB_URL", " API_KEY", "DB_URL"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(apiMock.PullKeys, ",") != "API_KEY,DB_URL" {
+		t.Errorf("expected the key filter sent to the API, got %v", apiMock.PullKeys)
+	}
+	if len(cmdRunner.LastSecrets) != 2 || cmdRunner.LastSecrets["OTHER"] != "" {
+		t.Errorf("expected only the selected keys, got %v", cmdRunner.LastSecrets)
+	}
+}
+
+func TestRunRunWithDeps_OnlyMissingKey(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=a"}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Only: []string{"API_KEY", "MISSING"}}
+	err := runRunWithDeps(opts, deps)
+
+	if err == nil || !strings.Contains(err.Error(), "not found in vault: MISSING") {
+		t.Fatalf("expected a missing key error, got %v", err)
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command must not run")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected the error to be shown")
+	}
+}
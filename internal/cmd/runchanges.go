@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/syncbase"
+)
+
+// maxChangedKeysShown caps the keys named in the run change summary
+const maxChangedKeysShown = 5
+
+// RunChanges lists the vault keys that changed since the previous keyway run
+// of an environment on this machine
+type RunChanges struct {
+	Changed []string
+	Added   []string
+	Removed []string
+}
+
+// Count returns the number of changed keys
+func (c *RunChanges) Count() int {
+	return len(c.Changed) + len(c.Added) + len(c.Removed)
+}
+
+// compareRunBase compares vault values with the snapshot of the previous run.
+// With only, keys outside the selection are ignored.
+func compareRunBase(base syncbase.Snapshot, vault map[string]string, only []string) *RunChanges {
+	changes := &RunChanges{}
+	for key, value := range vault {
+		digest, ok := base[key]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, key)
+		case digest != syncbase.Digest(value):
+			changes.Changed = append(changes.Changed, key)
+		}
+	}
+	if len(only) == 0 {
+		for key := range base {
+			if _, ok := vault[key]; !ok {
+				changes.Removed = append(changes.Removed, key)
+			}
+		}
+	}
+	sort.Strings(changes.Changed)
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	return changes
+}
+
+// formatRunChanges renders the one-line summary, e.g.
+// "3 keys changed since your last run: STRIPE_KEY, NEW_FLAG (new), OLD_URL (removed)"
+func formatRunChanges(c *RunChanges) string {
+	names := make([]string, 0, c.Count())
+	names = append(names, c.Changed...)
+	for _, key := range c.Added {
+		names = append(names, key+" (new)")
+	}
+	for _, key := range c.Removed {
+		names = append(names, key+" (removed)")
+	}
+	if len(names) > maxChangedKeysShown {
+		names = append(names[:maxChangedKeysShown], fmt.Sprintf("and %d more", len(names)-maxChangedKeysShown))
+	}
+
+	noun := "keys"
+	if c.Count() == 1 {
+		noun = "key"
+	}
+	return fmt.Sprintf("%d %s changed since your last run: %s", c.Count(), noun, strings.Join(names, ", "))
+}
+
+// reportRunChanges prints which vault keys changed since the previous run of
+// the environment, then records the current values (as digests). Nothing is
+// printed on the first run. Failures only warn: the summary is best effort.
+func reportRunChanges(deps *Dependencies, repo, envName string, vault map[string]string, only []string) {
+	base, ok, err := deps.RunBase.Load(repo, envName)
+	if err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not read last run state: %s", err.Error()))
+		return
+	}
+
+	if ok {
+		if changes := compareRunBase(base, vault, only); changes.Count() > 0 {
+			deps.UI.Warn(formatRunChanges(changes))
+		}
+	}
+
+	// A selective run only updates the keys it injected
+	snap := syncbase.NewSnapshot(vault)
+	if len(only) > 0 {
+		for key, digest := range base {
+			if _, selected := snap[key]; !selected {
+				snap[key] = digest
+			}
+		}
+	}
+	if err := deps.RunBase.Save(repo, envName, snap); err != nil {
+		deps.UI.Warn(fmt.Sprintf("Failed to record run state: %s", err.Error()))
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/syncbase"
+)
+
+func TestCompareRunBase(t *testing.T) {
+	base := syncbase.NewSnapshot(map[string]string{"SAME": "1", "CHANGED": "old", "REMOVED": "x"})
+	vault := map[string]string{"SAME": "1", "CHANGED": "new", "ADDED": "y"}
+
+	changes := compareRunBase(base, vault, nil)
+
+	if strings.Join(changes.Changed, ",") != "CHANGED" || strings.Join(changes.Added, ",") != "ADDED" || strings.Join(changes.Removed, ",") != "REMOVED" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	// A selective run can't tell removed keys from unselected ones
+	if changes := compareRunBase(base, map[string]string{"SAME": "1"}, []string{"SAME"}); changes.Count() != 0 {
+		t.Errorf("expected no changes for a selection, got %+v", changes)
+	}
+}
+
+func TestFormatRunChanges(t *testing.T) {
+	got := formatRunChanges(&RunChanges{Changed: []string{"STRIPE_KEY"}, Added: []string{"NEW"}, Removed: []string{"OLD"}})
+	if got != "3 keys changed since your last run: STRIPE_KEY, NEW (new), OLD (removed)" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+
+	got = formatRunChanges(&RunChanges{Changed: []string{"A", "B", "C", "D", "E", "F", "G"}})
+	if got != "7 keys changed since your last run: A, B, C, D, E, and 2 more" {
+		t.Errorf("unexpected truncated summary: %q", got)
+	}
+
+	if got := formatRunChanges(&RunChanges{Changed: []string{"A"}}); !strings.HasPrefix(got, "1 key changed") {
+		t.Errorf("expected a singular summary, got %q", got)
+	}
+}
+
+func TestRunRunWithDeps_ChangesSinceLastRun(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDepsWithRunner()
+	fs := deps.FS.(*MockFileSystem)
+	fs.Files["overrides.env"] = []byte("LOCAL=1\n")
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "npm", Overlays: []string{"overrides.env"}}
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "STRIPE_KEY=sk_1\nDB_URL=postgres://db"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("expected no summary on the first run, got %v", uiMock.WarnCalls)
+	}
+
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "STRIPE_KEY=sk_2\nDB_URL=postgres://db"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := strings.Join(uiMock.WarnCalls, "\n")
+	if warnings != "1 key changed since your last run: STRIPE_KEY" {
+		t.Errorf("expected only the vault change reported, got %q", warnings)
+	}
+	if strings.Contains(warnings, "sk_") {
+		t.Error("values must never be printed")
+	}
+
+	snap, _, _ := deps.RunBase.Load("owner/repo", "development")
+	if _, ok := snap["LOCAL"]; ok {
+		t.Error("overlay keys must not be recorded as vault state")
+	}
+	if _, ok, _ := deps.SyncBase.Load("owner/repo", "development"); ok {
+		t.Error("run must not move the push/pull sync base")
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/config"
+)
+
+// loadRunProfile returns the keyway.toml profile selected with --profile
+func loadRunProfile(deps *Dependencies, name string) (config.ProfileConfig, error) {
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		return config.ProfileConfig{}, err
+	}
+	profile, ok := project.Profile(name)
+	if !ok {
+		if names := project.ProfileNames(); len(names) > 0 {
+			return config.ProfileConfig{}, fmt.Errorf("unknown profile %q (keyway.toml defines %s)", name, strings.Join(names, ", "))
+		}
+		return config.ProfileConfig{}, fmt.Errorf("unknown profile %q: define it under [profiles.%s] in keyway.toml", name, name)
+	}
+	return profile, nil
+}
+
+// applyProfileOverrides sets the profile's override values and returns their sorted keys
+func applyProfileOverrides(secrets map[string]string, profile config.ProfileConfig) []string {
+	keys := make([]string, 0, len(profile.Overrides))
+	for key, value := range profile.Overrides {
+		secrets[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestRunRunWithDeps_Profile(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, apiMock := NewTestDepsWithRunner()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Profiles: map[string]config.ProfileConfig{
+		"worker": {
+			Keys:      []string{"DATABASE_URL", "QUEUE_*", "SENTRY_DSN"},
+			Overrides: map[string]string{"CONCURRENCY": "4"},
+		},
+	}}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "DATABASE_URL=postgres://db\nQUEUE_URL=redis://q\nSESSION_SECRET=s3cret"}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "node", Args: []string{"worker.js"}, Profile: "worker"}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := cmdRunner.LastSecrets
+	if len(got) != 3 || got["DATABASE_URL"] != "postgres://db" || got["QUEUE_URL"] != "redis://q" || got["CONCURRENCY"] != "4" {
+		t.Errorf("unexpected secrets: %v", got)
+	}
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "no key matches SENTRY_DSN") {
+		t.Errorf("expected a warning for the unmatched pattern, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunRunWithDeps_UnknownProfile(t *testing.T) {
+	deps, _, _, uiMock, cmdRunner, _ := NewTestDepsWithRunner()
+	deps.Config.(*MockConfigLoader).Project = &config.ProjectConfig{Profiles: map[string]config.ProfileConfig{
+		"web":    {Keys: []string{"PORT"}},
+		"worker": {Keys: []string{"QUEUE_*"}},
+	}}
+
+	opts := RunOptions{EnvName: "production", EnvFlagSet: true, Command: "node", Profile: "cron"}
+	if err := runRunWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+	if cmdRunner.LastCommand != "" {
+		t.Error("command should not run")
+	}
+	if len(uiMock.ErrorCalls) == 0 || uiMock.ErrorCalls[0] != `unknown profile "cron" (keyway.toml defines web, worker)` {
+		t.Errorf("unexpected error: %v", uiMock.ErrorCalls)
+	}
+}
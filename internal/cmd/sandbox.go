@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/auth"
+	"github.com/keywaysh/cli/internal/injector"
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// sandboxExecCmd is started by keyway run --sandbox in place of the command.
+// It starts itself again with --inside in new namespaces, which sets up the
+// mounts and execs the command.
+var sandboxExecCmd = &cobra.Command{
+	Use:    "sandbox-exec [flags] -- command [args...]",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE:   runSandboxExec,
+}
+
+func init() {
+	sandboxExecCmd.Flags().StringArray("hide", nil, "Directory the command sees as empty")
+	sandboxExecCmd.Flags().Bool("private-tmp", false, "Give the command its own /tmp")
+	sandboxExecCmd.Flags().Bool("no-network", false, "Deny network access")
+	sandboxExecCmd.Flags().Bool("inside", false, "Set up the sandbox and exec the command")
+}
+
+func runSandboxExec(cmd *cobra.Command, args []string) error {
+	var sandbox injector.Sandbox
+	sandbox.Hide, _ = cmd.Flags().GetStringArray("hide")
+	sandbox.PrivateTmp, _ = cmd.Flags().GetBool("private-tmp")
+	sandbox.DenyNetwork, _ = cmd.Flags().GetBool("no-network")
+
+	if inside, _ := cmd.Flags().GetBool("inside"); inside {
+		return sandbox.Enter(args[0], args[1:])
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	code, err := sandbox.Start(self, sandboxArgs(sandbox, true, args[0], args[1:]))
+	if err != nil {
+		return err
+	}
+	// Exit here: the outer keyway reports the result
+	os.Exit(code)
+	return nil
+}
+
+// sandboxArgs returns the keyway arguments that run command in sandbox
+func sandboxArgs(sandbox injector.Sandbox, inside bool, command string, args []string) []string {
+	out := []string{"sandbox-exec"}
+	for _, dir := range sandbox.Hide {
+		out = append(out, "--hide", dir)
+	}
+	if sandbox.PrivateTmp {
+		out = append(out, "--private-tmp")
+	}
+	if sandbox.DenyNetwork {
+		out = append(out, "--no-network")
+	}
+	if inside {
+		out = append(out, "--inside")
+	}
+	out = append(out, "--", command)
+	return append(out, args...)
+}
+
+// sandboxedCommand returns the command and arguments that run command in a
+// sandbox hiding keyway's credentials and state, with a private /tmp
+func sandboxedCommand(denyNetwork bool, command string, args []string) (string, []string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", nil, err
+	}
+	sandbox := injector.Sandbox{Hide: keywayDirs(), PrivateTmp: true, DenyNetwork: denyNetwork}
+	return self, sandboxArgs(sandbox, false, command, args), nil
+}
+
+// keywayDirs returns the existing directories holding keyway's credentials and state
+func keywayDirs() []string {
+	var candidates []string
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".keyway"))
+	}
+	store := auth.NewStore()
+	candidates = append(candidates, filepath.Dir(store.GetConfigPath()))
+	// A session in the Secret Service is one D-Bus call away: hide the session bus
+	if store.UsesKeyring() {
+		if dir := sessionBusDir(os.Getenv("DBUS_SESSION_BUS_ADDRESS")); dir != "" {
+			candidates = append(candidates, dir)
+		}
+	}
+	if dir, err := state.Dir(); err == nil {
+		candidates = append(candidates, dir)
+	}
+
+	var dirs []string
+	for _, dir := range candidates {
+		if containsDir(dirs, dir) {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// containsDir reports whether dir is one of dirs or inside one of them
+func containsDir(dirs []string, dir string) bool {
+	for _, d := range dirs {
+		if dir == d || strings.HasPrefix(dir, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionBusDir returns the directory of the D-Bus session bus socket, from
+// an address such as unix:path=/run/user/1000/bus. Abstract sockets have no
+// directory; only --no-network, with its own network namespace, cuts them.
+func sessionBusDir(address string) string {
+	for _, transport := range strings.Split(address, ";") {
+		if !strings.HasPrefix(transport, "unix:") {
+			continue
+		}
+		for _, kv := range strings.Split(strings.TrimPrefix(transport, "unix:"), ",") {
+			if path, ok := strings.CutPrefix(kv, "path="); ok && filepath.IsAbs(path) {
+				return filepath.Dir(path)
+			}
+		}
+	}
+	return ""
+}
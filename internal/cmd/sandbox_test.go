@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/injector"
+)
+
+func TestSandboxArgs(t *testing.T) {
+	sandbox := injector.Sandbox{Hide: []string{"/home/u/.keyway"}, PrivateTmp: true, DenyNetwork: true}
+
+	got := strings.Join(sandboxArgs(sandbox, true, "node", []string{"--flag", "script.js"}), " ")
+	want := "sandbox-exec --hide /home/u/.keyway --private-tmp --no-network --inside -- node --flag script.js"
+	if got != want {
+		t.Errorf("sandboxArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestContainsDir(t *testing.T) {
+	dirs := []string{filepath.Join("home", ".keyway")}
+	if !containsDir(dirs, filepath.Join("home", ".keyway", "state")) {
+		t.Error("a nested directory is already hidden")
+	}
+	if containsDir(dirs, filepath.Join("home", ".keyway-other")) {
+		t.Error("a sibling with the same prefix is not")
+	}
+}
+
+func TestSessionBusDir(t *testing.T) {
+	tests := map[string]string{
+		"unix:path=/run/user/1000/bus":                          "/run/user/1000",
+		"unix:abstract=/tmp/dbus-x,guid=1;unix:path=/tmp/d/bus": "/tmp/d",
+		"unix:abstract=/tmp/dbus-x,guid=1":                      "",
+		"":                                                      "",
+	}
+	for address, want := range tests {
+		if got := sessionBusDir(address); got != want {
+			t.Errorf("sessionBusDir(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+func TestRunRunWithDeps_Sandbox(t *testing.T) {
+	deps, _, _, uiMock, runner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret\n"}
+
+	err := runRunWithDeps(RunOptions{EnvName: "development", EnvFlagSet: true, Command: "node", Args: []string{"script.js"}, NoNetwork: true}, deps)
+
+	if runtime.GOOS != "linux" {
+		if err == nil || runner.LastCommand != "" {
+			t.Fatal("expected --sandbox to be refused outside Linux")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	args := strings.Join(runner.LastArgs, " ")
+	if !strings.HasPrefix(args, "sandbox-exec ") || !strings.Contains(args, "--no-network") || !strings.HasSuffix(args, "-- node script.js") {
+		t.Errorf("expected the command wrapped in sandbox-exec, got %s %s", runner.LastCommand, args)
+	}
+	if runner.LastSecrets["API_KEY"] != "secret" {
+		t.Errorf("expected secrets injected, got %v", runner.LastSecrets)
+	}
+	if !strings.Contains(strings.Join(uiMock.StepCalls, "\n"), "Sandbox: credentials and /tmp hidden, no network") {
+		t.Errorf("expected a sandbox step, got %v", uiMock.StepCalls)
+	}
+}
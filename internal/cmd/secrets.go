@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/clipboard"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// defaultClipboardClearAfter is how long a copied secret stays on the clipboard
+const defaultClipboardClearAfter = 30 * time.Second
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Work with individual secrets",
+	Long:  `Work with individual secrets in the vault without touching env files.`,
+}
+
+var secretsCopyValueCmd = &cobra.Command{
+	Use:   "copy-value <KEY>",
+	Short: "Copy a secret value to the clipboard",
+	Long: `Copy a secret value to the clipboard without printing it.
+
+The clipboard is cleared automatically after 30 seconds (use --clear-after to
+change the delay, or 0 to keep the value). If you copy something else in the
+meantime, it is left untouched.
+
+Examples:
+  keyway secrets copy-value API_KEY
+  keyway secrets copy-value API_KEY --env production
+  keyway secrets copy-value API_KEY --clear-after 2m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsCopyValue,
+}
+
+// secretsClearClipboardCmd is spawned in the background by copy-value, which
+// writes the digest of the copied value to its stdin: on the command line, any
+// local user could read it, and a short value is easy to find from its digest
+var secretsClearClipboardCmd = &cobra.Command{
+	Use:    "clear-clipboard",
+	Hidden: true,
+	RunE:   runSecretsClearClipboard,
+}
+
+func init() {
+	secretsCopyValueCmd.Flags().StringP("env", "e", "development", "Environment name")
+	secretsCopyValueCmd.Flags().Duration("clear-after", defaultClipboardClearAfter, "Clear the clipboard after this delay (0 to disable)")
+
+	secretsClearClipboardCmd.Flags().Duration("after", defaultClipboardClearAfter, "Delay before clearing")
+
+	secretsCmd.AddCommand(secretsCopyValueCmd)
+	secretsCmd.AddCommand(secretsClearClipboardCmd)
+}
+
+// CopyValueOptions contains the parsed flags for the secrets copy-value command
+type CopyValueOptions struct {
+	Key        string
+	EnvName    string
+	EnvFlagSet bool
+	ClearAfter time.Duration
+}
+
+// runSecretsCopyValue is the entry point for the secrets copy-value command (uses default dependencies)
+func runSecretsCopyValue(cmd *cobra.Command, args []string) error {
+	opts := CopyValueOptions{Key: args[0], EnvFlagSet: cmd.Flags().Changed("env")}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.ClearAfter, _ = cmd.Flags().GetDuration("clear-after")
+
+	return runSecretsCopyValueWithDeps(opts, defaultDeps)
+}
+
+// runSecretsCopyValueWithDeps is the testable version of runSecretsCopyValue
+func runSecretsCopyValueWithDeps(opts CopyValueOptions, deps *Dependencies) error {
+	deps.UI.Intro("secrets copy-value")
+
+	if opts.ClearAfter < 0 {
+		deps.UI.Error("--clear-after cannot be negative")
+		return fmt.Errorf("invalid clear-after duration")
+	}
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "secrets copy-value", opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	secrets, _, err := fetchSecrets(deps, client, repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	value, ok := secrets[opts.Key]
+	if !ok {
+		deps.UI.Error(fmt.Sprintf("%s not found in %s", opts.Key, opts.EnvName))
+		return fmt.Errorf("secret not found")
+	}
+
+	if err := deps.Clipboard.Copy(value); err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to copy to clipboard: %s", err.Error()))
+		if err == clipboard.ErrUnavailable {
+			deps.UI.Message(deps.UI.Dim("Install pbcopy, wl-copy, xclip or xsel"))
+		}
+		return err
+	}
+	deps.UI.Success(fmt.Sprintf("Copied %s to clipboard", opts.Key))
+
+	if opts.ClearAfter == 0 {
+		deps.UI.Warn("Clipboard will not be cleared automatically")
+		return nil
+	}
+
+	if err := deps.Clipboard.ScheduleClear(value, opts.ClearAfter); err != nil {
+		deps.UI.Warn(fmt.Sprintf("Could not schedule clipboard clearing: %s", err.Error()))
+		return nil
+	}
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Clipboard will be cleared in %s", opts.ClearAfter)))
+	return nil
+}
+
+// fetchSecrets pulls and parses an environment, re-authenticating once on 401.
+// Returns the client to use for follow-up calls (it changes after a re-login).
+func fetchSecrets(deps *Dependencies, client api.APIClient, repo, envName string) (map[string]string, api.APIClient, error) {
+	ctx := context.Background()
+
+	var content string
+	pull := func() error {
+		resp, err := client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			return err
+		}
+		content = resp.Content
+		return nil
+	}
+
+	err := deps.UI.Spin("Fetching secrets...", pull)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return nil, client, authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = deps.UI.Spin("Fetching secrets...", pull)
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, client, err
+	}
+
+	content, err = resolveSharedRefs(ctx, deps, client, repo, content)
+	if err != nil {
+		return nil, client, err
+	}
+
+	return env.Parse(content), client, nil
+}
+
+// readClipboardDigest reads the digest copy-value writes to stdin
+func readClipboardDigest(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(io.LimitReader(r, 256)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	digest := strings.TrimSpace(line)
+	if digest == "" {
+		return "", fmt.Errorf("digest required on stdin")
+	}
+	return digest, nil
+}
+
+// runSecretsClearClipboard waits, then clears the clipboard if it still holds the copied value
+func runSecretsClearClipboard(cmd *cobra.Command, args []string) error {
+	after, _ := cmd.Flags().GetDuration("after")
+	digest, err := readClipboardDigest(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(after)
+
+	backend, err := clipboard.Detect()
+	if err != nil {
+		return err
+	}
+	_, err = clipboard.ClearIfUnchanged(backend, digest)
+	return err
+}
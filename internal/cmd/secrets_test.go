@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/clipboard"
+)
+
+func TestRunSecretsCopyValueWithDeps_Success(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	clip := deps.Clipboard.(*MockClipboard)
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123\nOTHER=x"}
+
+	opts := CopyValueOptions{Key: "API_KEY", EnvName: "development", ClearAfter: 30 * time.Second}
+	err := runSecretsCopyValueWithDeps(opts, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clip.Copied != "secret123" {
+		t.Errorf("expected secret123 on clipboard, got %q", clip.Copied)
+	}
+	if !clip.ClearScheduled || clip.ClearAfter != 30*time.Second {
+		t.Errorf("expected clear scheduled after 30s, got scheduled=%v after=%v", clip.ClearScheduled, clip.ClearAfter)
+	}
+	if len(uiMock.SuccessCalls) == 0 {
+		t.Error("expected Success to be called")
+	}
+
+	// The value must never be printed
+	for _, calls := range [][]string{uiMock.SuccessCalls, uiMock.MessageCalls, uiMock.StepCalls, uiMock.WarnCalls} {
+		for _, msg := range calls {
+			if strings.Contains(msg, "secret123") {
+				t.Errorf("secret value leaked in output: %q", msg)
+			}
+		}
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_NoAutoClear(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	clip := deps.Clipboard.(*MockClipboard)
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development"}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clip.ClearScheduled {
+		t.Error("expected no clear to be scheduled")
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected a warning that the clipboard will not be cleared")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_KeyNotFound(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	clip := deps.Clipboard.(*MockClipboard)
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "OTHER=x"}
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development"}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if clip.Copied != "" {
+		t.Error("nothing should be copied")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_NegativeClearAfter(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development", ClearAfter: -time.Second}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_ClipboardUnavailable(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	clip := deps.Clipboard.(*MockClipboard)
+	clip.CopyError = clipboard.ErrUnavailable
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development", ClearAfter: time.Second}, deps)
+
+	if !errors.Is(err, clipboard.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if len(uiMock.MessageCalls) == 0 {
+		t.Error("expected install hint")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_ScheduleFailureIsWarning(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	clip := deps.Clipboard.(*MockClipboard)
+	clip.ScheduleError = errors.New("cannot spawn")
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret123"}
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development", ClearAfter: time.Second}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) == 0 {
+		t.Error("expected warning")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_PullError(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullError = &api.APIError{StatusCode: 404, Detail: "Vault not found"}
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development"}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunSecretsCopyValueWithDeps_GitError(t *testing.T) {
+	deps, gitMock, _, _, _, _ := NewTestDeps()
+	gitMock.RepoError = errors.New("not a git repo")
+
+	err := runSecretsCopyValueWithDeps(CopyValueOptions{Key: "API_KEY", EnvName: "development"}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestReadClipboardDigest(t *testing.T) {
+	if digest, err := readClipboardDigest(strings.NewReader("abc123\n")); err != nil || digest != "abc123" {
+		t.Errorf("expected the digest from stdin, got %q, %v", digest, err)
+	}
+	if _, err := readClipboardDigest(strings.NewReader("")); err == nil {
+		t.Error("expected an error without a digest")
+	}
+}
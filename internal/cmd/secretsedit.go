@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/spf13/cobra"
+)
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <KEY> [VALUE]",
+	Short: "Set a secret in the vault (same as keyway set)",
+	Long: `Set a single secret in the vault, leaving the other keys of the environment
+as they are. Takes the same flags as keyway set.
+
+Examples:
+  keyway secrets set API_KEY --prompt
+  keyway secrets set API_KEY=abc --env production`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSet,
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <KEY>",
+	Short: "Print the value of a secret",
+	Long: `Print the value of a single secret to stdout, for use in scripts.
+$ref: values are resolved like keyway run resolves them.
+
+Examples:
+  keyway secrets get API_KEY
+  curl -H "Authorization: Bearer $(keyway secrets get API_KEY -e production)" ...`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsGet,
+}
+
+var secretsUnsetCmd = &cobra.Command{
+	Use:   "unset <KEY>",
+	Short: "Remove a secret from the vault",
+	Long: `Remove a single secret from an environment, leaving the other keys as they are.
+
+Examples:
+  keyway secrets unset OLD_API_KEY
+  keyway secrets unset OLD_API_KEY --env production --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsUnset,
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the secrets of an environment, values masked",
+	Long: `List the keys of an environment with their values masked. Pass --reveal to
+print the values.
+
+Examples:
+  keyway secrets list
+  keyway secrets list --env production --reveal
+  keyway secrets list --json`,
+	Args: cobra.NoArgs,
+	RunE: runSecretsList,
+}
+
+func init() {
+	secretsGetCmd.Flags().StringP("env", "e", "development", "Environment name")
+
+	secretsUnsetCmd.Flags().StringP("env", "e", "development", "Environment name")
+	secretsUnsetCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	secretsListCmd.Flags().StringP("env", "e", "development", "Environment name")
+	secretsListCmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	secretsListCmd.Flags().Bool("json", false, "Output as JSON")
+
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+	secretsCmd.AddCommand(secretsUnsetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+}
+
+// SecretKeyOptions contains the parsed flags for secrets get and unset
+type SecretKeyOptions struct {
+	Key        string
+	EnvName    string
+	EnvFlagSet bool
+	Yes        bool
+}
+
+// SecretsListOptions contains the parsed flags for secrets list
+type SecretsListOptions struct {
+	EnvName    string
+	EnvFlagSet bool
+	Reveal     bool
+	JSONOutput bool
+}
+
+// runSecretsGet is the entry point for secrets get (uses default dependencies)
+func runSecretsGet(cmd *cobra.Command, args []string) error {
+	opts := SecretKeyOptions{Key: args[0], EnvFlagSet: cmd.Flags().Changed("env")}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+
+	return runSecretsGetWithDeps(opts, os.Stdout, defaultDeps)
+}
+
+// runSecretsGetWithDeps is the testable version of runSecretsGet. Only the
+// value is written to w, so the output can be captured by a script.
+func runSecretsGetWithDeps(opts SecretKeyOptions, w io.Writer, deps *Dependencies) error {
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "secrets get", opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	secrets, _, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	value, ok := secrets[opts.Key]
+	if !ok {
+		deps.UI.Error(fmt.Sprintf("%s not found in %s", opts.Key, opts.EnvName))
+		return fmt.Errorf("secret not found")
+	}
+	_, err = fmt.Fprintln(w, value)
+	return err
+}
+
+// runSecretsUnset is the entry point for secrets unset (uses default dependencies)
+func runSecretsUnset(cmd *cobra.Command, args []string) error {
+	opts := SecretKeyOptions{Key: args[0], EnvFlagSet: cmd.Flags().Changed("env")}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Yes, _ = cmd.Flags().GetBool("yes")
+
+	return runSecretsUnsetWithDeps(opts, defaultDeps)
+}
+
+// runSecretsUnsetWithDeps is the testable version of runSecretsUnset
+func runSecretsUnsetWithDeps(opts SecretKeyOptions, deps *Dependencies) error {
+	deps.UI.Intro("secrets unset")
+
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "secrets unset", opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	vaultSecrets, client, err := fetchStoredSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := vaultSecrets[opts.Key]; !ok {
+		deps.UI.Error(fmt.Sprintf("%s not found in %s", opts.Key, opts.EnvName))
+		return fmt.Errorf("secret not found")
+	}
+
+	if !opts.Yes && deps.UI.IsInteractive() {
+		confirm, _ := deps.UI.Confirm(fmt.Sprintf("Remove %s from %s?", opts.Key, opts.EnvName), false)
+		if !confirm {
+			deps.UI.Warn("Aborted.")
+			return nil
+		}
+	} else if !opts.Yes {
+		return fmt.Errorf("confirmation required - use --yes in non-interactive mode")
+	}
+
+	ctx := context.Background()
+	delete(vaultSecrets, opts.Key)
+	err = deps.UI.Spin("Pushing to vault...", func() error {
+		_, pushErr := client.PushSecrets(ctx, repo, opts.EnvName, vaultSecrets)
+		return pushErr
+	})
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok {
+			deps.UI.Error(apiErr.Error())
+		} else {
+			deps.UI.Error(err.Error())
+		}
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Removed %s from vault (%s)", opts.Key, opts.EnvName))
+	notifyChange(deps, NotifyEvent{Event: "unset", Repository: repo, Environment: opts.EnvName, Removed: []string{opts.Key}})
+	return nil
+}
+
+// fetchStoredSecrets pulls an environment as stored, with $ref: values
+// unresolved so it can be pushed back, re-authenticating once on 401.
+// Returns the client to use for follow-up calls.
+func fetchStoredSecrets(deps *Dependencies, client api.APIClient, repo, envName string) (map[string]string, api.APIClient, error) {
+	ctx := context.Background()
+	var secrets map[string]string
+	pull := func() error {
+		resp, err := client.PullSecrets(ctx, repo, envName)
+		if err != nil {
+			return err
+		}
+		secrets = env.Parse(resp.Content)
+		return nil
+	}
+
+	err := deps.UI.Spin("Fetching current secrets...", pull)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return nil, client, authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = deps.UI.Spin("Fetching current secrets...", pull)
+	}
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return nil, client, err
+	}
+	return secrets, client, nil
+}
+
+// runSecretsList is the entry point for secrets list (uses default dependencies)
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	opts := SecretsListOptions{EnvFlagSet: cmd.Flags().Changed("env")}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Reveal, _ = cmd.Flags().GetBool("reveal")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runSecretsListWithDeps(opts, os.Stdout, defaultDeps)
+}
+
+// runSecretsListWithDeps is the testable version of runSecretsList
+func runSecretsListWithDeps(opts SecretsListOptions, w io.Writer, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("secrets list")
+	}
+
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "secrets list", opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	if !opts.JSONOutput {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	secrets, _, err := fetchSecrets(deps, deps.APIFactory.NewClient(token), repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	values := secrets
+	if !opts.Reveal {
+		values = make(map[string]string, len(secrets))
+		for key, value := range secrets {
+			values[key] = maskValue(value)
+		}
+	}
+
+	if opts.JSONOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	}
+
+	if len(values) == 0 {
+		deps.UI.Message(fmt.Sprintf("No secrets in %s", opts.EnvName))
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, key := range sortedSecretKeys(values) {
+		fmt.Fprintf(tw, "  %s\t%s\n", key, values[key])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !opts.Reveal {
+		deps.UI.Message(deps.UI.Dim("Values are masked: pass --reveal to show them"))
+	}
+	deps.UI.Outro(fmt.Sprintf("%d keys", len(values)))
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunSecretsGetWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=abc123\nOTHER=x"}
+
+	var out bytes.Buffer
+	if err := runSecretsGetWithDeps(SecretKeyOptions{Key: "API_KEY", EnvName: "development"}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "abc123\n" {
+		t.Errorf("expected only the value, got %q", out.String())
+	}
+	if len(uiMock.IntroCalls) != 0 {
+		t.Error("expected no decoration around the value")
+	}
+
+	if err := runSecretsGetWithDeps(SecretKeyOptions{Key: "MISSING", EnvName: "development"}, &out, deps); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+func TestRunSecretsUnsetWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=abc\nDB=$ref:shared/DB"}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secrets saved"}
+
+	opts := SecretKeyOptions{Key: "API_KEY", EnvName: "production", EnvFlagSet: true, Yes: true}
+	if err := runSecretsUnsetWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pushed := apiMock.PushedSecrets
+	if _, ok := pushed["API_KEY"]; ok || len(pushed) != 1 {
+		t.Errorf("expected API_KEY removed, got %v", pushed)
+	}
+	if pushed["DB"] != "$ref:shared/DB" {
+		t.Errorf("expected references pushed back unresolved, got %q", pushed["DB"])
+	}
+	if len(uiMock.SuccessCalls) != 1 {
+		t.Errorf("expected a success message, got %v", uiMock.SuccessCalls)
+	}
+}
+
+func TestRunSecretsUnsetWithDeps_RequiresConfirmation(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=abc"}
+
+	if err := runSecretsUnsetWithDeps(SecretKeyOptions{Key: "API_KEY", EnvName: "production"}, deps); err == nil {
+		t.Fatal("expected an error without --yes in non-interactive mode")
+	}
+	if err := runSecretsUnsetWithDeps(SecretKeyOptions{Key: "MISSING", EnvName: "production", Yes: true}, deps); err == nil {
+		t.Fatal("expected an error for a missing key")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("expected nothing pushed")
+	}
+}
+
+func TestRunSecretsListWithDeps_MasksValues(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=sk_live_123456\nPORT=80"}
+
+	var out bytes.Buffer
+	if err := runSecretsListWithDeps(SecretsListOptions{EnvName: "development"}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "sk_live_123456") || !strings.Contains(out.String(), "sk**********56") {
+		t.Errorf("expected masked values, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runSecretsListWithDeps(SecretsListOptions{EnvName: "development", Reveal: true, JSONOutput: true}, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(out.Bytes(), &values); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if values["API_KEY"] != "sk_live_123456" || values["PORT"] != "80" {
+		t.Errorf("expected revealed values, got %v", values)
+	}
+}
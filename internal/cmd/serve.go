@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"unicode"
+
+	"github.com/keywaysh/cli/internal/activity"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local socket for desktop integrations",
+	Long: `Listen on a unix socket so desktop apps (a Docker Desktop extension, an
+editor, a tray app) can list environments and start commands with secrets
+injected, using this CLI's login instead of their own.
+
+Clients never receive secret values or the session token. Every run they
+request is shown in this terminal and only starts once you approve it; the
+command's output is returned with secret values masked.
+
+The socket is only accessible to the current user. The protocol is one JSON
+object per line: {"id": 1, "method": "...", "params": {...}} answered by
+{"id": 1, "result": ...} or {"id": 1, "error": "..."}. Methods:
+
+  hello                                  Protocol version, repository and user
+  environments {repository}              Environments of the vault
+  run {repository, environment,          Run a command after your approval,
+       command: [...], client}           returns exitCode and masked output
+
+Examples:
+  keyway serve
+  keyway serve --socket /tmp/keyway.sock`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().String("socket", "", "Socket path (default ~/.keyway/state/keyway.sock)")
+}
+
+// serveProtocolVersion is bumped on incompatible protocol changes
+const serveProtocolVersion = 1
+
+// ServeOptions contains the parsed flags for the serve command
+type ServeOptions struct {
+	Socket string
+}
+
+// runServe is the entry point for the serve command (uses default dependencies)
+func runServe(cmd *cobra.Command, args []string) error {
+	opts := ServeOptions{}
+	opts.Socket, _ = cmd.Flags().GetString("socket")
+
+	return runServeWithDeps(opts, defaultDeps)
+}
+
+// runServeWithDeps is the testable version of runServe
+func runServeWithDeps(opts ServeOptions, deps *Dependencies) error {
+	deps.UI.Intro("serve")
+
+	if !deps.UI.IsInteractive() {
+		deps.UI.Error("keyway serve asks for your approval before each run and needs an interactive terminal")
+		return fmt.Errorf("interactive terminal required")
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	path := opts.Socket
+	if path == "" {
+		if path, err = state.Path("keyway.sock"); err != nil {
+			deps.UI.Error(fmt.Sprintf("Cannot locate the state directory: %s", err.Error()))
+			return err
+		}
+	}
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	defer ln.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	deps.UI.Success(fmt.Sprintf("Listening on %s", deps.UI.File(path)))
+	deps.UI.Message(deps.UI.Dim("Runs requested by clients wait for your approval here. Press Ctrl+C to stop."))
+
+	server := newRPCServer(deps, deps.APIFactory.NewClient(token))
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			deps.UI.Error(err.Error())
+			return err
+		}
+		go server.serveConn(conn)
+	}
+}
+
+// listenSocket listens on a unix socket that only the current user can use.
+// A socket left behind by a stopped server is replaced.
+func listenSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("another keyway serve is listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// rpcRequest is one line sent by a client
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse answers a request with the same ID
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// rpcServer handles the requests of every connection
+type rpcServer struct {
+	deps *Dependencies
+	// mu serializes requests: they share the terminal for approval prompts
+	mu     sync.Mutex
+	client api.APIClient
+}
+
+func newRPCServer(deps *Dependencies, client api.APIClient) *rpcServer {
+	return &rpcServer{deps: deps, client: client}
+}
+
+// serveConn answers requests from conn until it is closed
+func (s *rpcServer) serveConn(conn io.ReadWriteCloser) {
+	serveRPC(conn, s.handle)
+}
+
+// serveRPC answers the line-delimited JSON requests read from conn with
+// handle, until conn is closed
+func serveRPC(conn io.ReadWriteCloser, handle func(method string, params json.RawMessage) (interface{}, error)) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var resp rpcResponse
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %s", err.Error())
+		} else {
+			resp.ID = req.ID
+			result, err := handle(req.Method, req.Params)
+			if err != nil {
+				resp.Error = err.Error()
+			} else {
+				resp.Result = result
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *rpcServer) handle(method string, params json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch method {
+	case "hello":
+		return s.hello(), nil
+	case "environments":
+		var p struct {
+			Repository string `json:"repository"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.environments(p.Repository)
+	case "run":
+		var p rpcRunParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.run(p)
+	default:
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("invalid params: %s", err.Error())
+	}
+	return nil
+}
+
+// hello lets a client check the protocol version and who it acts as
+func (s *rpcServer) hello() map[string]interface{} {
+	result := map[string]interface{}{
+		"name":     "keyway",
+		"protocol": serveProtocolVersion,
+		"version":  rootCmd.Version,
+	}
+	if repo, err := s.deps.Git.DetectRepo(); err == nil {
+		result["repository"] = repo
+	}
+	if stored, err := s.deps.AuthStore.GetAuth(); err == nil && stored != nil && stored.GitHubLogin != "" {
+		result["user"] = stored.GitHubLogin
+	}
+	return result
+}
+
+// repository returns the requested repository, else the one of the working directory
+func (s *rpcServer) repository(requested string) (string, error) {
+	if requested != "" {
+		return requested, nil
+	}
+	repo, err := s.deps.Git.DetectRepo()
+	if err != nil {
+		return "", fmt.Errorf("no repository given and none detected where keyway serve runs")
+	}
+	return repo, nil
+}
+
+func (s *rpcServer) environments(requested string) (map[string]interface{}, error) {
+	repo, err := s.repository(requested)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := s.client.GetVaultEnvironments(context.Background(), repo)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"repository": repo, "environments": envs}, nil
+}
+
+// ansiEscape matches terminal escape sequences (CSI and OSC)
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)?|\x1b.`)
+
+// promptValue quotes a client-supplied value for a prompt, without the escape
+// sequences and control characters that could move the cursor or erase text
+func promptValue(v string) string {
+	v = ansiEscape.ReplaceAllString(v, "")
+	v = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, v)
+	return fmt.Sprintf("%q", v)
+}
+
+// rpcRunParams are the parameters of the run method
+type rpcRunParams struct {
+	Repository  string   `json:"repository"`
+	Environment string   `json:"environment"`
+	Command     []string `json:"command"`
+	// Client names the app asking, for the approval prompt
+	Client string `json:"client"`
+}
+
+// run starts a command with an environment's secrets once the user approves it
+func (s *rpcServer) run(p rpcRunParams) (map[string]interface{}, error) {
+	if p.Environment == "" {
+		return nil, fmt.Errorf("environment required")
+	}
+	if len(p.Command) == 0 || p.Command[0] == "" {
+		return nil, fmt.Errorf("command required")
+	}
+	repo, err := s.repository(p.Repository)
+	if err != nil {
+		return nil, err
+	}
+	client := p.Client
+	if client == "" {
+		client = "A local app"
+	}
+	commandLine := strings.Join(p.Command, " ")
+
+	// Everything in the prompt but the wording comes from the client: quote
+	// it so it cannot rewrite the question the user approves
+	approved, err := s.deps.UI.Confirm(fmt.Sprintf("%s wants to run %s with the %s secrets of %s. Allow?",
+		promptValue(client), promptValue(commandLine), promptValue(p.Environment), promptValue(repo)), false)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		s.deps.UI.Warn("Denied.")
+		return nil, errors.New("denied by the user")
+	}
+
+	if err := enforceBranchPolicy(s.deps, p.Environment, false); err != nil {
+		return nil, err
+	}
+	secrets, apiClient, err := fetchSecrets(s.deps, s.client, repo, p.Environment)
+	s.client = apiClient
+	if err != nil {
+		return nil, err
+	}
+	dropExpiredSecrets(context.Background(), s.deps, s.client, repo, p.Environment, secrets)
+	if err := enforceLabelPolicy(s.deps, p.Environment, sortedSecretKeys(secrets), false); err != nil {
+		return nil, err
+	}
+	if err := checkCaseCollisions(s.deps, secrets, runtime.GOOS == "windows"); err != nil {
+		return nil, err
+	}
+
+	recordActivity(s.deps, activity.Entry{
+		Action:      activity.ActionRun,
+		Repository:  repo,
+		Environment: p.Environment,
+		Command:     commandLine,
+		Keys:        sortedSecretKeys(secrets),
+	})
+
+	environ, err := inheritedEnviron(inheritDefault, osEnviron())
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	code, err := s.deps.CmdRunner.RunCommandCaptured(p.Command[0], p.Command[1:], environ, secrets, &out)
+	if err != nil {
+		s.deps.UI.Error(err.Error())
+		return nil, err
+	}
+	s.deps.UI.Step(fmt.Sprintf("%s exited with code %d", s.deps.UI.Command(commandLine), code))
+
+	return map[string]interface{}{"exitCode": code, "output": out.String()}, nil
+}
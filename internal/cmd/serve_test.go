@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+// rpcCall sends one request to server over an in-memory connection
+func rpcCall(t *testing.T, server *rpcServer, request string) rpcResponse {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.serveConn(serverConn)
+
+	if _, err := clientConn.Write([]byte(request + "\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	line, err := bufio.NewReader(clientConn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", line, err)
+	}
+	return resp
+}
+
+func TestServe_RequiresInteractiveTerminal(t *testing.T) {
+	deps, _, _, ui, _, _ := NewTestDeps()
+	ui.Interactive = false
+
+	err := runServeWithDeps(ServeOptions{Socket: filepath.Join(t.TempDir(), "k.sock")}, deps)
+	if err == nil {
+		t.Fatal("expected error without a terminal")
+	}
+}
+
+func TestServe_Hello(t *testing.T) {
+	deps, _, _, _, _, apiClient := NewTestDeps()
+	deps.AuthStore.(*MockAuthStore).StoredAuth = &StoredAuthInfo{GitHubLogin: "octocat"}
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":1,"method":"hello"}`)
+
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if string(resp.ID) != "1" {
+		t.Errorf("expected id 1, got %s", resp.ID)
+	}
+	result := resp.Result.(map[string]interface{})
+	if result["protocol"] != float64(serveProtocolVersion) {
+		t.Errorf("expected protocol %d, got %v", serveProtocolVersion, result["protocol"])
+	}
+	if result["repository"] != "owner/repo" || result["user"] != "octocat" {
+		t.Errorf("unexpected hello result: %v", result)
+	}
+}
+
+func TestServe_Environments(t *testing.T) {
+	deps, _, _, _, _, apiClient := NewTestDeps()
+	apiClient.VaultEnvs = []string{"development", "production"}
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":"a","method":"environments","params":{"repository":"other/repo"}}`)
+
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	result := resp.Result.(map[string]interface{})
+	if result["repository"] != "other/repo" {
+		t.Errorf("expected requested repository, got %v", result["repository"])
+	}
+	envs := result["environments"].([]interface{})
+	if len(envs) != 2 || envs[0] != "development" {
+		t.Errorf("unexpected environments: %v", envs)
+	}
+}
+
+func TestServe_RunDenied(t *testing.T) {
+	deps, _, _, ui, runner, apiClient := NewTestDepsWithRunner()
+	ui.Interactive = true
+	ui.ConfirmResult = false
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":2,"method":"run","params":{"environment":"production","command":["npm","start"],"client":"Docker Desktop"}}`)
+
+	if resp.Error != "denied by the user" {
+		t.Errorf("expected denial, got %q", resp.Error)
+	}
+	if runner.LastCommand != "" {
+		t.Error("command should not run without approval")
+	}
+	if len(ui.ConfirmCalls) != 1 || !strings.Contains(ui.ConfirmCalls[0], `"Docker Desktop" wants to run "npm start" with the "production" secrets of "owner/repo"`) {
+		t.Errorf("unexpected prompt: %v", ui.ConfirmCalls)
+	}
+}
+
+func TestServe_RunApproved(t *testing.T) {
+	deps, _, _, ui, runner, apiClient := NewTestDepsWithRunner()
+	ui.Interactive = true
+	ui.ConfirmResult = true
+	apiClient.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret\n"}
+	runner.Output = "started\n"
+	runner.ExitCode = 3
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":3,"method":"run","params":{"environment":"development","command":["npm","start"]}}`)
+
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if runner.LastCommand != "npm" || len(runner.LastArgs) != 1 || runner.LastArgs[0] != "start" {
+		t.Errorf("unexpected command: %s %v", runner.LastCommand, runner.LastArgs)
+	}
+	if runner.LastSecrets["API_KEY"] != "secret" {
+		t.Errorf("expected secrets injected, got %v", runner.LastSecrets)
+	}
+	result := resp.Result.(map[string]interface{})
+	if result["exitCode"] != float64(3) || result["output"] != "started\n" {
+		t.Errorf("unexpected run result: %v", result)
+	}
+}
+
+func TestServe_RunRequiresCommand(t *testing.T) {
+	deps, _, _, ui, _, apiClient := NewTestDepsWithRunner()
+	ui.Interactive = true
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":4,"method":"run","params":{"environment":"development"}}`)
+
+	if resp.Error != "command required" {
+		t.Errorf("expected missing command error, got %q", resp.Error)
+	}
+	if len(ui.ConfirmCalls) != 0 {
+		t.Error("should not prompt for an invalid request")
+	}
+}
+
+func TestServe_UnknownMethodAndInvalidJSON(t *testing.T) {
+	deps, _, _, _, _, apiClient := NewTestDeps()
+	server := newRPCServer(deps, apiClient)
+
+	if resp := rpcCall(t, server, `{"id":5,"method":"secrets"}`); resp.Error != `unknown method "secrets"` {
+		t.Errorf("unexpected error: %q", resp.Error)
+	}
+	if resp := rpcCall(t, server, `{not json`); !strings.HasPrefix(resp.Error, "invalid request") {
+		t.Errorf("unexpected error: %q", resp.Error)
+	}
+}
+
+func TestServe_EnvironmentsError(t *testing.T) {
+	deps, _, _, _, _, apiClient := NewTestDeps()
+	apiClient.VaultEnvsError = errors.New("vault not found")
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":6,"method":"environments"}`)
+
+	if resp.Error != "vault not found" {
+		t.Errorf("expected API error, got %q", resp.Error)
+	}
+}
+
+func TestListenSocket_ReplacesStaleSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket permissions")
+	}
+	path := filepath.Join(t.TempDir(), "k.sock")
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if _, err := listenSocket(path); err == nil || !strings.Contains(err.Error(), "another keyway serve") {
+		t.Errorf("expected live socket to be refused, got %v", err)
+	}
+
+	// Closing a unix listener removes the file; recreate a stale one
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = ln.Close()
+
+	ln, err = listenSocket(path)
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced: %v", err)
+	}
+	defer ln.Close()
+}
+
+func TestServe_RunPromptQuotesClientValues(t *testing.T) {
+	deps, _, _, ui, _, apiClient := NewTestDepsWithRunner()
+	ui.Interactive = true
+
+	rpcCall(t, newRPCServer(deps, apiClient), `{"id":6,"method":"run","params":{"environment":"production","command":["ls","\u001b[2K\rDocker Desktop wants to run ls. Allow?\n"],"client":"App\u001b]0;x\u0007"}}`)
+
+	if len(ui.ConfirmCalls) != 1 {
+		t.Fatalf("expected one prompt, got %v", ui.ConfirmCalls)
+	}
+	prompt := ui.ConfirmCalls[0]
+	if strings.ContainsAny(prompt, "\x1b\r\n\x07") {
+		t.Errorf("expected control characters stripped, got %q", prompt)
+	}
+	if !strings.HasPrefix(prompt, `"App" wants to run "ls Docker Desktop wants to run ls. Allow?" with`) {
+		t.Errorf("expected client values quoted, got %q", prompt)
+	}
+}
+
+func TestServe_RunFiltersEnvironment(t *testing.T) {
+	deps, _, _, ui, runner, apiClient := NewTestDepsWithRunner()
+	ui.Interactive = true
+	ui.ConfirmResult = true
+	apiClient.PullResponse = &api.PullSecretsResponse{Content: "API_KEY=secret\n"}
+
+	original := osEnviron
+	defer func() { osEnviron = original }()
+	osEnviron = func() []string { return []string{"PATH=/usr/bin", "KEYWAY_TOKEN=kw_leak", "LD_PRELOAD=/tmp/x.so"} }
+
+	resp := rpcCall(t, newRPCServer(deps, apiClient), `{"id":7,"method":"run","params":{"environment":"development","command":["npm","start"]}}`)
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if strings.Join(runner.LastEnviron, " ") != "PATH=/usr/bin" {
+		t.Errorf("expected the inheritance policy of keyway run, got %v", runner.LastEnviron)
+	}
+}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/keywaysh/cli/internal/activity"
 	"github.com/keywaysh/cli/internal/analytics"
 	"github.com/keywaysh/cli/internal/api"
 	"github.com/keywaysh/cli/internal/config"
@@ -20,9 +23,18 @@ var setCmd = &cobra.Command{
 
 Examples:
   keyway set API_KEY                    # Prompt for value (masked)
+  keyway set API_KEY --prompt           # Always prompt, even in scripts with a TTY
+  pbpaste | keyway set API_KEY --stdin  # Read the value from stdin
   keyway set API_KEY=sk_live_xxx        # Set with inline value
   keyway set API_KEY -e production      # Set in specific environment
-  keyway set API_KEY -y                 # Skip confirmation if updating`,
+  keyway set API_KEY -y                 # Skip confirmation if updating
+  keyway set TEMP_TOKEN=xxx --ttl 24h   # Expire after 24 hours
+
+Values typed on the command line end up in your shell history: prefer the
+prompt or --stdin.
+
+Secrets set with --ttl are no longer injected by keyway run once expired;
+keyway secrets expiring lists upcoming expirations.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runSet,
 }
@@ -31,6 +43,12 @@ func init() {
 	setCmd.Flags().StringP("env", "e", "", "Environment name (default: development)")
 	setCmd.Flags().BoolP("local", "l", false, "Write to local .env file instead of vault (legacy)")
 	setCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompts")
+	setCmd.Flags().String("ttl", "", "Expire the secret after this duration (e.g. 12h, 7d)")
+	setCmd.Flags().Bool("prompt", false, "Read the value interactively with hidden input")
+	setCmd.Flags().Bool("stdin", false, "Read the value from stdin")
+
+	// secrets set is the same command under the secrets group
+	secretsSetCmd.Flags().AddFlagSet(setCmd.Flags())
 }
 
 // SetOptions contains the parsed flags for the set command
@@ -41,6 +59,10 @@ type SetOptions struct {
 	LocalOnly  bool
 	Yes        bool
 	EnvFlagSet bool
+	TTL        string
+	Prompt     bool
+	// Stdin is read for the value with --stdin, nil otherwise
+	Stdin io.Reader
 }
 
 // runSet is the entry point for the set command (uses default dependencies)
@@ -64,6 +86,11 @@ func runSet(cmd *cobra.Command, args []string) error {
 	opts.EnvName, _ = cmd.Flags().GetString("env")
 	opts.LocalOnly, _ = cmd.Flags().GetBool("local")
 	opts.Yes, _ = cmd.Flags().GetBool("yes")
+	opts.TTL, _ = cmd.Flags().GetString("ttl")
+	opts.Prompt, _ = cmd.Flags().GetBool("prompt")
+	if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+		opts.Stdin = cmd.InOrStdin()
+	}
 
 	return runSetWithDeps(opts, defaultDeps)
 }
@@ -86,8 +113,47 @@ func runSetWithDeps(opts SetOptions, deps *Dependencies) error {
 		}
 	}
 
+	var ttl time.Duration
+	if opts.TTL != "" {
+		var err error
+		if ttl, err = activity.ParseSince(opts.TTL); err != nil || ttl == 0 {
+			deps.UI.Error(fmt.Sprintf("Invalid --ttl %q (use e.g. 12h, 7d)", opts.TTL))
+			return fmt.Errorf("invalid ttl")
+		}
+		if opts.LocalOnly {
+			deps.UI.Error("--ttl is not supported with --local")
+			return fmt.Errorf("invalid ttl")
+		}
+	}
+
 	deps.UI.Step(fmt.Sprintf("Key: %s", deps.UI.Value(opts.Key)))
 
+	if opts.Prompt && opts.Stdin != nil {
+		deps.UI.Error("--prompt and --stdin cannot be combined")
+		return fmt.Errorf("conflicting value sources")
+	}
+	if (opts.Prompt || opts.Stdin != nil) && opts.Value != "" {
+		deps.UI.Error("Pass the value as an argument or with --prompt/--stdin, not both")
+		return fmt.Errorf("conflicting value sources")
+	}
+
+	if opts.Stdin != nil {
+		data, err := io.ReadAll(opts.Stdin)
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to read stdin: %s", err.Error()))
+			return err
+		}
+		opts.Value = strings.TrimRight(string(data), "\r\n")
+		if opts.Value == "" {
+			deps.UI.Error("Value cannot be empty")
+			return fmt.Errorf("value cannot be empty")
+		}
+	} else if opts.Value != "" && deps.UI.IsInteractive() {
+		// Someone typed the value: it is now in their shell history
+		deps.UI.Warn("The value was passed on the command line and may be saved in your shell history")
+		deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Next time use keyway set %s --prompt, or pipe it with --stdin", opts.Key)))
+	}
+
 	// Prompt for value if not provided
 	if opts.Value == "" {
 		if !deps.UI.IsInteractive() {
@@ -112,7 +178,7 @@ func runSetWithDeps(opts SetOptions, deps *Dependencies) error {
 	}
 
 	// Default: push to vault
-	return runSetRemote(opts, deps)
+	return runSetRemote(opts, ttl, deps)
 }
 
 // runSetLocal handles the legacy --local mode
@@ -161,7 +227,7 @@ func runSetLocal(opts SetOptions, deps *Dependencies) error {
 }
 
 // runSetRemote handles pushing to the vault (default behavior)
-func runSetRemote(opts SetOptions, deps *Dependencies) error {
+func runSetRemote(opts SetOptions, ttl time.Duration, deps *Dependencies) error {
 	// Detect repo
 	repo, err := deps.Git.DetectRepo()
 	if err != nil {
@@ -181,17 +247,23 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 	ctx := context.Background()
 
 	envName := opts.EnvName
+	if !opts.EnvFlagSet {
+		envName = defaultEnvironment(deps, "set", envName)
+	}
 
 	// Default to development if not specified
 	if envName == "" {
 		if !opts.EnvFlagSet && deps.UI.IsInteractive() {
 			// Fetch available environments
-			vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
-			if err != nil || len(vaultEnvs) == 0 {
-				vaultEnvs = []string{"development", "staging", "production"}
+			vaultEnvs, err := environmentCandidates(ctx, deps, client, repo)
+			if err != nil {
+				return err
+			}
+			if vaultEnvs, err = accessibleEnvironments(ctx, deps, client, repo, vaultEnvs, true); err != nil {
+				return err
 			}
 
-			selected, err := deps.UI.Select("Environment:", vaultEnvs)
+			selected, err := promptEnvironment(deps, repo, "Environment:", vaultEnvs, "")
 			if err != nil {
 				return err
 			}
@@ -208,7 +280,7 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 	err = deps.UI.Spin("Fetching current secrets...", func() error {
 		resp, err := client.PullSecrets(ctx, repo, envName)
 		if err != nil {
-			if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+			if api.IsNotFound(err) {
 				vaultSecrets = make(map[string]string)
 				return nil
 			}
@@ -228,7 +300,7 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 			err = deps.UI.Spin("Fetching current secrets...", func() error {
 				resp, err := client.PullSecrets(ctx, repo, envName)
 				if err != nil {
-					if apiErr, ok := err.(*api.APIError); ok && apiErr.StatusCode == 404 {
+					if api.IsNotFound(err) {
 						vaultSecrets = make(map[string]string)
 						return nil
 					}
@@ -310,10 +382,24 @@ func runSetRemote(opts SetOptions, deps *Dependencies) error {
 		}
 	}
 
+	changed := NotifyEvent{Event: "set", Repository: repo, Environment: envName}
 	if existsInVault {
 		deps.UI.Success(fmt.Sprintf("Updated %s in vault (%s)", opts.Key, envName))
+		changed.Changed = []string{opts.Key}
 	} else {
 		deps.UI.Success(fmt.Sprintf("Added %s to vault (%s)", opts.Key, envName))
+		changed.Added = []string{opts.Key}
+	}
+	notifyChange(deps, changed)
+
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		if err := client.SetSecretExpiry(ctx, repo, envName, opts.Key, &expiresAt); err != nil {
+			deps.UI.Error(fmt.Sprintf("Could not set the expiry of %s: %s", opts.Key, err.Error()))
+			deps.UI.Message(deps.UI.Dim("The value was saved without a TTL"))
+			return err
+		}
+		deps.UI.Step(fmt.Sprintf("Expires: %s %s", deps.UI.Value(formatTimestamp(&expiresAt)), deps.UI.Dim("(in "+roughDuration(ttl)+")")))
 	}
 
 	// Show tip for using the secret
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/keywaysh/cli/internal/api"
@@ -689,3 +690,63 @@ func TestFormatEnvContent_SortsKeys(t *testing.T) {
 		t.Errorf("expected sorted output:\n%s\ngot:\n%s", expected, result)
 	}
 }
+
+func TestRunSetWithDeps_Stdin(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "API_KEY", EnvName: "development", EnvFlagSet: true, Stdin: strings.NewReader("sk_live_123\n")}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets["API_KEY"] != "sk_live_123" {
+		t.Errorf("expected the stdin value without its newline, got %q", apiMock.PushedSecrets["API_KEY"])
+	}
+}
+
+func TestRunSetWithDeps_StdinWithValueArgument(t *testing.T) {
+	deps, _, _, _, _, _, apiMock := NewTestDepsWithEnv()
+
+	opts := SetOptions{Key: "API_KEY", Value: "inline", EnvName: "development", EnvFlagSet: true, Stdin: strings.NewReader("piped")}
+	if err := runSetWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for two value sources")
+	}
+	if apiMock.PushedSecrets != nil {
+		t.Error("nothing should be pushed")
+	}
+}
+
+func TestRunSetWithDeps_PromptFlag(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	uiMock.Interactive = true
+	uiMock.PasswordResult = "typed-secret"
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "API_KEY", EnvName: "development", EnvFlagSet: true, Prompt: true}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if apiMock.PushedSecrets["API_KEY"] != "typed-secret" {
+		t.Errorf("expected the prompted value, got %q", apiMock.PushedSecrets["API_KEY"])
+	}
+	if len(uiMock.WarnCalls) != 0 {
+		t.Errorf("no history warning expected for a prompted value, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunSetWithDeps_WarnsAboutShellHistory(t *testing.T) {
+	deps, _, _, uiMock, _, _, apiMock := NewTestDepsWithEnv()
+	uiMock.Interactive = true
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: ""}
+	apiMock.PushResponse = &api.PushSecretsResponse{Message: "Secret saved"}
+
+	opts := SetOptions{Key: "API_KEY", Value: "sk_live_123", EnvName: "development", EnvFlagSet: true}
+	if err := runSetWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.WarnCalls) == 0 || !strings.Contains(uiMock.WarnCalls[0], "shell history") {
+		t.Errorf("expected a shell history warning, got %v", uiMock.WarnCalls)
+	}
+}
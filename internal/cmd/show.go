@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List the keys of an environment",
+	Long: `List the key names of an environment, without their values.
+
+With --details, show when each key was created, last modified and last included
+in a pull, and who pulled it, to find dead keys and see how often values change.
+Details are shown when the API provides them.
+
+Examples:
+  keyway show
+  keyway show --env production --details
+  keyway show --details --json
+  keyway show --details --format template='{{.Key}} {{.LastPulledBy}}'`,
+	Args: cobra.NoArgs,
+	RunE: runShow,
+}
+
+func init() {
+	showCmd.Flags().StringP("env", "e", "development", "Environment name")
+	showCmd.Flags().Bool("details", false, "Show created, modified and last pulled timestamps")
+	showCmd.Flags().Bool("json", false, "Output as JSON")
+	showCmd.Flags().String("format", "", formatFlagUsage)
+}
+
+// ShowOptions contains the parsed flags for the show command
+type ShowOptions struct {
+	EnvName    string
+	EnvFlagSet bool
+	Details    bool
+	JSONOutput bool
+	// Format is a --format value, see parseOutputFormat
+	Format string
+}
+
+// runShow is the entry point for the show command (uses default dependencies)
+func runShow(cmd *cobra.Command, args []string) error {
+	opts := ShowOptions{EnvFlagSet: cmd.Flags().Changed("env")}
+	opts.EnvName, _ = cmd.Flags().GetString("env")
+	opts.Details, _ = cmd.Flags().GetBool("details")
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+	opts.Format, _ = cmd.Flags().GetString("format")
+
+	return runShowWithDeps(opts, defaultDeps)
+}
+
+// runShowWithDeps is the testable version of runShow
+func runShowWithDeps(opts ShowOptions, deps *Dependencies) error {
+	var tmpl *template.Template
+	if opts.Format != "" {
+		if opts.JSONOutput {
+			return fmt.Errorf("--json and --format cannot be used together")
+		}
+		var err error
+		if tmpl, err = parseOutputFormat(opts.Format); err != nil {
+			return err
+		}
+	}
+	quiet := opts.JSONOutput || tmpl != nil
+
+	if !quiet {
+		deps.UI.Intro("show")
+	}
+
+	if !opts.EnvFlagSet {
+		opts.EnvName = defaultEnvironment(deps, "show", opts.EnvName)
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	if !quiet {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+		deps.UI.Step(fmt.Sprintf("Environment: %s", deps.UI.Value(opts.EnvName)))
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	secrets, client, err := fetchSecrets(deps, client, repo, opts.EnvName)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]api.KeyMetadata, 0, len(secrets))
+	for _, key := range sortedSecretKeys(secrets) {
+		keys = append(keys, api.KeyMetadata{Key: key})
+	}
+
+	withDetails := false
+	if opts.Details {
+		metadata, err := client.GetSecretsMetadata(context.Background(), repo, opts.EnvName)
+		if err != nil {
+			deps.UI.Error(fmt.Sprintf("Failed to fetch key details: %s", err.Error()))
+			return err
+		}
+		if metadata == nil {
+			deps.UI.Warn("Key details are not available for this vault")
+		} else {
+			withDetails = true
+			mergeKeyMetadata(keys, metadata)
+		}
+	}
+
+	if opts.JSONOutput {
+		return printShowJSON(os.Stdout, keys)
+	}
+	if tmpl != nil {
+		var out bytes.Buffer
+		for _, k := range keys {
+			if err := executeOutputFormat(&out, tmpl, k); err != nil {
+				deps.UI.Error(err.Error())
+				return err
+			}
+		}
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+
+	if len(keys) == 0 {
+		deps.UI.Message(fmt.Sprintf("No secrets in %s", opts.EnvName))
+		return nil
+	}
+
+	fmt.Println()
+	printShowTable(os.Stdout, keys, withDetails)
+
+	if withDetails {
+		if n := countNeverPulled(keys); n > 0 {
+			deps.UI.Message(deps.UI.Dim(fmt.Sprintf("%d of %d keys were never pulled", n, len(keys))))
+		}
+	}
+	deps.UI.Outro(fmt.Sprintf("%d keys", len(keys)))
+	return nil
+}
+
+// mergeKeyMetadata copies the API metadata onto keys. Metadata for keys that
+// are no longer in the environment is ignored.
+func mergeKeyMetadata(keys []api.KeyMetadata, metadata []api.KeyMetadata) {
+	byKey := make(map[string]api.KeyMetadata, len(metadata))
+	for _, m := range metadata {
+		byKey[m.Key] = m
+	}
+	for i := range keys {
+		if m, ok := byKey[keys[i].Key]; ok {
+			keys[i] = m
+		}
+	}
+}
+
+// countNeverPulled returns the number of keys without a last pull
+func countNeverPulled(keys []api.KeyMetadata) int {
+	n := 0
+	for _, k := range keys {
+		if k.LastPulledAt == nil || k.LastPulledAt.IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+func printShowTable(w io.Writer, keys []api.KeyMetadata, withDetails bool) {
+	if !withDetails {
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %s\n", k.Key)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  KEY\tCREATED\tMODIFIED\tLAST PULL\tPULLED BY")
+	for _, k := range keys {
+		pulledBy := k.LastPulledBy
+		if pulledBy == "" {
+			pulledBy = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+			k.Key, formatKnownTimestamp(k.CreatedAt), formatKnownTimestamp(k.UpdatedAt), formatTimestamp(k.LastPulledAt), pulledBy)
+	}
+	_ = tw.Flush()
+}
+
+// formatKnownTimestamp is formatTimestamp for dates that are missing rather than "never"
+func formatKnownTimestamp(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return formatTimestamp(t)
+}
+
+func printShowJSON(w io.Writer, keys []api.KeyMetadata) error {
+	output, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(output))
+	return nil
+}
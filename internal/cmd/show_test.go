@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunShowWithDeps_Details(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "B=2\nA=1\n"}
+	pulled := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apiMock.KeyMetadata = []api.KeyMetadata{
+		{Key: "A", LastPulledAt: &pulled, LastPulledBy: "alice"},
+		{Key: "DELETED", LastPulledAt: &pulled},
+	}
+
+	err := runShowWithDeps(ShowOptions{EnvName: "production", Details: true}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, m := range uiMock.MessageCalls {
+		if strings.Contains(m, "1 of 2 keys were never pulled") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected never pulled summary, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunShowWithDeps_DetailsUnavailable(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1\n"}
+
+	err := runShowWithDeps(ShowOptions{EnvName: "production", Details: true}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "not available") {
+		t.Errorf("expected a warning, got %v", uiMock.WarnCalls)
+	}
+}
+
+func TestRunShowWithDeps_DetailsError(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1\n"}
+	apiMock.KeyMetadataError = errors.New("server error")
+
+	if err := runShowWithDeps(ShowOptions{EnvName: "production", Details: true}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMergeKeyMetadata(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []api.KeyMetadata{{Key: "A"}, {Key: "B"}}
+
+	mergeKeyMetadata(keys, []api.KeyMetadata{{Key: "B", CreatedAt: &created}, {Key: "GONE"}})
+
+	if keys[0].CreatedAt != nil || keys[1].CreatedAt == nil || len(keys) != 2 {
+		t.Errorf("unexpected merge result %+v", keys)
+	}
+	if countNeverPulled(keys) != 2 {
+		t.Errorf("expected 2 never pulled keys")
+	}
+}
+
+func TestPrintShowTable(t *testing.T) {
+	pulled := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	keys := []api.KeyMetadata{
+		{Key: "API_KEY", LastPulledAt: &pulled, LastPulledBy: "alice"},
+		{Key: "OLD_KEY"},
+	}
+
+	var buf bytes.Buffer
+	printShowTable(&buf, keys, true)
+	out := buf.String()
+
+	if !strings.Contains(out, "PULLED BY") || !strings.Contains(out, "alice") {
+		t.Errorf("expected details columns, got:\n%s", out)
+	}
+	if !strings.Contains(out, "never") {
+		t.Errorf("expected never for unpulled key, got:\n%s", out)
+	}
+
+	buf.Reset()
+	printShowTable(&buf, keys, false)
+	if strings.Contains(buf.String(), "PULLED BY") || !strings.Contains(buf.String(), "OLD_KEY") {
+		t.Errorf("expected plain key list, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keywaysh/cli/internal/analytics"
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/env"
+	"github.com/keywaysh/cli/internal/jobs"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show vault statistics per environment",
+	Long: `Show key counts, payload size and activity for each environment of the vault.
+
+Last pull/push timestamps and top pullers are shown when the API provides them.
+
+Examples:
+  keyway stats
+  keyway stats --json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// StatsOptions contains the parsed flags for the stats command
+type StatsOptions struct {
+	JSONOutput bool
+}
+
+// VaultStats is the output of the stats command
+type VaultStats struct {
+	Repository   string             `json:"repository"`
+	Environments []EnvironmentStats `json:"environments"`
+	TotalKeys    int                `json:"totalKeys"`
+	TotalBytes   int                `json:"totalBytes"`
+}
+
+// EnvironmentStats holds the statistics for one environment
+type EnvironmentStats struct {
+	Name         string       `json:"name"`
+	Keys         int          `json:"keys"`
+	Bytes        int          `json:"bytes"`
+	LastPulledAt *time.Time   `json:"lastPulledAt,omitempty"`
+	LastPushedAt *time.Time   `json:"lastPushedAt,omitempty"`
+	TopPullers   []api.Puller `json:"topPullers,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// runStats is the entry point for the stats command (uses default dependencies)
+func runStats(cmd *cobra.Command, args []string) error {
+	opts := StatsOptions{}
+	opts.JSONOutput, _ = cmd.Flags().GetBool("json")
+
+	return runStatsWithDeps(opts, defaultDeps)
+}
+
+// runStatsWithDeps is the testable version of runStats
+func runStatsWithDeps(opts StatsOptions, deps *Dependencies) error {
+	if !opts.JSONOutput {
+		deps.UI.Intro("stats")
+	}
+
+	repo, err := deps.Git.DetectRepo()
+	if err != nil {
+		deps.UI.Error("Not in a git repository with GitHub remote")
+		return err
+	}
+	if !opts.JSONOutput {
+		deps.UI.Step(fmt.Sprintf("Repository: %s", deps.UI.Value(repo)))
+	}
+
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	client := deps.APIFactory.NewClient(token)
+	ctx := context.Background()
+
+	var environments []string
+	fetchEnvs := func() error {
+		var fetchErr error
+		environments, fetchErr = client.GetVaultEnvironments(ctx, repo)
+		return fetchErr
+	}
+	err = deps.UI.Spin("Fetching environments...", fetchEnvs)
+	if err != nil && isAuthError(err) {
+		newToken, authErr := handleAuthError(err, deps)
+		if authErr != nil {
+			return authErr
+		}
+		client = deps.APIFactory.NewClient(newToken)
+		err = deps.UI.Spin("Fetching environments...", fetchEnvs)
+	}
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Failed to fetch environments: %v", err))
+		return err
+	}
+
+	stats := &VaultStats{Repository: repo, Environments: []EnvironmentStats{}}
+	var activity *api.VaultActivity
+
+	err = deps.UI.Spin("Collecting statistics...", func() error {
+		stats.Environments = make([]EnvironmentStats, len(environments))
+		index := make(map[string]int, len(environments))
+		for i, name := range environments {
+			index[name] = i
+			stats.Environments[i].Name = name
+		}
+
+		summary := jobs.Run(ctx, environments, func(ctx context.Context, name string) error {
+			resp, pullErr := client.PullSecrets(ctx, repo, name)
+			if pullErr != nil {
+				return pullErr
+			}
+			envStats := &stats.Environments[index[name]]
+			envStats.Keys = len(env.Parse(resp.Content))
+			envStats.Bytes = len(resp.Content)
+			return nil
+		}, jobs.Options{Retry: api.WithRetry})
+		for _, failed := range summary.Failed() {
+			stats.Environments[index[failed.Name]].Error = failed.Err.Error()
+		}
+
+		// Activity is optional: older API versions don't expose it
+		activity, _ = client.GetVaultActivity(ctx, repo)
+		return nil
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	applyActivity(stats, activity)
+
+	analytics.Track(analytics.EventStats, map[string]interface{}{
+		"environments": len(stats.Environments),
+		"total_keys":   stats.TotalKeys,
+		"has_activity": activity != nil,
+	})
+
+	if opts.JSONOutput {
+		return printStatsJSON(os.Stdout, stats)
+	}
+
+	fmt.Println()
+	printStatsTable(os.Stdout, stats, activity != nil)
+	if activity == nil {
+		deps.UI.Message(deps.UI.Dim("Pull/push activity is not available for this vault"))
+	}
+	deps.UI.Outro("")
+	return nil
+}
+
+// applyActivity merges API activity into the stats and computes totals
+func applyActivity(stats *VaultStats, activity *api.VaultActivity) {
+	byEnv := make(map[string]api.EnvironmentActivity)
+	if activity != nil {
+		for _, a := range activity.Environments {
+			byEnv[a.Environment] = a
+		}
+	}
+
+	stats.TotalKeys = 0
+	stats.TotalBytes = 0
+	for i := range stats.Environments {
+		e := &stats.Environments[i]
+		if a, ok := byEnv[e.Name]; ok {
+			e.LastPulledAt = a.LastPulledAt
+			e.LastPushedAt = a.LastPushedAt
+			e.TopPullers = a.TopPullers
+		}
+		stats.TotalKeys += e.Keys
+		stats.TotalBytes += e.Bytes
+	}
+}
+
+func printStatsTable(w io.Writer, stats *VaultStats, withActivity bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	if withActivity {
+		fmt.Fprintln(tw, "  ENVIRONMENT\tKEYS\tSIZE\tLAST PULL\tLAST PUSH\tTOP PULLERS")
+	} else {
+		fmt.Fprintln(tw, "  ENVIRONMENT\tKEYS\tSIZE")
+	}
+
+	for _, e := range stats.Environments {
+		if e.Error != "" {
+			fmt.Fprintf(tw, "  %s\t-\t-\tunavailable\n", e.Name)
+			continue
+		}
+		if withActivity {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\t%s\n",
+				e.Name, e.Keys, formatSize(e.Bytes),
+				formatTimestamp(e.LastPulledAt), formatTimestamp(e.LastPushedAt), formatPullers(e.TopPullers))
+		} else {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\n", e.Name, e.Keys, formatSize(e.Bytes))
+		}
+	}
+
+	fmt.Fprintf(tw, "  Total\t%d\t%s\n", stats.TotalKeys, formatSize(stats.TotalBytes))
+	_ = tw.Flush()
+}
+
+func printStatsJSON(w io.Writer, stats *VaultStats) error {
+	output, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(output))
+	return nil
+}
+
+// formatSize renders a byte count as B, KB or MB
+func formatSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func formatTimestamp(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatPullers renders up to three pullers as "login (n)"
+func formatPullers(pullers []api.Puller) string {
+	if len(pullers) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, 3)
+	for i, p := range pullers {
+		if i == 3 {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", p.Login, p.Pulls))
+	}
+	return strings.Join(parts, ", ")
+}
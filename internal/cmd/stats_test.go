@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunStatsWithDeps_Success(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development", "production"}
+	apiMock.PullResponses = map[string]*api.PullSecretsResponse{
+		"development": {Content: "A=1\nB=2\n"},
+		"production":  {Content: "A=1\n"},
+	}
+
+	err := runStatsWithDeps(StatsOptions{}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.IntroCalls) == 0 {
+		t.Error("expected Intro to be called")
+	}
+	if len(uiMock.MessageCalls) == 0 {
+		t.Error("expected a note that activity is unavailable")
+	}
+}
+
+func TestRunStatsWithDeps_JSONSkipsIntro(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvs = []string{"development"}
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "A=1"}
+
+	err := runStatsWithDeps(StatsOptions{JSONOutput: true}, deps)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.IntroCalls) != 0 {
+		t.Error("JSON output should not print the intro")
+	}
+}
+
+func TestRunStatsWithDeps_EnvironmentsError(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.VaultEnvsError = errors.New("network error")
+
+	err := runStatsWithDeps(StatsOptions{}, deps)
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(uiMock.ErrorCalls) == 0 {
+		t.Error("expected UI.Error to be called")
+	}
+}
+
+func TestRunStatsWithDeps_GitError(t *testing.T) {
+	deps, gitMock, _, _, _, _ := NewTestDeps()
+	gitMock.RepoError = errors.New("not a git repo")
+
+	if err := runStatsWithDeps(StatsOptions{}, deps); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestApplyActivity(t *testing.T) {
+	pulled := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	stats := &VaultStats{
+		Environments: []EnvironmentStats{
+			{Name: "development", Keys: 2, Bytes: 8},
+			{Name: "production", Keys: 1, Bytes: 4},
+			{Name: "staging", Error: "not found"},
+		},
+	}
+	activity := &api.VaultActivity{
+		Environments: []api.EnvironmentActivity{
+			{Environment: "production", LastPulledAt: &pulled, TopPullers: []api.Puller{{Login: "alice", Pulls: 3}}},
+		},
+	}
+
+	applyActivity(stats, activity)
+
+	if stats.TotalKeys != 3 || stats.TotalBytes != 12 {
+		t.Errorf("unexpected totals: keys=%d bytes=%d", stats.TotalKeys, stats.TotalBytes)
+	}
+	prod := stats.Environments[1]
+	if prod.LastPulledAt == nil || !prod.LastPulledAt.Equal(pulled) {
+		t.Errorf("expected production LastPulledAt to be set, got %v", prod.LastPulledAt)
+	}
+	if len(prod.TopPullers) != 1 || prod.TopPullers[0].Login != "alice" {
+		t.Errorf("unexpected top pullers: %v", prod.TopPullers)
+	}
+	if stats.Environments[0].LastPulledAt != nil {
+		t.Error("development should have no activity")
+	}
+}
+
+func TestPrintStatsTable(t *testing.T) {
+	stats := &VaultStats{
+		Environments: []EnvironmentStats{
+			{Name: "development", Keys: 2, Bytes: 2048},
+			{Name: "staging", Error: "not found"},
+		},
+		TotalKeys:  2,
+		TotalBytes: 2048,
+	}
+
+	var buf bytes.Buffer
+	printStatsTable(&buf, stats, false)
+	out := buf.String()
+
+	for _, want := range []string{"ENVIRONMENT", "development", "2.0 KB", "unavailable", "Total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "LAST PULL") {
+		t.Error("activity columns should be hidden without activity data")
+	}
+}
+
+func TestPrintStatsJSON(t *testing.T) {
+	stats := &VaultStats{Repository: "owner/repo", Environments: []EnvironmentStats{{Name: "development", Keys: 1, Bytes: 3}}, TotalKeys: 1, TotalBytes: 3}
+
+	var buf bytes.Buffer
+	if err := printStatsJSON(&buf, stats); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded VaultStats
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Repository != "owner/repo" || decoded.TotalKeys != 1 {
+		t.Errorf("unexpected decoded stats: %+v", decoded)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{3 * 1024 * 1024, "3.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
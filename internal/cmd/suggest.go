@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxSuggestionDistance is the largest edit distance offered as "did you mean"
+const maxSuggestionDistance = 2
+
+// flagErrorWithSuggestion adds a "did you mean" hint to unknown flag errors
+func flagErrorWithSuggestion(cmd *cobra.Command, err error) error {
+	name, ok := strings.CutPrefix(err.Error(), "unknown flag: --")
+	if !ok {
+		return err
+	}
+	if suggestion := suggestFlag(cmd, name); suggestion != "" {
+		return fmt.Errorf("%w\n\nDid you mean this?\n\t--%s", err, suggestion)
+	}
+	return err
+}
+
+// suggestFlag returns the closest flag name of cmd, or "" if none is close enough
+func suggestFlag(cmd *cobra.Command, name string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	visit := func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		if d := editDistance(name, f.Name); d < bestDistance || (d == bestDistance && f.Name < best) {
+			best, bestDistance = f.Name, d
+		}
+	}
+	cmd.LocalFlags().VisitAll(visit)
+	cmd.InheritedFlags().VisitAll(visit)
+	return best
+}
+
+// addUnknownSubcommandErrors makes group commands (keyway secrets, keyway cloud run...)
+// reject unknown subcommands with suggestions, like the root command does,
+// instead of silently printing their help.
+func addUnknownSubcommandErrors(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		addUnknownSubcommandErrors(sub)
+	}
+	if cmd == rootCmd || !cmd.HasSubCommands() || cmd.Runnable() {
+		return
+	}
+
+	cmd.SuggestionsMinimumDistance = maxSuggestionDistance
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		message := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+		if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+			message += "\n\nDid you mean this?\n\t" + strings.Join(suggestions, "\n\t")
+		}
+		return fmt.Errorf("%s", message)
+	}
+}
+
+// editDistance is the Levenshtein distance between a and b, ignoring case
+func editDistance(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"force", "force", 0},
+		{"foce", "force", 1},
+		{"Force", "force", 0},
+		{"evn", "env", 2},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFlagErrorWithSuggestion(t *testing.T) {
+	cmd := &cobra.Command{Use: "pull"}
+	cmd.Flags().Bool("force", false, "")
+	cmd.Flags().String("env", "", "")
+	cmd.Flags().Bool("internal", false, "")
+	_ = cmd.Flags().MarkHidden("internal")
+
+	err := flagErrorWithSuggestion(cmd, errors.New("unknown flag: --foce"))
+	if !strings.Contains(err.Error(), "Did you mean this?\n\t--force") {
+		t.Errorf("expected --force suggestion, got %q", err.Error())
+	}
+
+	err = flagErrorWithSuggestion(cmd, errors.New("unknown flag: --internl"))
+	if strings.Contains(err.Error(), "Did you mean") {
+		t.Errorf("hidden flags should not be suggested, got %q", err.Error())
+	}
+
+	err = flagErrorWithSuggestion(cmd, errors.New("unknown shorthand flag: 'z' in -z"))
+	if err.Error() != "unknown shorthand flag: 'z' in -z" {
+		t.Errorf("other errors should be unchanged, got %q", err.Error())
+	}
+}
+
+func TestAddUnknownSubcommandErrors(t *testing.T) {
+	group := &cobra.Command{Use: "secrets"}
+	group.AddCommand(&cobra.Command{Use: "copy-value", Run: func(*cobra.Command, []string) {}})
+	root := &cobra.Command{Use: "keyway"}
+	root.AddCommand(group)
+
+	addUnknownSubcommandErrors(root)
+
+	root.SetArgs([]string{"secrets", "copyvalue"})
+	root.SilenceErrors, root.SilenceUsage = true, true
+	err := root.Execute()
+	if err == nil {
+		t.Fatal("expected unknown command error")
+	}
+	if !strings.Contains(err.Error(), `unknown command "copyvalue" for "keyway secrets"`) ||
+		!strings.Contains(err.Error(), "copy-value") {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+}
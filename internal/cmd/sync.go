@@ -25,17 +25,25 @@ var (
 
 var syncCmd = &cobra.Command{
 	Use:   "sync [provider]",
-	Short: "Sync secrets with a provider (vercel, railway)",
+	Short: "Sync secrets with a provider (vercel, railway, azure-devops, bitbucket)",
 	Long: `Sync secrets between your Keyway vault and a provider like Vercel or Railway.
 
 If no provider is specified, you'll be prompted to select one.
 
+Azure DevOps targets are variable groups of a project; the Keyway environment
+name is used as the group name unless --provider-env is set. Bitbucket targets
+are the deployment environments of a repository (Test, Staging, Production) or
+its repository variables (--provider-env repository). Keys pushed to either are
+secret (Azure DevOps) or secured (Bitbucket), except those in --plain-keys.
+
 Examples:
   keyway sync              # Interactive provider selection
   keyway sync vercel       # Sync with Vercel
   keyway sync railway      # Sync with Railway
   keyway sync vercel --push --env production
-  keyway sync vercel --pull --env staging`,
+  keyway sync vercel --pull --env staging
+  keyway sync azure-devops --push --env production --plain-keys NODE_ENV,LOG_LEVEL
+  keyway sync bitbucket --push --env staging`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSync,
 }
@@ -48,6 +56,7 @@ func init() {
 	syncCmd.Flags().StringP("project", "p", "", "Provider project name or ID")
 	syncCmd.Flags().String("team", "", "Filter by team/organization")
 	syncCmd.Flags().Bool("allow-delete", false, "Allow deleting secrets during push")
+	syncCmd.Flags().StringSlice("plain-keys", nil, "Keys pushed as plain, unmasked variables (azure-devops, bitbucket)")
 	syncCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompts")
 }
 
@@ -76,11 +85,37 @@ func mapToProviderEnvironment(provider, keywayEnv string) string {
 			return env
 		}
 		return "production"
+	case "bitbucket":
+		// Bitbucket's default deployment environments
+		mapping := map[string]string{
+			"production":  "Production",
+			"staging":     "Staging",
+			"dev":         "Test",
+			"development": "Test",
+			"test":        "Test",
+		}
+		if env, ok := mapping[strings.ToLower(keywayEnv)]; ok {
+			return env
+		}
+		return "Production"
 	default:
+		// Azure DevOps variable groups are named freely: keep the Keyway name
 		return keywayEnv
 	}
 }
 
+// Providers whose variables carry a secret/masked flag set from --plain-keys
+var secretFlagProviders = []string{"azure-devops", "bitbucket"}
+
+func supportsSecretFlag(provider string) bool {
+	for _, p := range secretFlagProviders {
+		if strings.EqualFold(p, provider) {
+			return true
+		}
+	}
+	return false
+}
+
 // ProjectWithLinkedRepo represents a provider project with metadata
 type ProjectWithLinkedRepo struct {
 	ID           string
@@ -169,7 +204,7 @@ func ensureProvider(ctx context.Context, client *api.Client, args []string) (str
 	providers, err := client.GetProviders(ctx)
 	if err != nil {
 		if apiErr, ok := err.(*api.APIError); ok {
-			if apiErr.StatusCode == 401 {
+			if apiErr.AuthExpired() {
 				ui.Error("Authentication failed. Please login again.")
 				ui.Message(ui.Dim("Run: keyway login"))
 				return "", err
@@ -364,9 +399,9 @@ func selectSyncEnvironments(ctx context.Context, client *api.Client, repo string
 	providerEnv = providerEnvFlag
 
 	if keywayEnv == "" && ui.IsInteractive() {
-		vaultEnvs, err := client.GetVaultEnvironments(ctx, repo)
-		if err != nil || len(vaultEnvs) == 0 {
-			vaultEnvs = []string{"production", "staging", "development"}
+		vaultEnvs, err := environmentCandidates(ctx, defaultDeps, client, repo)
+		if err != nil {
+			return "", "", err
 		}
 
 		selected, err := ui.Select("Keyway environment:", vaultEnvs)
@@ -423,6 +458,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 	teamFlag, _ := cmd.Flags().GetString("team")
 	allowDelete, _ := cmd.Flags().GetBool("allow-delete")
 	skipConfirm, _ := cmd.Flags().GetBool("yes")
+	plainKeys, _ := cmd.Flags().GetStringSlice("plain-keys")
 
 	// Validate incompatible options
 	if pullFlag && allowDelete {
@@ -430,6 +466,11 @@ func runSync(cmd *cobra.Command, args []string) error {
 		ui.Message(ui.Dim("The --allow-delete flag is only for push operations."))
 		return fmt.Errorf("invalid options")
 	}
+	if pullFlag && len(plainKeys) > 0 {
+		ui.Error("--plain-keys cannot be used with --pull")
+		ui.Message(ui.Dim("The --plain-keys flag is only for push operations."))
+		return fmt.Errorf("invalid options")
+	}
 
 	token, err := EnsureLogin()
 	if err != nil {
@@ -444,6 +485,11 @@ func runSync(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(plainKeys) > 0 && !supportsSecretFlag(provider) {
+		ui.Error(fmt.Sprintf("--plain-keys is not supported for %s", provider))
+		ui.Message(ui.Dim(fmt.Sprintf("Supported providers: %s", strings.Join(secretFlagProviders, ", "))))
+		return fmt.Errorf("invalid options")
+	}
 
 	// Detect current repo
 	repo, err := git.DetectRepo()
@@ -653,7 +699,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute sync
-	return executeSyncOperation(client, ctx, repo, selectedProject, keywayEnv, providerEnv, direction, allowDelete, skipConfirm, provider)
+	return executeSyncOperation(client, ctx, repo, selectedProject, keywayEnv, providerEnv, direction, allowDelete, skipConfirm, provider, plainKeys)
 }
 
 func promptProjectSelection(projects []ProjectWithLinkedRepo, repoFullName, providerDisplayName string, hasMultipleAccounts bool) (ProjectWithLinkedRepo, error) {
@@ -766,7 +812,7 @@ func displayDiffSummary(diff *api.SyncDiff, providerName string) {
 	}
 }
 
-func executeSyncOperation(client *api.Client, ctx context.Context, repo string, project ProjectWithLinkedRepo, keywayEnv, providerEnv, direction string, allowDelete, skipConfirm bool, provider string) error {
+func executeSyncOperation(client *api.Client, ctx context.Context, repo string, project ProjectWithLinkedRepo, keywayEnv, providerEnv, direction string, allowDelete, skipConfirm bool, provider string, plainKeys []string) error {
 	providerName := cases.Title(language.English).String(provider)
 
 	// Get preview
@@ -781,6 +827,7 @@ func executeSyncOperation(client *api.Client, ctx context.Context, repo string,
 			ProviderEnvironment: providerEnv,
 			Direction:           direction,
 			AllowDelete:         allowDelete,
+			PlainKeys:           plainKeys,
 		})
 		return err
 	})
@@ -865,6 +912,7 @@ func executeSyncOperation(client *api.Client, ctx context.Context, repo string,
 			ProviderEnvironment: providerEnv,
 			Direction:           direction,
 			AllowDelete:         allowDelete,
+			PlainKeys:           plainKeys,
 		})
 		return err
 	})
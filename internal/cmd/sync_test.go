@@ -51,6 +51,43 @@ func TestMapToProviderEnvironment_Railway(t *testing.T) {
 	}
 }
 
+func TestMapToProviderEnvironment_Bitbucket(t *testing.T) {
+	tests := []struct {
+		keywayEnv string
+		expected  string
+	}{
+		{"production", "Production"},
+		{"staging", "Staging"},
+		{"development", "Test"},
+		{"test", "Test"},
+		{"unknown", "Production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keywayEnv, func(t *testing.T) {
+			got := mapToProviderEnvironment("bitbucket", tt.keywayEnv)
+			if got != tt.expected {
+				t.Errorf("mapToProviderEnvironment(bitbucket, %q) = %q, want %q", tt.keywayEnv, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMapToProviderEnvironment_AzureDevOps(t *testing.T) {
+	// Variable groups keep the Keyway environment name
+	if got := mapToProviderEnvironment("azure-devops", "production"); got != "production" {
+		t.Errorf("mapToProviderEnvironment(azure-devops, production) = %q, want %q", got, "production")
+	}
+}
+
+func TestSupportsSecretFlag(t *testing.T) {
+	for provider, expected := range map[string]bool{"azure-devops": true, "Bitbucket": true, "vercel": false, "railway": false} {
+		if got := supportsSecretFlag(provider); got != expected {
+			t.Errorf("supportsSecretFlag(%q) = %v, want %v", provider, got, expected)
+		}
+	}
+}
+
 func TestMapToProviderEnvironment_UnknownProvider(t *testing.T) {
 	// Unknown provider should return the keyway env as-is
 	got := mapToProviderEnvironment("unknown-provider", "custom-env")
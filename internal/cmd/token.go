@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage machine tokens for CI",
+	Long: `Create machine tokens (API keys) for pipelines and other places where
+nobody can sign in. Set the token as KEYWAY_TOKEN: keyway then uses it
+without prompting.
+
+A token is restricted to the current repository's vault and, by default, can
+only read secrets. It is shown once, when created.
+
+Examples:
+  keyway token create --name ci
+  keyway token create --name deploy --expires 30d --scope read:secrets,write:secrets`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a machine token",
+	Args:  cobra.NoArgs,
+	RunE:  runTokenCreate,
+}
+
+// defaultTokenExpiry is how long a machine token lives unless --expires says otherwise
+const defaultTokenExpiry = "90d"
+
+func init() {
+	tokenCreateCmd.Flags().String("name", "", "Name of the token, e.g. the pipeline using it")
+	tokenCreateCmd.Flags().String("expires", defaultTokenExpiry, "Lifetime, e.g. 30d or 12h, or never")
+	tokenCreateCmd.Flags().StringSlice("scope", []string{api.ScopeReadSecrets}, "Scopes: "+strings.Join(api.APIKeyScopes, ", "))
+	_ = tokenCreateCmd.MarkFlagRequired("name")
+
+	tokenCmd.AddCommand(tokenCreateCmd)
+}
+
+// TokenCreateOptions contains the parsed flags of token create
+type TokenCreateOptions struct {
+	Name    string
+	Expires string
+	Scopes  []string
+}
+
+func runTokenCreate(cmd *cobra.Command, args []string) error {
+	var opts TokenCreateOptions
+	opts.Name, _ = cmd.Flags().GetString("name")
+	opts.Expires, _ = cmd.Flags().GetString("expires")
+	opts.Scopes, _ = cmd.Flags().GetStringSlice("scope")
+	return runTokenCreateWithDeps(opts, time.Now(), defaultDeps)
+}
+
+// runTokenCreateWithDeps is the testable version of runTokenCreate
+func runTokenCreateWithDeps(opts TokenCreateOptions, now time.Time, deps *Dependencies) error {
+	deps.UI.Intro("token create")
+
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		err := fmt.Errorf("--name is required")
+		deps.UI.Error(err.Error())
+		return err
+	}
+	expiresAt, err := parseTokenExpiry(opts.Expires, now)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	scopes, err := parseTokenScopes(opts.Scopes)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	var key *api.APIKey
+	err = deps.UI.Spin("Creating token...", func() error {
+		var createErr error
+		key, createErr = client.CreateAPIKey(context.Background(), api.CreateAPIKeyRequest{
+			Name:         name,
+			Scopes:       scopes,
+			Repositories: []string{repo},
+			ExpiresAt:    expiresAt,
+		})
+		return createErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Created token %s (%s)", deps.UI.Value(key.Name), strings.Join(key.Scopes, ", ")))
+	if key.ExpiresAt != nil {
+		deps.UI.Step(fmt.Sprintf("Expires: %s", key.ExpiresAt.Local().Format("2006-01-02 15:04")))
+	} else {
+		deps.UI.Step("Expires: never")
+	}
+	deps.UI.Message(fmt.Sprintf("Token: %s", key.Token))
+	deps.UI.Message(deps.UI.Dim("It is not shown again: store it as the KEYWAY_TOKEN secret of your pipeline."))
+	return nil
+}
+
+// parseTokenExpiry returns when a token created at now expires: raw is a
+// number of days (90d), a Go duration (12h), or never (nil)
+func parseTokenExpiry(raw string, now time.Time) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "never" {
+		return nil, nil
+	}
+
+	var lifetime time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expires %q (use e.g. 90d, 12h or never)", raw)
+		}
+		lifetime = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expires %q (use e.g. 90d, 12h or never)", raw)
+		}
+		lifetime = d
+	}
+	if lifetime <= 0 {
+		return nil, fmt.Errorf("--expires must be positive, got %q", raw)
+	}
+
+	expiresAt := now.Add(lifetime).UTC()
+	return &expiresAt, nil
+}
+
+// parseTokenScopes checks the --scope values against the known scopes
+func parseTokenScopes(values []string) ([]string, error) {
+	var scopes []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		known := false
+		for _, scope := range api.APIKeyScopes {
+			if value == scope {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q (available: %s)", value, strings.Join(api.APIKeyScopes, ", "))
+		}
+		scopes = append(scopes, value)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one --scope is required")
+	}
+	return scopes, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunTokenCreateWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	opts := TokenCreateOptions{Name: "ci", Expires: "90d", Scopes: []string{api.ScopeReadSecrets}}
+	if err := runTokenCreateWithDeps(opts, now, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := apiMock.APIKeyCreated
+	if req == nil {
+		t.Fatal("expected a key to be created")
+	}
+	if req.Name != "ci" || strings.Join(req.Scopes, ",") != api.ScopeReadSecrets || strings.Join(req.Repositories, ",") != "owner/repo" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.ExpiresAt == nil || !req.ExpiresAt.Equal(now.Add(90*24*time.Hour)) {
+		t.Errorf("expected expiry in 90 days, got %v", req.ExpiresAt)
+	}
+	if !strings.Contains(strings.Join(uiMock.MessageCalls, "\n"), "kw_machine_abc") {
+		t.Errorf("expected the token shown once, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunTokenCreateWithDeps_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TokenCreateOptions
+		want string
+	}{
+		{"no name", TokenCreateOptions{Expires: "90d", Scopes: []string{api.ScopeReadSecrets}}, "--name"},
+		{"bad expiry", TokenCreateOptions{Name: "ci", Expires: "soon", Scopes: []string{api.ScopeReadSecrets}}, "invalid --expires"},
+		{"unknown scope", TokenCreateOptions{Name: "ci", Expires: "90d", Scopes: []string{"admin"}}, "unknown scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _, _, _, apiMock := NewTestDeps()
+			err := runTokenCreateWithDeps(tt.opts, time.Now(), deps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if apiMock.APIKeyCreated != nil {
+				t.Error("no key should be created")
+			}
+		})
+	}
+}
+
+func TestParseTokenExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		raw  string
+		want time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := parseTokenExpiry(tt.raw, now)
+		if err != nil || !got.Equal(now.Add(tt.want)) {
+			t.Errorf("%s: expected %v, got %v, %v", tt.raw, now.Add(tt.want), got, err)
+		}
+	}
+
+	if got, err := parseTokenExpiry("never", now); got != nil || err != nil {
+		t.Errorf("expected no expiry for never, got %v, %v", got, err)
+	}
+	for _, raw := range []string{"0d", "-1h", "d", "1w"} {
+		if _, err := parseTokenExpiry(raw, now); err == nil {
+			t.Errorf("%s: expected an error", raw)
+		}
+	}
+}
+
+func TestHandleAuthError_MachineToken(t *testing.T) {
+	withHostEnv(t, map[string]string{"KEYWAY_TOKEN": "kw_expired"})
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	uiMock.Interactive = true
+	authErr := &api.APIError{StatusCode: 401, Detail: "token expired"}
+
+	token, err := handleAuthError(authErr, deps)
+	if token != "" || !errors.Is(err, authErr) {
+		t.Errorf("expected the original error, got %q, %v", token, err)
+	}
+	if len(uiMock.ConfirmCalls) != 0 {
+		t.Error("a machine token must never prompt to sign in again")
+	}
+	if len(uiMock.ErrorCalls) == 0 || !strings.Contains(uiMock.ErrorCalls[0], "KEYWAY_TOKEN") {
+		t.Errorf("expected an error naming KEYWAY_TOKEN, got %v", uiMock.ErrorCalls)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// parseTransformFlags parses --transform KEY=NAME values
+func parseTransformFlags(flags []string) (map[string]string, error) {
+	transforms := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		key, name, ok := strings.Cut(flag, "=")
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+		if !ok || !isValidKeyName(key) || name == "" {
+			return nil, fmt.Errorf("invalid --transform %q (expected KEY=TRANSFORM)", flag)
+		}
+		if !env.IsTransform(name) {
+			return nil, fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(env.TransformNames(), ", "))
+		}
+		transforms[key] = name
+	}
+	return transforms, nil
+}
+
+// applyTransforms rewrites secrets in place with keyway.toml transforms and the
+// --transform flags, which take precedence. Keys that are not set are skipped
+// with a warning. Values are never printed.
+func applyTransforms(deps *Dependencies, secrets map[string]string, flags []string) error {
+	transforms, err := parseTransformFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	project, err := deps.Config.LoadProject()
+	if err != nil {
+		return err
+	}
+	for key, name := range project.Transforms {
+		if _, set := transforms[key]; !set {
+			transforms[key] = name
+		}
+	}
+	if len(transforms) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(transforms))
+	for key := range transforms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var applied []string
+	for _, key := range keys {
+		value, ok := secrets[key]
+		if !ok {
+			deps.UI.Warn(fmt.Sprintf("Transform %s skipped: %s is not set", transforms[key], key))
+			continue
+		}
+		transformed, err := env.Transform(transforms[key], value)
+		if err != nil {
+			return fmt.Errorf("cannot %s %s: %w", transforms[key], key, err)
+		}
+		secrets[key] = transformed
+		applied = append(applied, fmt.Sprintf("%s (%s)", key, transforms[key]))
+	}
+
+	if len(applied) > 0 {
+		deps.UI.Step(fmt.Sprintf("Transforms: %s", strings.Join(applied, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/keywaysh/cli/internal/config"
+)
+
+func TestParseTransformFlags(t *testing.T) {
+	transforms, err := parseTransformFlags([]string{"TLS_CERT=base64decode", "DB_PASSWORD = urlencode"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transforms["TLS_CERT"] != "base64decode" || transforms["DB_PASSWORD"] != "urlencode" {
+		t.Errorf("unexpected transforms: %v", transforms)
+	}
+
+	for _, bad := range []string{"TLS_CERT", "=base64decode", "TLS_CERT=gzip", "BAD-KEY=urlencode"} {
+		if _, err := parseTransformFlags([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestApplyTransforms_FlagOverridesConfig(t *testing.T) {
+	deps, _, _, uiMock, _, _ := NewTestDeps()
+	deps.Config = &MockConfigLoader{Project: &config.ProjectConfig{
+		Transforms: map[string]string{"CERT": "base64decode", "PASSWORD": "base64decode", "MISSING": "urlencode"},
+	}}
+	secrets := map[string]string{"CERT": "aGVsbG8=", "PASSWORD": "p@ss"}
+
+	err := applyTransforms(deps, secrets, []string{"PASSWORD=urlencode"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secrets["CERT"] != "hello" || secrets["PASSWORD"] != "p%40ss" {
+		t.Errorf("unexpected values: %v", secrets)
+	}
+	if len(uiMock.WarnCalls) != 1 || !strings.Contains(uiMock.WarnCalls[0], "MISSING") {
+		t.Errorf("expected a warning for the missing key, got %v", uiMock.WarnCalls)
+	}
+	for _, step := range uiMock.StepCalls {
+		if strings.Contains(step, "hello") {
+			t.Error("transformed values must not be printed")
+		}
+	}
+}
+
+func TestApplyTransforms_InvalidValue(t *testing.T) {
+	deps, _, _, _, _, _ := NewTestDeps()
+	secrets := map[string]string{"CERT": "***"}
+
+	if err := applyTransforms(deps, secrets, []string{"CERT=base64decode"}); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestRunRunWithDeps_Transform(t *testing.T) {
+	deps, _, _, _, cmdRunner, apiMock := NewTestDepsWithRunner()
+	apiMock.PullResponse = &api.PullSecretsResponse{Content: "CERT=aGVsbG8="}
+
+	opts := RunOptions{EnvName: "development", EnvFlagSet: true, Command: "./server", Transforms: []string{"CERT=base64decode"}}
+	if err := runRunWithDeps(opts, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmdRunner.LastSecrets["CERT"] != "hello" {
+		t.Errorf("expected decoded value, got %q", cmdRunner.LastSecrets["CERT"])
+	}
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keywaysh/cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage the vault's outbound webhooks",
+	Long: `Manage webhooks notified of vault events:
+  push    secrets were pushed to an environment
+  rotate  a secret was rotated
+  pull    secrets were pulled (useful on protected environments)
+
+Payloads never contain secret values. Each one is signed with the webhook's
+secret in the X-Keyway-Signature header; the secret is shown once, when the
+webhook is added.
+
+Examples:
+  keyway webhooks add https://example.com/keyway --events push,rotate
+  keyway webhooks add https://example.com/audit --events pull --env production
+  keyway webhooks list
+  keyway webhooks test wh_123
+  keyway webhooks delete wh_123`,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWebhooksAdd,
+}
+
+var webhooksDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWebhooksDelete,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <id>",
+	Short: "Send a signed sample payload to a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWebhooksTest,
+}
+
+func init() {
+	webhooksListCmd.Flags().Bool("json", false, "Output as JSON")
+	webhooksAddCmd.Flags().StringSlice("events", []string{api.WebhookEventPush}, "Events to send: push, rotate, pull")
+	webhooksAddCmd.Flags().StringSliceP("env", "e", nil, "Only send events of these environments (default: all)")
+
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksDeleteCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+}
+
+// WebhooksAddOptions contains the parsed arguments of webhooks add
+type WebhooksAddOptions struct {
+	URL          string
+	Events       []string
+	Environments []string
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	return runWebhooksListWithDeps(jsonOutput, defaultDeps)
+}
+
+// runWebhooksListWithDeps is the testable version of runWebhooksList
+func runWebhooksListWithDeps(jsonOutput bool, deps *Dependencies) error {
+	if !jsonOutput {
+		deps.UI.Intro("webhooks list")
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	var webhooks []api.Webhook
+	err = deps.UI.Spin("Fetching webhooks...", func() error {
+		var fetchErr error
+		webhooks, fetchErr = client.ListWebhooks(context.Background(), repo)
+		return fetchErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if jsonOutput {
+		if webhooks == nil {
+			webhooks = []api.Webhook{}
+		}
+		output, err := json.MarshalIndent(webhooks, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(webhooks) == 0 {
+		deps.UI.Message("No webhooks yet.")
+		deps.UI.Message(deps.UI.Dim("Add one with: keyway webhooks add <url> --events push"))
+		return nil
+	}
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  ID\tURL\tEVENTS\tENVIRONMENTS")
+	for _, w := range webhooks {
+		envs := "all"
+		if len(w.Environments) > 0 {
+			envs = strings.Join(w.Environments, ",")
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", w.ID, w.URL, strings.Join(w.Events, ","), envs)
+	}
+	_ = tw.Flush()
+	deps.UI.Outro(fmt.Sprintf("%d webhooks", len(webhooks)))
+	return nil
+}
+
+func runWebhooksAdd(cmd *cobra.Command, args []string) error {
+	opts := WebhooksAddOptions{URL: args[0]}
+	opts.Events, _ = cmd.Flags().GetStringSlice("events")
+	opts.Environments, _ = cmd.Flags().GetStringSlice("env")
+	return runWebhooksAddWithDeps(opts, defaultDeps)
+}
+
+// runWebhooksAddWithDeps is the testable version of runWebhooksAdd
+func runWebhooksAddWithDeps(opts WebhooksAddOptions, deps *Dependencies) error {
+	deps.UI.Intro("webhooks add")
+
+	if err := validateWebhookURL(opts.URL); err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	events, err := parseWebhookEvents(opts.Events)
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+	var environments []string
+	for _, name := range opts.Environments {
+		if name = normalizeEnvName(name); name != "" {
+			environments = append(environments, name)
+		}
+	}
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	var webhook *api.Webhook
+	err = deps.UI.Spin("Adding webhook...", func() error {
+		var createErr error
+		webhook, createErr = client.CreateWebhook(context.Background(), repo, api.CreateWebhookRequest{
+			URL:          opts.URL,
+			Events:       events,
+			Environments: environments,
+		})
+		return createErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Added webhook %s", deps.UI.Value(webhook.ID)))
+	if webhook.Secret != "" {
+		deps.UI.Message(fmt.Sprintf("Signing secret: %s", webhook.Secret))
+		deps.UI.Message(deps.UI.Dim("It is not shown again: store it where your receiver verifies X-Keyway-Signature."))
+	}
+	deps.UI.Message(deps.UI.Dim(fmt.Sprintf("Check the receiver with: keyway webhooks test %s", webhook.ID)))
+	return nil
+}
+
+func runWebhooksDelete(cmd *cobra.Command, args []string) error {
+	return runWebhooksDeleteWithDeps(args[0], defaultDeps)
+}
+
+// runWebhooksDeleteWithDeps is the testable version of runWebhooksDelete
+func runWebhooksDeleteWithDeps(id string, deps *Dependencies) error {
+	deps.UI.Intro("webhooks delete")
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	err = deps.UI.Spin("Deleting webhook...", func() error {
+		return client.DeleteWebhook(context.Background(), repo, id)
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	deps.UI.Success(fmt.Sprintf("Deleted webhook %s", id))
+	return nil
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	return runWebhooksTestWithDeps(args[0], defaultDeps)
+}
+
+// runWebhooksTestWithDeps is the testable version of runWebhooksTest
+func runWebhooksTestWithDeps(id string, deps *Dependencies) error {
+	deps.UI.Intro("webhooks test")
+
+	repo, client, err := refsSetup(deps)
+	if err != nil {
+		return err
+	}
+
+	var delivery *api.WebhookDelivery
+	err = deps.UI.Spin("Sending sample payload...", func() error {
+		var sendErr error
+		delivery, sendErr = client.TestWebhook(context.Background(), repo, id)
+		return sendErr
+	})
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	if delivery.Error != "" || delivery.StatusCode < 200 || delivery.StatusCode >= 300 {
+		reason := delivery.Error
+		if reason == "" {
+			reason = fmt.Sprintf("HTTP %d", delivery.StatusCode)
+		}
+		deps.UI.Error(fmt.Sprintf("Delivery failed: %s", reason))
+		return fmt.Errorf("webhook delivery failed: %s", reason)
+	}
+
+	deps.UI.Success(fmt.Sprintf("Delivered: HTTP %d in %dms", delivery.StatusCode, delivery.DurationMs))
+	return nil
+}
+
+// validateWebhookURL accepts https URLs, and http ones on localhost for development
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", raw)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if host := u.Hostname(); host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook URL must use https: %s", raw)
+}
+
+// parseWebhookEvents validates and deduplicates --events
+func parseWebhookEvents(values []string) ([]string, error) {
+	valid := make(map[string]bool, len(api.WebhookEvents))
+	for _, event := range api.WebhookEvents {
+		valid[event] = true
+	}
+
+	seen := make(map[string]bool, len(values))
+	var events []string
+	for _, value := range values {
+		event := strings.ToLower(strings.TrimSpace(value))
+		if event == "" || seen[event] {
+			continue
+		}
+		if !valid[event] {
+			return nil, fmt.Errorf("unknown event %q (use %s)", value, strings.Join(api.WebhookEvents, ", "))
+		}
+		seen[event] = true
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("at least one event is required (%s)", strings.Join(api.WebhookEvents, ", "))
+	}
+	return events, nil
+}
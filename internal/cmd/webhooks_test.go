@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestParseWebhookEvents(t *testing.T) {
+	events, err := parseWebhookEvents([]string{"Push", " rotate", "push", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(events, ",") != "push,rotate" {
+		t.Errorf("unexpected events: %v", events)
+	}
+
+	if _, err := parseWebhookEvents([]string{"delete"}); err == nil || !strings.Contains(err.Error(), `unknown event "delete"`) {
+		t.Errorf("expected an unknown event error, got %v", err)
+	}
+	if _, err := parseWebhookEvents(nil); err == nil {
+		t.Error("expected an error without events")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	for _, valid := range []string{"https://example.com/hook", "http://localhost:8080/hook", "http://127.0.0.1/hook"} {
+		if err := validateWebhookURL(valid); err != nil {
+			t.Errorf("validateWebhookURL(%q) = %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"http://example.com/hook", "example.com", "ftp://example.com", ""} {
+		if err := validateWebhookURL(invalid); err == nil {
+			t.Errorf("validateWebhookURL(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestRunWebhooksAddWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+
+	opts := WebhooksAddOptions{URL: "https://example.com/hook", Events: []string{"push", "pull"}, Environments: []string{"prod"}}
+	if err := runWebhooksAddWithDeps(opts, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := apiMock.WebhookCreated
+	if req == nil || req.URL != opts.URL || strings.Join(req.Events, ",") != "push,pull" || strings.Join(req.Environments, ",") != "production" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	found := false
+	for _, m := range uiMock.MessageCalls {
+		found = found || strings.Contains(m, "whsec_abc")
+	}
+	if !found {
+		t.Errorf("expected the signing secret to be shown, got %v", uiMock.MessageCalls)
+	}
+}
+
+func TestRunWebhooksAddWithDeps_InvalidURL(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+
+	opts := WebhooksAddOptions{URL: "http://example.com/hook", Events: []string{"push"}}
+	if err := runWebhooksAddWithDeps(opts, deps); err == nil {
+		t.Fatal("expected an error for a plain http URL")
+	}
+	if apiMock.WebhookCreated != nil {
+		t.Error("nothing should be created")
+	}
+}
+
+func TestRunWebhooksListWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.Webhooks = []api.Webhook{
+		{ID: "wh_1", URL: "https://example.com/a", Events: []string{"push"}},
+		{ID: "wh_2", URL: "https://example.com/b", Events: []string{"pull"}, Environments: []string{"production"}},
+	}
+
+	if err := runWebhooksListWithDeps(false, deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(uiMock.OutroCalls) != 1 || uiMock.OutroCalls[0] != "2 webhooks" {
+		t.Errorf("unexpected outro: %v", uiMock.OutroCalls)
+	}
+}
+
+func TestRunWebhooksDeleteWithDeps(t *testing.T) {
+	deps, _, _, _, _, apiMock := NewTestDeps()
+
+	if err := runWebhooksDeleteWithDeps("wh_1", deps); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(apiMock.WebhooksDeleted) != 1 || apiMock.WebhooksDeleted[0] != "wh_1" {
+		t.Errorf("unexpected deletions: %v", apiMock.WebhooksDeleted)
+	}
+}
+
+func TestRunWebhooksTestWithDeps(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *api.WebhookDelivery
+		err      error
+		wantErr  bool
+	}{
+		{"delivered", &api.WebhookDelivery{StatusCode: 204, DurationMs: 35}, nil, false},
+		{"receiver error", &api.WebhookDelivery{StatusCode: 500}, nil, true},
+		{"unreachable", &api.WebhookDelivery{Error: "connection refused"}, nil, true},
+		{"api error", nil, errors.New("webhook not found"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _, _, _, apiMock := NewTestDeps()
+			apiMock.WebhookDelivery = tt.delivery
+			apiMock.WebhooksError = tt.err
+
+			err := runWebhooksTestWithDeps("wh_1", deps)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
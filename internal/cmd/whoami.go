@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the account you are logged in with",
+	Long: `Show the GitHub account and plan of the current session. It never starts a
+login: without a session it fails, so scripts can check for one.
+
+Examples:
+  keyway whoami
+  keyway whoami --output json`,
+	Args: cobra.NoArgs,
+	RunE: runWhoami,
+}
+
+// runWhoami is the entry point for the whoami command (uses default dependencies)
+func runWhoami(cmd *cobra.Command, args []string) error {
+	return runWhoamiWithDeps(outputFlag == outputJSON, os.Stdout, defaultDeps)
+}
+
+// runWhoamiWithDeps is the testable version of runWhoami
+func runWhoamiWithDeps(jsonOutput bool, w io.Writer, deps *Dependencies) error {
+	deps.UI.Intro("whoami")
+
+	if !deps.Auth.HasSession() {
+		deps.UI.Error("Not logged in")
+		deps.UI.Message(deps.UI.Dim("Log in with: keyway login"))
+		return fmt.Errorf("not logged in")
+	}
+	token, err := deps.Auth.EnsureLogin()
+	if err != nil {
+		deps.UI.Error(err.Error())
+		return err
+	}
+
+	validation, err := deps.APIFactory.NewClient(token).ValidateToken(context.Background())
+	if err != nil {
+		deps.UI.Error(fmt.Sprintf("Session expired or invalid: %s", err.Error()))
+		return err
+	}
+
+	if jsonOutput {
+		output, err := json.MarshalIndent(validation, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(output))
+		return nil
+	}
+
+	username := validation.Username
+	if username == "" {
+		username = validation.Login
+	}
+	deps.UI.Success(fmt.Sprintf("Logged in as %s", deps.UI.Value(username)))
+	if validation.Plan != "" {
+		deps.UI.Step(fmt.Sprintf("Plan: %s", deps.UI.Value(validation.Plan)))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/api"
+)
+
+func TestRunWhoamiWithDeps(t *testing.T) {
+	deps, _, _, uiMock, _, apiMock := NewTestDeps()
+	apiMock.ValidateTokenResponse = &api.ValidateTokenResponse{Login: "octocat", Username: "octocat", Plan: "pro"}
+
+	if err := runWhoamiWithDeps(false, &bytes.Buffer{}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uiMock.SuccessCalls) != 1 || len(uiMock.StepCalls) != 1 {
+		t.Errorf("expected the login and plan shown, got %v %v", uiMock.SuccessCalls, uiMock.StepCalls)
+	}
+
+	var out bytes.Buffer
+	if err := runWhoamiWithDeps(true, &out, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"plan": "pro"`) {
+		t.Errorf("expected JSON output, got %q", out.String())
+	}
+}
+
+func TestRunWhoamiWithDeps_NoSession(t *testing.T) {
+	deps, _, authMock, _, _, _ := NewTestDeps()
+	authMock.NoSession = true
+
+	if err := runWhoamiWithDeps(false, &bytes.Buffer{}, deps); err == nil {
+		t.Fatal("expected an error without a session")
+	}
+}
@@ -56,6 +56,12 @@ func IsTelemetryDisabled() bool {
 	return val == "1" || val == "true"
 }
 
+// IsHTTP3Enabled returns true if API requests should try HTTP/3 first
+func IsHTTP3Enabled() bool {
+	val := os.Getenv("KEYWAY_HTTP3")
+	return val == "1" || val == "true"
+}
+
 // IsCI returns true if running in CI environment
 func IsCI() bool {
 	ci := os.Getenv("CI")
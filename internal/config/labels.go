@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// LabelViolation is a key that carries a label its environment denies
+type LabelViolation struct {
+	Key   string
+	Label string
+}
+
+func (v LabelViolation) String() string {
+	return fmt.Sprintf("%s (%s)", v.Key, v.Label)
+}
+
+// LabelsFor returns the sorted labels whose patterns match key
+func (c *ProjectConfig) LabelsFor(key string) []string {
+	if c == nil {
+		return nil
+	}
+	var labels []string
+	for label, patterns := range c.Labels {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, key); ok {
+				labels = append(labels, label)
+				break
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// LabelViolations returns the keys that envName may not receive, sorted by key.
+// Returns nil if the environment denies no labels.
+func (c *ProjectConfig) LabelViolations(envName string, keys []string) []LabelViolation {
+	if c == nil {
+		return nil
+	}
+	envCfg, ok := c.Environments[strings.ToLower(envName)]
+	if !ok || len(envCfg.DenyLabels) == 0 {
+		return nil
+	}
+
+	denied := make(map[string]bool, len(envCfg.DenyLabels))
+	for _, label := range envCfg.DenyLabels {
+		denied[label] = true
+	}
+
+	var violations []LabelViolation
+	for _, key := range keys {
+		for _, label := range c.LabelsFor(key) {
+			if denied[label] {
+				violations = append(violations, LabelViolation{Key: key, Label: label})
+				break
+			}
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+	return violations
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelsFor(t *testing.T) {
+	cfg := &ProjectConfig{Labels: map[string][]string{
+		"payment":  {"STRIPE_*", "ADYEN_API_KEY"},
+		"pii":      {"*_EMAIL", "STRIPE_CUSTOMER_EMAIL"},
+		"internal": {"ADMIN_*"},
+	}}
+
+	if got := strings.Join(cfg.LabelsFor("STRIPE_CUSTOMER_EMAIL"), ","); got != "payment,pii" {
+		t.Errorf("unexpected labels: %s", got)
+	}
+	if got := cfg.LabelsFor("PORT"); len(got) != 0 {
+		t.Errorf("expected no labels, got %v", got)
+	}
+
+	var nilCfg *ProjectConfig
+	if nilCfg.LabelsFor("STRIPE_KEY") != nil {
+		t.Error("expected nil labels for nil config")
+	}
+}
+
+func TestLabelViolations(t *testing.T) {
+	cfg := &ProjectConfig{
+		Labels: map[string][]string{"payment": {"STRIPE_*"}},
+		Environments: map[string]EnvironmentConfig{
+			"development": {DenyLabels: []string{"payment"}},
+		},
+	}
+
+	violations := cfg.LabelViolations("Development", []string{"STRIPE_WEBHOOK", "PORT", "STRIPE_KEY"})
+	if len(violations) != 2 || violations[0].Key != "STRIPE_KEY" || violations[1].Key != "STRIPE_WEBHOOK" {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+	if violations[0].String() != "STRIPE_KEY (payment)" {
+		t.Errorf("unexpected String(): %s", violations[0].String())
+	}
+
+	if v := cfg.LabelViolations("production", []string{"STRIPE_KEY"}); v != nil {
+		t.Errorf("expected no violations in production, got %v", v)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+)
+
+// Notification target types
+const (
+	NotifySlack = "slack"
+	NotifyHTTP  = "http"
+)
+
+// NotifyEvents are the commands that send notifications after changing the vault
+var NotifyEvents = []string{"push", "set", "unset"}
+
+// NotifyConfig is a notification target, sent a message after a command
+// changed the vault. Messages and payloads name keys, never values.
+type NotifyConfig struct {
+	// Type is "slack" (incoming webhook) or "http" (JSON POST of the event)
+	Type string `toml:"type"`
+	// URL receives the notification; $VAR and ${VAR} are expanded from the
+	// environment, so webhook URLs need not be committed
+	URL string `toml:"url"`
+	// Events limits the notification to these commands (default: all)
+	Events []string `toml:"events"`
+	// Environments limits the notification to these environments (default: all)
+	Environments []string `toml:"environments"`
+	// Message is a text/template for the message, e.g. "{{.Actor}} updated {{.Environment}}"
+	Message string `toml:"message"`
+}
+
+// Wants reports whether the target is notified of event in envName
+func (n NotifyConfig) Wants(event, envName string) bool {
+	return matchesAny(n.Events, event) && matchesAny(n.Environments, envName)
+}
+
+// matchesAny reports whether value is in list, ignoring case; an empty list matches everything
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotifyEvent(event string) bool {
+	for _, e := range NotifyEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestNotifyConfigWants(t *testing.T) {
+	target := NotifyConfig{Events: []string{"push"}, Environments: []string{"Production"}}
+	tests := []struct {
+		event, env string
+		want       bool
+	}{
+		{"push", "production", true},
+		{"set", "production", false},
+		{"push", "staging", false},
+	}
+	for _, tt := range tests {
+		if got := target.Wants(tt.event, tt.env); got != tt.want {
+			t.Errorf("Wants(%q, %q) = %v, want %v", tt.event, tt.env, got, tt.want)
+		}
+	}
+
+	if !(NotifyConfig{}).Wants("unset", "development") {
+		t.Error("a target without filters should be notified of everything")
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// EnvOfflineTTL enables the offline cache, e.g. KEYWAY_OFFLINE_TTL=72h
+const EnvOfflineTTL = "KEYWAY_OFFLINE_TTL"
+
+// DefaultOfflineTTL is how old cached secrets --offline reads when the cache
+// is not enabled: nothing new is cached then, but earlier entries stay usable
+const DefaultOfflineTTL = 24 * time.Hour
+
+// OfflineTTL returns how long a pulled environment can be served from the
+// offline cache; 0, the default, disables the cache, so secrets are never
+// written to disk. KEYWAY_OFFLINE_TTL takes precedence over offline.ttl in
+// keyway.toml. An invalid value disables the cache and returns an error.
+func OfflineTTL() (time.Duration, error) {
+	name := EnvOfflineTTL
+	raw := strings.TrimSpace(os.Getenv(EnvOfflineTTL))
+	if raw == "" {
+		if project, err := LoadProject("."); err == nil {
+			name = "offline.ttl"
+			raw = strings.TrimSpace(project.Offline.TTL)
+		}
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := parseTimeout(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s=%s (use a duration like 72h)", name, raw)
+	}
+	return d, nil
+}
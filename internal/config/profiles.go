@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path"
+	"sort"
+)
+
+// ProfileConfig selects the keys one kind of process needs from an
+// environment, e.g. a worker that only uses the database and the queue
+type ProfileConfig struct {
+	// Keys lists key name glob patterns to inject; empty means every key
+	Keys []string `toml:"keys"`
+	// Overrides sets values over the vault's for this profile
+	Overrides map[string]string `toml:"overrides"`
+}
+
+// Profile returns the named profile
+func (c *ProjectConfig) Profile(name string) (ProfileConfig, bool) {
+	if c == nil {
+		return ProfileConfig{}, false
+	}
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// ProfileNames returns the sorted names of the defined profiles
+func (c *ProjectConfig) ProfileNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select removes the keys the profile does not match from secrets, in place.
+// It returns the patterns that matched no key, which are likely typos.
+func (p ProfileConfig) Select(secrets map[string]string) (unmatched []string) {
+	if len(p.Keys) == 0 {
+		return nil
+	}
+
+	matched := make(map[string]bool, len(p.Keys))
+	for key := range secrets {
+		keep := false
+		for _, pattern := range p.Keys {
+			if ok, _ := path.Match(pattern, key); ok {
+				matched[pattern] = true
+				keep = true
+			}
+		}
+		if !keep {
+			delete(secrets, key)
+		}
+	}
+
+	for _, pattern := range p.Keys {
+		if !matched[pattern] {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	return unmatched
+}
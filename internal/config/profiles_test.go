@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfileSelect(t *testing.T) {
+	profile := ProfileConfig{Keys: []string{"DATABASE_URL", "QUEUE_*", "SENTRY_DSN"}}
+	secrets := map[string]string{
+		"DATABASE_URL":   "postgres://",
+		"QUEUE_URL":      "redis://",
+		"QUEUE_NAME":     "jobs",
+		"SESSION_SECRET": "s3cret",
+	}
+
+	unmatched := profile.Select(secrets)
+
+	want := map[string]string{"DATABASE_URL": "postgres://", "QUEUE_URL": "redis://", "QUEUE_NAME": "jobs"}
+	if !reflect.DeepEqual(secrets, want) {
+		t.Errorf("unexpected selection: %v", secrets)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"SENTRY_DSN"}) {
+		t.Errorf("expected SENTRY_DSN to be reported, got %v", unmatched)
+	}
+}
+
+func TestProfileSelect_AllKeys(t *testing.T) {
+	secrets := map[string]string{"A": "1", "B": "2"}
+	if unmatched := (ProfileConfig{}).Select(secrets); unmatched != nil || len(secrets) != 2 {
+		t.Errorf("expected every key to be kept, got %v (unmatched %v)", secrets, unmatched)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	cfg := &ProjectConfig{Profiles: map[string]ProfileConfig{
+		"worker": {Keys: []string{"QUEUE_*"}},
+		"web":    {Overrides: map[string]string{"PORT": "3000"}},
+	}}
+
+	if _, ok := cfg.Profile("worker"); !ok {
+		t.Error("expected the worker profile")
+	}
+	if _, ok := cfg.Profile("cron"); ok {
+		t.Error("expected no cron profile")
+	}
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"web", "worker"}) {
+		t.Errorf("unexpected names: %v", got)
+	}
+
+	var nilCfg *ProjectConfig
+	if _, ok := nilCfg.Profile("worker"); ok {
+		t.Error("expected no profile for nil config")
+	}
+}
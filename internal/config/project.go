@@ -0,0 +1,289 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectConfigFile is the name of the per-project config file
+const ProjectConfigFile = "keyway.toml"
+
+// DefaultEnvironmentCommands are the commands whose environment default
+// keyway.toml can set
+var DefaultEnvironmentCommands = []string{"run", "pull", "set", "show", "secrets copy-value", "secrets get", "secrets unset", "secrets list", "secrets import-json", "secrets import-yaml", "exec-init", "dev"}
+
+// DefaultFlagPrefix starts the keys that hold feature flags (keyway flags)
+const DefaultFlagPrefix = "FLAG_"
+
+// DefaultFallbackEnvironments are offered when the vault's environments cannot be listed
+var DefaultFallbackEnvironments = []string{"development", "staging", "production"}
+
+// Branch policy enforcement modes
+const (
+	EnforceBlock = "block"
+	EnforceWarn  = "warn"
+)
+
+// Values of network.background
+const (
+	BackgroundOn  = "on"
+	BackgroundOff = "off"
+)
+
+// ProjectConfig is the content of keyway.toml
+type ProjectConfig struct {
+	// Path is the file the config was loaded from (empty if none was found)
+	Path string `toml:"-"`
+
+	// Repository is the vault's repository (owner/repo), set by keyway link when
+	// the git remote no longer matches it, e.g. after a rename or transfer
+	Repository string `toml:"repository"`
+
+	// FallbackEnvironments are offered when the vault's environments cannot be listed
+	FallbackEnvironments []string `toml:"fallback_environments"`
+
+	// FlagPrefix starts the keys that keyway flags manages as feature flags
+	FlagPrefix string `toml:"flag_prefix"`
+
+	// DefaultEnvironments maps a command (e.g. "run", "exec-init") to the
+	// environment it uses when --env is not given
+	DefaultEnvironments map[string]string `toml:"default_environments"`
+
+	// Transforms maps a key to a transform applied by keyway run (e.g. "base64decode")
+	Transforms map[string]string `toml:"transforms"`
+
+	// Labels maps a classification label (e.g. "payment") to key name patterns
+	Labels map[string][]string `toml:"labels"`
+
+	// Aliases maps a shortcut to keyway arguments, e.g. web = "run -e development -- npm run dev"
+	Aliases map[string]string `toml:"alias"`
+
+	// Profiles select a subset of an environment's keys, plus overrides, for
+	// one kind of process (keyway run --profile worker)
+	Profiles map[string]ProfileConfig `toml:"profiles"`
+
+	// Keys documents individual keys and declares the schema every
+	// environment must satisfy
+	Keys map[string]KeyConfig `toml:"keys"`
+
+	Environments map[string]EnvironmentConfig `toml:"environments"`
+
+	Network NetworkConfig `toml:"network"`
+
+	Offline OfflineConfig `toml:"offline"`
+
+	Dev DevConfig `toml:"dev"`
+
+	// Notify lists the targets told about changes made by push, set and unset
+	Notify []NotifyConfig `toml:"notify"`
+}
+
+// DevConfig configures keyway dev
+type DevConfig struct {
+	// Command starts the project, e.g. "docker compose up" (detected when empty)
+	Command string `toml:"command"`
+}
+
+// NetworkConfig controls the requests keyway makes besides API calls
+type NetworkConfig struct {
+	// Background is "off" to disable the update check and telemetry (default "on")
+	Background string `toml:"background"`
+}
+
+// OfflineConfig configures the offline cache
+type OfflineConfig struct {
+	// TTL is how long pulled secrets stay usable offline, e.g. "72h" (default: not cached)
+	TTL string `toml:"ttl"`
+}
+
+// KeyConfig documents one key and its place in the schema
+type KeyConfig struct {
+	Description string `toml:"description"`
+	// Owner is who to ask about the key, e.g. "@acme/payments"
+	Owner string `toml:"owner"`
+	// Type is one of KeyTypes; values of another form are rejected
+	Type string `toml:"type"`
+	// Required keys must be set in every environment not listing them in optional_keys
+	Required bool `toml:"required"`
+}
+
+// EnvironmentConfig holds per-environment settings
+type EnvironmentConfig struct {
+	// Branches lists the git branches (glob patterns) allowed to use this environment
+	Branches []string `toml:"branches"`
+	// Enforce is "block" (default) or "warn"
+	Enforce string `toml:"enforce"`
+	// DenyLabels lists the labels whose keys must never be injected into this environment
+	DenyLabels []string `toml:"deny_labels"`
+	// RequiredKeys must be set in this environment, on top of the keys marked required
+	RequiredKeys []string `toml:"required_keys"`
+	// OptionalKeys are exempt here from the keys marked required
+	OptionalKeys []string `toml:"optional_keys"`
+}
+
+// BranchPolicy is the branch restriction for one environment
+type BranchPolicy struct {
+	Environment string
+	Branches    []string
+	Warn        bool
+}
+
+// Allows returns true if the branch matches one of the allowed patterns
+func (p *BranchPolicy) Allows(branch string) bool {
+	for _, pattern := range p.Branches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns a human readable rule, e.g. "production requires branch main"
+func (p *BranchPolicy) Describe() string {
+	noun := "branch"
+	if len(p.Branches) > 1 {
+		noun = "one of branches"
+	}
+	return fmt.Sprintf("%s requires %s %s", p.Environment, noun, strings.Join(p.Branches, ", "))
+}
+
+// BranchPolicy returns the branch restriction for an environment, or nil if there is none
+func (c *ProjectConfig) BranchPolicy(envName string) *BranchPolicy {
+	if c == nil {
+		return nil
+	}
+	envCfg, ok := c.Environments[strings.ToLower(envName)]
+	if !ok || len(envCfg.Branches) == 0 {
+		return nil
+	}
+	return &BranchPolicy{
+		Environment: envName,
+		Branches:    envCfg.Branches,
+		Warn:        envCfg.Enforce == EnforceWarn,
+	}
+}
+
+// EnvironmentFallback returns the configured fallback environments, or the defaults
+func (c *ProjectConfig) EnvironmentFallback() []string {
+	if c == nil || len(c.FallbackEnvironments) == 0 {
+		return append([]string(nil), DefaultFallbackEnvironments...)
+	}
+	return append([]string(nil), c.FallbackEnvironments...)
+}
+
+// FlagKeyPrefix returns the prefix of feature flag keys
+func (c *ProjectConfig) FlagKeyPrefix() string {
+	if c == nil || c.FlagPrefix == "" {
+		return DefaultFlagPrefix
+	}
+	return c.FlagPrefix
+}
+
+// KeyDoc returns the documentation of key, empty if it has none
+func (c *ProjectConfig) KeyDoc(key string) KeyConfig {
+	if c == nil {
+		return KeyConfig{}
+	}
+	return c.Keys[key]
+}
+
+// DefaultEnvironment returns the environment configured for command, or "" if none is
+func (c *ProjectConfig) DefaultEnvironment(command string) string {
+	if c == nil {
+		return ""
+	}
+	return c.DefaultEnvironments[command]
+}
+
+func isDefaultEnvironmentCommand(command string) bool {
+	for _, c := range DefaultEnvironmentCommands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadProject finds keyway.toml in dir or a parent directory (stopping at the
+// git root) and parses it. Returns an empty config if no file exists.
+func LoadProject(dir string) (*ProjectConfig, error) {
+	file := FindProjectFile(dir)
+	if file == "" {
+		return &ProjectConfig{}, nil
+	}
+	return ParseProjectFile(file)
+}
+
+// FindProjectFile returns the path of the nearest keyway.toml, or "" if none exists
+func FindProjectFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		candidate := filepath.Join(dir, ProjectConfigFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+
+		// Don't look above the repository root
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SetRepository returns keyway.toml content with the top-level repository key
+// set to repo, keeping the rest of the file (comments, tables) as is
+func SetRepository(content []byte, repo string) []byte {
+	line := fmt.Sprintf("repository = %q", repo)
+	lines := strings.Split(string(content), "\n")
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			break // top-level keys must come before the first table
+		}
+		if key, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(key) == "repository" {
+			lines[i] = line
+			return []byte(strings.Join(lines, "\n"))
+		}
+	}
+	if len(content) == 0 {
+		return []byte(line + "\n")
+	}
+	return []byte(line + "\n" + string(content))
+}
+
+// ParseProjectFile parses a keyway.toml file
+func ParseProjectFile(file string) (*ProjectConfig, error) {
+	cfg := &ProjectConfig{}
+	if _, err := toml.DecodeFile(file, cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	cfg.Path = file
+
+	normalized := make(map[string]EnvironmentConfig, len(cfg.Environments))
+	for name, envCfg := range cfg.Environments {
+		switch envCfg.Enforce {
+		case "", EnforceBlock, EnforceWarn:
+		default:
+			return nil, fmt.Errorf("invalid %s: environments.%s.enforce must be %q or %q", file, name, EnforceBlock, EnforceWarn)
+		}
+		normalized[strings.ToLower(name)] = envCfg
+	}
+	cfg.Environments = normalized
+
+	return cfg, nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func writeProjectFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, ProjectConfigFile)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadProject_NoFile(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+
+	cfg, err := LoadProject(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg == nil || cfg.Path != "" {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+	if cfg.BranchPolicy("production") != nil {
+		t.Error("expected no branch policy")
+	}
+}
+
+func TestLoadProject_FromParentDirectory(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	file := writeProjectFile(t, root, `
+[environments.production]
+branches = ["main", "release/*"]
+`)
+	sub := filepath.Join(root, "apps", "web")
+	os.MkdirAll(sub, 0755)
+
+	cfg, err := LoadProject(sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Path != file {
+		t.Errorf("expected path %s, got %s", file, cfg.Path)
+	}
+
+	policy := cfg.BranchPolicy("production")
+	if policy == nil {
+		t.Fatal("expected a branch policy for production")
+	}
+	if policy.Warn {
+		t.Error("enforce should default to block")
+	}
+	if !policy.Allows("main") || !policy.Allows("release/1.2") {
+		t.Error("expected main and release/1.2 to be allowed")
+	}
+	if policy.Allows("feature/x") {
+		t.Error("feature/x should not be allowed")
+	}
+}
+
+func TestLoadProject_StopsAtGitRoot(t *testing.T) {
+	outer := t.TempDir()
+	writeProjectFile(t, outer, `[environments.production]
+branches = ["main"]`)
+	repo := filepath.Join(outer, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+
+	cfg, err := LoadProject(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Path != "" {
+		t.Errorf("config above the git root should be ignored, got %s", cfg.Path)
+	}
+}
+
+func TestParseProjectFile_WarnMode(t *testing.T) {
+	file := writeProjectFile(t, t.TempDir(), `
+[environments.Staging]
+branches = ["develop"]
+enforce = "warn"
+`)
+
+	cfg, err := ParseProjectFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := cfg.BranchPolicy("staging")
+	if policy == nil || !policy.Warn {
+		t.Errorf("expected warn policy for staging, got %+v", policy)
+	}
+}
+
+func TestParseProjectFile_InvalidEnforce(t *testing.T) {
+	file := writeProjectFile(t, t.TempDir(), `
+[environments.production]
+branches = ["main"]
+enforce = "deny"
+`)
+
+	_, err := ParseProjectFile(file)
+	if err == nil || !strings.Contains(err.Error(), "enforce") {
+		t.Errorf("expected enforce error, got %v", err)
+	}
+}
+
+func TestParseProjectFile_InvalidTOML(t *testing.T) {
+	file := writeProjectFile(t, t.TempDir(), `[environments.production`)
+
+	if _, err := ParseProjectFile(file); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestBranchPolicy_Describe(t *testing.T) {
+	p := &BranchPolicy{Environment: "production", Branches: []string{"main"}}
+	if got := p.Describe(); got != "production requires branch main" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestEnvironmentFallback(t *testing.T) {
+	var nilCfg *ProjectConfig
+	if got := strings.Join(nilCfg.EnvironmentFallback(), ","); got != "development,staging,production" {
+		t.Errorf("unexpected default fallback: %s", got)
+	}
+
+	dir := t.TempDir()
+	file := writeProjectFile(t, dir, `fallback_environments = ["local", "prod"]`)
+	cfg, err := ParseProjectFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(cfg.EnvironmentFallback(), ","); got != "local,prod" {
+		t.Errorf("unexpected configured fallback: %s", got)
+	}
+}
+
+func TestDefaultEnvironment(t *testing.T) {
+	var nilCfg *ProjectConfig
+	if got := nilCfg.DefaultEnvironment("run"); got != "" {
+		t.Errorf("expected no default without a config, got %q", got)
+	}
+
+	dir := t.TempDir()
+	file := writeProjectFile(t, dir, "[default_environments]\nrun = \"local\"\n\"exec-init\" = \"production\"\n")
+	cfg, err := ParseProjectFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DefaultEnvironment("run") != "local" || cfg.DefaultEnvironment("exec-init") != "production" || cfg.DefaultEnvironment("pull") != "" {
+		t.Errorf("unexpected defaults: %v", cfg.DefaultEnvironments)
+	}
+}
+
+func TestSetRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", "repository = \"neworg/app\"\n"},
+		{"prepended", "# Project settings\n[labels]\npii = [\"*_EMAIL\"]\n", "repository = \"neworg/app\"\n# Project settings\n[labels]\npii = [\"*_EMAIL\"]\n"},
+		{"replaced", "repository = \"old/app\"\n[alias]\ndev = \"run\"\n", "repository = \"neworg/app\"\n[alias]\ndev = \"run\"\n"},
+		{"table key kept", "[alias]\nrepository = \"run\"\n", "repository = \"neworg/app\"\n[alias]\nrepository = \"run\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(SetRepository([]byte(tt.content), "neworg/app"))
+			if got != tt.want {
+				t.Errorf("SetRepository() =\n%q\nwant\n%q", got, tt.want)
+			}
+			cfg := &ProjectConfig{}
+			if _, err := toml.Decode(got, cfg); err != nil || cfg.Repository != "neworg/app" {
+				t.Errorf("expected valid TOML with the repository, got %+v, %v", cfg, err)
+			}
+		})
+	}
+}
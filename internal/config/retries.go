@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvRetries overrides how many times idempotent API requests are retried, e.g. KEYWAY_RETRIES=5
+const EnvRetries = "KEYWAY_RETRIES"
+
+// DefaultRetries is how many times idempotent API requests are retried
+const DefaultRetries = 2
+
+// Retries returns how many times an idempotent API request is retried after
+// a network failure, a 429 or a 5xx; 0 disables retries. An invalid value
+// returns the default and an error.
+func Retries() (int, error) {
+	raw := strings.TrimSpace(os.Getenv(EnvRetries))
+	if raw == "" {
+		return DefaultRetries, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return DefaultRetries, fmt.Errorf("invalid %s=%s (use a number like 3, or 0 to disable)", EnvRetries, raw)
+	}
+	return n, nil
+}
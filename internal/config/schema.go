@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyTypes are the value types a key can declare in keyway.toml
+var KeyTypes = []string{"string", "int", "number", "bool", "url", "port", "json", "duration"}
+
+// SchemaViolation is a key that is missing or whose value doesn't match its type
+type SchemaViolation struct {
+	Key     string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	return fmt.Sprintf("%s %s", v.Key, v.Message)
+}
+
+// HasSchema returns true if any key is required or typed
+func (c *ProjectConfig) HasSchema() bool {
+	if c == nil {
+		return false
+	}
+	for _, kc := range c.Keys {
+		if kc.Required || kc.Type != "" {
+			return true
+		}
+	}
+	for _, envCfg := range c.Environments {
+		if len(envCfg.RequiredKeys) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredKeys returns the sorted keys envName must define: the keys marked
+// required, less the environment's optional_keys, plus its required_keys
+func (c *ProjectConfig) RequiredKeys(envName string) []string {
+	if c == nil {
+		return nil
+	}
+	envCfg := c.Environments[strings.ToLower(envName)]
+	optional := make(map[string]bool, len(envCfg.OptionalKeys))
+	for _, key := range envCfg.OptionalKeys {
+		optional[key] = true
+	}
+
+	required := make(map[string]bool)
+	for key, kc := range c.Keys {
+		if kc.Required && !optional[key] {
+			required[key] = true
+		}
+	}
+	for _, key := range envCfg.RequiredKeys {
+		required[key] = true
+	}
+
+	keys := make([]string, 0, len(required))
+	for key := range required {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SchemaViolations checks an environment's secrets against the schema: required
+// keys must be set and non-empty, and typed values must parse. isRef reports
+// values resolved elsewhere (shared references), whose type is not checked.
+// Violations are sorted by key.
+func (c *ProjectConfig) SchemaViolations(envName string, secrets map[string]string, isRef func(string) bool) []SchemaViolation {
+	if c == nil {
+		return nil
+	}
+	var violations []SchemaViolation
+	for _, key := range c.RequiredKeys(envName) {
+		value, ok := secrets[key]
+		if !ok {
+			violations = append(violations, SchemaViolation{Key: key, Message: "is required but missing"})
+		} else if strings.TrimSpace(value) == "" {
+			violations = append(violations, SchemaViolation{Key: key, Message: "is required but empty"})
+		}
+	}
+	for key, kc := range c.Keys {
+		value, ok := secrets[key]
+		if !ok || value == "" || kc.Type == "" || (isRef != nil && isRef(value)) {
+			continue
+		}
+		if err := checkKeyType(kc.Type, value); err != nil {
+			violations = append(violations, SchemaViolation{Key: key, Message: err.Error()})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+	return violations
+}
+
+// checkKeyType returns an error naming the type when value doesn't parse as it.
+// The error never contains the value.
+func checkKeyType(keyType, value string) error {
+	var ok bool
+	switch keyType {
+	case "string":
+		ok = true
+	case "int":
+		_, err := strconv.ParseInt(value, 10, 64)
+		ok = err == nil
+	case "number":
+		_, err := strconv.ParseFloat(value, 64)
+		ok = err == nil
+	case "bool":
+		switch strings.ToLower(value) {
+		case "true", "false", "1", "0", "yes", "no", "on", "off":
+			ok = true
+		}
+	case "url":
+		u, err := url.Parse(value)
+		ok = err == nil && u.Scheme != "" && u.Host != ""
+	case "port":
+		port, err := strconv.Atoi(value)
+		ok = err == nil && port > 0 && port < 65536
+	case "json":
+		ok = json.Valid([]byte(value))
+	case "duration":
+		_, err := time.ParseDuration(value)
+		ok = err == nil
+	default:
+		return fmt.Errorf("has unknown type %q", keyType)
+	}
+	if !ok {
+		return fmt.Errorf("is not a valid %s", keyType)
+	}
+	return nil
+}
+
+func isKeyType(name string) bool {
+	for _, t := range KeyTypes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
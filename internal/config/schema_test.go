@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func schemaConfig() *ProjectConfig {
+	return &ProjectConfig{
+		Keys: map[string]KeyConfig{
+			"DATABASE_URL": {Type: "url", Required: true},
+			"PORT":         {Type: "port"},
+			"SENTRY_DSN":   {Required: true},
+		},
+		Environments: map[string]EnvironmentConfig{
+			"development": {OptionalKeys: []string{"SENTRY_DSN"}},
+			"production":  {RequiredKeys: []string{"STRIPE_KEY"}},
+		},
+	}
+}
+
+func TestRequiredKeys(t *testing.T) {
+	cfg := schemaConfig()
+
+	if got := strings.Join(cfg.RequiredKeys("production"), ","); got != "DATABASE_URL,SENTRY_DSN,STRIPE_KEY" {
+		t.Errorf("unexpected production keys: %s", got)
+	}
+	if got := strings.Join(cfg.RequiredKeys("Development"), ","); got != "DATABASE_URL" {
+		t.Errorf("unexpected development keys: %s", got)
+	}
+	if got := strings.Join(cfg.RequiredKeys("staging"), ","); got != "DATABASE_URL,SENTRY_DSN" {
+		t.Errorf("unexpected staging keys: %s", got)
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	cfg := schemaConfig()
+	secrets := map[string]string{
+		"DATABASE_URL": "not a url",
+		"PORT":         "99999",
+		"SENTRY_DSN":   " ",
+	}
+
+	violations := cfg.SchemaViolations("production", secrets, nil)
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"DATABASE_URL is not a valid url",
+		"PORT is not a valid port",
+		"SENTRY_DSN is required but empty",
+		"STRIPE_KEY is required but missing",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestSchemaViolations_SkipsReferences(t *testing.T) {
+	cfg := schemaConfig()
+	secrets := map[string]string{"DATABASE_URL": "${shared:db}"}
+	isRef := func(value string) bool { return strings.HasPrefix(value, "${shared:") }
+
+	if violations := cfg.SchemaViolations("development", secrets, isRef); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+}
+
+func TestCheckKeyType(t *testing.T) {
+	tests := []struct {
+		keyType string
+		value   string
+		valid   bool
+	}{
+		{"int", "42", true},
+		{"int", "4.2", false},
+		{"number", "4.2", true},
+		{"bool", "Yes", true},
+		{"bool", "maybe", false},
+		{"url", "postgres://db:5432/app", true},
+		{"url", "localhost", false},
+		{"port", "8080", true},
+		{"port", "0", false},
+		{"json", `{"a":1}`, true},
+		{"json", `{a:1}`, false},
+		{"duration", "1m30s", true},
+		{"duration", "90", false},
+	}
+	for _, tt := range tests {
+		err := checkKeyType(tt.keyType, tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("checkKeyType(%q, %q) = %v, want valid=%v", tt.keyType, tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestHasSchema(t *testing.T) {
+	if (&ProjectConfig{Keys: map[string]KeyConfig{"A": {Description: "docs only"}}}).HasSchema() {
+		t.Error("expected documentation alone not to be a schema")
+	}
+	if !schemaConfig().HasSchema() {
+		t.Error("expected a schema")
+	}
+	var nilCfg *ProjectConfig
+	if nilCfg.HasSchema() {
+		t.Error("expected no schema for nil config")
+	}
+}
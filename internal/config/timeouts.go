@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables overriding HTTP timeouts, e.g. KEYWAY_CONNECT_TIMEOUT=3s
+const (
+	EnvConnectTimeout  = "KEYWAY_CONNECT_TIMEOUT"
+	EnvTLSTimeout      = "KEYWAY_TLS_TIMEOUT"
+	EnvResponseTimeout = "KEYWAY_RESPONSE_TIMEOUT"
+	EnvHTTPTimeout     = "KEYWAY_HTTP_TIMEOUT"
+)
+
+// HTTPTimeouts are the timeouts of an HTTP client. Zero means no limit.
+type HTTPTimeouts struct {
+	// Connect bounds the TCP connection
+	Connect time.Duration
+	// TLSHandshake bounds the TLS handshake
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for response headers once the request is sent
+	ResponseHeader time.Duration
+	// Total bounds the whole request, including reading the body
+	Total time.Duration
+}
+
+// DefaultAPITimeouts are used for Keyway API requests
+var DefaultAPITimeouts = HTTPTimeouts{
+	Connect:      10 * time.Second,
+	TLSHandshake: 10 * time.Second,
+	Total:        30 * time.Second,
+}
+
+// ApplyEnv returns t with the timeouts set in the environment replaced.
+// Values are Go durations (500ms, 10s, 2m) or a number of seconds; 0 disables
+// the timeout. Invalid values are ignored and reported in the error.
+func (t HTTPTimeouts) ApplyEnv() (HTTPTimeouts, error) {
+	var invalid []string
+	for _, setting := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{EnvConnectTimeout, &t.Connect},
+		{EnvTLSTimeout, &t.TLSHandshake},
+		{EnvResponseTimeout, &t.ResponseHeader},
+		{EnvHTTPTimeout, &t.Total},
+	} {
+		raw := strings.TrimSpace(os.Getenv(setting.name))
+		if raw == "" {
+			continue
+		}
+		d, err := parseTimeout(raw)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s=%s", setting.name, raw))
+			continue
+		}
+		*setting.value = d
+	}
+
+	if len(invalid) > 0 {
+		return t, fmt.Errorf("invalid timeout %s (use a duration like 10s)", strings.Join(invalid, ", "))
+	}
+	return t, nil
+}
+
+// parseTimeout parses a duration or a number of seconds
+func parseTimeout(raw string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("negative timeout")
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout")
+	}
+	return d, nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPTimeouts_ApplyEnv(t *testing.T) {
+	t.Setenv(EnvConnectTimeout, "3s")
+	t.Setenv(EnvTLSTimeout, "")
+	t.Setenv(EnvResponseTimeout, "1.5")
+	t.Setenv(EnvHTTPTimeout, "0")
+
+	got, err := DefaultAPITimeouts.ApplyEnv()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := HTTPTimeouts{
+		Connect:        3 * time.Second,
+		TLSHandshake:   DefaultAPITimeouts.TLSHandshake,
+		ResponseHeader: 1500 * time.Millisecond,
+		Total:          0,
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestHTTPTimeouts_ApplyEnvInvalid(t *testing.T) {
+	t.Setenv(EnvConnectTimeout, "soon")
+	t.Setenv(EnvTLSTimeout, "-2s")
+	t.Setenv(EnvResponseTimeout, "")
+	t.Setenv(EnvHTTPTimeout, "1m")
+
+	got, err := DefaultAPITimeouts.ApplyEnv()
+
+	if err == nil || !strings.Contains(err.Error(), "KEYWAY_CONNECT_TIMEOUT=soon") || !strings.Contains(err.Error(), "KEYWAY_TLS_TIMEOUT=-2s") {
+		t.Errorf("expected both invalid values reported, got %v", err)
+	}
+	if got.Connect != DefaultAPITimeouts.Connect || got.TLSHandshake != DefaultAPITimeouts.TLSHandshake {
+		t.Errorf("invalid values should keep the defaults, got %+v", got)
+	}
+	if got.Total != time.Minute {
+		t.Errorf("valid values should still apply, got %v", got.Total)
+	}
+}
+
+func TestOfflineTTL(t *testing.T) {
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(origDir)
+
+	t.Setenv(EnvOfflineTTL, "")
+	if ttl, err := OfflineTTL(); err != nil || ttl != 0 {
+		t.Errorf("expected the cache disabled by default, got %s, %v", ttl, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ProjectConfigFile), []byte("[offline]\nttl = \"48h\"\n"), 0644)
+	if ttl, err := OfflineTTL(); err != nil || ttl != 48*time.Hour {
+		t.Errorf("expected offline.ttl from keyway.toml, got %s, %v", ttl, err)
+	}
+
+	t.Setenv(EnvOfflineTTL, "72h")
+	if ttl, err := OfflineTTL(); err != nil || ttl != 72*time.Hour {
+		t.Errorf("expected %s to take precedence, got %s, %v", EnvOfflineTTL, ttl, err)
+	}
+
+	t.Setenv(EnvOfflineTTL, "0")
+	if ttl, err := OfflineTTL(); err != nil || ttl != 0 {
+		t.Errorf("expected the cache disabled, got %s, %v", ttl, err)
+	}
+
+	t.Setenv(EnvOfflineTTL, "forever")
+	if ttl, err := OfflineTTL(); err == nil || ttl != 0 {
+		t.Errorf("expected the cache disabled and an error, got %s, %v", ttl, err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	t.Setenv(EnvRetries, "")
+	if n, err := Retries(); err != nil || n != DefaultRetries {
+		t.Errorf("expected the default, got %d, %v", n, err)
+	}
+
+	t.Setenv(EnvRetries, "5")
+	if n, err := Retries(); err != nil || n != 5 {
+		t.Errorf("expected 5, got %d, %v", n, err)
+	}
+
+	t.Setenv(EnvRetries, "0")
+	if n, err := Retries(); err != nil || n != 0 {
+		t.Errorf("expected retries disabled, got %d, %v", n, err)
+	}
+
+	t.Setenv(EnvRetries, "-1")
+	if n, err := Retries(); err == nil || n != DefaultRetries {
+		t.Errorf("expected the default and an error, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/keywaysh/cli/internal/env"
+)
+
+// ProjectSchema is the JSON Schema for keyway.toml, for editor integration
+//
+//go:embed keyway.schema.json
+var ProjectSchema []byte
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+var keyNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var repositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// ValidationError is a problem found in a config file, with its position
+type ValidationError struct {
+	File    string
+	Line    int // 1-based, 0 if unknown
+	Column  int // 1-based, 0 if unknown
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ValidateProject checks keyway.toml content for syntax errors, unknown keys,
+// invalid environment names, bad glob patterns and invalid values.
+// Errors are sorted by position.
+func ValidateProject(file string, data []byte) []ValidationError {
+	var cfg ProjectConfig
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return []ValidationError{parseErrorPosition(file, data, err)}
+	}
+
+	loc := newKeyLocator(data)
+	var errs []ValidationError
+	add := func(key []string, format string, args ...interface{}) {
+		line, col := loc.find(key)
+		errs = append(errs, ValidationError{File: file, Line: line, Column: col, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, key := range md.Undecoded() {
+		add(key, "unknown key %q", key.String())
+	}
+
+	if cfg.Repository != "" && !repositoryPattern.MatchString(cfg.Repository) {
+		add([]string{"repository"}, "invalid repository %q (use owner/repo)", cfg.Repository)
+	}
+
+	for _, name := range cfg.FallbackEnvironments {
+		if !envNamePattern.MatchString(name) {
+			add([]string{"fallback_environments"}, "invalid environment name %q (use letters, digits, - and _)", name)
+		}
+	}
+
+	switch cfg.Network.Background {
+	case "", BackgroundOn, BackgroundOff:
+	default:
+		add([]string{"network", "background"}, "background must be %q or %q, got %q", BackgroundOn, BackgroundOff, cfg.Network.Background)
+	}
+
+	if cfg.Offline.TTL != "" {
+		if _, err := parseTimeout(cfg.Offline.TTL); err != nil {
+			add([]string{"offline", "ttl"}, "invalid ttl %q (use a duration like 72h)", cfg.Offline.TTL)
+		}
+	}
+
+	if cfg.FlagPrefix != "" && !keyNamePattern.MatchString(cfg.FlagPrefix) {
+		add([]string{"flag_prefix"}, "invalid flag prefix %q (use letters, digits and _)", cfg.FlagPrefix)
+	}
+
+	for command, name := range cfg.DefaultEnvironments {
+		key := []string{"default_environments", command}
+		if !isDefaultEnvironmentCommand(command) {
+			add(key, "unknown command %q (available: %s)", command, strings.Join(DefaultEnvironmentCommands, ", "))
+		}
+		if !envNamePattern.MatchString(name) {
+			add(key, "invalid environment name %q (use letters, digits, - and _)", name)
+		}
+	}
+
+	for key, name := range cfg.Transforms {
+		if !env.IsTransform(name) {
+			add([]string{"transforms", key}, "unknown transform %q (available: %s)", name, strings.Join(env.TransformNames(), ", "))
+		}
+	}
+
+	for label, patterns := range cfg.Labels {
+		key := []string{"labels", label}
+		if !envNamePattern.MatchString(label) {
+			add(key, "invalid label name %q (use letters, digits, - and _)", label)
+		}
+		for _, pattern := range patterns {
+			if pattern == "" {
+				add(key, "empty key pattern")
+			} else if _, err := path.Match(pattern, ""); err != nil {
+				add(key, "bad glob pattern %q", pattern)
+			}
+		}
+	}
+
+	for name, expansion := range cfg.Aliases {
+		key := []string{"alias", name}
+		if !envNamePattern.MatchString(name) {
+			add(key, "invalid alias name %q (use letters, digits, - and _)", name)
+		}
+		if strings.TrimSpace(expansion) == "" {
+			add(key, "alias %q is empty", name)
+		}
+	}
+
+	for name, profile := range cfg.Profiles {
+		key := []string{"profiles", name}
+		if !envNamePattern.MatchString(name) {
+			add(key, "invalid profile name %q (use letters, digits, - and _)", name)
+		}
+		for _, pattern := range profile.Keys {
+			if pattern == "" {
+				add(append(key, "keys"), "empty key pattern")
+			} else if _, err := path.Match(pattern, ""); err != nil {
+				add(append(key, "keys"), "bad glob pattern %q", pattern)
+			}
+		}
+		if len(profile.Keys) == 0 && len(profile.Overrides) == 0 {
+			add(key, "profile %q selects no keys and sets no overrides", name)
+		}
+	}
+
+	for name, doc := range cfg.Keys {
+		key := []string{"keys", name}
+		if !keyNamePattern.MatchString(name) {
+			add(key, "invalid key name %q (use letters, digits and _)", name)
+		}
+		if doc.Type != "" && !isKeyType(doc.Type) {
+			add(append(key, "type"), "unknown type %q (available: %s)", doc.Type, strings.Join(KeyTypes, ", "))
+		}
+		if strings.TrimSpace(doc.Description) == "" && strings.TrimSpace(doc.Owner) == "" && doc.Type == "" && !doc.Required {
+			add(key, "key %q sets nothing", name)
+		}
+	}
+
+	for i, target := range cfg.Notify {
+		key := []string{"notify"}
+		switch target.Type {
+		case NotifySlack, NotifyHTTP:
+		default:
+			add(append(key, "type"), "notify[%d].type must be %q or %q, got %q", i, NotifySlack, NotifyHTTP, target.Type)
+		}
+		if url := strings.TrimSpace(target.URL); url == "" {
+			add(append(key, "url"), "notify[%d].url is required", i)
+		} else if !strings.HasPrefix(url, "$") && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			add(append(key, "url"), "notify[%d].url must be an http(s) URL or an environment variable, got %q", i, url)
+		}
+		for _, event := range target.Events {
+			if !isNotifyEvent(event) {
+				add(append(key, "events"), "unknown event %q (available: %s)", event, strings.Join(NotifyEvents, ", "))
+			}
+		}
+		for _, name := range target.Environments {
+			if !envNamePattern.MatchString(name) {
+				add(append(key, "environments"), "invalid environment name %q (use letters, digits, - and _)", name)
+			}
+		}
+		if target.Message != "" {
+			if _, err := template.New("message").Parse(target.Message); err != nil {
+				add(append(key, "message"), "invalid message template: %v", err)
+			}
+		}
+	}
+
+	for name, envCfg := range cfg.Environments {
+		key := []string{"environments", name}
+		if !envNamePattern.MatchString(name) {
+			add(key, "invalid environment name %q (use letters, digits, - and _)", name)
+		}
+		for _, pattern := range envCfg.Branches {
+			if pattern == "" {
+				add(append(key, "branches"), "empty branch pattern")
+			} else if _, err := path.Match(pattern, ""); err != nil {
+				add(append(key, "branches"), "bad glob pattern %q", pattern)
+			}
+		}
+		for _, name := range envCfg.RequiredKeys {
+			if !keyNamePattern.MatchString(name) {
+				add(append(key, "required_keys"), "invalid key name %q (use letters, digits and _)", name)
+			}
+		}
+		for _, name := range envCfg.OptionalKeys {
+			if !keyNamePattern.MatchString(name) {
+				add(append(key, "optional_keys"), "invalid key name %q (use letters, digits and _)", name)
+			}
+		}
+		for _, label := range envCfg.DenyLabels {
+			if _, ok := cfg.Labels[label]; !ok {
+				add(append(key, "deny_labels"), "unknown label %q (define it under [labels])", label)
+			}
+		}
+		switch envCfg.Enforce {
+		case "", EnforceBlock, EnforceWarn:
+		default:
+			add(append(key, "enforce"), "enforce must be %q or %q, got %q", EnforceBlock, EnforceWarn, envCfg.Enforce)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+	return errs
+}
+
+// parseErrorPosition converts a TOML decode error into a positioned validation error
+func parseErrorPosition(file string, data []byte, err error) ValidationError {
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		line, col := perr.Position.Line, 1
+		if start := perr.Position.Start; start > 0 && start <= len(data) {
+			col = start - strings.LastIndex(string(data[:start]), "\n")
+		}
+		return ValidationError{File: file, Line: line, Column: col, Message: perr.Message}
+	}
+	return ValidationError{File: file, Message: err.Error()}
+}
+
+// keyLocator finds the line and column where a key is defined.
+// It understands [table] headers and dotted keys, which covers keyway.toml.
+type keyLocator struct {
+	positions map[string][2]int
+}
+
+func newKeyLocator(data []byte) *keyLocator {
+	loc := &keyLocator{positions: make(map[string][2]int)}
+	var table []string
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+
+		if strings.HasPrefix(line, "[") {
+			header := strings.Trim(strings.SplitN(line, "]", 2)[0], "[")
+			if strings.HasPrefix(line, "[[") {
+				header = strings.Trim(strings.SplitN(line, "]]", 2)[0], "[")
+			}
+			table = splitDottedKey(header)
+			loc.record(table, i+1, indent+1)
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq == -1 {
+			continue
+		}
+		key := append(append([]string{}, table...), splitDottedKey(line[:eq])...)
+		loc.record(key, i+1, indent+1)
+	}
+
+	return loc
+}
+
+func (l *keyLocator) record(key []string, line, col int) {
+	// Register every prefix so parents of a dotted key are locatable too
+	for n := 1; n <= len(key); n++ {
+		k := strings.Join(key[:n], ".")
+		if _, seen := l.positions[k]; !seen || n == len(key) {
+			l.positions[k] = [2]int{line, col}
+		}
+	}
+}
+
+// find returns the position of key, or of its closest defined parent
+func (l *keyLocator) find(key []string) (int, int) {
+	for n := len(key); n > 0; n-- {
+		if pos, ok := l.positions[strings.Join(key[:n], ".")]; ok {
+			return pos[0], pos[1]
+		}
+	}
+	return 0, 0
+}
+
+// splitDottedKey splits a.b."c.d" into [a b c.d]
+func splitDottedKey(s string) []string {
+	var parts []string
+	var cur strings.Builder
+	quote := rune(0)
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '.':
+			parts = append(parts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(parts, strings.TrimSpace(cur.String()))
+}
@@ -0,0 +1,385 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/keywaysh/cli/internal/env"
+)
+
+func TestValidateProject_Valid(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[environments.production]
+branches = ["main", "release/*"]
+enforce = "warn"
+`))
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateProject_UnknownKeyPosition(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`[environments.production]
+branches = ["main"]
+  enforse = "warn"
+`))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	e := errs[0]
+	if e.Line != 3 || e.Column != 3 {
+		t.Errorf("expected 3:3, got %d:%d", e.Line, e.Column)
+	}
+	if !strings.Contains(e.Message, "environments.production.enforse") {
+		t.Errorf("unexpected message: %s", e.Message)
+	}
+	if e.Error() != "keyway.toml:3:3: "+e.Message {
+		t.Errorf("unexpected Error(): %s", e.Error())
+	}
+}
+
+func TestValidateProject_UnknownTopLevelTable(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[environment.production]
+branches = ["main"]
+`))
+	if len(errs) == 0 {
+		t.Fatal("expected errors for misspelled table")
+	}
+	if errs[0].Line != 2 {
+		t.Errorf("expected line 2, got %d", errs[0].Line)
+	}
+}
+
+func TestValidateProject_BadGlob(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[environments.production]
+branches = ["release/[", ""]
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	for _, e := range errs {
+		if e.Line != 3 {
+			t.Errorf("expected line 3, got %d", e.Line)
+		}
+	}
+}
+
+func TestValidateProject_InvalidValues(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[environments."prod env"]
+enforce = "always"
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Message, "invalid environment name") || errs[0].Line != 2 {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Message, "enforce must be") || errs[1].Line != 3 {
+		t.Errorf("unexpected second error: %v", errs[1])
+	}
+}
+
+func TestValidateProject_DottedKeys(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+environments.staging.branches = ["develop"]
+environments.staging.enfroce = "warn"
+`))
+	if len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("expected 1 error on line 3, got %v", errs)
+	}
+}
+
+func TestValidateProject_SyntaxError(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[environments.production]
+branches = ["main"
+`))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if errs[0].Line == 0 {
+		t.Errorf("expected a position, got %v", errs[0])
+	}
+}
+
+func TestProjectSchema_MatchesConfigStructs(t *testing.T) {
+	type object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(ProjectSchema, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	if got, want := schemaKeys(schema.Properties), tomlKeys(reflect.TypeOf(ProjectConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("top-level schema properties %v, struct fields %v", got, want)
+	}
+
+	var environments struct {
+		AdditionalProperties object `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(schema.Properties["environments"], &environments); err != nil {
+		t.Fatalf("invalid environments schema: %v", err)
+	}
+	if got, want := schemaKeys(environments.AdditionalProperties.Properties), tomlKeys(reflect.TypeOf(EnvironmentConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("environment schema properties %v, struct fields %v", got, want)
+	}
+
+	var network object
+	if err := json.Unmarshal(schema.Properties["network"], &network); err != nil {
+		t.Fatalf("invalid network schema: %v", err)
+	}
+	if got, want := schemaKeys(network.Properties), tomlKeys(reflect.TypeOf(NetworkConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("network schema properties %v, struct fields %v", got, want)
+	}
+
+	var offline object
+	if err := json.Unmarshal(schema.Properties["offline"], &offline); err != nil {
+		t.Fatalf("invalid offline schema: %v", err)
+	}
+	if got, want := schemaKeys(offline.Properties), tomlKeys(reflect.TypeOf(OfflineConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("offline schema properties %v, struct fields %v", got, want)
+	}
+
+	var dev object
+	if err := json.Unmarshal(schema.Properties["dev"], &dev); err != nil {
+		t.Fatalf("invalid dev schema: %v", err)
+	}
+	if got, want := schemaKeys(dev.Properties), tomlKeys(reflect.TypeOf(DevConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("dev schema properties %v, struct fields %v", got, want)
+	}
+
+	var notify struct {
+		Items object `json:"items"`
+	}
+	if err := json.Unmarshal(schema.Properties["notify"], &notify); err != nil {
+		t.Fatalf("invalid notify schema: %v", err)
+	}
+	if got, want := schemaKeys(notify.Items.Properties), tomlKeys(reflect.TypeOf(NotifyConfig{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("notify schema properties %v, struct fields %v", got, want)
+	}
+}
+
+func TestValidateProject_Notify(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[[notify]]
+type = "slack"
+url = "${SLACK_WEBHOOK_URL}"
+events = ["push"]
+environments = ["production"]
+message = "{{.Actor}} updated {{.Environment}}"
+
+[[notify]]
+type = "teams"
+url = "hooks.example.com"
+events = ["rotate"]
+message = "{{.Actor"
+`))
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Message)
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{`notify[1].type must be "slack" or "http", got "teams"`, `notify[1].url must be an http(s) URL`, `unknown event "rotate"`, "invalid message template"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in:\n%s", want, joined)
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %v", errs)
+	}
+}
+
+func schemaKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func tomlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("toml"); tag != "" && tag != "-" {
+			keys = append(keys, strings.Split(tag, ",")[0])
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestValidateProject_FallbackEnvironments(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`fallback_environments = ["dev", "bad name"]`))
+	if len(errs) != 1 || errs[0].Line != 1 || !strings.Contains(errs[0].Message, "bad name") {
+		t.Errorf("expected 1 error on line 1, got %v", errs)
+	}
+}
+
+func TestValidateProject_FlagPrefix(t *testing.T) {
+	if errs := ValidateProject("keyway.toml", []byte(`flag_prefix = "FEATURE_"`)); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidateProject("keyway.toml", []byte(`flag_prefix = "feature-"`))
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "invalid flag prefix") {
+		t.Errorf("expected an invalid prefix error, got %v", errs)
+	}
+}
+
+func TestValidateProject_NetworkBackground(t *testing.T) {
+	if errs := ValidateProject("keyway.toml", []byte("[network]\nbackground = \"off\"")); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidateProject("keyway.toml", []byte("[network]\nbackground = \"disabled\""))
+	if len(errs) != 1 || errs[0].Line != 2 || !strings.Contains(errs[0].Message, "background must be") {
+		t.Errorf("expected 1 error on line 2, got %v", errs)
+	}
+}
+
+func TestValidateProject_OfflineTTL(t *testing.T) {
+	if errs := ValidateProject("keyway.toml", []byte("[offline]\nttl = \"72h\"")); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidateProject("keyway.toml", []byte("[offline]\nttl = \"forever\""))
+	if len(errs) != 1 || errs[0].Line != 2 || !strings.Contains(errs[0].Message, "invalid ttl") {
+		t.Errorf("expected 1 error on line 2, got %v", errs)
+	}
+}
+
+func TestValidateProject_DefaultEnvironments(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[default_environments]
+run = "development"
+docker = "development"
+pull = "bad name"
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Line != 4 || !strings.Contains(errs[0].Message, `unknown command "docker"`) {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	if errs[1].Line != 5 || !strings.Contains(errs[1].Message, "bad name") {
+		t.Errorf("unexpected second error: %v", errs[1])
+	}
+}
+
+func TestValidateProject_Labels(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[labels]
+payment = ["STRIPE_*", "["]
+
+[environments.development]
+deny_labels = ["payment", "pci"]
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Line != 3 || !strings.Contains(errs[0].Message, "bad glob") {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	if errs[1].Line != 6 || !strings.Contains(errs[1].Message, `unknown label "pci"`) {
+		t.Errorf("unexpected second error: %v", errs[1])
+	}
+}
+
+func TestValidateProject_Keys(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[keys.DATABASE_URL]
+description = "Primary Postgres connection string"
+owner = "@acme/platform"
+
+[keys.STRIPE_KEY]
+
+[keys."BAD-NAME"]
+owner = "@acme/payments"
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Line != 6 || !strings.Contains(errs[0].Message, "sets nothing") {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Message, `invalid key name "BAD-NAME"`) {
+		t.Errorf("unexpected second error: %v", errs[1])
+	}
+}
+
+func TestValidateProject_Transforms(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[transforms]
+TLS_CERT = "base64decode"
+DB_PASSWORD = "gzip"
+`))
+	if len(errs) != 1 || errs[0].Line != 4 || !strings.Contains(errs[0].Message, `unknown transform "gzip"`) {
+		t.Errorf("expected 1 error on line 4, got %v", errs)
+	}
+}
+
+func TestValidateProject_Aliases(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+alias.dev = "run -e development -- npm run dev"
+alias.empty = " "
+`))
+	if len(errs) != 1 || errs[0].Line != 3 || !strings.Contains(errs[0].Message, `alias "empty" is empty`) {
+		t.Errorf("expected 1 error on line 3, got %v", errs)
+	}
+}
+
+func TestProjectSchema_TransformNames(t *testing.T) {
+	var schema struct {
+		Properties struct {
+			Transforms struct {
+				AdditionalProperties struct {
+					Enum []string `json:"enum"`
+				} `json:"additionalProperties"`
+			} `json:"transforms"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(ProjectSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schema.Properties.Transforms.AdditionalProperties.Enum, env.TransformNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema transforms %v, available %v", got, want)
+	}
+}
+
+func TestValidateProject_Repository(t *testing.T) {
+	if errs := ValidateProject("keyway.toml", []byte(`repository = "neworg/new-name"`)); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := ValidateProject("keyway.toml", []byte(`repository = "new-name"`))
+	if len(errs) != 1 || errs[0].Line != 1 || !strings.Contains(errs[0].Message, "owner/repo") {
+		t.Errorf("expected 1 error on line 1, got %v", errs)
+	}
+}
+
+func TestValidateProject_Profiles(t *testing.T) {
+	errs := ValidateProject("keyway.toml", []byte(`
+[profiles.worker]
+keys = ["DATABASE_URL", "QUEUE_["]
+
+[profiles.worker.overrides]
+CONCURRENCY = "4"
+
+[profiles.empty]
+`))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Line != 3 || !strings.Contains(errs[0].Message, "bad glob") {
+		t.Errorf("unexpected first error: %v", errs[0])
+	}
+	if errs[1].Line != 8 || !strings.Contains(errs[1].Message, `profile "empty" selects no keys`) {
+		t.Errorf("unexpected second error: %v", errs[1])
+	}
+}
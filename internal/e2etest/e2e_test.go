@@ -0,0 +1,207 @@
+package e2etest
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const repo = "owner/repo"
+
+var binary string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if testing.Short() {
+		os.Exit(m.Run())
+	}
+
+	dir, err := os.MkdirTemp("", "keyway-e2e-*")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary, err = BuildCLI(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newCLI(t *testing.T) (*CLI, *FakeAPI) {
+	if testing.Short() {
+		t.Skip("end-to-end test")
+	}
+	api := NewFakeAPI(t)
+	return NewCLI(t, binary, api), api
+}
+
+func TestPull_WritesEnvFile(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "production", "API_KEY=secret\nDB_URL=postgres://db\n")
+
+	res := cli.Run(t, "pull", "--repo", repo, "-e", "production", "-y")
+
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d\n%s", res.ExitCode, res.Stderr+res.Stdout)
+	}
+	content := cli.ReadFile(t, ".env")
+	if !strings.Contains(content, "API_KEY=secret") || !strings.Contains(content, "DB_URL=postgres://db") {
+		t.Errorf("unexpected .env:\n%s", content)
+	}
+}
+
+func TestPull_UnknownEnvironmentFails(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "production", "A=1\n")
+
+	res := cli.Run(t, "pull", "--repo", repo, "-e", "staging", "-y")
+
+	if res.ExitCode == 0 {
+		t.Fatal("expected a nonzero exit code")
+	}
+}
+
+func TestPush_UploadsEnvFile(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "production", "A=1\n")
+	cli.WriteFile(t, ".env", "A=1\nB=2\n")
+
+	res := cli.Run(t, "push", "--repo", repo, "-e", "production", "-y")
+
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d\n%s", res.ExitCode, res.Stderr+res.Stdout)
+	}
+	if got := api.Secrets(repo, "production"); got != "A=1\nB=2\n" {
+		t.Errorf("unexpected vault content:\n%s", got)
+	}
+}
+
+func TestRun_InjectsSecretsIntoCommand(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "development", "API_KEY=from-vault\n")
+	cli.Stub(t, "docker", `echo "docker $* API_KEY=$API_KEY KEYWAY_TOKEN=$KEYWAY_TOKEN"`)
+
+	res := cli.Run(t, "run", "--repo", repo, "--", "docker", "compose", "up")
+
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d\n%s", res.ExitCode, res.Stderr+res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, "docker compose up API_KEY=from-vault KEYWAY_TOKEN=\n") {
+		t.Errorf("expected secrets injected and KEYWAY_TOKEN withheld, got:\n%s", res.Stdout)
+	}
+}
+
+func TestRun_PropagatesExitCode(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "development", "A=1\n")
+	cli.Stub(t, "npm", "exit 7")
+
+	res := cli.Run(t, "run", "--repo", repo, "--", "npm", "test")
+
+	if res.ExitCode != 7 {
+		t.Errorf("expected exit code 7, got %d\n%s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestRun_ForwardsSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not forwarded on Windows")
+	}
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "development", "A=1\n")
+	cli.Stub(t, "server", `trap 'echo got-term; exit 42' TERM
+echo ready
+while true; do sleep 0.1; done`)
+
+	cmd := cli.Command("run", "--repo", repo, "--", "server")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cmd.Process.Kill() }()
+
+	lines := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	waitForLine(t, lines, "ready")
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	waitForLine(t, lines, "got-term")
+
+	_ = cmd.Wait()
+	if code := cmd.ProcessState.ExitCode(); code != 42 {
+		t.Errorf("expected the child's exit code 42, got %d", code)
+	}
+}
+
+func TestUnauthorizedTokenFails(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "development", "A=1\n")
+	cli.Env = append(cli.Env, "KEYWAY_TOKEN=kw_wrong")
+
+	res := cli.Run(t, "pull", "--repo", repo, "-y")
+
+	if res.ExitCode == 0 {
+		t.Fatal("expected a nonzero exit code")
+	}
+}
+
+func waitForLine(t *testing.T, lines <-chan string, want string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("output ended before %q", want)
+			}
+			if line == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestRun_LogOutputMasksSecrets(t *testing.T) {
+	cli, api := newCLI(t)
+	api.SetSecrets(repo, "development", "API_KEY=from-vault-123\n")
+	cli.Stub(t, "app", `echo "key is $API_KEY"; echo "warning" >&2`)
+
+	res := cli.Run(t, "run", "--repo", repo, "--log-output", "run.log", "--", "app")
+
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d\n%s", res.ExitCode, res.Stderr+res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, "key is from-vault-123") {
+		t.Errorf("expected unmasked output on the terminal, got:\n%s", res.Stdout)
+	}
+	log := cli.ReadFile(t, "run.log")
+	// stdout and stderr are copied concurrently, so their order may vary
+	if len(log) != len("key is ***\nwarning\n") || !strings.Contains(log, "key is ***\n") || !strings.Contains(log, "warning\n") {
+		t.Errorf("unexpected log:\n%s", log)
+	}
+}
@@ -0,0 +1,263 @@
+// Package e2etest runs the built keyway binary against an in-memory fake of
+// the Keyway API, with stub commands (docker, npm...) on PATH, to test
+// behavior that unit tests with mocked dependencies can't reach: flag parsing,
+// exit codes, signal forwarding and files written to disk.
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Token is the session token the harness passes to the CLI
+const Token = "kw_e2e_token"
+
+// BuildCLI builds the keyway binary into dir and returns its path
+func BuildCLI(dir string) (string, error) {
+	path := filepath.Join(dir, "keyway")
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", path, "github.com/keywaysh/cli/cmd/keyway")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("go build failed: %w\n%s", err, out)
+	}
+	return path, nil
+}
+
+// FakeAPI is an in-memory Keyway API holding one env file per repo and environment
+type FakeAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	vaults   map[string]map[string]string // repo -> environment -> content
+	requests []string
+}
+
+// NewFakeAPI starts a fake API server, closed when the test ends
+func NewFakeAPI(t *testing.T) *FakeAPI {
+	t.Helper()
+	f := &FakeAPI{vaults: make(map[string]map[string]string)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// SetSecrets stores the env file content of an environment
+func (f *FakeAPI) SetSecrets(repo, env, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.vaults[repo] == nil {
+		f.vaults[repo] = make(map[string]string)
+	}
+	f.vaults[repo][env] = content
+}
+
+// Secrets returns the env file content of an environment
+func (f *FakeAPI) Secrets(repo, env string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.vaults[repo][env]
+}
+
+// Requests returns the "METHOD /path" of every request received so far
+func (f *FakeAPI) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *FakeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/secrets/pull":
+		repo, env := r.URL.Query().Get("repo"), r.URL.Query().Get("environment")
+		f.mu.Lock()
+		content, ok := f.vaults[repo][env]
+		f.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "Environment not found")
+			return
+		}
+		writeData(w, map[string]string{"content": content})
+
+	case r.Method == "POST" && r.URL.Path == "/v1/secrets/push":
+		var body struct {
+			Repo        string            `json:"repoFullName"`
+			Environment string            `json:"environment"`
+			Secrets     map[string]string `json:"secrets"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid body")
+			return
+		}
+		f.SetSecrets(body.Repo, body.Environment, formatEnv(body.Secrets))
+		writeData(w, map[string]interface{}{"success": true, "message": "Secrets pushed"})
+
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/v1/vaults/"):
+		repo := strings.TrimPrefix(r.URL.Path, "/v1/vaults/")
+		f.mu.Lock()
+		envs, ok := f.vaults[repo]
+		names := make([]string, 0, len(envs))
+		for name := range envs {
+			names = append(names, name)
+		}
+		f.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "Vault not found")
+			return
+		}
+		sort.Strings(names)
+		writeData(w, map[string]interface{}{"environments": names})
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+// formatEnv renders secrets as a sorted env file
+func formatEnv(secrets map[string]string) string {
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, secrets[k])
+	}
+	return b.String()
+}
+
+// CLI runs a keyway binary in an isolated home, working directory and PATH
+type CLI struct {
+	Binary string
+	// Dir is the working directory, a fresh temp dir
+	Dir string
+	// Env is the environment of every run; tests may append to it
+	Env []string
+
+	binDir string
+}
+
+// NewCLI prepares an isolated environment talking to api
+func NewCLI(t *testing.T, binary string, api *FakeAPI) *CLI {
+	t.Helper()
+	home := t.TempDir()
+	c := &CLI{
+		Binary: binary,
+		Dir:    t.TempDir(),
+		binDir: t.TempDir(),
+	}
+	c.Env = []string{
+		"HOME=" + home,
+		"USERPROFILE=" + home,
+		"APPDATA=" + home,
+		"PATH=" + c.binDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"KEYWAY_API_URL=" + api.URL,
+		"KEYWAY_TOKEN=" + Token,
+		"KEYWAY_STATE_DIR=" + filepath.Join(home, "state"),
+		"KEYWAY_DISABLE_TELEMETRY=1",
+		"KEYWAY_DISABLE_UPDATE_CHECK=1",
+		"NO_COLOR=1",
+		// Never prompt: an empty stdin is /dev/null, which looks like a terminal
+		"CI=true",
+	}
+	return c
+}
+
+// Stub installs a shell script named name on the CLI's PATH.
+// Stubs are POSIX shell scripts, so tests using them skip on Windows.
+func (c *CLI) Stub(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command stubs need a POSIX shell")
+	}
+	path := filepath.Join(c.binDir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Result is the outcome of a CLI run
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Command returns the exec.Cmd for a run, for tests that need to drive the process
+func (c *CLI) Command(args ...string) *exec.Cmd {
+	cmd := exec.Command(c.Binary, args...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	return cmd
+}
+
+// Run runs keyway with args and waits for it to exit
+func (c *CLI) Run(t *testing.T, args ...string) Result {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := c.Command(args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	result := Result{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("failed to run keyway: %v", err)
+	}
+	return result
+}
+
+// ReadFile reads a file from the working directory
+func (c *CLI) ReadFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(c.Dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// WriteFile writes a file to the working directory
+func (c *CLI) WriteFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(c.Dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
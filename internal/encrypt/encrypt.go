@@ -0,0 +1,77 @@
+// Package encrypt encrypts pulled env files for age recipients, so they can
+// be stored or transferred without being plaintext on disk.
+package encrypt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+)
+
+// ParseRecipient parses an age public key (age1...) or an SSH public key
+// (ssh-ed25519 or ssh-rsa, as found in authorized_keys or GitHub's .keys).
+func ParseRecipient(s string) (age.Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", s, err)
+		}
+		return r, nil
+	case strings.HasPrefix(s, "ssh-"):
+		r, err := agessh.ParseRecipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH recipient: %w", err)
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("unsupported recipient %q (use an age1... or ssh-ed25519/ssh-rsa public key)", s)
+}
+
+// Encrypt encrypts data for all recipients, any of whom can decrypt it with
+// `age -d`. With armor, the output is PEM-style text instead of binary.
+func Encrypt(data []byte, recipients []string, withArmor bool) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, s := range recipients {
+		r, err := ParseRecipient(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+
+	var out bytes.Buffer
+	var dst io.Writer = &out
+	var armorWriter io.WriteCloser
+	if withArmor {
+		armorWriter = armor.NewWriter(&out)
+		dst = armorWriter
+	}
+
+	w, err := age.Encrypt(dst, parsed...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
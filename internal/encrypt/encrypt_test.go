@@ -0,0 +1,59 @@
+package encrypt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+func decrypt(t *testing.T, data []byte, identity age.Identity, armored bool) string {
+	t.Helper()
+	var src io.Reader = bytes.NewReader(data)
+	if armored {
+		src = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(src, identity)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(out)
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	alice, _ := age.GenerateX25519Identity()
+	bob, _ := age.GenerateX25519Identity()
+
+	for _, armored := range []bool{false, true} {
+		data, err := Encrypt([]byte("API_KEY=secret\n"), []string{alice.Recipient().String(), bob.Recipient().String()}, armored)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if bytes.Contains(data, []byte("secret")) {
+			t.Fatal("output contains plaintext")
+		}
+		if armored != strings.HasPrefix(string(data), "-----BEGIN AGE ENCRYPTED FILE-----") {
+			t.Errorf("armor=%v mismatch: %q", armored, data[:20])
+		}
+		for _, id := range []*age.X25519Identity{alice, bob} {
+			if got := decrypt(t, data, id, armored); got != "API_KEY=secret\n" {
+				t.Errorf("expected round trip, got %q", got)
+			}
+		}
+	}
+}
+
+func TestEncrypt_InvalidRecipients(t *testing.T) {
+	for _, recipients := range [][]string{nil, {"age1notakey"}, {"0xDEADBEEF"}, {"ssh-ed25519 AAAA"}} {
+		if _, err := Encrypt([]byte("x"), recipients, false); err == nil {
+			t.Errorf("%v: expected error", recipients)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Array handling modes for Flatten
+const (
+	ArraysIndex = "index" // LIST__0, LIST__1...
+	ArraysJSON  = "json"  // LIST='["a","b"]'
+	ArraysJoin  = "join"  // LIST=a,b (scalars only)
+)
+
+// ArrayModes returns the array handling modes accepted by Flatten
+func ArrayModes() []string {
+	return []string{ArraysIndex, ArraysJSON, ArraysJoin}
+}
+
+// FlattenOptions controls how nested documents become env keys
+type FlattenOptions struct {
+	// Separator joins nested key segments, e.g. "__" gives DATABASE__HOST
+	Separator string
+	// Arrays is one of ArraysIndex, ArraysJSON or ArraysJoin
+	Arrays string
+	// Prefix is prepended to every key
+	Prefix string
+}
+
+// Flatten converts a decoded document (objects as map[string]interface{},
+// arrays as []interface{}) into flat env keys.
+// Key segments are upper-cased and characters other than letters, digits
+// and underscores are replaced by underscores. Nulls become empty values.
+// Returns an error if two paths produce the same key.
+func Flatten(doc interface{}, opts FlattenOptions) (map[string]string, error) {
+	root, ok := asMap(doc)
+	if !ok {
+		return nil, fmt.Errorf("top level must be an object, got %s", kindOf(doc))
+	}
+
+	f := &flattener{opts: opts, out: make(map[string]string), paths: make(map[string]string)}
+	if err := f.walkMap(opts.Prefix, "", root); err != nil {
+		return nil, err
+	}
+	return f.out, nil
+}
+
+type flattener struct {
+	opts  FlattenOptions
+	out   map[string]string
+	paths map[string]string // key -> source path, to report collisions
+}
+
+func (f *flattener) walkMap(key, path string, m map[string]interface{}) error {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := f.walk(f.join(key, normalizeSegment(name)), joinPath(path, name), m[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *flattener) walk(key, path string, value interface{}) error {
+	if m, ok := asMap(value); ok {
+		return f.walkMap(key, path, m)
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return f.set(key, path, scalarString(value))
+	}
+
+	switch f.opts.Arrays {
+	case ArraysJSON:
+		data, err := json.Marshal(list)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return f.set(key, path, string(data))
+	case ArraysJoin:
+		parts := make([]string, len(list))
+		for i, item := range list {
+			if _, nested := asMap(item); nested {
+				return fmt.Errorf("%s: cannot join an array of objects (use --arrays index or json)", path)
+			}
+			if _, nested := item.([]interface{}); nested {
+				return fmt.Errorf("%s: cannot join nested arrays (use --arrays index or json)", path)
+			}
+			parts[i] = scalarString(item)
+		}
+		return f.set(key, path, strings.Join(parts, ","))
+	default:
+		for i, item := range list {
+			if err := f.walk(f.join(key, strconv.Itoa(i)), fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (f *flattener) set(key, path, value string) error {
+	if previous, exists := f.paths[key]; exists {
+		return fmt.Errorf("%s and %s both map to %s", previous, path, key)
+	}
+	f.paths[key] = path
+	f.out[key] = value
+	return nil
+}
+
+func (f *flattener) join(key, segment string) string {
+	if key == "" {
+		return segment
+	}
+	return key + f.opts.Separator + segment
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// normalizeSegment upper-cases a key segment and replaces unsupported characters
+func normalizeSegment(name string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(name) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	m, ok := value.(map[string]interface{})
+	return m, ok
+}
+
+// scalarString formats a scalar. Numbers should be decoded as json.Number so
+// they are kept exactly as written.
+func scalarString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func kindOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	}
+	return "a scalar"
+}
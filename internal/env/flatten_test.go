@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatalf("bad fixture: %v", err)
+	}
+	return doc
+}
+
+func TestFlatten(t *testing.T) {
+	doc := decodeJSON(t, `{"database": {"host": "db", "port": 5432, "ssl": true}, "api-key": "k", "empty": null, "ratio": 1.50}`)
+
+	got, err := Flatten(doc, FlattenOptions{Separator: "__", Arrays: ArraysIndex})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"DATABASE__HOST": "db",
+		"DATABASE__PORT": "5432",
+		"DATABASE__SSL":  "true",
+		"API_KEY":        "k",
+		"EMPTY":          "",
+		"RATIO":          "1.50",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestFlatten_Arrays(t *testing.T) {
+	doc := decodeJSON(t, `{"hosts": ["a", "b"], "users": [{"name": "x"}]}`)
+
+	tests := []struct {
+		mode    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{ArraysIndex, map[string]string{"APP_HOSTS_0": "a", "APP_HOSTS_1": "b", "APP_USERS_0_NAME": "x"}, false},
+		{ArraysJSON, map[string]string{"APP_HOSTS": `["a","b"]`, "APP_USERS": `[{"name":"x"}]`}, false},
+		{ArraysJoin, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := Flatten(doc, FlattenOptions{Separator: "_", Arrays: tt.mode, Prefix: "APP"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v", tt.mode, err)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %q, want %q", tt.mode, k, got[k], v)
+			}
+		}
+	}
+
+	got, err := Flatten(decodeJSON(t, `{"hosts": ["a", 1]}`), FlattenOptions{Separator: "_", Arrays: ArraysJoin})
+	if err != nil || got["HOSTS"] != "a,1" {
+		t.Errorf("join: got %v, %v", got, err)
+	}
+}
+
+func TestFlatten_Collision(t *testing.T) {
+	_, err := Flatten(decodeJSON(t, `{"a": {"b": "1"}, "a_b": "2"}`), FlattenOptions{Separator: "_"})
+	if err == nil || !strings.Contains(err.Error(), "A_B") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+}
+
+func TestFlatten_TopLevelMustBeObject(t *testing.T) {
+	if _, err := Flatten(decodeJSON(t, `["a"]`), FlattenOptions{Separator: "__"}); err == nil {
+		t.Error("expected error for top-level array")
+	}
+}
@@ -0,0 +1,100 @@
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// RefPrefix marks a value that points to a vault-level shared value,
+// e.g. SENTRY_DSN=$ref:shared/SENTRY_DSN
+const RefPrefix = "$ref:shared/"
+
+// RefName returns the shared value name referenced by value, or "" if value is not a reference.
+func RefName(value string) string {
+	if !strings.HasPrefix(value, RefPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(value, RefPrefix))
+}
+
+// HasRefs returns true if any value in the map is a shared reference.
+func HasRefs(secrets map[string]string) bool {
+	for _, value := range secrets {
+		if RefName(value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveRefs replaces shared references with their values.
+// Returns the resolved map and the sorted names of references that could not be resolved.
+func ResolveRefs(secrets, shared map[string]string) (map[string]string, []string) {
+	resolved := make(map[string]string, len(secrets))
+	missing := make(map[string]bool)
+
+	for key, value := range secrets {
+		name := RefName(value)
+		if name == "" {
+			resolved[key] = value
+			continue
+		}
+		if sharedValue, ok := shared[name]; ok {
+			resolved[key] = sharedValue
+		} else {
+			resolved[key] = value
+			missing[name] = true
+		}
+	}
+
+	return resolved, sortedKeys(missing)
+}
+
+// ResolveRefsInContent rewrites env file content, replacing shared references with their values.
+// Comments, ordering and non-reference lines are kept as-is.
+func ResolveRefsInContent(content string, shared map[string]string) (string, []string) {
+	lines := strings.Split(content, "\n")
+	missing := make(map[string]bool)
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		parsed := Parse(trimmed)
+		if len(parsed) != 1 {
+			continue
+		}
+		for key, value := range parsed {
+			name := RefName(value)
+			if name == "" {
+				continue
+			}
+			sharedValue, ok := shared[name]
+			if !ok {
+				missing[name] = true
+				continue
+			}
+			lines[i] = key + "=" + quoteValue(sharedValue)
+		}
+	}
+
+	return strings.Join(lines, "\n"), sortedKeys(missing)
+}
+
+// quoteValue wraps values that would not survive Parse unquoted
+func quoteValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "#\"'") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"$ref:shared/SENTRY_DSN", "SENTRY_DSN"},
+		{"$ref:shared/ SENTRY_DSN ", "SENTRY_DSN"},
+		{"https://sentry.io", ""},
+		{"$ref:other/X", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := RefName(tt.value); got != tt.want {
+			t.Errorf("RefName(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	secrets := map[string]string{
+		"SENTRY_DSN": "$ref:shared/SENTRY_DSN",
+		"API_KEY":    "abc",
+		"MISSING":    "$ref:shared/NOPE",
+	}
+	shared := map[string]string{"SENTRY_DSN": "https://sentry.io/1"}
+
+	resolved, missing := ResolveRefs(secrets, shared)
+
+	if resolved["SENTRY_DSN"] != "https://sentry.io/1" {
+		t.Errorf("SENTRY_DSN not resolved: %q", resolved["SENTRY_DSN"])
+	}
+	if resolved["API_KEY"] != "abc" {
+		t.Errorf("API_KEY changed: %q", resolved["API_KEY"])
+	}
+	if !reflect.DeepEqual(missing, []string{"NOPE"}) {
+		t.Errorf("missing = %v, want [NOPE]", missing)
+	}
+}
+
+func TestResolveRefsInContent(t *testing.T) {
+	content := "# Monitoring\nSENTRY_DSN=$ref:shared/SENTRY_DSN\nAPI_KEY=abc\nGREETING=$ref:shared/GREETING\n"
+	shared := map[string]string{
+		"SENTRY_DSN": "https://sentry.io/1",
+		"GREETING":   " hello ",
+	}
+
+	resolved, missing := ResolveRefsInContent(content, shared)
+
+	if len(missing) != 0 {
+		t.Fatalf("unexpected missing refs: %v", missing)
+	}
+	want := "# Monitoring\nSENTRY_DSN=https://sentry.io/1\nAPI_KEY=abc\nGREETING=\" hello \"\n"
+	if resolved != want {
+		t.Errorf("resolved content =\n%q\nwant\n%q", resolved, want)
+	}
+
+	// The rewritten content must parse back to the shared values
+	parsed := Parse(resolved)
+	if parsed["GREETING"] != " hello " {
+		t.Errorf("GREETING did not round-trip: %q", parsed["GREETING"])
+	}
+}
+
+func TestResolveRefsInContent_Missing(t *testing.T) {
+	content := "A=$ref:shared/B\nC=$ref:shared/A\n"
+
+	resolved, missing := ResolveRefsInContent(content, map[string]string{})
+
+	if !reflect.DeepEqual(missing, []string{"A", "B"}) {
+		t.Errorf("missing = %v, want [A B]", missing)
+	}
+	if resolved != content {
+		t.Error("content should be unchanged when refs are missing")
+	}
+}
+
+func TestHasRefs(t *testing.T) {
+	if HasRefs(map[string]string{"A": "1"}) {
+		t.Error("expected no refs")
+	}
+	if !HasRefs(map[string]string{"A": "1", "B": "$ref:shared/B"}) {
+		t.Error("expected refs")
+	}
+}
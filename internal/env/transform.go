@@ -0,0 +1,56 @@
+package env
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// transformers are the value transforms available at injection time
+var transformers = map[string]func(string) (string, error){
+	"base64decode": func(v string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil {
+			// Accept unpadded and URL-safe encodings too
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(v), "="))
+			if err != nil {
+				decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(v), "="))
+			}
+		}
+		return string(decoded), err
+	},
+	"base64encode": func(v string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(v)), nil
+	},
+	"urlencode": func(v string) (string, error) {
+		return url.QueryEscape(v), nil
+	},
+	"urldecode": url.QueryUnescape,
+}
+
+// TransformNames returns the sorted names of the available transforms
+func TransformNames() []string {
+	names := make([]string, 0, len(transformers))
+	for name := range transformers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTransform returns true if name is an available transform
+func IsTransform(name string) bool {
+	_, ok := transformers[name]
+	return ok
+}
+
+// Transform applies the named transform to value
+func Transform(name, value string) (string, error) {
+	fn, ok := transformers[name]
+	if !ok {
+		return "", fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(TransformNames(), ", "))
+	}
+	return fn(value)
+}
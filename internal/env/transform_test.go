@@ -0,0 +1,41 @@
+package env
+
+import "testing"
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"base64decode", "aGVsbG8gd29ybGQ=", "hello world", false},
+		{"base64decode", "aGVsbG8gd29ybGQ", "hello world", false},
+		{"base64decode", "not base64!", "", true},
+		{"base64encode", "hello world", "aGVsbG8gd29ybGQ=", false},
+		{"urlencode", "p@ss/w:rd", "p%40ss%2Fw%3Ard", false},
+		{"urldecode", "p%40ss", "p@ss", false},
+		{"rot13", "x", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Transform(tt.name, tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Transform(%s, %q) error = %v", tt.name, tt.in, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("Transform(%s, %q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransformNames(t *testing.T) {
+	names := TransformNames()
+	if len(names) != 4 || names[0] != "base64decode" {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if !IsTransform("urlencode") || IsTransform("gzip") {
+		t.Error("IsTransform mismatch")
+	}
+}
@@ -0,0 +1,117 @@
+package env
+
+import "unicode"
+
+// WordOp is the kind of a word-level edit
+type WordOp int
+
+const (
+	WordEqual WordOp = iota
+	WordDeleted
+	WordInserted
+)
+
+// WordEdit is a run of text kept, removed from the old value or added to the new one
+type WordEdit struct {
+	Op   WordOp
+	Text string
+	// Separator is true for punctuation runs (":", "//", "@"...), which give
+	// values their structure and carry no secret material
+	Separator bool
+}
+
+// maxWordDiffCells bounds the LCS table; longer values are diffed as a whole
+const maxWordDiffCells = 1 << 20
+
+// DiffWords compares two values word by word. Words are runs of letters and
+// digits; every other character is a separator token.
+func DiffWords(before, after string) []WordEdit {
+	a, b := splitWords(before), splitWords(after)
+	if len(a)*len(b) > maxWordDiffCells {
+		return []WordEdit{{Op: WordDeleted, Text: before}, {Op: WordInserted, Text: after}}
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []WordEdit
+	add := func(op WordOp, text string) {
+		sep := isSeparator(text)
+		if n := len(edits); n > 0 && edits[n-1].Op == op && edits[n-1].Separator == sep {
+			edits[n-1].Text += text
+			return
+		}
+		edits = append(edits, WordEdit{Op: op, Text: text, Separator: sep})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(WordEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(WordDeleted, a[i])
+			i++
+		default:
+			add(WordInserted, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(WordDeleted, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(WordInserted, b[j])
+	}
+	return edits
+}
+
+// splitWords splits s into words and single separator characters
+func splitWords(s string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, s[start:i])
+			start = -1
+		}
+		tokens = append(tokens, string(r))
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isSeparator(token string) bool {
+	for _, r := range token {
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
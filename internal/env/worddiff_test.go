@@ -0,0 +1,42 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	got := DiffWords("https://api.example.com/v1", "https://api.example.org/v2")
+	want := []WordEdit{
+		{Op: WordEqual, Text: "https"},
+		{Op: WordEqual, Text: "://", Separator: true},
+		{Op: WordEqual, Text: "api"},
+		{Op: WordEqual, Text: ".", Separator: true},
+		{Op: WordEqual, Text: "example"},
+		{Op: WordEqual, Text: ".", Separator: true},
+		{Op: WordDeleted, Text: "com"},
+		{Op: WordInserted, Text: "org"},
+		{Op: WordEqual, Text: "/", Separator: true},
+		{Op: WordDeleted, Text: "v1"},
+		{Op: WordInserted, Text: "v2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffWords_Identical(t *testing.T) {
+	for _, edit := range DiffWords("a=b,c", "a=b,c") {
+		if edit.Op != WordEqual {
+			t.Errorf("expected only equal edits, got %+v", edit)
+		}
+	}
+}
+
+func TestDiffWords_Empty(t *testing.T) {
+	got := DiffWords("", "token")
+	want := []WordEdit{{Op: WordInserted, Text: "token"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords() = %+v, want %+v", got, want)
+	}
+}
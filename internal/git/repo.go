@@ -24,8 +24,48 @@ func IsGitRepository() bool {
 	return cmd.Run() == nil
 }
 
-// DetectRepo detects the GitHub repository from git remote
+// repoOverride is set from the --repo flag and takes precedence over detection
+var repoOverride string
+
+// SetRepoOverride makes DetectRepo return repo (owner/repo or a GitHub URL)
+func SetRepoOverride(repo string) {
+	repoOverride = strings.TrimSpace(repo)
+}
+
+// DetectRepo detects the GitHub repository.
+// Order: --repo override, git remote, .git/config read directly (no git binary
+// needed), then the GITHUB_REPOSITORY and CI_REPOSITORY_URL variables.
 func DetectRepo() (string, error) {
+	if repoOverride != "" {
+		return NormalizeRepo(repoOverride)
+	}
+
+	repo, gitErr := detectRepoWithGit()
+	if gitErr == nil {
+		return repo, nil
+	}
+
+	if remoteURL, err := readOriginFromGitConfig("."); err == nil {
+		return ParseGitHubURL(remoteURL)
+	}
+
+	if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+		return NormalizeRepo(repo)
+	}
+
+	if remoteURL := os.Getenv("CI_REPOSITORY_URL"); remoteURL != "" {
+		return ParseGitHubURL(stripURLCredentials(remoteURL))
+	}
+
+	return "", gitErr
+}
+
+// detectRepoWithGit reads the origin remote using the git binary
+func detectRepoWithGit() (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git not found in PATH (use --repo owner/repo)")
+	}
+
 	if !IsGitRepository() {
 		return "", fmt.Errorf("not in a git repository")
 	}
@@ -41,6 +81,148 @@ func DetectRepo() (string, error) {
 	return ParseGitHubURL(remoteURL)
 }
 
+// NormalizeRepo accepts "owner/repo" or a GitHub URL and returns "owner/repo"
+func NormalizeRepo(repo string) (string, error) {
+	repo = strings.TrimSpace(repo)
+	if strings.Contains(repo, "github.com") {
+		return ParseGitHubURL(stripURLCredentials(repo))
+	}
+
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid repository %q: expected owner/repo", repo)
+	}
+	return repo, nil
+}
+
+// stripURLCredentials removes user:token@ from an https URL
+func stripURLCredentials(remoteURL string) string {
+	scheme := strings.Index(remoteURL, "://")
+	if scheme == -1 {
+		return remoteURL
+	}
+	rest := remoteURL[scheme+3:]
+	slash := strings.Index(rest, "/")
+	at := strings.LastIndex(rest, "@")
+	if at == -1 || (slash != -1 && at > slash) {
+		return remoteURL
+	}
+	return remoteURL[:scheme+3] + rest[at+1:]
+}
+
+// readOriginFromGitConfig finds the repository's config file from dir upwards
+// and returns the origin remote URL, without invoking git
+func readOriginFromGitConfig(dir string) (string, error) {
+	configPath, err := findGitConfig(dir)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	inOrigin := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section := strings.Join(strings.Fields(strings.Trim(line, "[]")), " ")
+			inOrigin = section == `remote "origin"`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if found && strings.TrimSpace(key) == "url" {
+			return strings.Trim(strings.TrimSpace(value), `"`), nil
+		}
+	}
+
+	return "", fmt.Errorf("no remote origin in %s", configPath)
+}
+
+// findGitConfig locates .git/config, following "gitdir:" files used by worktrees and submodules
+func findGitConfig(dir string) (string, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// Worktrees keep the shared config in the common dir
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		gitDir = commonDir
+	}
+
+	return filepath.Join(gitDir, "config"), nil
+}
+
+// findGitDir locates the .git directory from dir upwards. For worktrees and
+// submodules it follows the "gitdir:" file to their own git dir.
+func findGitDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		info, err := os.Stat(gitPath)
+		if err == nil {
+			if info.IsDir() {
+				return gitPath, nil
+			}
+			return gitDirFromGitFile(dir, gitPath)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not in a git repository")
+		}
+		dir = parent
+	}
+}
+
+func gitDirFromGitFile(dir, gitFile string) (string, error) {
+	content, err := os.ReadFile(gitFile)
+	if err != nil {
+		return "", err
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(content)), "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return gitDir, nil
+}
+
+// readBranchFromGitHead returns the branch HEAD points to, without invoking git.
+// HEAD is per worktree, so it is read from the worktree's own git dir.
+func readBranchFromGitHead(dir string) (string, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+
+	branch, found := strings.CutPrefix(strings.TrimSpace(string(content)), "ref: refs/heads/")
+	if !found || branch == "" {
+		return "", fmt.Errorf("HEAD is not on a branch")
+	}
+	return branch, nil
+}
+
 // ParseGitHubURL extracts owner/repo from a GitHub URL
 func ParseGitHubURL(url string) (string, error) {
 	// Try SSH format
@@ -67,6 +249,41 @@ func GetGitRoot() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// CurrentBranch returns the checked out branch name, read from .git/HEAD when
+// git is not installed. On a detached HEAD (common in CI) it falls back to the
+// CI provider's branch variable.
+func CurrentBranch() (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "HEAD")
+	cmd.Stderr = nil
+	output, err := cmd.Output()
+	if err == nil {
+		if branch := strings.TrimSpace(string(output)); branch != "" {
+			return branch, nil
+		}
+	}
+
+	if branch, err := readBranchFromGitHead("."); err == nil {
+		return branch, nil
+	}
+
+	if branch := branchFromCIEnv(); branch != "" {
+		return branch, nil
+	}
+
+	return "", fmt.Errorf("could not determine current branch (detached HEAD?)")
+}
+
+// branchFromCIEnv reads the branch name set by common CI providers
+func branchFromCIEnv() string {
+	// GITHUB_HEAD_REF is only set for pull requests and holds the source branch
+	for _, name := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "CIRCLE_BRANCH", "BUILDKITE_BRANCH"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // CheckEnvGitignore checks if .env files are in .gitignore
 func CheckEnvGitignore() bool {
 	gitRoot, err := GetGitRoot()
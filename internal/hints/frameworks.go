@@ -0,0 +1,72 @@
+package hints
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Framework is a web framework recognized from a project's files
+type Framework struct {
+	Name string
+	// PublicPrefix marks the variables the framework bundles into client
+	// code, readable by anyone who loads the page ("" when there are none)
+	PublicPrefix string
+}
+
+var (
+	NextJS = Framework{Name: "Next.js", PublicPrefix: "NEXT_PUBLIC_"}
+	Vite   = Framework{Name: "Vite", PublicPrefix: "VITE_"}
+	Rails  = Framework{Name: "Rails"}
+	Django = Framework{Name: "Django"}
+)
+
+// DetectFrameworks returns the frameworks used by the project in dir
+func DetectFrameworks(dir string) []Framework {
+	var frameworks []Framework
+
+	deps := nodeDependencies(dir)
+	if deps["next"] || firstExisting(dir, []string{"next.config.js", "next.config.mjs", "next.config.ts"}) != "" {
+		frameworks = append(frameworks, NextJS)
+	}
+	if deps["vite"] || firstExisting(dir, []string{"vite.config.js", "vite.config.mjs", "vite.config.ts"}) != "" {
+		frameworks = append(frameworks, Vite)
+	}
+	if exists(dir, filepath.Join("config", "application.rb")) || fileContains(dir, "Gemfile", "rails") {
+		frameworks = append(frameworks, Rails)
+	}
+	if exists(dir, "manage.py") || fileContains(dir, "requirements.txt", "django") || fileContains(dir, "pyproject.toml", "django") {
+		frameworks = append(frameworks, Django)
+	}
+	return frameworks
+}
+
+// nodeDependencies returns the names in package.json's dependencies and devDependencies
+func nodeDependencies(dir string) map[string]bool {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	names := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return names
+	}
+	for name := range pkg.Dependencies {
+		names[name] = true
+	}
+	for name := range pkg.DevDependencies {
+		names[name] = true
+	}
+	return names
+}
+
+// fileContains reports whether the file mentions word, case-insensitively
+func fileContains(dir, name, word string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(data)), word)
+}
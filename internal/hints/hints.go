@@ -0,0 +1,155 @@
+// Package hints detects what kind of project a directory holds (Compose,
+// Node, Docker, Kubernetes) and suggests the keyway commands that fit it, with
+// examples tailored to the files found. The help and error output show them
+// instead of generic examples. It also recognizes web frameworks, whose
+// conventions keyway lint checks.
+package hints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Project is a kind of project recognized from its files
+type Project string
+
+const (
+	Compose    Project = "compose"
+	Node       Project = "node"
+	Docker     Project = "docker"
+	Kubernetes Project = "kubernetes"
+)
+
+// Tip is a suggested command with what it does
+type Tip struct {
+	Command     string
+	Description string
+}
+
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// kubernetesMarkers are files and directories that usually hold manifests
+var kubernetesMarkers = []string{"kustomization.yaml", "kustomization.yml", "Chart.yaml", "k8s", "kubernetes", "manifests", "helm", "charts"}
+
+// Detect returns the kinds of project found in dir, most specific first
+func Detect(dir string) []Project {
+	var projects []Project
+	if firstExisting(dir, composeFiles) != "" {
+		projects = append(projects, Compose)
+	}
+	if exists(dir, "package.json") {
+		projects = append(projects, Node)
+	}
+	if exists(dir, "Dockerfile") {
+		projects = append(projects, Docker)
+	}
+	if firstExisting(dir, kubernetesMarkers) != "" {
+		projects = append(projects, Kubernetes)
+	}
+	return projects
+}
+
+// For returns up to limit tips for the project in dir, nil when nothing is recognized
+func For(dir string, limit int) []Tip {
+	var tips []Tip
+	for _, project := range Detect(dir) {
+		tips = append(tips, tipsFor(dir, project)...)
+	}
+	if len(tips) > limit {
+		tips = tips[:limit]
+	}
+	return tips
+}
+
+func tipsFor(dir string, project Project) []Tip {
+	name := filepath.Base(absolute(dir))
+
+	switch project {
+	case Compose:
+		return []Tip{
+			{"keyway run -- docker compose up", "Interpolate ${VARS} in " + firstExisting(dir, composeFiles) + " from the vault"},
+		}
+	case Node:
+		manager, script := nodeRunner(dir)
+		return []Tip{
+			{fmt.Sprintf("keyway run -- %s", script), "Start the app with secrets in process.env, no .env on disk"},
+			{fmt.Sprintf("keyway run -e production -- %s", manager+" run build"), "Build with production secrets"},
+		}
+	case Docker:
+		return []Tip{
+			{fmt.Sprintf("keyway run -- docker run -e API_KEY %s", name), "Pass vault keys into the container by name"},
+		}
+	case Kubernetes:
+		return []Tip{
+			{"keyway pull -e production -f .env.production", "Write a file for kubectl create secret generic " + name + " --from-env-file"},
+		}
+	}
+	return nil
+}
+
+// DevCommand returns the command that starts the project in dir for local
+// development: docker compose up with a compose file, else the Node dev
+// script. It is empty when nothing is recognized.
+func DevCommand(dir string) string {
+	if firstExisting(dir, composeFiles) != "" {
+		return "docker compose up"
+	}
+	if exists(dir, "package.json") {
+		_, command := nodeRunner(dir)
+		return command
+	}
+	return ""
+}
+
+// nodeRunner returns the package manager of a Node project (from its lockfile)
+// and the command that starts it in development
+func nodeRunner(dir string) (manager, command string) {
+	manager = "npm"
+	switch {
+	case exists(dir, "pnpm-lock.yaml"):
+		manager = "pnpm"
+	case exists(dir, "yarn.lock"):
+		manager = "yarn"
+	case exists(dir, "bun.lockb"), exists(dir, "bun.lock"):
+		manager = "bun"
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		_ = json.Unmarshal(data, &pkg)
+	}
+	for _, script := range []string{"dev", "start"} {
+		if _, ok := pkg.Scripts[script]; ok {
+			if manager == "npm" && script == "start" {
+				return manager, "npm start"
+			}
+			return manager, manager + " run " + script
+		}
+	}
+	return manager, manager + " start"
+}
+
+func exists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+func firstExisting(dir string, names []string) string {
+	for _, name := range names {
+		if exists(dir, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func absolute(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
@@ -0,0 +1,124 @@
+package hints
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yaml":      "services: {}",
+		"package.json":      "{}",
+		"Dockerfile":        "FROM node",
+		"k8s/app.yaml":      "kind: Deployment",
+		"unrelated/file.md": "",
+	})
+
+	want := []Project{Compose, Node, Docker, Kubernetes}
+	if got := Detect(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+	if got := Detect(t.TempDir()); got != nil {
+		t.Errorf("expected nothing in an empty directory, got %v", got)
+	}
+}
+
+func TestFor_Node(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"npm dev", map[string]string{"package.json": `{"scripts": {"dev": "vite"}}`}, "keyway run -- npm run dev"},
+		{"npm start", map[string]string{"package.json": `{"scripts": {"start": "node ."}}`}, "keyway run -- npm start"},
+		{"pnpm", map[string]string{"package.json": `{"scripts": {"dev": "next"}}`, "pnpm-lock.yaml": ""}, "keyway run -- pnpm run dev"},
+		{"no scripts", map[string]string{"package.json": `{}`, "yarn.lock": ""}, "keyway run -- yarn start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tips := For(writeFiles(t, tt.files), 3)
+			if len(tips) == 0 || tips[0].Command != tt.want {
+				t.Errorf("expected %q first, got %v", tt.want, tips)
+			}
+		})
+	}
+}
+
+func TestDevCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"compose first", map[string]string{"compose.yaml": "services: {}", "package.json": `{"scripts": {"dev": "vite"}}`}, "docker compose up"},
+		{"node", map[string]string{"package.json": `{"scripts": {"dev": "vite"}}`, "bun.lock": ""}, "bun run dev"},
+		{"nothing", map[string]string{"Dockerfile": "FROM node"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DevCommand(writeFiles(t, tt.files)); got != tt.want {
+				t.Errorf("DevCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFor_Limit(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"docker-compose.yml": "services: {}",
+		"package.json":       "{}",
+		"Dockerfile":         "FROM node",
+	})
+
+	tips := For(dir, 3)
+	if len(tips) != 3 {
+		t.Fatalf("expected 3 tips, got %v", tips)
+	}
+	if tips[0].Command != "keyway run -- docker compose up" {
+		t.Errorf("expected the compose tip first, got %v", tips[0])
+	}
+	if For(t.TempDir(), 3) != nil {
+		t.Error("expected no tips for an unrecognized project")
+	}
+}
+
+func TestDetectFrameworks(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Framework
+	}{
+		{"next dependency", map[string]string{"package.json": `{"dependencies": {"next": "14.0.0"}}`}, []Framework{NextJS}},
+		{"vite config", map[string]string{"package.json": `{}`, "vite.config.ts": ""}, []Framework{Vite}},
+		{"rails", map[string]string{"Gemfile": "gem 'rails', '~> 7.1'"}, []Framework{Rails}},
+		{"django", map[string]string{"requirements.txt": "Django==5.0\n"}, []Framework{Django}},
+		{"none", map[string]string{"package.json": `{"dependencies": {"express": "4"}}`}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFrameworks(writeFiles(t, tt.files)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectFrameworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package injector
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// Exec replaces the current process with the command, started from environ
+// plus the secrets. It only returns on failure. As a container entrypoint, the
+// command takes over the process ID and receives signals directly.
+func Exec(command string, args []string, environ []string, secrets map[string]string) error {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	if err := syscall.Exec(path, append([]string{command}, args...), buildEnv(environ, secrets)); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package injector
+
+// Exec runs the command and exits with its status: Windows cannot replace
+// the current process.
+func Exec(command string, args []string, environ []string, secrets map[string]string) error {
+	return RunCommandWithEnv(command, args, environ, secrets)
+}
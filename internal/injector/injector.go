@@ -2,6 +2,7 @@ package injector
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -11,26 +12,122 @@ import (
 // RunCommand executes a command with the provided secrets injected into the environment.
 // It handles signal forwarding and exit code propagation.
 func RunCommand(command string, args []string, secrets map[string]string) error {
-	// Prepare the command
-	cmd := exec.Command(command, args...)
+	code, err := Run(command, args, secrets)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
 
-	// Connect standard input/output
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// RunCommandWithEnv is like RunCommand but starts the child from environ
+// (KEY=VALUE entries) instead of the current process environment.
+func RunCommandWithEnv(command string, args []string, environ []string, secrets map[string]string) error {
+	code, err := run(command, args, environ, secrets)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// RunCommandWithLog is like RunCommandWithEnv but also writes the command's
+// stdout and stderr to logPath, with secret values masked, while still
+// streaming them to the terminal.
+func RunCommandWithLog(command string, args []string, environ []string, secrets map[string]string, logPath string) error {
+	code, err := runWithLog(command, args, environ, secrets, logPath)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// Run is like RunCommand but returns the command's exit code instead of exiting,
+// so callers can clean up first.
+func Run(command string, args []string, secrets map[string]string) (int, error) {
+	return run(command, args, os.Environ(), secrets)
+}
+
+func run(command string, args []string, environ []string, secrets map[string]string) (int, error) {
+	return start(command, args, environ, secrets, os.Stdin, os.Stdout, os.Stderr)
+}
+
+// RunCaptured runs a command without a terminal and writes its stdout and
+// stderr to out, secret values masked. It returns the exit code.
+func RunCaptured(command string, args []string, environ []string, secrets map[string]string, out io.Writer) (int, error) {
+	locked := &lockedWriter{w: out}
+	redactor := newRedactor(secrets)
+	stdout := &redactingWriter{out: locked, replacer: redactor}
+	stderr := &redactingWriter{out: locked, replacer: redactor}
+
+	code, err := start(command, args, environ, secrets, nil, stdout, stderr)
 
-	// Build the environment
-	// Start with current environment
-	currentEnv := os.Environ()
-	newEnv := make([]string, 0, len(currentEnv)+len(secrets))
-	newEnv = append(newEnv, currentEnv...)
+	_ = stdout.Close()
+	_ = stderr.Close()
+	return code, err
+}
+
+func runWithLog(command string, args []string, environ []string, secrets map[string]string, logPath string) (int, error) {
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	out := &lockedWriter{w: f}
+	redactor := newRedactor(secrets)
+	// One writer per stream so partial lines from stdout and stderr don't mix
+	stdoutLog := &redactingWriter{out: out, replacer: redactor}
+	stderrLog := &redactingWriter{out: out, replacer: redactor}
+
+	code, runErr := start(command, args, environ, secrets, os.Stdin,
+		io.MultiWriter(os.Stdout, stdoutLog), io.MultiWriter(os.Stderr, stderrLog))
 
-	// Append secrets
+	_ = stdoutLog.Close()
+	_ = stderrLog.Close()
+	if err := f.Close(); err != nil && out.err == nil {
+		out.err = err
+	}
+	// The command's exit code matters more than the log: report and carry on
+	if out.err != nil {
+		fmt.Fprintf(os.Stderr, "keyway: failed to write %s: %v\n", logPath, out.err)
+	}
+	return code, runErr
+}
+
+// buildEnv returns environ followed by the secrets as KEY=VALUE entries
+func buildEnv(environ []string, secrets map[string]string) []string {
+	newEnv := make([]string, 0, len(environ)+len(secrets))
+	newEnv = append(newEnv, environ...)
 	for k, v := range secrets {
 		newEnv = append(newEnv, fmt.Sprintf("%s=%s", k, v))
 	}
-	cmd.Env = newEnv
+	return newEnv
+}
 
+func start(command string, args []string, environ []string, secrets map[string]string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	// Prepare the command
+	cmd := exec.Command(command, args...)
+
+	// Connect standard input/output
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	cmd.Env = buildEnv(environ, secrets)
+
+	return forwardAndWait(cmd)
+}
+
+// forwardAndWait starts cmd, forwards the signals keyway receives to it, and
+// returns its exit code
+func forwardAndWait(cmd *exec.Cmd) (int, error) {
 	// Handle signals
 	sigs := make(chan os.Signal, 1)
 	// Notify on all common signals
@@ -38,7 +135,8 @@ func RunCommand(command string, args []string, secrets map[string]string) error
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start command: %w", err)
+		signal.Stop(sigs)
+		return 0, fmt.Errorf("failed to start command: %w", err)
 	}
 
 	// Forward signals to the child process
@@ -52,15 +150,21 @@ func RunCommand(command string, args []string, secrets map[string]string) error
 
 	// Wait for the command to finish
 	err := cmd.Wait()
+	signal.Stop(sigs)
+	close(sigs)
 
 	// Handle exit code
 	if exitError, ok := err.(*exec.ExitError); ok {
 		// The process exited with a non-zero status
 		if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-			os.Exit(status.ExitStatus())
+			// Like a shell, report a command killed by a signal as 128+signal
+			if status.Signaled() {
+				return 128 + int(status.Signal()), nil
+			}
+			return status.ExitStatus(), nil
 		}
-		os.Exit(1)
+		return 1, nil
 	}
 
-	return err
+	return 0, err
 }
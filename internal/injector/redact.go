@@ -0,0 +1,97 @@
+package injector
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minRedactLength is the shortest value redacted from logs. Shorter values
+// ("1", "true", "dev") would mask unrelated output.
+const minRedactLength = 4
+
+// maxPendingLine is how much output without a line break is buffered before
+// it is written anyway
+const maxPendingLine = 64 * 1024
+
+// newRedactor returns a replacer masking every secret value with ***,
+// longest values first so overlapping values are fully masked. Output is
+// redacted line by line, so each line of a multi-line value (a PEM key, a
+// JSON service account) is masked on its own too.
+func newRedactor(secrets map[string]string) *strings.Replacer {
+	seen := make(map[string]bool)
+	var values []string
+	add := func(v string) {
+		if len(v) >= minRedactLength && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	for _, v := range secrets {
+		add(v)
+		if strings.ContainsAny(v, "\r\n") {
+			for _, line := range strings.FieldsFunc(v, func(r rune) bool { return r == '\r' || r == '\n' }) {
+				add(strings.TrimSpace(line))
+			}
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, "***")
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// lockedWriter serializes writes from the stdout and stderr copiers
+type lockedWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		_, l.err = l.w.Write(p)
+	}
+	return len(p), nil
+}
+
+// redactingWriter masks secret values line by line, so a value split across
+// two writes is still masked. It never fails, to keep the terminal stream
+// going: write errors are kept by the underlying lockedWriter.
+type redactingWriter struct {
+	out      *lockedWriter
+	replacer *strings.Replacer
+	pending  []byte
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	r.pending = append(r.pending, p...)
+	if i := bytes.LastIndexAny(r.pending, "\r\n"); i >= 0 {
+		r.flush(i + 1)
+	}
+	if len(r.pending) > maxPendingLine {
+		r.flush(len(r.pending))
+	}
+	return len(p), nil
+}
+
+// Close writes any output left without a final line break
+func (r *redactingWriter) Close() error {
+	r.flush(len(r.pending))
+	return nil
+}
+
+func (r *redactingWriter) flush(n int) {
+	if n == 0 {
+		return
+	}
+	_, _ = r.out.Write([]byte(r.replacer.Replace(string(r.pending[:n]))))
+	r.pending = append(r.pending[:0], r.pending[n:]...)
+}
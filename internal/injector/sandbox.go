@@ -0,0 +1,18 @@
+package injector
+
+import "errors"
+
+// Sandbox restricts what a command can reach besides its secrets, so a script
+// run with them cannot also read keyway's own credentials. It relies on Linux
+// user and mount namespaces.
+type Sandbox struct {
+	// Hide lists directories the command sees as empty
+	Hide []string
+	// PrivateTmp gives the command an empty /tmp of its own
+	PrivateTmp bool
+	// DenyNetwork leaves the command with an unconfigured loopback interface only
+	DenyNetwork bool
+}
+
+// ErrSandboxUnsupported is returned by Sandbox methods outside Linux
+var ErrSandboxUnsupported = errors.New("sandboxing is only supported on Linux")
@@ -0,0 +1,92 @@
+//go:build linux
+
+package injector
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Linux constants missing from the syscall package
+const (
+	capSysAdmin          = 21
+	prSetNoNewPrivs      = 38
+	prSetSecurebits      = 28
+	prCapAmbient         = 47
+	prCapAmbientClearAll = 4
+	// SECBIT_NOROOT and SECBIT_NOROOT_LOCKED: uid 0 gains no capabilities on exec
+	secbitNoRootLocked = 1<<0 | 1<<1
+)
+
+// Start runs self (the keyway executable) with initArgs in new user and mount
+// namespaces, plus a network namespace when DenyNetwork is set, and returns
+// its exit code. initArgs must make that process call Enter.
+func (s Sandbox) Start(self string, initArgs []string) (int, error) {
+	cmd := exec.Command(self, initArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	flags := syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS
+	if s.DenyNetwork {
+		flags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: uintptr(flags),
+		// Same user and group inside, so file permissions are unchanged
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		// Needed by Enter for the mounts, dropped before the command runs
+		AmbientCaps: []uintptr{capSysAdmin},
+	}
+
+	code, err := forwardAndWait(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("%w (user namespaces may be disabled on this system)", err)
+	}
+	return code, nil
+}
+
+// Enter sets up the sandbox's mounts from inside the namespaces created by
+// Start, drops every capability, then replaces the process with the command.
+// It only returns on failure.
+func (s Sandbox) Enter(command string, args []string) error {
+	// Keep the mounts below out of the parent namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("sandbox: %w", err)
+	}
+	for _, dir := range s.Hide {
+		if err := syscall.Mount("tmpfs", dir, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size=64k,mode=0700"); err != nil {
+			return fmt.Errorf("sandbox: hiding %s: %w", dir, err)
+		}
+	}
+	if s.PrivateTmp {
+		if err := syscall.Mount("tmpfs", "/tmp", "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777"); err != nil {
+			return fmt.Errorf("sandbox: private /tmp: %w", err)
+		}
+	}
+
+	// Without capabilities the command cannot undo the mounts
+	if os.Getuid() == 0 {
+		if err := prctl(prSetSecurebits, secbitNoRootLocked); err != nil {
+			return fmt.Errorf("sandbox: %w", err)
+		}
+	}
+	if err := prctl(prCapAmbient, prCapAmbientClearAll); err != nil {
+		return fmt.Errorf("sandbox: %w", err)
+	}
+	if err := prctl(prSetNoNewPrivs, 1); err != nil {
+		return fmt.Errorf("sandbox: %w", err)
+	}
+
+	return Exec(command, args, os.Environ(), nil)
+}
+
+func prctl(option, arg uintptr) error {
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, option, arg, 0, 0, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package injector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The test binary doubles as the process Sandbox.Start launches
+func TestMain(m *testing.M) {
+	if hide := os.Getenv("KEYWAY_TEST_SANDBOX_HIDE"); hide != "" {
+		err := Sandbox{Hide: []string{hide}, PrivateTmp: true, DenyNetwork: true}.Enter(os.Args[1], os.Args[2:])
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(125)
+	}
+	os.Exit(m.Run())
+}
+
+func TestSandbox_HidesDirectories(t *testing.T) {
+	hidden := t.TempDir()
+	if err := os.WriteFile(filepath.Join(hidden, "config.json"), []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KEYWAY_TEST_SANDBOX_HIDE", hidden)
+
+	script := "test ! -e " + filepath.Join(hidden, "config.json") + " && test -z \"$(ls /tmp)\""
+	code, err := Sandbox{DenyNetwork: true}.Start(os.Args[0], []string{"sh", "-c", script})
+	if err != nil {
+		if strings.Contains(err.Error(), "user namespaces") {
+			t.Skipf("sandbox unavailable: %v", err)
+		}
+		t.Fatal(err)
+	}
+	if code != 0 {
+		t.Fatalf("expected the directory to be hidden inside the sandbox, exit code %d", code)
+	}
+
+	if _, err := os.Stat(filepath.Join(hidden, "config.json")); err != nil {
+		t.Errorf("the file should be untouched outside the sandbox: %v", err)
+	}
+}
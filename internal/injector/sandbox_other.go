@@ -0,0 +1,13 @@
+//go:build !linux
+
+package injector
+
+// Start is only supported on Linux
+func (s Sandbox) Start(self string, initArgs []string) (int, error) {
+	return 0, ErrSandboxUnsupported
+}
+
+// Enter is only supported on Linux
+func (s Sandbox) Enter(command string, args []string) error {
+	return ErrSandboxUnsupported
+}
@@ -0,0 +1,114 @@
+// Package jobs runs per-item operations (one call per environment, key or
+// provider) on a bounded pool of workers, with retries, progress reporting and
+// a summary of partial failures.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultWorkers is the pool size used when Options.Workers is not set.
+// Kept small to stay well below API rate limits.
+const DefaultWorkers = 4
+
+// Options configures Run
+type Options struct {
+	// Workers is the maximum number of items processed at once
+	Workers int
+	// Retry wraps each call, e.g. api.WithRetry. Nil means no retry.
+	Retry func(ctx context.Context, fn func() error) error
+	// OnProgress is called after each item completes, from a single goroutine at a time
+	OnProgress func(done, total int)
+}
+
+// Result is the outcome of one item
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Summary holds the results of a Run, in the order the items were given
+type Summary struct {
+	Results []Result
+}
+
+// Failed returns the results that have an error
+func (s *Summary) Failed() []Result {
+	var failed []Result
+	for _, r := range s.Results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Err returns nil if every item succeeded, or an error listing the failures
+func (s *Summary) Err() error {
+	failed := s.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	parts := make([]string, len(failed))
+	for i, r := range failed {
+		parts[i] = fmt.Sprintf("%s: %v", r.Name, r.Err)
+	}
+	return fmt.Errorf("%d of %d failed: %s", len(failed), len(s.Results), strings.Join(parts, "; "))
+}
+
+// Run calls fn for each name using at most opts.Workers goroutines.
+// An item failing does not stop the others. Once ctx is cancelled, items not
+// yet started are not run and report ctx.Err().
+func Run(ctx context.Context, names []string, fn func(ctx context.Context, name string) error, opts Options) *Summary {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if workers > len(names) {
+		workers = len(names)
+	}
+
+	summary := &Summary{Results: make([]Result, len(names))}
+	indexes := make(chan int)
+	var progressMu sync.Mutex
+	done := 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				name := names[i]
+				var err error
+				if err = ctx.Err(); err == nil {
+					call := func() error { return fn(ctx, name) }
+					if opts.Retry != nil {
+						err = opts.Retry(ctx, call)
+					} else {
+						err = call()
+					}
+				}
+				summary.Results[i] = Result{Name: name, Err: err}
+
+				if opts.OnProgress != nil {
+					progressMu.Lock()
+					done++
+					opts.OnProgress(done, len(names))
+					progressMu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range names {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return summary
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRun_PartialFailure(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	var calls atomic.Int32
+
+	summary := Run(context.Background(), names, func(ctx context.Context, name string) error {
+		calls.Add(1)
+		if name == "b" || name == "d" {
+			return errors.New("boom")
+		}
+		return nil
+	}, Options{Workers: 2})
+
+	if calls.Load() != 5 {
+		t.Errorf("expected every item to run, got %d calls", calls.Load())
+	}
+	for i, r := range summary.Results {
+		if r.Name != names[i] {
+			t.Errorf("results out of order: %v", summary.Results)
+		}
+	}
+	if len(summary.Failed()) != 2 {
+		t.Errorf("expected 2 failures, got %v", summary.Failed())
+	}
+	err := summary.Err()
+	if err == nil || !strings.Contains(err.Error(), "2 of 5 failed") || !strings.Contains(err.Error(), "b: boom") {
+		t.Errorf("unexpected summary error: %v", err)
+	}
+}
+
+func TestRun_BoundedWorkers(t *testing.T) {
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = string(rune('a' + i))
+	}
+
+	go func() {
+		for running.Load() < 3 {
+			runtime.Gosched()
+		}
+		close(release)
+	}()
+
+	Run(context.Background(), names, func(ctx context.Context, name string) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	}, Options{Workers: 3})
+
+	if peak.Load() != 3 {
+		t.Errorf("expected at most 3 concurrent workers, peak was %d", peak.Load())
+	}
+}
+
+func TestRun_RetryAndProgress(t *testing.T) {
+	var retried atomic.Int32
+	var progress []int
+
+	summary := Run(context.Background(), []string{"a", "b"}, func(ctx context.Context, name string) error {
+		return nil
+	}, Options{
+		Retry: func(ctx context.Context, fn func() error) error {
+			retried.Add(1)
+			return fn()
+		},
+		OnProgress: func(done, total int) {
+			if total != 2 {
+				t.Errorf("expected total 2, got %d", total)
+			}
+			progress = append(progress, done)
+		},
+	})
+
+	if summary.Err() != nil {
+		t.Errorf("unexpected error: %v", summary.Err())
+	}
+	if retried.Load() != 2 {
+		t.Errorf("expected Retry to wrap each call, got %d", retried.Load())
+	}
+	if len(progress) != 2 || progress[1] != 2 {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+
+	summary := Run(ctx, []string{"a", "b", "c", "d"}, func(ctx context.Context, name string) error {
+		calls.Add(1)
+		cancel()
+		return nil
+	}, Options{Workers: 1})
+
+	if calls.Load() != 1 {
+		t.Errorf("expected remaining items to be skipped, got %d calls", calls.Load())
+	}
+	if failed := summary.Failed(); len(failed) != 3 || !errors.Is(failed[0].Err, context.Canceled) {
+		t.Errorf("expected 3 cancelled items, got %v", failed)
+	}
+}
+
+func TestRun_Empty(t *testing.T) {
+	summary := Run(context.Background(), nil, func(ctx context.Context, name string) error {
+		t.Error("fn should not be called")
+		return nil
+	}, Options{})
+	if len(summary.Results) != 0 || summary.Err() != nil {
+		t.Errorf("unexpected summary %v", summary)
+	}
+}
@@ -0,0 +1,44 @@
+package namecache
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// LastEnvironmentPath returns the path of the file remembering the
+// environment last chosen at a prompt for each repository
+func LastEnvironmentPath() (string, error) {
+	return state.Path("last-environment.json")
+}
+
+// LoadLastEnvironment returns the environment last chosen for repo, or ""
+func LoadLastEnvironment(path, repo string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var last map[string]string
+	if err := json.Unmarshal(data, &last); err != nil {
+		return "", err
+	}
+	return last[repo], nil
+}
+
+// SaveLastEnvironment records envName as the environment last chosen for repo
+func SaveLastEnvironment(path, repo, envName string) error {
+	return state.Update(path, 0600, func(current []byte) ([]byte, error) {
+		last := make(map[string]string)
+		if len(current) > 0 {
+			// A corrupt file only loses the last choices, start over
+			_ = json.Unmarshal(current, &last)
+		}
+		last[repo] = envName
+		return json.MarshalIndent(last, "", "  ")
+	})
+}
@@ -0,0 +1,67 @@
+// Package namecache keeps the environment and key names of each repository's
+// vault on disk, so shell completion can offer them without calling the API,
+// and the environment last chosen at a prompt. It never contains secret values.
+package namecache
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// MaxAge is how long names are used before a background refresh is started
+const MaxAge = 10 * time.Minute
+
+// Entry holds the names of one repository's vault
+type Entry struct {
+	Environments []string            `json:"environments"`
+	Keys         map[string][]string `json:"keys"`
+	RefreshedAt  time.Time           `json:"refreshedAt"`
+}
+
+// Stale reports whether the entry is older than MaxAge
+func (e *Entry) Stale(now time.Time) bool {
+	return now.Sub(e.RefreshedAt) > MaxAge
+}
+
+// DefaultPath returns the path of the cache file
+func DefaultPath() (string, error) {
+	return state.Path("names.json")
+}
+
+// Load returns the entry recorded for repo, and nil if there is none
+func Load(path, repo string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries[repo], nil
+}
+
+// Save records the entry for repo, with its names sorted
+func Save(path, repo string, entry *Entry) error {
+	sort.Strings(entry.Environments)
+	for _, keys := range entry.Keys {
+		sort.Strings(keys)
+	}
+	return state.Update(path, 0600, func(current []byte) ([]byte, error) {
+		entries := make(map[string]*Entry)
+		if len(current) > 0 {
+			// A corrupt file only loses cached names, start over
+			_ = json.Unmarshal(current, &entries)
+		}
+		entries[repo] = entry
+		return json.MarshalIndent(entries, "", "  ")
+	})
+}
@@ -0,0 +1,72 @@
+package namecache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.json")
+
+	if entry, err := Load(path, "acme/api"); err != nil || entry != nil {
+		t.Fatalf("expected no entry before saving, got %+v, %v", entry, err)
+	}
+
+	err := Save(path, "acme/api", &Entry{
+		Environments: []string{"staging", "development"},
+		Keys:         map[string][]string{"staging": {"PORT", "API_KEY"}},
+		RefreshedAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := Save(path, "acme/web", &Entry{Environments: []string{"production"}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	entry, err := Load(path, "acme/api")
+	if err != nil || entry == nil {
+		t.Fatalf("load: %+v, %v", entry, err)
+	}
+	if strings.Join(entry.Environments, ",") != "development,staging" {
+		t.Errorf("expected sorted environments, got %v", entry.Environments)
+	}
+	if strings.Join(entry.Keys["staging"], ",") != "API_KEY,PORT" {
+		t.Errorf("expected sorted keys, got %v", entry.Keys["staging"])
+	}
+	if other, _ := Load(path, "acme/web"); other == nil || other.Environments[0] != "production" {
+		t.Errorf("expected the other repository to be kept, got %+v", other)
+	}
+}
+
+func TestEntryStale(t *testing.T) {
+	now := time.Now()
+	if (&Entry{RefreshedAt: now.Add(-time.Minute)}).Stale(now) {
+		t.Error("expected a recent entry to be fresh")
+	}
+	if !(&Entry{RefreshedAt: now.Add(-MaxAge - time.Second)}).Stale(now) {
+		t.Error("expected an old entry to be stale")
+	}
+}
+
+func TestLastEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-environment.json")
+
+	if envName, err := LoadLastEnvironment(path, "acme/api"); err != nil || envName != "" {
+		t.Fatalf("expected no environment before saving, got %q, %v", envName, err)
+	}
+	if err := SaveLastEnvironment(path, "acme/api", "staging"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := SaveLastEnvironment(path, "acme/web", "production"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if envName, _ := LoadLastEnvironment(path, "acme/api"); envName != "staging" {
+		t.Errorf("expected staging, got %q", envName)
+	}
+	if envName, _ := LoadLastEnvironment(path, "acme/web"); envName != "production" {
+		t.Errorf("expected production, got %q", envName)
+	}
+}
@@ -0,0 +1,228 @@
+// Package offline keeps the last pulled content of each environment on disk,
+// encrypted, so commands can still run when the API is unreachable.
+//
+// Entries are sealed with AES-GCM under a key derived from a random secret
+// kept next to the cache, readable by the owner only. The environment is bound
+// to each entry, so an entry copied to another environment fails to open.
+package offline
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// Cache is the offline cache of pulled environments
+type Cache struct {
+	path    string
+	keyPath string
+}
+
+// Entry is a cached environment
+type Entry struct {
+	Content string
+	SavedAt time.Time
+}
+
+// sealedEntry is an Entry as stored on disk
+type sealedEntry struct {
+	Sealed  []byte    `json:"sealed"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// New returns the cache stored in path, with its secret in keyPath
+func New(path, keyPath string) *Cache {
+	return &Cache{path: path, keyPath: keyPath}
+}
+
+// Default returns the cache in the state directory
+func Default() (*Cache, error) {
+	path, err := state.Path("offline.json")
+	if err != nil {
+		return nil, err
+	}
+	keyPath, err := state.Path("offline.key")
+	if err != nil {
+		return nil, err
+	}
+	return New(path, keyPath), nil
+}
+
+// Save replaces the entry of an environment
+func (c *Cache) Save(repo, env, content string, now time.Time) error {
+	aead, err := c.aead(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	entry := sealedEntry{
+		Sealed:  aead.Seal(nonce, nonce, []byte(content), additionalData(repo, env)),
+		SavedAt: now.UTC(),
+	}
+
+	return state.Update(c.path, 0600, func(current []byte) ([]byte, error) {
+		entries := make(map[string]map[string]sealedEntry)
+		if len(current) > 0 {
+			// A corrupt file only loses cached environments, start over
+			_ = json.Unmarshal(current, &entries)
+		}
+		if entries[repo] == nil {
+			entries[repo] = make(map[string]sealedEntry)
+		}
+		entries[repo][env] = entry
+		return json.MarshalIndent(entries, "", "  ")
+	})
+}
+
+// Load returns the entry of an environment, and nil if there is none
+func (c *Cache) Load(repo, env string) (*Entry, error) {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]map[string]sealedEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	sealed, ok := entries[repo][env]
+	if !ok {
+		return nil, nil
+	}
+
+	aead, err := c.aead(false)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(sealed.Sealed) < nonceSize {
+		return nil, errors.New("corrupt offline cache entry")
+	}
+	content, err := aead.Open(nil, sealed.Sealed[:nonceSize], sealed.Sealed[nonceSize:], additionalData(repo, env))
+	if err != nil {
+		return nil, errors.New("cannot decrypt offline cache entry")
+	}
+	return &Entry{Content: string(content), SavedAt: sealed.SavedAt}, nil
+}
+
+// Clear removes every cached environment
+func (c *Cache) Clear() error {
+	return state.WithLock(c.path, func() error {
+		if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Prune removes the entries saved before cutoff, and the file once it is
+// empty. It returns how many entries were removed and the bytes freed; with
+// dryRun, what would be removed, leaving the file untouched.
+func (c *Cache) Prune(cutoff time.Time, dryRun bool) (int, int64, error) {
+	removed, freed := 0, int64(0)
+	err := state.WithLock(c.path, func() error {
+		data, err := os.ReadFile(c.path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var entries map[string]map[string]sealedEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			// Unreadable, so nothing in it can be served
+			removed, freed = 1, int64(len(data))
+			if dryRun {
+				return nil
+			}
+			return os.Remove(c.path)
+		}
+		for repo, envs := range entries {
+			for env, entry := range envs {
+				if entry.SavedAt.Before(cutoff) {
+					delete(envs, env)
+					removed++
+				}
+			}
+			if len(envs) == 0 {
+				delete(entries, repo)
+			}
+		}
+		if removed == 0 {
+			return nil
+		}
+
+		if len(entries) == 0 {
+			freed = int64(len(data))
+			if dryRun {
+				return nil
+			}
+			return os.Remove(c.path)
+		}
+		next, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		freed = int64(len(data) - len(next))
+		if dryRun {
+			return nil
+		}
+		return state.WriteFile(c.path, next, 0600)
+	})
+	return removed, freed, err
+}
+
+// aead returns the cipher of the cache, creating its secret when create is set
+func (c *Cache) aead(create bool) (cipher.AEAD, error) {
+	secret, err := os.ReadFile(c.keyPath)
+	if os.IsNotExist(err) && create {
+		secret, err = c.createSecret()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256(append([]byte("keyway offline cache v1\x00"), secret...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// createSecret writes a random secret to keyPath, keeping the one another
+// process may have written first
+func (c *Cache) createSecret() ([]byte, error) {
+	var secret []byte
+	err := state.Update(c.keyPath, 0600, func(current []byte) ([]byte, error) {
+		if len(current) > 0 {
+			secret = current
+			return current, nil
+		}
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	})
+	return secret, err
+}
+
+// additionalData binds a sealed entry to its environment
+func additionalData(repo, env string) []byte {
+	return []byte(repo + "\x00" + env)
+}
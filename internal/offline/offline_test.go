@@ -0,0 +1,131 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T) *Cache {
+	dir := t.TempDir()
+	return New(filepath.Join(dir, "offline.json"), filepath.Join(dir, "offline.key"))
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	cache := newTestCache(t)
+
+	if entry, err := cache.Load("acme/api", "production"); err != nil || entry != nil {
+		t.Fatalf("expected no entry before saving, got %+v, %v", entry, err)
+	}
+
+	savedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := cache.Save("acme/api", "production", "API_KEY=secret\n", savedAt); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := cache.Save("acme/api", "staging", "API_KEY=other\n", savedAt); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	entry, err := cache.Load("acme/api", "production")
+	if err != nil || entry == nil {
+		t.Fatalf("expected an entry, got %+v, %v", entry, err)
+	}
+	if entry.Content != "API_KEY=secret\n" || !entry.SavedAt.Equal(savedAt) {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	// Values are never stored in clear
+	data, _ := os.ReadFile(cache.path)
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected values encrypted, got %s", data)
+	}
+	if info, err := os.Stat(cache.keyPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected an owner-only key file, got %v, %v", info, err)
+	}
+}
+
+func TestLoad_EntryBoundToEnvironment(t *testing.T) {
+	cache := newTestCache(t)
+	if err := cache.Save("acme/api", "production", "API_KEY=secret", time.Now()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// Move the production entry to staging
+	data, _ := os.ReadFile(cache.path)
+	data = []byte(strings.Replace(string(data), `"production"`, `"staging"`, 1))
+	if err := os.WriteFile(cache.path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Load("acme/api", "staging"); err == nil {
+		t.Error("expected a moved entry to fail to open")
+	}
+}
+
+func TestLoad_OtherKey(t *testing.T) {
+	cache := newTestCache(t)
+	if err := cache.Save("acme/api", "production", "API_KEY=secret", time.Now()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.WriteFile(cache.keyPath, []byte("another secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Load("acme/api", "production"); err == nil {
+		t.Error("expected an error with another key")
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := newTestCache(t)
+	if err := cache.Save("acme/api", "production", "API_KEY=secret", time.Now()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if entry, err := cache.Load("acme/api", "production"); err != nil || entry != nil {
+		t.Errorf("expected no entry after clearing, got %+v, %v", entry, err)
+	}
+	if err := cache.Clear(); err != nil {
+		t.Errorf("expected clearing twice to succeed, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	cache := newTestCache(t)
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	_ = cache.Save("acme/api", "production", "API_KEY=old", now.Add(-48*time.Hour))
+	_ = cache.Save("acme/api", "staging", "API_KEY=new", now.Add(-time.Hour))
+	_ = cache.Save("acme/web", "production", "API_KEY=old", now.Add(-72*time.Hour))
+
+	removed, freed, err := cache.Prune(now.Add(-24*time.Hour), true)
+	if err != nil || removed != 2 || freed <= 0 {
+		t.Fatalf("expected 2 entries in a dry run, got %d, %d, %v", removed, freed, err)
+	}
+	if entry, _ := cache.Load("acme/api", "production"); entry == nil {
+		t.Fatal("a dry run must not remove entries")
+	}
+
+	if removed, _, err := cache.Prune(now.Add(-24*time.Hour), false); err != nil || removed != 2 {
+		t.Fatalf("expected 2 entries removed, got %d, %v", removed, err)
+	}
+	if entry, _ := cache.Load("acme/api", "production"); entry != nil {
+		t.Error("expected the expired entry removed")
+	}
+	if entry, _ := cache.Load("acme/api", "staging"); entry == nil || entry.Content != "API_KEY=new" {
+		t.Errorf("expected the fresh entry kept, got %+v", entry)
+	}
+
+	if removed, _, err := cache.Prune(now, false); err != nil || removed != 1 {
+		t.Fatalf("expected the last entry removed, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(cache.path); !os.IsNotExist(err) {
+		t.Errorf("expected the empty cache file removed, got %v", err)
+	}
+	if removed, freed, err := cache.Prune(now, false); err != nil || removed != 0 || freed != 0 {
+		t.Errorf("expected nothing to prune, got %d, %d, %v", removed, freed, err)
+	}
+}
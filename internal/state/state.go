@@ -0,0 +1,155 @@
+// Package state manages keyway's local state files so that parallel keyway
+// processes (CI jobs, several terminals) never corrupt each other's data.
+//
+// Files live in ~/.keyway/state unless KEYWAY_STATE_DIR is set. Writers take an
+// exclusive lock on a sibling ".lock" file and replace content atomically, so
+// readers never observe a partially written file.
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultLockTimeout is how long Lock waits for another process by default
+const DefaultLockTimeout = 10 * time.Second
+
+// ErrLockTimeout is returned when a lock could not be acquired in time
+var ErrLockTimeout = errors.New("timed out waiting for state lock")
+
+// lockPollInterval is how often a busy lock is retried
+var lockPollInterval = 20 * time.Millisecond
+
+// Dir returns the state directory, creating it with owner-only permissions
+func Dir() (string, error) {
+	dir := os.Getenv("KEYWAY_STATE_DIR")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".keyway", "state")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns the path of a file in the state directory
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Lock acquires an exclusive inter-process lock for path, waiting up to timeout.
+// The returned function releases it.
+func Lock(path string, timeout time.Duration) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLockTimeout, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// WithLock runs fn while holding the lock for path
+func WithLock(path string, fn func() error) error {
+	unlock, err := Lock(path, DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// WriteFile replaces path atomically: data is written to a temporary file in
+// the same directory, synced, then renamed over path. Callers that read,
+// modify and write should hold the lock (see Update).
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// Update reads path (nil if it does not exist), passes the content to fn and
+// atomically writes the result, all under the lock for path.
+func Update(path string, perm os.FileMode, fn func(current []byte) ([]byte, error)) error {
+	return WithLock(path, func() error {
+		current, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+		return WriteFile(path, next, perm)
+	})
+}
+
+// Append appends data to path under the lock for path
+func Append(path string, data []byte, perm os.FileMode) error {
+	return WithLock(path, func() error {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDir_FromEnv(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	t.Setenv("KEYWAY_STATE_DIR", dir)
+
+	got, err := Dir()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != dir {
+		t.Errorf("expected %s, got %s", dir, got)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("expected directory to be created: %v", err)
+	}
+
+	path, _ := Path("cache.json")
+	if path != filepath.Join(dir, "cache.json") {
+		t.Errorf("unexpected path: %s", path)
+	}
+}
+
+func TestWriteFile_Atomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "file.json")
+
+	if err := WriteFile(path, []byte("one"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteFile(path, []byte("two"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "two" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestUpdate_ConcurrentWritersDoNotLoseUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, 0600, func(current []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(current))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "20" {
+		t.Errorf("expected 20 increments, got %s", data)
+	}
+}
+
+func TestUpdate_ErrorKeepsContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(path, []byte("keep"), 0600)
+
+	err := Update(path, 0600, func([]byte) ([]byte, error) { return nil, errors.New("boom") })
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep" {
+		t.Errorf("content changed: %q", data)
+	}
+}
+
+func TestAppend_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Append(path, []byte("line\n"), 0600); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	if len(data) != 50 {
+		t.Errorf("expected 10 lines, got %q", data)
+	}
+}
+
+func TestLock_Timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+
+	unlock, err := Lock(path, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unlock()
+
+	if _, err := Lock(path, 50*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("expected ErrLockTimeout, got %v", err)
+	}
+}
+
+func TestLock_ReleasedLockCanBeTaken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+
+	unlock, err := Lock(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	unlock, err = Lock(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected lock to be free, got %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,134 @@
+// Package syncbase remembers, per repository and environment, which vault values
+// were last pulled or pushed from this machine, so push can tell local edits
+// apart from changes made by someone else in the meantime.
+package syncbase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/keywaysh/cli/internal/state"
+)
+
+// Snapshot maps key names to value digests. It never contains secret values.
+type Snapshot map[string]string
+
+// Digest returns the digest stored for a value
+func Digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSnapshot digests the values of secrets
+func NewSnapshot(secrets map[string]string) Snapshot {
+	snap := make(Snapshot, len(secrets))
+	for key, value := range secrets {
+		snap[key] = Digest(value)
+	}
+	return snap
+}
+
+// Changes is the three-way comparison of a local file with the vault
+type Changes struct {
+	// Stale keys are unchanged locally but changed or deleted in the vault:
+	// pushing the local value would silently revert someone else's change
+	Stale []string
+	// Conflicts are keys changed both locally and in the vault
+	Conflicts []string
+	// RemoteAdded keys were added to the vault and are not in the local file
+	RemoteAdded []string
+}
+
+// HasChanges returns true if the vault diverged from the snapshot
+func (c *Changes) HasChanges() bool {
+	return len(c.Stale) > 0 || len(c.Conflicts) > 0 || len(c.RemoteAdded) > 0
+}
+
+// Compare classifies local keys against the vault, using base as the common ancestor
+func Compare(base Snapshot, local, remote map[string]string) *Changes {
+	changes := &Changes{}
+
+	for key, localValue := range local {
+		remoteValue, inRemote := remote[key]
+		if inRemote && remoteValue == localValue {
+			continue
+		}
+		baseDigest, inBase := base[key]
+
+		localChanged := !inBase || Digest(localValue) != baseDigest
+		remoteChanged := inBase != inRemote || (inRemote && Digest(remoteValue) != baseDigest)
+
+		switch {
+		case !remoteChanged:
+		case !localChanged:
+			changes.Stale = append(changes.Stale, key)
+		default:
+			changes.Conflicts = append(changes.Conflicts, key)
+		}
+	}
+
+	for key := range remote {
+		if _, inLocal := local[key]; inLocal {
+			continue
+		}
+		if _, inBase := base[key]; !inBase {
+			changes.RemoteAdded = append(changes.RemoteAdded, key)
+		}
+	}
+
+	sort.Strings(changes.Stale)
+	sort.Strings(changes.Conflicts)
+	sort.Strings(changes.RemoteAdded)
+	return changes
+}
+
+// DefaultPath returns the path of the snapshot file
+func DefaultPath() (string, error) {
+	return state.Path("sync-base.json")
+}
+
+// RunPath returns the path of the snapshots of values injected by keyway run.
+// They are kept apart so running a command never moves the push/pull base.
+func RunPath() (string, error) {
+	return state.Path("run-base.json")
+}
+
+type record struct {
+	Keys      Snapshot  `json:"keys"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Load returns the snapshot recorded for repo and env, and false if there is none
+func Load(path, repo, env string) (Snapshot, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var records map[string]record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, false, err
+	}
+	rec, ok := records[repo+"/"+env]
+	return rec.Keys, ok, nil
+}
+
+// Save records the snapshot for repo and env
+func Save(path, repo, env string, snap Snapshot) error {
+	return state.Update(path, 0600, func(current []byte) ([]byte, error) {
+		records := make(map[string]record)
+		if len(current) > 0 {
+			// A corrupt file only loses the snapshots, start over
+			_ = json.Unmarshal(current, &records)
+		}
+		records[repo+"/"+env] = record{Keys: snap, UpdatedAt: time.Now().UTC()}
+		return json.MarshalIndent(records, "", "  ")
+	})
+}
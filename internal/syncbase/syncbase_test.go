@@ -0,0 +1,74 @@
+package syncbase
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	base := NewSnapshot(map[string]string{
+		"SAME":          "1",
+		"LOCAL_EDIT":    "old",
+		"REMOTE_EDIT":   "old",
+		"BOTH_EDIT":     "old",
+		"BOTH_SAME":     "old",
+		"REMOTE_DELETE": "x",
+		"LOCAL_DELETE":  "x",
+	})
+	local := map[string]string{
+		"SAME":          "1",
+		"LOCAL_EDIT":    "mine",
+		"REMOTE_EDIT":   "old",
+		"BOTH_EDIT":     "mine",
+		"BOTH_SAME":     "new",
+		"REMOTE_DELETE": "x",
+		"NEW_LOCAL":     "n",
+		"BOTH_ADDED":    "mine",
+	}
+	remote := map[string]string{
+		"SAME":         "1",
+		"LOCAL_EDIT":   "old",
+		"REMOTE_EDIT":  "theirs",
+		"BOTH_EDIT":    "theirs",
+		"BOTH_SAME":    "new",
+		"LOCAL_DELETE": "x",
+		"BOTH_ADDED":   "theirs",
+		"NEW_REMOTE":   "r",
+	}
+
+	changes := Compare(base, local, remote)
+
+	if got := strings.Join(changes.Stale, ","); got != "REMOTE_DELETE,REMOTE_EDIT" {
+		t.Errorf("Stale = %s", got)
+	}
+	if got := strings.Join(changes.Conflicts, ","); got != "BOTH_ADDED,BOTH_EDIT" {
+		t.Errorf("Conflicts = %s", got)
+	}
+	if got := strings.Join(changes.RemoteAdded, ","); got != "NEW_REMOTE" {
+		t.Errorf("RemoteAdded = %s", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync-base.json")
+
+	if _, ok, err := Load(path, "owner/repo", "production"); ok || err != nil {
+		t.Fatalf("expected no snapshot, got ok=%v err=%v", ok, err)
+	}
+
+	if err := Save(path, "owner/repo", "production", NewSnapshot(map[string]string{"A": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, "owner/repo", "staging", NewSnapshot(map[string]string{"B": "2"})); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, ok, err := Load(path, "owner/repo", "production")
+	if err != nil || !ok {
+		t.Fatalf("expected snapshot, got ok=%v err=%v", ok, err)
+	}
+	if snap["A"] != Digest("1") || len(snap) != 1 {
+		t.Errorf("unexpected snapshot %v", snap)
+	}
+}
@@ -119,6 +119,44 @@ func Select(message string, options []string) (string, error) {
 	return result, err
 }
 
+// MultiSelect prompts for any number of options, with selected checked
+// initially, and returns the checked options in their original order
+func MultiSelect(message string, options []string, selected []string) ([]string, error) {
+	checked := make(map[string]bool, len(selected))
+	for _, opt := range selected {
+		checked[opt] = true
+	}
+	opts := make([]huh.Option[string], len(options))
+	for i, opt := range options {
+		opts[i] = huh.NewOption(opt, opt).Selected(checked[opt])
+	}
+
+	var result []string
+	err := huh.NewMultiSelect[string]().
+		Title(message).
+		Description("space to toggle, a to toggle all, enter to confirm").
+		Options(opts...).
+		Filterable(true).
+		Value(&result).
+		Run()
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the order of options rather than the order they were checked in
+	picked := make(map[string]bool, len(result))
+	for _, opt := range result {
+		picked[opt] = true
+	}
+	ordered := make([]string, 0, len(result))
+	for _, opt := range options {
+		if picked[opt] {
+			ordered = append(ordered, opt)
+		}
+	}
+	return ordered, nil
+}
+
 // Password prompts for password input (masked)
 func Password(message string) (string, error) {
 	var result string
@@ -3,8 +3,9 @@ package version
 import (
 	"encoding/json"
 	"os"
-	"path/filepath"
 	"time"
+
+	"github.com/keywaysh/cli/internal/state"
 )
 
 // CacheData represents the cached version check data
@@ -16,11 +17,7 @@ type CacheData struct {
 
 // getCacheFilePath returns the path to the cache file
 func getCacheFilePath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".config", "keyway", "update-check.json"), nil
+	return state.Path("update-check.json")
 }
 
 // LoadCache loads the cached version check data
@@ -50,15 +47,13 @@ func SaveCache(cache *CacheData) error {
 		return err
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-		return err
-	}
-
 	data, err := json.MarshalIndent(cache, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0600)
+	// Written atomically under lock: the detached update check may race with other invocations
+	return state.WithLock(path, func() error {
+		return state.WriteFile(path, data, 0600)
+	})
 }
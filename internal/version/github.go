@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/keywaysh/cli/internal/background"
 )
 
 const (
@@ -23,10 +25,8 @@ func FetchLatestVersion(ctx context.Context) (string, error) {
 	}
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "keyway-cli")
 
-	client := &http.Client{Timeout: CheckTimeout}
-	resp, err := client.Do(req)
+	resp, err := background.NewClient().Do(req)
 	if err != nil {
 		return "", err
 	}
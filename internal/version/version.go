@@ -5,15 +5,32 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/keywaysh/cli/internal/background"
+	"github.com/keywaysh/cli/internal/config"
 )
 
 const (
 	// CacheDuration is how long to cache version check results
 	CacheDuration = 24 * time.Hour
-	// CheckTimeout is the maximum time to wait for version check
-	CheckTimeout = 2 * time.Second
+	// CheckTimeout is the default maximum time to wait for version check
+	CheckTimeout = background.DefaultTimeout
 )
 
+// CheckTimeouts returns the HTTP timeouts of the version check, those of
+// background requests
+func CheckTimeouts() config.HTTPTimeouts {
+	return background.Timeouts()
+}
+
+// CheckContext returns a context bounded by the version check's total timeout
+func CheckContext() (context.Context, context.CancelFunc) {
+	if total := CheckTimeouts().Total; total > 0 {
+		return context.WithTimeout(context.Background(), total)
+	}
+	return context.WithCancel(context.Background())
+}
+
 // UpdateInfo contains information about an available update
 type UpdateInfo struct {
 	Available      bool
@@ -26,43 +43,75 @@ type UpdateInfo struct {
 // CheckForUpdate checks if a newer version is available
 // Returns nil if no update is available, check is disabled, or on any error
 func CheckForUpdate(ctx context.Context, currentVersion string) *UpdateInfo {
-	if IsUpdateCheckDisabled() {
-		return nil
+	info, stale := CheckCachedUpdate(currentVersion)
+	if !stale {
+		return info
 	}
 
-	// Skip check for dev builds
-	if currentVersion == "dev" || currentVersion == "" {
-		return nil
+	if err := RefreshCache(ctx); err != nil {
+		return nil // Silent failure
 	}
 
-	// Detect install method once
-	method := DetectInstallMethod()
+	info, _ = CheckCachedUpdate(currentVersion)
+	return info
+}
 
-	// Skip check for npx (always fetches latest)
-	if method == InstallMethodNPX {
-		return nil
+// CheckCachedUpdate checks for an update using only the local cache (no network).
+// stale is true when the cache is missing or expired and RefreshCache should run.
+func CheckCachedUpdate(currentVersion string) (info *UpdateInfo, stale bool) {
+	if !shouldCheck(currentVersion) {
+		return nil, false
 	}
 
-	// Check cache first
 	cached, err := LoadCache()
-	if err == nil && cached != nil && time.Since(cached.LastCheck) < CacheDuration {
-		return buildUpdateInfo(currentVersion, cached.LatestVersion, cached.InstallMethod)
+	if err != nil || cached == nil {
+		return nil, true
 	}
 
-	// Fetch from GitHub
-	latest, err := FetchLatestVersion(ctx)
+	info = buildUpdateInfo(currentVersion, cached.LatestVersion, cached.InstallMethod)
+	return info, time.Since(cached.LastCheck) >= CacheDuration
+}
+
+// RefreshCache fetches the latest version from GitHub and stores it in the cache.
+// A failed fetch still bumps LastCheck so offline machines don't retry on every run.
+func RefreshCache(ctx context.Context) error {
+	method := DetectInstallMethod()
+
+	latest, err := fetchLatestVersion(ctx)
 	if err != nil {
-		return nil // Silent failure
+		// Without a cache file, every command would start another check
+		cache := &CacheData{InstallMethod: method}
+		if cached, cacheErr := LoadCache(); cacheErr == nil && cached != nil {
+			cache = cached
+		}
+		cache.LastCheck = time.Now()
+		_ = SaveCache(cache)
+		return err
 	}
 
-	// Save to cache (ignore errors)
-	_ = SaveCache(&CacheData{
+	return SaveCache(&CacheData{
 		LastCheck:     time.Now(),
 		LatestVersion: latest,
 		InstallMethod: method,
 	})
+}
+
+// fetchLatestVersion is FetchLatestVersion, replaced in tests
+var fetchLatestVersion = FetchLatestVersion
+
+// shouldCheck returns false when update checks don't apply to this build
+func shouldCheck(currentVersion string) bool {
+	if IsUpdateCheckDisabled() || !background.Enabled() {
+		return false
+	}
+
+	// Skip check for dev builds
+	if currentVersion == "dev" || currentVersion == "" {
+		return false
+	}
 
-	return buildUpdateInfo(currentVersion, latest, method)
+	// Skip check for npx (always fetches latest)
+	return DetectInstallMethod() != InstallMethodNPX
 }
 
 func buildUpdateInfo(current, latest string, method InstallMethod) *UpdateInfo {
@@ -1,6 +1,11 @@
 package version
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
 
 func TestIsNewerVersion(t *testing.T) {
 	tests := []struct {
@@ -111,3 +116,94 @@ func TestGetUpdateCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckCachedUpdate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KEYWAY_DISABLE_UPDATE_CHECK", "")
+
+	// No cache yet
+	info, stale := CheckCachedUpdate("1.0.0")
+	if info != nil || !stale {
+		t.Errorf("expected (nil, stale) without cache, got (%v, %v)", info, stale)
+	}
+
+	// Fresh cache with a newer version
+	if err := SaveCache(&CacheData{LastCheck: time.Now(), LatestVersion: "v1.1.0", InstallMethod: InstallMethodBinary}); err != nil {
+		t.Fatal(err)
+	}
+	info, stale = CheckCachedUpdate("1.0.0")
+	if info == nil || !info.Available || stale {
+		t.Errorf("expected fresh update info, got (%v, %v)", info, stale)
+	}
+
+	// Expired cache still reports the known version but asks for a refresh
+	if err := SaveCache(&CacheData{LastCheck: time.Now().Add(-2 * CacheDuration), LatestVersion: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	info, stale = CheckCachedUpdate("1.0.0")
+	if info == nil || !stale {
+		t.Errorf("expected stale update info, got (%v, %v)", info, stale)
+	}
+}
+
+func TestCheckCachedUpdate_Skipped(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if info, stale := CheckCachedUpdate("dev"); info != nil || stale {
+		t.Errorf("dev builds should skip the check, got (%v, %v)", info, stale)
+	}
+
+	t.Setenv("KEYWAY_DISABLE_UPDATE_CHECK", "1")
+	if info, stale := CheckCachedUpdate("1.0.0"); info != nil || stale {
+		t.Errorf("disabled check should be skipped, got (%v, %v)", info, stale)
+	}
+}
+
+func TestCheckTimeouts(t *testing.T) {
+	t.Setenv("KEYWAY_HTTP_TIMEOUT", "")
+	if got := CheckTimeouts(); got.Total != CheckTimeout || got.Connect != time.Second {
+		t.Errorf("unexpected default timeouts %+v", got)
+	}
+
+	t.Setenv("KEYWAY_HTTP_TIMEOUT", "10s")
+	if got := CheckTimeouts(); got.Total != 10*time.Second {
+		t.Errorf("expected KEYWAY_HTTP_TIMEOUT to apply, got %+v", got)
+	}
+
+	t.Setenv("KEYWAY_HTTP_TIMEOUT", "0")
+	ctx, cancel := CheckContext()
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("a disabled total timeout should not set a deadline")
+	}
+}
+
+func TestRefreshCache_FailedFetchRecordsCheck(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KEYWAY_STATE_DIR", "")
+	t.Setenv("KEYWAY_DISABLE_UPDATE_CHECK", "")
+	original := fetchLatestVersion
+	defer func() { fetchLatestVersion = original }()
+	fetchLatestVersion = func(ctx context.Context) (string, error) { return "", errors.New("offline") }
+
+	// First check ever, offline: the attempt is still recorded
+	if err := RefreshCache(context.Background()); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+	cached, err := LoadCache()
+	if err != nil || cached == nil || time.Since(cached.LastCheck) > time.Minute {
+		t.Fatalf("expected a recent LastCheck, got %+v, %v", cached, err)
+	}
+	if _, stale := CheckCachedUpdate("1.0.0"); stale {
+		t.Error("expected no new check right after a failed one")
+	}
+
+	// A known version is kept
+	if err := SaveCache(&CacheData{LastCheck: time.Now().Add(-2 * CacheDuration), LatestVersion: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = RefreshCache(context.Background())
+	if cached, _ := LoadCache(); cached.LatestVersion != "v1.1.0" || time.Since(cached.LastCheck) > time.Minute {
+		t.Errorf("expected the known version kept with a new LastCheck, got %+v", cached)
+	}
+}